@@ -12,4 +12,5 @@ var (
 	customerService              = service.ServiceGroupApp.ExampleServiceGroup.CustomerService
 	fileUploadAndDownloadService = service.ServiceGroupApp.ExampleServiceGroup.FileUploadAndDownloadService
 	attachmentCategoryService    = service.ServiceGroupApp.ExampleServiceGroup.AttachmentCategoryService
+	fieldRuleService             = service.ServiceGroupApp.SystemServiceGroup.AuthorityFieldRuleService
 )
@@ -19,7 +19,7 @@ type AttachmentCategoryApi struct{}
 // @Success   200   {object}  response.Response{data=example.ExaAttachmentCategory,msg=string}  "媒体库分类列表"
 // @Router    /attachmentCategory/getCategoryList [get]
 func (a *AttachmentCategoryApi) GetCategoryList(c *gin.Context) {
-	res, err := attachmentCategoryService.GetCategoryList()
+	res, err := attachmentCategoryService.GetCategoryList(c.Request.Context())
 	if err != nil {
 		global.GVA_LOG.Error("获取分类列表失败!", zap.Error(err))
 		response.FailWithMessage("获取分类列表失败", c)
@@ -44,7 +44,7 @@ func (a *AttachmentCategoryApi) AddCategory(c *gin.Context) {
 		return
 	}
 
-	if err := attachmentCategoryService.AddCategory(&req); err != nil {
+	if err := attachmentCategoryService.AddCategory(c.Request.Context(), &req); err != nil {
 		global.GVA_LOG.Error("创建/更新失败!", zap.Error(err))
 		response.FailWithMessage("创建/更新失败："+err.Error(), c)
 		return
@@ -73,7 +73,7 @@ func (a *AttachmentCategoryApi) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := attachmentCategoryService.DeleteCategory(&req.ID); err != nil {
+	if err := attachmentCategoryService.DeleteCategory(c.Request.Context(), &req.ID); err != nil {
 		response.FailWithMessage("删除失败", c)
 		return
 	}
@@ -36,7 +36,8 @@ func (e *CustomerApi) CreateExaCustomer(c *gin.Context) {
 	}
 	customer.SysUserID = utils.GetUserID(c)
 	customer.SysUserAuthorityID = utils.GetUserAuthorityId(c)
-	err = customerService.CreateExaCustomer(customer)
+	customer.CreatedBy = utils.GetUserID(c)
+	err = customerService.CreateExaCustomer(c.Request.Context(), customer)
 	if err != nil {
 		global.GVA_LOG.Error("创建失败!", zap.Error(err))
 		response.FailWithMessage("创建失败", c)
@@ -66,7 +67,7 @@ func (e *CustomerApi) DeleteExaCustomer(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = customerService.DeleteExaCustomer(customer)
+	err = customerService.DeleteExaCustomer(c.Request.Context(), customer)
 	if err != nil {
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败", c)
@@ -101,7 +102,7 @@ func (e *CustomerApi) UpdateExaCustomer(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = customerService.UpdateExaCustomer(&customer)
+	err = customerService.UpdateExaCustomer(c.Request.Context(), utils.GetUserAuthorityId(c), &customer)
 	if err != nil {
 		global.GVA_LOG.Error("更新失败!", zap.Error(err))
 		response.FailWithMessage("更新失败", c)
@@ -131,7 +132,10 @@ func (e *CustomerApi) GetExaCustomer(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	data, err := customerService.GetExaCustomer(customer.ID)
+	data, err := customerService.GetExaCustomer(c.Request.Context(), customer.ID)
+	if err == nil {
+		err = fieldRuleService.Apply(utils.GetUserAuthorityId(c), &data)
+	}
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
@@ -161,7 +165,11 @@ func (e *CustomerApi) GetExaCustomerList(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	customerList, total, err := customerService.GetCustomerInfoList(utils.GetUserAuthorityId(c), pageInfo)
+	authorityID := utils.GetUserAuthorityId(c)
+	customerList, total, err := customerService.GetCustomerInfoList(c.Request.Context(), pageInfo)
+	if err == nil {
+		err = fieldRuleService.Apply(authorityID, customerList)
+	}
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败"+err.Error(), c)
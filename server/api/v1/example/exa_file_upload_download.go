@@ -32,7 +32,7 @@ func (b *FileUploadAndDownloadApi) UploadFile(c *gin.Context) {
 		response.FailWithMessage("接收文件失败", c)
 		return
 	}
-	file, err = fileUploadAndDownloadService.UploadFile(header, noSave, classId) // 文件上传后拿到文件路径
+	file, err = fileUploadAndDownloadService.UploadFile(c.Request.Context(), header, noSave, classId) // 文件上传后拿到文件路径
 	if err != nil {
 		global.GVA_LOG.Error("上传文件失败!", zap.Error(err))
 		response.FailWithMessage("上传文件失败", c)
@@ -49,7 +49,7 @@ func (b *FileUploadAndDownloadApi) EditFileName(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = fileUploadAndDownloadService.EditFileName(file)
+	err = fileUploadAndDownloadService.EditFileName(c.Request.Context(), file)
 	if err != nil {
 		global.GVA_LOG.Error("编辑失败!", zap.Error(err))
 		response.FailWithMessage("编辑失败", c)
@@ -73,7 +73,7 @@ func (b *FileUploadAndDownloadApi) DeleteFile(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	if err := fileUploadAndDownloadService.DeleteFile(file); err != nil {
+	if err := fileUploadAndDownloadService.DeleteFile(c.Request.Context(), file); err != nil {
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败", c)
 		return
@@ -97,7 +97,7 @@ func (b *FileUploadAndDownloadApi) GetFileList(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	list, total, err := fileUploadAndDownloadService.GetFileRecordInfoList(pageInfo)
+	list, total, err := fileUploadAndDownloadService.GetFileRecordInfoList(c.Request.Context(), pageInfo)
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
@@ -126,7 +126,7 @@ func (b *FileUploadAndDownloadApi) ImportURL(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	if err := fileUploadAndDownloadService.ImportURL(&file); err != nil {
+	if err := fileUploadAndDownloadService.ImportURL(c.Request.Context(), &file); err != nil {
 		global.GVA_LOG.Error("导入URL失败!", zap.Error(err))
 		response.FailWithMessage("导入URL失败", c)
 		return
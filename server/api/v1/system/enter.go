@@ -22,6 +22,11 @@ type ApiGroup struct {
 	AutoCodeHistoryApi
 	AutoCodeTemplateApi
 	SysParamsApi
+	AuditLogApi
+	LoginLogApi
+	DeptApi
+	PermissionChangeApi
+	TenantApi
 }
 
 var (
@@ -37,6 +42,18 @@ var (
 	authorityBtnService     = service.ServiceGroupApp.SystemServiceGroup.AuthorityBtnService
 	systemConfigService     = service.ServiceGroupApp.SystemServiceGroup.SystemConfigService
 	sysParamsService        = service.ServiceGroupApp.SystemServiceGroup.SysParamsService
+	oidcService             = service.ServiceGroupApp.SystemServiceGroup.OidcService
+	sessionService          = service.ServiceGroupApp.SystemServiceGroup.SessionService
+	lockoutService          = service.ServiceGroupApp.SystemServiceGroup.LockoutService
+	auditLogService         = service.ServiceGroupApp.SystemServiceGroup.AuditLogService
+	loginLogService         = service.ServiceGroupApp.SystemServiceGroup.LoginLogService
+	apiKeyService           = service.ServiceGroupApp.SystemServiceGroup.ApiKeyService
+	jwtKeyService           = service.ServiceGroupApp.SystemServiceGroup.JwtKeyService
+	passkeyService          = service.ServiceGroupApp.SystemServiceGroup.PasskeyService
+	fieldRuleService        = service.ServiceGroupApp.SystemServiceGroup.AuthorityFieldRuleService
+	deptService             = service.ServiceGroupApp.SystemServiceGroup.DeptService
+	permissionChangeService = service.ServiceGroupApp.SystemServiceGroup.PermissionChangeService
+	tenantService           = service.ServiceGroupApp.SystemServiceGroup.TenantService
 	operationRecordService  = service.ServiceGroupApp.SystemServiceGroup.OperationRecordService
 	dictionaryDetailService = service.ServiceGroupApp.SystemServiceGroup.DictionaryDetailService
 	autoCodeService         = service.ServiceGroupApp.SystemServiceGroup.AutoCodeService
@@ -271,7 +271,7 @@ func (s *SystemApiApi) UpdateApi(c *gin.Context) {
 // @Router    /api/getAllApis [post]
 func (s *SystemApiApi) GetAllApis(c *gin.Context) {
 	authorityID := utils.GetUserAuthorityId(c)
-	apis, err := apiService.GetAllApis(authorityID)
+	apis, err := apiService.GetAllApis(c.Request.Context(), authorityID)
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
@@ -0,0 +1,157 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateApiKey
+// @Tags      SysUser
+// @Summary   创建自身的API密钥 密钥明文仅返回一次
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.CreateApiKey                                          true  "名称, 接口范围, 有效天数"
+// @Success   200   {object}  response.Response{data=systemRes.CreateApiKeyResponse,msg=string}  "创建API密钥"
+// @Router    /user/createApiKey [post]
+func (b *BaseApi) CreateApiKey(c *gin.Context) {
+	if _, ok := c.Get("api_key_id"); ok {
+		response.FailWithMessage("不允许使用API密钥创建API密钥", c)
+		return
+	}
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能创建API密钥", c)
+		return
+	}
+	var req systemReq.CreateApiKey
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.CreateApiKeyVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	apiKey, key, err := apiKeyService.CreateApiKey(utils.GetUserID(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.CreateApiKeyResponse{ApiKey: apiKey, Key: key}, "创建成功，请妥善保存密钥，关闭后将无法再次查看", c)
+}
+
+// GetApiKeyList
+// @Tags      SysUser
+// @Summary   获取自身的API密钥
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysApiKey,msg=string}  "获取自身API密钥"
+// @Router    /user/getApiKeyList [get]
+func (b *BaseApi) GetApiKeyList(c *gin.Context) {
+	list, err := apiKeyService.GetApiKeyList(utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// DeleteApiKey
+// @Tags      SysUser
+// @Summary   删除自身的API密钥
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "API密钥ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除自身API密钥"
+// @Router    /user/deleteApiKey [delete]
+func (b *BaseApi) DeleteApiKey(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = apiKeyService.DeleteApiKey(c.Request.Context(), utils.GetUserID(c), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// GetUserApiKeyList
+// @Tags      SysUser
+// @Summary   获取用户的API密钥
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                                        true  "用户ID"
+// @Success   200   {object}  response.Response{data=[]system.SysApiKey,msg=string}  "获取用户API密钥"
+// @Router    /user/getUserApiKeyList [post]
+func (b *BaseApi) GetUserApiKeyList(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := apiKeyService.GetUserApiKeyList(c.Request.Context(), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// DeleteUserApiKey
+// @Tags      SysUser
+// @Summary   删除任意用户的API密钥
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "API密钥ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除用户API密钥"
+// @Router    /user/deleteUserApiKey [delete]
+func (b *BaseApi) DeleteUserApiKey(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = apiKeyService.DeleteApiKey(c.Request.Context(), 0, req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
@@ -2,10 +2,13 @@ package system
 
 import (
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/fieldrule"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -40,7 +43,7 @@ func (a *AuthorityApi) CreateAuthority(c *gin.Context) {
 		authority.ParentId = utils.Pointer(utils.GetUserAuthorityId(c))
 	}
 
-	if authBack, err = authorityService.CreateAuthority(authority); err != nil {
+	if authBack, err = authorityService.CreateAuthority(c.Request.Context(), authority); err != nil {
 		global.GVA_LOG.Error("创建失败!", zap.Error(err))
 		response.FailWithMessage("创建失败"+err.Error(), c)
 		return
@@ -81,7 +84,7 @@ func (a *AuthorityApi) CopyAuthority(c *gin.Context) {
 		return
 	}
 	adminAuthorityID := utils.GetUserAuthorityId(c)
-	authBack, err := authorityService.CopyAuthority(adminAuthorityID, copyInfo)
+	authBack, err := authorityService.CopyAuthority(c.Request.Context(), adminAuthorityID, copyInfo)
 	if err != nil {
 		global.GVA_LOG.Error("拷贝失败!", zap.Error(err))
 		response.FailWithMessage("拷贝失败"+err.Error(), c)
@@ -147,6 +150,12 @@ func (a *AuthorityApi) UpdateAuthority(c *gin.Context) {
 		response.FailWithMessage("更新失败"+err.Error(), c)
 		return
 	}
+	err = casbinService.FreshCasbin()
+	if err != nil {
+		global.GVA_LOG.Error("更新成功，权限刷新失败。", zap.Error(err))
+		response.FailWithMessage("更新成功，权限刷新失败。"+err.Error(), c)
+		return
+	}
 	response.OkWithDetailed(systemRes.SysAuthorityResponse{Authority: authority}, "更新成功", c)
 }
 
@@ -191,6 +200,9 @@ func (a *AuthorityApi) SetDataAuthority(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if submitPermissionChange(c, auth) {
+		return
+	}
 	adminAuthorityID := utils.GetUserAuthorityId(c)
 	err = authorityService.SetDataAuthority(adminAuthorityID, auth)
 	if err != nil {
@@ -200,3 +212,167 @@ func (a *AuthorityApi) SetDataAuthority(c *gin.Context) {
 	}
 	response.OkWithMessage("设置成功", c)
 }
+
+// SetAuthorityTwoFactor
+// @Tags      Authority
+// @Summary   设置角色是否强制两步验证
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.SetAuthorityTwoFactor  true  "角色ID, 是否强制两步验证"
+// @Success   200   {object}  response.Response{msg=string}    "设置角色是否强制两步验证"
+// @Router    /authority/setTwoFactor [post]
+func (a *AuthorityApi) SetAuthorityTwoFactor(c *gin.Context) {
+	var req systemReq.SetAuthorityTwoFactor
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.AuthorityIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	adminAuthorityID := utils.GetUserAuthorityId(c)
+	err = authorityService.SetAuthorityTwoFactor(adminAuthorityID, req.AuthorityId, req.RequireTwoFactor)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// SetAuthorityMaxSessions
+// @Tags      Authority
+// @Summary   设置角色最大同时在线会话数
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.SetAuthorityMaxSessions  true  "角色ID, 最大会话数"
+// @Success   200   {object}  response.Response{msg=string}      "设置角色最大同时在线会话数"
+// @Router    /authority/setMaxSessions [post]
+func (a *AuthorityApi) SetAuthorityMaxSessions(c *gin.Context) {
+	var req systemReq.SetAuthorityMaxSessions
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.AuthorityIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	adminAuthorityID := utils.GetUserAuthorityId(c)
+	err = authorityService.SetAuthorityMaxSessions(adminAuthorityID, req.AuthorityId, req.MaxSessions)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// SetAuthorityDataScope
+// @Tags      Authority
+// @Summary   设置角色的数据范围
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.SetAuthorityDataScope  true  "角色ID, 数据范围, 自定义条件"
+// @Success   200   {object}  response.Response{msg=string}    "设置角色的数据范围"
+// @Router    /authority/setDataScope [post]
+func (a *AuthorityApi) SetAuthorityDataScope(c *gin.Context) {
+	var req systemReq.SetAuthorityDataScope
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.AuthorityIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	adminAuthorityID := utils.GetUserAuthorityId(c)
+	err = authorityService.SetAuthorityDataScope(adminAuthorityID, req)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// SetAuthorityFieldRules
+// @Tags      Authority
+// @Summary   设置角色的字段规则
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.SetAuthorityFieldRules  true  "角色ID, 字段规则"
+// @Success   200   {object}  response.Response{msg=string}     "设置角色的字段规则"
+// @Router    /authority/setFieldRules [post]
+func (a *AuthorityApi) SetAuthorityFieldRules(c *gin.Context) {
+	var req systemReq.SetAuthorityFieldRules
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.AuthorityIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = fieldRuleService.SetFieldRules(utils.GetUserAuthorityId(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// GetAuthorityFieldRules
+// @Tags      Authority
+// @Summary   获取角色的字段规则
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetAuthorityId                                                  true  "角色ID"
+// @Success   200   {object}  response.Response{data=[]system.SysAuthorityFieldRule,msg=string}  "获取角色的字段规则"
+// @Router    /authority/getFieldRules [post]
+func (a *AuthorityApi) GetAuthorityFieldRules(c *gin.Context) {
+	var req request.GetAuthorityId
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.AuthorityIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := fieldRuleService.GetFieldRules(req.AuthorityId)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// GetFieldRuleModels
+// @Tags      Authority
+// @Summary   获取支持字段规则的模型及字段
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=map[string][]string,msg=string}  "获取支持字段规则的模型及字段"
+// @Router    /authority/getFieldRuleModels [get]
+func (a *AuthorityApi) GetFieldRuleModels(c *gin.Context) {
+	response.OkWithDetailed(fieldrule.Models(), "获取成功", c)
+}
@@ -1,10 +1,16 @@
 package system
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -33,8 +39,11 @@ func (cas *CasbinApi) UpdateCasbin(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if submitPermissionChange(c, cmr) {
+		return
+	}
 	adminAuthorityID := utils.GetUserAuthorityId(c)
-	err = casbinService.UpdateCasbin(adminAuthorityID, cmr.AuthorityId, cmr.CasbinInfos)
+	err = casbinService.UpdateCasbin(c.Request.Context(), adminAuthorityID, cmr.AuthorityId, cmr.CasbinInfos)
 	if err != nil {
 		global.GVA_LOG.Error("更新失败!", zap.Error(err))
 		response.FailWithMessage("更新失败", c)
@@ -64,6 +73,144 @@ func (cas *CasbinApi) GetPolicyPathByAuthorityId(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	paths := casbinService.GetPolicyPathByAuthorityId(casbin.AuthorityId)
+	paths := casbinService.GetPolicyPathByAuthorityId(c.Request.Context(), casbin.AuthorityId)
 	response.OkWithDetailed(systemRes.PolicyPathResponse{Paths: paths}, "获取成功", c)
 }
+
+// ExplainPermission
+// @Tags      Casbin
+// @Summary   模拟权限判定 说明用户或角色能否访问指定api及原因
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.CasbinExplain                                               true  "用户ID或角色ID, 路径, 方法"
+// @Success   200   {object}  response.Response{data=systemRes.CasbinExplainResponse,msg=string}  "返回判定结果,命中的策略,api登记情况及角色的菜单与按钮"
+// @Router    /casbin/explainPermission [post]
+func (cas *CasbinApi) ExplainPermission(c *gin.Context) {
+	var req request.CasbinExplain
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.CasbinExplainVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	res, err := casbinService.ExplainPermission(c.Request.Context(), utils.GetUserAuthorityId(c), req)
+	if err != nil {
+		global.GVA_LOG.Error("查询失败!", zap.Error(err))
+		response.FailWithMessage("查询失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(res, "查询成功", c)
+}
+
+// maxPolicyFileSize 策略文件大小上限
+const maxPolicyFileSize = 10 << 20
+
+// ExportPolicy
+// @Tags      Casbin
+// @Summary   导出全部p策略及api为策略文件
+// @Security  ApiKeyAuth
+// @Produce   application/octet-stream
+// @Param     format  query     string  false  "文件格式 json或yaml 默认json"
+// @Success   200     {file}    file    "策略文件"
+// @Router    /casbin/exportPolicy [get]
+func (cas *CasbinApi) ExportPolicy(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "yaml" {
+		response.FailWithMessage("文件格式仅支持json或yaml", c)
+		return
+	}
+	file, err := casbinService.ExportPolicy(c.Request.Context())
+	if err == nil {
+		var data []byte
+		if data, err = systemService.MarshalPolicyFile(file, format); err == nil {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=casbin_policy_%s.%s", file.ExportedAt.Format("20060102150405"), format))
+			c.Header("success", "true")
+			c.Data(http.StatusOK, "application/octet-stream", data)
+			return
+		}
+	}
+	global.GVA_LOG.Error("导出失败!", zap.Error(err))
+	response.FailWithMessage("导出失败", c)
+}
+
+// DiffPolicy
+// @Tags      Casbin
+// @Summary   比较上传的策略文件与当前环境的差异
+// @Security  ApiKeyAuth
+// @accept    multipart/form-data
+// @Produce   application/json
+// @Param     file  formData  file                                                           true  "策略文件 json或yaml"
+// @Success   200   {object}  response.Response{data=systemRes.CasbinPolicyDiff,msg=string}  "返回api及策略的差异"
+// @Router    /casbin/diffPolicy [post]
+func (cas *CasbinApi) DiffPolicy(c *gin.Context) {
+	file, err := readPolicyFile(c)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	diff, err := casbinService.DiffPolicy(c.Request.Context(), file)
+	if err != nil {
+		global.GVA_LOG.Error("比较失败!", zap.Error(err))
+		response.FailWithMessage("比较失败", c)
+		return
+	}
+	response.OkWithDetailed(diff, "比较成功", c)
+}
+
+// ImportPolicy
+// @Tags      Casbin
+// @Summary   导入策略文件 同步api及文件中角色的策略
+// @Security  ApiKeyAuth
+// @accept    multipart/form-data
+// @Produce   application/json
+// @Param     file    formData  file                                                           true   "策略文件 json或yaml"
+// @Param     dryRun  formData  bool                                                           false  "为true时仅预演 不修改数据"
+// @Param     prune   formData  bool                                                           false  "为true时删除文件中不存在的api及策略 默认仅新增及更新"
+// @Success   200     {object}  response.Response{data=systemRes.CasbinPolicyDiff,msg=string}  "返回应用的差异"
+// @Router    /casbin/importPolicy [post]
+func (cas *CasbinApi) ImportPolicy(c *gin.Context) {
+	file, err := readPolicyFile(c)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	dryRun := c.PostForm("dryRun") == "true"
+	prune := c.PostForm("prune") == "true"
+	diff, err := casbinService.ImportPolicy(c.Request.Context(), utils.GetUserAuthorityId(c), utils.GetUserID(c), file, dryRun, prune, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("导入失败!", zap.Error(err))
+		response.FailWithMessage("导入失败: "+err.Error(), c)
+		return
+	}
+	if dryRun {
+		response.OkWithDetailed(diff, "预演成功", c)
+		return
+	}
+	response.OkWithDetailed(diff, "导入成功", c)
+}
+
+// readPolicyFile 读取并解析上传的策略文件
+func readPolicyFile(c *gin.Context) (file request.CasbinPolicyFile, err error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return file, errors.New("文件获取失败")
+	}
+	if header.Size > maxPolicyFileSize {
+		return file, errors.New("策略文件过大")
+	}
+	f, err := header.Open()
+	if err != nil {
+		return file, errors.New("文件读取失败")
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxPolicyFileSize))
+	if err != nil {
+		return file, errors.New("文件读取失败")
+	}
+	return systemService.UnmarshalPolicyFile(header.Filename, data)
+}
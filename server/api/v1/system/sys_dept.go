@@ -0,0 +1,244 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DeptApi struct{}
+
+// CreateDept 创建部门
+// @Tags Dept
+// @Summary 创建部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysDept true "上级部门ID, 部门名称, 排序, 负责人ID"
+// @Success 200 {object} response.Response{msg=string} "创建成功"
+// @Router /dept/createDept [post]
+func (deptApi *DeptApi) CreateDept(c *gin.Context) {
+	var dept system.SysDept
+	err := c.ShouldBindJSON(&dept)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(dept, utils.DeptVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = deptService.CreateDept(c.Request.Context(), dept)
+	if err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("创建成功", c)
+}
+
+// UpdateDept 更新部门
+// @Tags Dept
+// @Summary 更新部门名称、排序及负责人
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysDept true "部门ID, 部门名称, 排序, 负责人ID"
+// @Success 200 {object} response.Response{msg=string} "更新成功"
+// @Router /dept/updateDept [put]
+func (deptApi *DeptApi) UpdateDept(c *gin.Context) {
+	var dept system.SysDept
+	err := c.ShouldBindJSON(&dept)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(dept.GVA_MODEL, utils.IdVerify)
+	if err == nil {
+		err = utils.Verify(dept, utils.DeptVerify)
+	}
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = deptService.UpdateDept(c.Request.Context(), dept)
+	if err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteDept 删除部门
+// @Tags Dept
+// @Summary 删除部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "部门ID"
+// @Success 200 {object} response.Response{msg=string} "删除成功"
+// @Router /dept/deleteDept [post]
+func (deptApi *DeptApi) DeleteDept(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = deptService.DeleteDept(c.Request.Context(), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// MoveDept 调整上级部门
+// @Tags Dept
+// @Summary 调整部门的上级部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.MoveDept true "部门ID, 新的上级部门ID"
+// @Success 200 {object} response.Response{msg=string} "调整成功"
+// @Router /dept/moveDept [post]
+func (deptApi *DeptApi) MoveDept(c *gin.Context) {
+	var req systemReq.MoveDept
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = deptService.MoveDept(c.Request.Context(), req)
+	if err != nil {
+		global.GVA_LOG.Error("调整失败!", zap.Error(err))
+		response.FailWithMessage("调整失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("调整成功", c)
+}
+
+// MergeDept 合并部门
+// @Tags Dept
+// @Summary 将部门合并到另一部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.MergeDept true "被合并的部门ID, 合并到的部门ID"
+// @Success 200 {object} response.Response{msg=string} "合并成功"
+// @Router /dept/mergeDept [post]
+func (deptApi *DeptApi) MergeDept(c *gin.Context) {
+	var req systemReq.MergeDept
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.MergeDeptVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = deptService.MergeDept(c.Request.Context(), req)
+	if err != nil {
+		global.GVA_LOG.Error("合并失败!", zap.Error(err))
+		response.FailWithMessage("合并失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("合并成功", c)
+}
+
+// GetDeptTree 获取部门树
+// @Tags Dept
+// @Summary 获取部门树
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Success 200 {object} response.Response{data=[]system.SysDept,msg=string} "获取成功"
+// @Router /dept/getDeptTree [get]
+func (deptApi *DeptApi) GetDeptTree(c *gin.Context) {
+	tree, err := deptService.GetDeptTree(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(tree, "获取成功", c)
+}
+
+// SetUserDepts 设置用户所属部门
+// @Tags Dept
+// @Summary 设置用户的主部门及次部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.SetUserDepts true "用户ID, 主部门ID, 次部门ID"
+// @Success 200 {object} response.Response{msg=string} "设置成功"
+// @Router /dept/setUserDepts [post]
+func (deptApi *DeptApi) SetUserDepts(c *gin.Context) {
+	var req systemReq.SetUserDepts
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = deptService.SetUserDepts(c.Request.Context(), req)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// GetUserDepts 获取用户所属部门
+// @Tags Dept
+// @Summary 获取用户所属部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "用户ID"
+// @Success 200 {object} response.Response{data=[]system.SysUserDept,msg=string} "获取成功"
+// @Router /dept/getUserDepts [post]
+func (deptApi *DeptApi) GetUserDepts(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := deptService.GetUserDepts(c.Request.Context(), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
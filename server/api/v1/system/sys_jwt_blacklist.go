@@ -20,6 +20,10 @@ type JwtApi struct{}
 // @Success   200  {object}  response.Response{msg=string}  "jwt加入黑名单"
 // @Router    /jwt/jsonInBlacklist [post]
 func (j *JwtApi) JsonInBlacklist(c *gin.Context) {
+	if _, ok := c.Get("api_key_id"); ok {
+		response.FailWithMessage("API密钥调用无需登出", c)
+		return
+	}
 	token := utils.GetToken(c)
 	jwt := system.JwtBlacklist{Jwt: token}
 	err := jwtService.JsonInBlacklist(jwt)
@@ -28,6 +32,14 @@ func (j *JwtApi) JsonInBlacklist(c *gin.Context) {
 		response.FailWithMessage("jwt作废失败", c)
 		return
 	}
+	// 注销当前会话 其刷新令牌一并吊销
+	if claims := utils.GetUserInfo(c); claims != nil {
+		if err = sessionService.RevokeSession(claims.SessionID); err != nil {
+			global.GVA_LOG.Error("注销会话失败!", zap.Error(err))
+			response.FailWithMessage("jwt作废失败", c)
+			return
+		}
+	}
 	utils.ClearToken(c)
 	response.OkWithMessage("jwt作废成功", c)
 }
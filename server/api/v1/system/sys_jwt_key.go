@@ -0,0 +1,57 @@
+package system
+
+import (
+	"net/http"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Jwks
+// @Tags     Base
+// @Summary  以JWKS格式发布jwt验签公钥 供其他服务验证令牌
+// @Produce  application/json
+// @Success  200  {object}  map[string]interface{}  "{"keys":[...]}"
+// @Router   /base/jwks [get]
+func (b *BaseApi) Jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
+
+// RotateKey
+// @Tags      Jwt
+// @Summary   立即轮换jwt签名密钥
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=system.SysJwtKey,msg=string}  "轮换jwt签名密钥"
+// @Router    /jwt/rotateKey [post]
+func (j *JwtApi) RotateKey(c *gin.Context) {
+	key, err := jwtKeyService.RotateKey(utils.GetUserID(c), c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("轮换失败!", zap.Error(err))
+		response.FailWithMessage("轮换失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(key, "轮换成功", c)
+}
+
+// GetKeyList
+// @Tags      Jwt
+// @Summary   获取可用于验签的jwt签名密钥
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysJwtKey,msg=string}  "获取jwt签名密钥"
+// @Router    /jwt/getKeyList [get]
+func (j *JwtApi) GetKeyList(c *gin.Context) {
+	list, err := jwtKeyService.GetJwtKeyList()
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
@@ -0,0 +1,75 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetLockoutList
+// @Tags      SysUser
+// @Summary   分页获取因连续登录失败被锁定的账号
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.PageInfo                                        true  "页码, 每页大小, 用户名关键字"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取被锁定的账号,返回包括列表,总数,页码,每页数量"
+// @Router    /user/getLockoutList [post]
+func (b *BaseApi) GetLockoutList(c *gin.Context) {
+	var pageInfo request.PageInfo
+	err := c.ShouldBindJSON(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(pageInfo, utils.PageInfoVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := lockoutService.GetLockoutList(c.Request.Context(), pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// UnlockUser
+// @Tags      SysUser
+// @Summary   解锁因连续登录失败被锁定的账号
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "用户ID"
+// @Success   200   {object}  response.Response{msg=string}  "解锁账号"
+// @Router    /user/unlockUser [post]
+func (b *BaseApi) UnlockUser(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = lockoutService.Unlock(c.Request.Context(), req.Uint(), utils.GetUserID(c), c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("解锁失败!", zap.Error(err))
+		response.FailWithMessage("解锁失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("解锁成功", c)
+}
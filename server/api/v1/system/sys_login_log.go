@@ -0,0 +1,72 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LoginLogApi struct{}
+
+// GetLoginLogList
+// @Tags      SysLoginLog
+// @Summary   分页获取登录日志
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     systemReq.SysLoginLogSearch                             true  "页码, 每页大小, 搜索条件"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取登录日志,返回包括列表,总数,页码,每页数量"
+// @Router    /loginLog/getLoginLogList [get]
+func (a *LoginLogApi) GetLoginLogList(c *gin.Context) {
+	var pageInfo systemReq.SysLoginLogSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := loginLogService.GetLoginLogList(c.Request.Context(), pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// GetRecentLogins
+// @Tags      SysLoginLog
+// @Summary   获取自身最近的登录记录
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysLoginLog,msg=string}  "获取自身最近的登录记录"
+// @Router    /loginLog/getRecentLogins [get]
+func (a *LoginLogApi) GetRecentLogins(c *gin.Context) {
+	list, err := loginLogService.GetRecentLogins(utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// recordLogin 记录登录日志 成功时reason为空
+func recordLogin(c *gin.Context, userID uint, username string, reason string) {
+	loginLogService.Record(system.SysLoginLog{
+		UserID:    userID,
+		Username:  username,
+		Success:   reason == "",
+		Reason:    reason,
+		Ip:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
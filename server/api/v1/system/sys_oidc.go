@@ -0,0 +1,62 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OidcAuthUrl
+// @Tags     Base
+// @Summary  获取OIDC单点登录授权地址
+// @Produce   application/json
+// @Success  200   {object}  response.Response{data=systemRes.OidcAuthUrlResponse,msg=string}  "返回授权地址与state"
+// @Router   /base/oidcAuthUrl [get]
+func (b *BaseApi) OidcAuthUrl(c *gin.Context) {
+	url, state, err := oidcService.AuthUrl(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取OIDC授权地址失败!", zap.Error(err))
+		response.FailWithMessage("获取授权地址失败", c)
+		return
+	}
+	response.OkWithDetailed(systemRes.OidcAuthUrlResponse{Url: url, State: state}, "获取成功", c)
+}
+
+// OidcLogin
+// @Tags     Base
+// @Summary  OIDC单点登录回调
+// @Produce   application/json
+// @Param    data  body      systemReq.OidcLogin                                         true  "授权码, state"
+// @Success  200   {object}  response.Response{data=systemRes.LoginResponse,msg=string}  "返回包括用户信息,token,过期时间"
+// @Router   /base/oidcLogin [post]
+func (b *BaseApi) OidcLogin(c *gin.Context) {
+	var req systemReq.OidcLogin
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.OidcLoginVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, err := oidcService.Login(c.Request.Context(), req.Code, req.State)
+	if err != nil {
+		global.GVA_LOG.Error("OIDC登录失败!", zap.Error(err))
+		recordLogin(c, 0, "", "OIDC登录失败: "+err.Error())
+		response.FailWithMessage("登录失败: "+err.Error(), c)
+		return
+	}
+	if user.Enable != 1 {
+		global.GVA_LOG.Error("登陆失败! 用户被禁止登录!")
+		recordLogin(c, user.ID, user.Username, "用户被禁止登录")
+		response.FailWithMessage("用户被禁止登录", c)
+		return
+	}
+	b.loginNext(c, user, true)
+}
@@ -29,7 +29,7 @@ func (s *OperationRecordApi) CreateSysOperationRecord(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = operationRecordService.CreateSysOperationRecord(sysOperationRecord)
+	err = operationRecordService.CreateSysOperationRecord(c.Request.Context(), sysOperationRecord)
 	if err != nil {
 		global.GVA_LOG.Error("创建失败!", zap.Error(err))
 		response.FailWithMessage("创建失败", c)
@@ -54,7 +54,7 @@ func (s *OperationRecordApi) DeleteSysOperationRecord(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = operationRecordService.DeleteSysOperationRecord(sysOperationRecord)
+	err = operationRecordService.DeleteSysOperationRecord(c.Request.Context(), sysOperationRecord)
 	if err != nil {
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败", c)
@@ -79,7 +79,7 @@ func (s *OperationRecordApi) DeleteSysOperationRecordByIds(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = operationRecordService.DeleteSysOperationRecordByIds(IDS)
+	err = operationRecordService.DeleteSysOperationRecordByIds(c.Request.Context(), IDS)
 	if err != nil {
 		global.GVA_LOG.Error("批量删除失败!", zap.Error(err))
 		response.FailWithMessage("批量删除失败", c)
@@ -109,7 +109,7 @@ func (s *OperationRecordApi) FindSysOperationRecord(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	reSysOperationRecord, err := operationRecordService.GetSysOperationRecord(sysOperationRecord.ID)
+	reSysOperationRecord, err := operationRecordService.GetSysOperationRecord(c.Request.Context(), sysOperationRecord.ID)
 	if err != nil {
 		global.GVA_LOG.Error("查询失败!", zap.Error(err))
 		response.FailWithMessage("查询失败", c)
@@ -134,7 +134,7 @@ func (s *OperationRecordApi) GetSysOperationRecordList(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	list, total, err := operationRecordService.GetSysOperationRecordInfoList(pageInfo)
+	list, total, err := operationRecordService.GetSysOperationRecordInfoList(c.Request.Context(), pageInfo)
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
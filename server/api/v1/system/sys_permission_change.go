@@ -0,0 +1,125 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type PermissionChangeApi struct{}
+
+// GetChangeList 分页获取权限变更申请
+// @Tags PermissionChange
+// @Summary 分页获取权限变更申请
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.PermissionChangeSearch true "页码, 每页大小, 类型, 状态"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取权限变更申请,返回包括列表,总数,页码,每页数量"
+// @Router /permissionChange/getChangeList [get]
+func (permissionChangeApi *PermissionChangeApi) GetChangeList(c *gin.Context) {
+	var pageInfo systemReq.PermissionChangeSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(pageInfo.PageInfo, utils.PageInfoVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := permissionChangeService.GetChangeList(c.Request.Context(), pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// ApproveChange 审批通过权限变更
+// @Tags PermissionChange
+// @Summary 审批通过权限变更 以审批人的权限应用变更
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.DecidePermissionChange true "申请ID, 审批意见"
+// @Success 200 {object} response.Response{msg=string} "审批成功"
+// @Router /permissionChange/approveChange [post]
+func (permissionChangeApi *PermissionChangeApi) ApproveChange(c *gin.Context) {
+	var req systemReq.DecidePermissionChange
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = permissionChangeService.ApproveChange(c.Request.Context(), utils.GetUserID(c), utils.GetUserAuthorityId(c), req, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("审批失败!", zap.Error(err))
+		response.FailWithMessage("审批失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("审批成功", c)
+}
+
+// RejectChange 驳回权限变更
+// @Tags PermissionChange
+// @Summary 驳回权限变更 申请人可撤回自己的申请
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.DecidePermissionChange true "申请ID, 驳回意见"
+// @Success 200 {object} response.Response{msg=string} "驳回成功"
+// @Router /permissionChange/rejectChange [post]
+func (permissionChangeApi *PermissionChangeApi) RejectChange(c *gin.Context) {
+	var req systemReq.DecidePermissionChange
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = permissionChangeService.RejectChange(c.Request.Context(), utils.GetUserID(c), req, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("驳回失败!", zap.Error(err))
+		response.FailWithMessage("驳回失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("驳回成功", c)
+}
+
+// submitPermissionChange 开启权限变更审批时提交申请并响应 返回false时由调用方直接执行变更
+func submitPermissionChange(c *gin.Context, payload interface{}) bool {
+	if !global.GVA_CONFIG.PermissionApproval.Enable {
+		return false
+	}
+	change, err := permissionChangeService.SubmitChange(c.Request.Context(), utils.GetUserID(c), utils.GetUserAuthorityId(c), payload, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("提交审批失败!", zap.Error(err))
+		response.FailWithMessage("提交审批失败:"+err.Error(), c)
+		return true
+	}
+	if change == nil {
+		return false
+	}
+	response.OkWithDetailed(change, "已提交审批 需另一名管理员审批后生效", c)
+	return true
+}
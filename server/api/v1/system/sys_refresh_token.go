@@ -0,0 +1,58 @@
+package system
+
+import (
+	"errors"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RefreshToken
+// @Tags     Base
+// @Summary  使用刷新令牌换取新的访问令牌
+// @Produce   application/json
+// @Param    data  body      systemReq.RefreshToken                                             true  "刷新令牌"
+// @Success  200   {object}  response.Response{data=systemRes.RefreshTokenResponse,msg=string}  "返回新的访问令牌与刷新令牌"
+// @Router   /base/refresh [post]
+func (b *BaseApi) RefreshToken(c *gin.Context) {
+	var req systemReq.RefreshToken
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.RefreshTokenVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, sessionID, refreshToken, refreshExpiresAt, err := jwtService.RotateRefreshToken(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, systemService.ErrRefreshTokenReused) {
+			global.GVA_LOG.Warn("刷新令牌重复使用!", zap.String("ip", c.ClientIP()))
+		}
+		utils.ClearToken(c)
+		response.NoAuth(err.Error(), c)
+		return
+	}
+	token, claims, err := utils.LoginToken(user, sessionID)
+	if err != nil {
+		global.GVA_LOG.Error("获取token失败!", zap.Error(err))
+		response.FailWithMessage("获取token失败", c)
+		return
+	}
+	utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
+	response.OkWithDetailed(systemRes.RefreshTokenResponse{
+		Token:            token,
+		ExpiresAt:        claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt.Unix() * 1000,
+	}, "刷新成功", c)
+}
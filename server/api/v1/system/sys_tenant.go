@@ -0,0 +1,181 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TenantApi struct{}
+
+// CreateTenant 创建租户
+// @Tags Tenant
+// @Summary 创建租户 仅超级管理员可用
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysTenant true "租户名称, 租户编码, 备注"
+// @Success 200 {object} response.Response{data=system.SysTenant,msg=string} "创建成功"
+// @Router /tenant/createTenant [post]
+func (tenantApi *TenantApi) CreateTenant(c *gin.Context) {
+	if !requireSuperAdmin(c) {
+		return
+	}
+	var t system.SysTenant
+	err := c.ShouldBindJSON(&t)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(t, utils.TenantVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	t, err = tenantService.CreateTenant(utils.GetUserInfo(c), t, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(t, "创建成功", c)
+}
+
+// UpdateTenant 更新租户
+// @Tags Tenant
+// @Summary 更新租户名称、状态及备注 仅超级管理员可用
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysTenant true "租户ID, 租户名称, 状态, 备注"
+// @Success 200 {object} response.Response{msg=string} "更新成功"
+// @Router /tenant/updateTenant [put]
+func (tenantApi *TenantApi) UpdateTenant(c *gin.Context) {
+	if !requireSuperAdmin(c) {
+		return
+	}
+	var t system.SysTenant
+	err := c.ShouldBindJSON(&t)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(t.GVA_MODEL, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = tenantService.UpdateTenant(t)
+	if err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// GetTenantList 分页获取租户
+// @Tags Tenant
+// @Summary 分页获取租户 仅超级管理员可用
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.TenantSearch true "页码, 每页大小, 租户名称或编码"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取租户,返回包括列表,总数,页码,每页数量"
+// @Router /tenant/getTenantList [get]
+func (tenantApi *TenantApi) GetTenantList(c *gin.Context) {
+	if !requireSuperAdmin(c) {
+		return
+	}
+	var pageInfo systemReq.TenantSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(pageInfo.PageInfo, utils.PageInfoVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := tenantService.GetTenantList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// SwitchTenant 切换当前租户
+// @Tags Tenant
+// @Summary 超级管理员切换当前会话的租户 返回新的令牌
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.SwitchTenant true "租户ID"
+// @Success 200 {object} response.Response{data=systemRes.TokenResponse,msg=string} "切换成功,返回新的访问令牌"
+// @Router /tenant/switchTenant [post]
+func (tenantApi *TenantApi) SwitchTenant(c *gin.Context) {
+	if _, ok := c.Get("api_key_id"); ok {
+		response.FailWithMessage("不允许使用API密钥切换租户", c)
+		return
+	}
+	if !requireSuperAdmin(c) {
+		return
+	}
+	var req systemReq.SwitchTenant
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.SwitchTenantVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	claims := utils.GetUserInfo(c)
+	err = tenantService.SwitchTenant(claims, req.TenantId, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("切换失败!", zap.Error(err))
+		response.FailWithMessage("切换失败:"+err.Error(), c)
+		return
+	}
+	// 切换前的令牌作废 防止继续访问原租户
+	if err = jwtService.JsonInBlacklist(system.JwtBlacklist{Jwt: utils.GetToken(c)}); err != nil {
+		global.GVA_LOG.Error("切换失败!", zap.Error(err))
+		response.FailWithMessage("切换失败", c)
+		return
+	}
+	claims.TenantId = req.TenantId
+	token, err := utils.NewJWT().CreateToken(*claims)
+	if err != nil {
+		global.GVA_LOG.Error("切换失败!", zap.Error(err))
+		response.FailWithMessage("切换失败", c)
+		return
+	}
+	utils.SetToken(c, token, int((claims.ExpiresAt.Unix()-time.Now().Unix())/60))
+	response.OkWithDetailed(systemRes.TokenResponse{Token: token, ExpiresAt: claims.ExpiresAt.Unix() * 1000}, "切换成功", c)
+}
+
+// requireSuperAdmin 校验当前用户为超级管理员 否则响应权限不足
+func requireSuperAdmin(c *gin.Context) bool {
+	if tenantService.IsSuperAdmin(utils.GetUserID(c), utils.GetUserAuthorityId(c)) {
+		return true
+	}
+	response.FailWithMessage("仅超级管理员可管理租户", c)
+	return false
+}
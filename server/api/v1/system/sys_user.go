@@ -1,7 +1,8 @@
 package system
 
 import (
-	"strconv"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
@@ -11,9 +12,9 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -50,12 +51,23 @@ func (b *BaseApi) Login(c *gin.Context) {
 	var oc bool = openCaptcha == 0 || openCaptcha < interfaceToInt(v)
 
 	if !oc || (l.CaptchaId != "" && l.Captcha != "" && store.Verify(l.CaptchaId, l.Captcha, true)) {
+		if lockedUntil, locked := lockoutService.CheckLocked(l.Username); locked {
+			lockoutService.RecordBlocked(l.Username, c.ClientIP(), lockedUntil)
+			recordLogin(c, 0, l.Username, "账号已锁定")
+			response.FailWithMessage("账号已被锁定，请于"+lockedUntil.Format(time.DateTime)+"后重试", c)
+			return
+		}
 		u := &system.SysUser{Username: l.Username, Password: l.Password}
-		user, err := userService.Login(u)
+		user, external, err := userService.Login(u)
 		if err != nil {
 			global.GVA_LOG.Error("登陆失败! 用户名不存在或者密码错误!", zap.Error(err))
 			// 验证码次数+1
 			global.BlackCache.Increment(key, 1)
+			recordLogin(c, 0, l.Username, "用户名不存在或者密码错误")
+			if lockedUntil, locked := lockoutService.RecordFailure(l.Username, c.ClientIP()); locked {
+				response.FailWithMessage("连续登录失败次数过多，账号已被锁定至"+lockedUntil.Format(time.DateTime), c)
+				return
+			}
 			response.FailWithMessage("用户名不存在或者密码错误", c)
 			return
 		}
@@ -63,68 +75,91 @@ func (b *BaseApi) Login(c *gin.Context) {
 			global.GVA_LOG.Error("登陆失败! 用户被禁止登录!")
 			// 验证码次数+1
 			global.BlackCache.Increment(key, 1)
-			response.FailWithMessage("用户被禁止登录", c)
+			reason := userService.DisabledReason(user.ID)
+			recordLogin(c, user.ID, user.Username, reason)
+			response.FailWithMessage(reason, c)
 			return
 		}
-		b.TokenNext(c, *user)
+		lockoutService.RecordSuccess(l.Username)
+		b.loginNext(c, user, external)
 		return
 	}
 	// 验证码次数+1
 	global.BlackCache.Increment(key, 1)
+	recordLogin(c, 0, l.Username, "验证码错误")
 	response.FailWithMessage("验证码错误", c)
 }
 
-// TokenNext 登录以后签发jwt
-func (b *BaseApi) TokenNext(c *gin.Context, user system.SysUser) {
-	token, claims, err := utils.LoginToken(&user)
-	if err != nil {
-		global.GVA_LOG.Error("获取token失败!", zap.Error(err))
-		response.FailWithMessage("获取token失败", c)
+// loginNext 身份校验通过后 需要二次验证时下发票据 否则直接签发jwt external为通过外部身份(OIDC/LDAP)认证
+func (b *BaseApi) loginNext(c *gin.Context, user *system.SysUser, external bool) {
+	if need, needSetup := userService.NeedTwoFactor(user); need {
+		response.OkWithDetailed(systemRes.TwoFactorResponse{
+			NeedTwoFactor:  true,
+			NeedSetup:      needSetup,
+			Passkey:        passkeyService.HasPasskey(user.ID),
+			TwoFactorToken: userService.CreateTwoFactorTicket(user.ID, external),
+		}, "请完成两步验证", c)
+		return
+	}
+	b.passwordNext(c, user, external)
+}
+
+// passwordNext 使用本地密码登录且密码已过期或被要求修改时下发修改密码票据 否则直接签发jwt
+// 通过外部身份登录时未使用本地密码 不校验密码策略
+func (b *BaseApi) passwordNext(c *gin.Context, user *system.SysUser, external bool) {
+	if need, expired := userService.NeedChangePassword(user); need && !external {
+		msg := "请修改初始密码"
+		if expired {
+			msg = "密码已过期，请修改密码"
+		}
+		response.OkWithDetailed(systemRes.PasswordChangeResponse{
+			NeedChangePassword: true,
+			Expired:            expired,
+			PasswordToken:      userService.CreatePasswordTicket(user.ID),
+		}, msg, c)
 		return
 	}
-	if !global.GVA_CONFIG.System.UseMultipoint {
-		utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
-		response.OkWithDetailed(systemRes.LoginResponse{
-			User:      user,
-			Token:     token,
-			ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
-		}, "登录成功", c)
+	b.TokenNext(c, *user)
+}
+
+// TokenNext 登录以后创建会话 签发jwt与刷新令牌
+func (b *BaseApi) TokenNext(c *gin.Context, user system.SysUser) {
+	if err := tenantService.CheckTenant(user.TenantId); err != nil {
+		recordLogin(c, user.ID, user.Username, err.Error())
+		response.FailWithMessage(err.Error(), c)
 		return
 	}
-
-	if jwtStr, err := jwtService.GetRedisJWT(user.Username); err == redis.Nil {
-		if err := jwtService.SetRedisJWT(token, user.Username); err != nil {
-			global.GVA_LOG.Error("设置登录状态失败!", zap.Error(err))
-			response.FailWithMessage("设置登录状态失败", c)
+	if err := userService.ApplyAuthorityGrants(&user); err != nil {
+		if errors.Is(err, systemService.ErrNoActiveAuthority) {
+			recordLogin(c, user.ID, user.Username, err.Error())
+			response.FailWithMessage(err.Error(), c)
 			return
 		}
-		utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
-		response.OkWithDetailed(systemRes.LoginResponse{
-			User:      user,
-			Token:     token,
-			ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
-		}, "登录成功", c)
-	} else if err != nil {
-		global.GVA_LOG.Error("设置登录状态失败!", zap.Error(err))
+		global.GVA_LOG.Error("校验角色有效期失败!", zap.Error(err))
 		response.FailWithMessage("设置登录状态失败", c)
-	} else {
-		var blackJWT system.JwtBlacklist
-		blackJWT.Jwt = jwtStr
-		if err := jwtService.JsonInBlacklist(blackJWT); err != nil {
-			response.FailWithMessage("jwt作废失败", c)
-			return
-		}
-		if err := jwtService.SetRedisJWT(token, user.GetUsername()); err != nil {
-			response.FailWithMessage("设置登录状态失败", c)
-			return
-		}
-		utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
-		response.OkWithDetailed(systemRes.LoginResponse{
-			User:      user,
-			Token:     token,
-			ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
-		}, "登录成功", c)
+		return
 	}
+	session, refreshToken, refreshExpiresAt, err := sessionService.CreateSession(&user, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		global.GVA_LOG.Error("创建登录会话失败!", zap.Error(err))
+		response.FailWithMessage("设置登录状态失败", c)
+		return
+	}
+	token, claims, err := utils.LoginToken(&user, session.SessionID)
+	if err != nil {
+		global.GVA_LOG.Error("获取token失败!", zap.Error(err))
+		response.FailWithMessage("获取token失败", c)
+		return
+	}
+	utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
+	recordLogin(c, user.ID, user.Username, "")
+	response.OkWithDetailed(systemRes.LoginResponse{
+		User:             user,
+		Token:            token,
+		ExpiresAt:        claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt.Unix() * 1000,
+	}, "登录成功", c)
 }
 
 // Register
@@ -153,10 +188,33 @@ func (b *BaseApi) Register(c *gin.Context) {
 		})
 	}
 	user := &system.SysUser{Username: r.Username, NickName: r.NickName, Password: r.Password, HeaderImg: r.HeaderImg, AuthorityId: r.AuthorityId, Authorities: authorities, Enable: r.Enable, Phone: r.Phone, Email: r.Email}
-	userReturn, err := userService.Register(*user)
+	approval := global.GVA_CONFIG.PermissionApproval.Enable
+	if approval {
+		// 开启权限变更审批时先创建不关联角色的用户 角色经审批后生效 审批前无法登录
+		user.Authorities = nil
+	}
+	userReturn, err := userService.Register(c.Request.Context(), *user)
 	if err != nil {
 		global.GVA_LOG.Error("注册失败!", zap.Error(err))
-		response.FailWithDetailed(systemRes.SysUserResponse{User: userReturn}, "注册失败", c)
+		response.FailWithDetailed(systemRes.SysUserResponse{User: userReturn}, "注册失败: "+err.Error(), c)
+		return
+	}
+	if approval {
+		authorityIds := []uint{r.AuthorityId}
+		for _, v := range r.AuthorityIds {
+			if !slices.Contains(authorityIds, v) {
+				authorityIds = append(authorityIds, v)
+			}
+		}
+		payload := systemReq.SetUserAuthorities{ID: userReturn.ID, AuthorityIds: authorityIds}
+		change, err := permissionChangeService.SubmitChange(c.Request.Context(), utils.GetUserID(c), utils.GetUserAuthorityId(c), payload, c.ClientIP())
+		if err != nil {
+			_ = userService.DeleteUser(c.Request.Context(), int(userReturn.ID))
+			global.GVA_LOG.Error("提交审批失败!", zap.Error(err))
+			response.FailWithMessage("注册失败: "+err.Error(), c)
+			return
+		}
+		response.OkWithDetailed(change, "注册成功 用户角色需另一名管理员审批后生效", c)
 		return
 	}
 	response.OkWithDetailed(systemRes.SysUserResponse{User: userReturn}, "注册成功", c)
@@ -168,9 +226,13 @@ func (b *BaseApi) Register(c *gin.Context) {
 // @Security  ApiKeyAuth
 // @Produce  application/json
 // @Param     data  body      systemReq.ChangePasswordReq    true  "用户名, 原密码, 新密码"
-// @Success   200   {object}  response.Response{msg=string}  "用户修改密码"
+// @Success   200   {object}  response.Response{data=systemRes.TokenResponse,msg=string}  "用户修改密码,返回新的访问令牌"
 // @Router    /user/changePassword [post]
 func (b *BaseApi) ChangePassword(c *gin.Context) {
+	if _, ok := c.Get("api_key_id"); ok {
+		response.FailWithMessage("不允许使用API密钥修改密码", c)
+		return
+	}
 	var req systemReq.ChangePasswordReq
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
@@ -182,15 +244,34 @@ func (b *BaseApi) ChangePassword(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	uid := utils.GetUserID(c)
-	u := &system.SysUser{GVA_MODEL: global.GVA_MODEL{ID: uid}, Password: req.Password}
-	_, err = userService.ChangePassword(u, req.NewPassword)
+	claims := utils.GetUserInfo(c)
+	if claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能修改密码", c)
+		return
+	}
+	u := &system.SysUser{GVA_MODEL: global.GVA_MODEL{ID: claims.BaseClaims.ID}, Password: req.Password}
+	_, err = userService.ChangePassword(u, req.NewPassword, claims.SessionID)
+	if err != nil {
+		global.GVA_LOG.Error("修改失败!", zap.Error(err))
+		response.FailWithMessage("修改失败: "+err.Error(), c)
+		return
+	}
+	// 修改密码后安全版本号已递增 为当前会话签发携带新版本号的令牌
+	version, err := userService.SecurityVersion(claims.BaseClaims.ID)
+	if err != nil {
+		global.GVA_LOG.Error("修改失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	claims.SecurityVersion = uint(version)
+	token, err := utils.NewJWT().CreateToken(*claims)
 	if err != nil {
 		global.GVA_LOG.Error("修改失败!", zap.Error(err))
-		response.FailWithMessage("修改失败，原密码与当前账户不符", c)
+		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	response.OkWithMessage("修改成功", c)
+	utils.SetToken(c, token, int((claims.ExpiresAt.Unix()-time.Now().Unix())/60))
+	response.OkWithDetailed(systemRes.TokenResponse{Token: token, ExpiresAt: claims.ExpiresAt.Unix() * 1000}, "修改成功", c)
 }
 
 // GetUserList
@@ -214,7 +295,10 @@ func (b *BaseApi) GetUserList(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	list, total, err := userService.GetUserInfoList(pageInfo)
+	list, total, err := userService.GetUserInfoList(c.Request.Context(), pageInfo)
+	if err == nil {
+		err = fieldRuleService.Apply(utils.GetUserAuthorityId(c), list)
+	}
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
@@ -234,10 +318,15 @@ func (b *BaseApi) GetUserList(c *gin.Context) {
 // @Security  ApiKeyAuth
 // @accept    application/json
 // @Produce   application/json
-// @Param     data  body      systemReq.SetUserAuth          true  "用户UUID, 角色ID"
-// @Success   200   {object}  response.Response{msg=string}  "设置用户权限"
+// @Param     data  body      systemReq.SetUserAuth                                       true  "用户UUID, 角色ID"
+// @Success   200   {object}  response.Response{data=systemRes.TokenResponse,msg=string}  "设置用户权限,返回新的访问令牌"
 // @Router    /user/setUserAuthority [post]
 func (b *BaseApi) SetUserAuthority(c *gin.Context) {
+	// API密钥不绑定登录会话 不能据此签发访问令牌
+	if _, ok := c.Get("api_key_id"); ok {
+		response.FailWithMessage("不允许使用API密钥切换角色", c)
+		return
+	}
 	var sua systemReq.SetUserAuth
 	err := c.ShouldBindJSON(&sua)
 	if err != nil {
@@ -248,6 +337,11 @@ func (b *BaseApi) SetUserAuthority(c *gin.Context) {
 		response.FailWithMessage(UserVerifyErr.Error(), c)
 		return
 	}
+	// 模拟登录只校验了目标用户的当前角色 不允许切换到其他可能超出管理员权限的角色
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能切换角色", c)
+		return
+	}
 	userID := utils.GetUserID(c)
 	err = userService.SetUserAuthority(userID, sua.AuthorityId)
 	if err != nil {
@@ -257,16 +351,22 @@ func (b *BaseApi) SetUserAuthority(c *gin.Context) {
 	}
 	claims := utils.GetUserInfo(c)
 	claims.AuthorityId = sua.AuthorityId
+	// 切换角色后安全版本号已递增 新令牌需携带新版本号
+	version, err := userService.SecurityVersion(userID)
+	if err != nil {
+		global.GVA_LOG.Error("修改失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	claims.SecurityVersion = uint(version)
 	token, err := utils.NewJWT().CreateToken(*claims)
 	if err != nil {
 		global.GVA_LOG.Error("修改失败!", zap.Error(err))
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	c.Header("new-token", token)
-	c.Header("new-expires-at", strconv.FormatInt(claims.ExpiresAt.Unix(), 10))
 	utils.SetToken(c, token, int((claims.ExpiresAt.Unix()-time.Now().Unix())/60))
-	response.OkWithMessage("修改成功", c)
+	response.OkWithDetailed(systemRes.TokenResponse{Token: token, ExpiresAt: claims.ExpiresAt.Unix() * 1000}, "修改成功", c)
 }
 
 // SetUserAuthorities
@@ -285,8 +385,11 @@ func (b *BaseApi) SetUserAuthorities(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
+	if submitPermissionChange(c, sua) {
+		return
+	}
 	authorityID := utils.GetUserAuthorityId(c)
-	err = userService.SetUserAuthorities(authorityID, sua.ID, sua.AuthorityIds)
+	err = userService.SetUserAuthorities(c.Request.Context(), authorityID, sua.ID, sua.AuthorityIds)
 	if err != nil {
 		global.GVA_LOG.Error("修改失败!", zap.Error(err))
 		response.FailWithMessage("修改失败", c)
@@ -321,7 +424,7 @@ func (b *BaseApi) DeleteUser(c *gin.Context) {
 		response.FailWithMessage("删除失败, 无法删除自己。", c)
 		return
 	}
-	err = userService.DeleteUser(reqId.ID)
+	err = userService.DeleteUser(c.Request.Context(), reqId.ID)
 	if err != nil {
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败", c)
@@ -351,16 +454,26 @@ func (b *BaseApi) SetUserInfo(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	if len(user.AuthorityIds) != 0 {
-		authorityID := utils.GetUserAuthorityId(c)
-		err = userService.SetUserAuthorities(authorityID, user.ID, user.AuthorityIds)
+	authorityID := utils.GetUserAuthorityId(c)
+	var change *system.SysPermissionChange
+	if len(user.AuthorityIds) != 0 && global.GVA_CONFIG.PermissionApproval.Enable {
+		// 开启审批时角色变更提交申请 其余信息直接更新
+		change, err = permissionChangeService.SubmitChange(c.Request.Context(), utils.GetUserID(c), authorityID,
+			systemReq.SetUserAuthorities{ID: user.ID, AuthorityIds: user.AuthorityIds}, c.ClientIP())
+		if err != nil {
+			global.GVA_LOG.Error("提交审批失败!", zap.Error(err))
+			response.FailWithMessage("提交审批失败:"+err.Error(), c)
+			return
+		}
+	} else if len(user.AuthorityIds) != 0 {
+		err = userService.SetUserAuthorities(c.Request.Context(), authorityID, user.ID, user.AuthorityIds)
 		if err != nil {
 			global.GVA_LOG.Error("设置失败!", zap.Error(err))
 			response.FailWithMessage("设置失败", c)
 			return
 		}
 	}
-	err = userService.SetUserInfo(system.SysUser{
+	err = userService.SetUserInfo(c.Request.Context(), authorityID, system.SysUser{
 		GVA_MODEL: global.GVA_MODEL{
 			ID: user.ID,
 		},
@@ -369,10 +482,15 @@ func (b *BaseApi) SetUserInfo(c *gin.Context) {
 		Phone:     user.Phone,
 		Email:     user.Email,
 		Enable:    user.Enable,
+		Password:  user.Password,
 	})
 	if err != nil {
 		global.GVA_LOG.Error("设置失败!", zap.Error(err))
-		response.FailWithMessage("设置失败", c)
+		response.FailWithMessage("设置失败: "+err.Error(), c)
+		return
+	}
+	if change != nil {
+		response.OkWithDetailed(change, "设置成功 角色变更已提交审批", c)
 		return
 	}
 	response.OkWithMessage("设置成功", c)
@@ -455,16 +573,21 @@ func (b *BaseApi) GetUserInfo(c *gin.Context) {
 		response.FailWithMessage("获取失败", c)
 		return
 	}
+	// 模拟登录时返回原管理员身份 前端据此展示模拟登录横幅
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.OkWithDetailed(gin.H{"userInfo": ReqUser, "impersonator": claims.Impersonator}, "获取成功", c)
+		return
+	}
 	response.OkWithDetailed(gin.H{"userInfo": ReqUser}, "获取成功", c)
 }
 
 // ResetPassword
 // @Tags      SysUser
-// @Summary   重置用户密码
+// @Summary   重置用户密码为一次性随机密码
 // @Security  ApiKeyAuth
 // @Produce  application/json
-// @Param     data  body      system.SysUser                 true  "ID"
-// @Success   200   {object}  response.Response{msg=string}  "重置用户密码"
+// @Param     data  body      system.SysUser                                                     true  "ID"
+// @Success   200   {object}  response.Response{data=systemRes.ResetPasswordResponse,msg=string}  "重置用户密码,返回一次性密码"
 // @Router    /user/resetPassword [post]
 func (b *BaseApi) ResetPassword(c *gin.Context) {
 	var user system.SysUser
@@ -473,11 +596,11 @@ func (b *BaseApi) ResetPassword(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = userService.ResetPassword(user.ID)
+	password, err := userService.ResetPassword(c.Request.Context(), user.ID)
 	if err != nil {
 		global.GVA_LOG.Error("重置失败!", zap.Error(err))
 		response.FailWithMessage("重置失败"+err.Error(), c)
 		return
 	}
-	response.OkWithMessage("重置成功", c)
+	response.OkWithDetailed(systemRes.ResetPasswordResponse{Password: password}, "重置成功", c)
 }
@@ -0,0 +1,101 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GrantAuthority
+// @Tags      SysUser
+// @Summary   授予用户限时角色 已有限时授权时更新有效期
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.GrantUserAuthority                               true  "用户ID, 角色ID, 生效时间, 失效时间, 原因"
+// @Success   200   {object}  response.Response{data=system.SysUserAuthority,msg=string}  "授予限时角色"
+// @Router    /user/grantAuthority [post]
+func (b *BaseApi) GrantAuthority(c *gin.Context) {
+	var req systemReq.GrantUserAuthority
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.GrantAuthorityVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	grant, err := userService.GrantAuthority(c.Request.Context(), utils.GetUserAuthorityId(c), utils.GetUserID(c), req, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("授权失败!", zap.Error(err))
+		response.FailWithMessage("授权失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(grant, "授权成功", c)
+}
+
+// RevokeAuthorityGrant
+// @Tags      SysUser
+// @Summary   提前收回用户的限时角色
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.RevokeUserAuthority  true  "用户ID, 角色ID"
+// @Success   200   {object}  response.Response{msg=string}  "收回限时角色"
+// @Router    /user/revokeAuthorityGrant [post]
+func (b *BaseApi) RevokeAuthorityGrant(c *gin.Context) {
+	var req systemReq.RevokeUserAuthority
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.RevokeAuthorityVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.RevokeAuthorityGrant(c.Request.Context(), utils.GetUserAuthorityId(c), utils.GetUserID(c), req.ID, req.AuthorityId, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("收回失败!", zap.Error(err))
+		response.FailWithMessage("收回失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("收回成功", c)
+}
+
+// GetAuthorityGrants
+// @Tags      SysUser
+// @Summary   获取用户的角色授权及有效期
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                                               true  "用户ID"
+// @Success   200   {object}  response.Response{data=[]system.SysUserAuthority,msg=string}  "获取用户的角色授权"
+// @Router    /user/getAuthorityGrants [post]
+func (b *BaseApi) GetAuthorityGrants(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, err := userService.GetAuthorityGrants(c.Request.Context(), req.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
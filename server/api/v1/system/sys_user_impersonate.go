@@ -0,0 +1,92 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Impersonate
+// @Tags      SysUser
+// @Summary   管理员模拟登录为指定用户 令牌同时携带被模拟用户与管理员身份
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                                                  true  "用户ID"
+// @Success   200   {object}  response.Response{data=systemRes.ImpersonateResponse,msg=string}  "模拟登录"
+// @Router    /user/impersonate [post]
+func (b *BaseApi) Impersonate(c *gin.Context) {
+	if _, ok := c.Get("api_key_id"); ok {
+		response.FailWithMessage("不允许使用API密钥模拟登录", c)
+		return
+	}
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, impersonator, err := userService.Impersonate(utils.GetUserInfo(c), req.Uint(), c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("模拟登录失败!", zap.Error(err))
+		response.FailWithMessage("模拟登录失败: "+err.Error(), c)
+		return
+	}
+	// 模拟令牌不绑定被模拟用户的会话 不会挤占其登录 有效期内随管理员会话失效
+	token, claims, err := utils.ImpersonateToken(user, "", impersonator)
+	if err != nil {
+		global.GVA_LOG.Error("获取token失败!", zap.Error(err))
+		response.FailWithMessage("获取token失败", c)
+		return
+	}
+	utils.SetToken(c, token, int(claims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
+	response.OkWithDetailed(systemRes.ImpersonateResponse{
+		User:      *user,
+		Token:     token,
+		ExpiresAt: claims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+	}, "模拟登录成功", c)
+}
+
+// EndImpersonate
+// @Tags      SysUser
+// @Summary   结束模拟登录 作废模拟令牌并重新签发管理员令牌
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=systemRes.ImpersonateResponse,msg=string}  "结束模拟登录"
+// @Router    /user/endImpersonate [post]
+func (b *BaseApi) EndImpersonate(c *gin.Context) {
+	claims := utils.GetUserInfo(c)
+	if claims == nil {
+		response.FailWithMessage("当前未处于模拟登录", c)
+		return
+	}
+	admin, err := userService.EndImpersonate(claims, utils.GetToken(c), c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("结束模拟登录失败!", zap.Error(err))
+		response.FailWithMessage("结束模拟登录失败: "+err.Error(), c)
+		return
+	}
+	token, adminClaims, err := utils.LoginToken(admin, claims.Impersonator.SessionID)
+	if err != nil {
+		global.GVA_LOG.Error("获取token失败!", zap.Error(err))
+		response.FailWithMessage("获取token失败", c)
+		return
+	}
+	utils.SetToken(c, token, int(adminClaims.RegisteredClaims.ExpiresAt.Unix()-time.Now().Unix()))
+	response.OkWithDetailed(systemRes.ImpersonateResponse{
+		User:      *admin,
+		Token:     token,
+		ExpiresAt: adminClaims.RegisteredClaims.ExpiresAt.Unix() * 1000,
+	}, "已结束模拟登录", c)
+}
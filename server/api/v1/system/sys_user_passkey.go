@@ -0,0 +1,238 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PasskeyRegisterBegin
+// @Tags      SysUser
+// @Summary   获取通行密钥注册参数
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=systemRes.PasskeyCreationResponse,msg=string}  "返回会话ID与navigator.credentials.create参数"
+// @Router    /user/passkeyRegisterBegin [post]
+func (b *BaseApi) PasskeyRegisterBegin(c *gin.Context) {
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能绑定通行密钥", c)
+		return
+	}
+	sessionId, options, err := passkeyService.BeginRegistration(utils.GetUserID(c))
+	if err != nil {
+		if !errors.Is(err, systemService.ErrPasskeyDisabled) {
+			global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		}
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.PasskeyCreationResponse{SessionId: sessionId, PublicKey: options}, "获取成功", c)
+}
+
+// PasskeyRegisterFinish
+// @Tags      SysUser
+// @Summary   校验并保存通行密钥
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.PasskeyRegister                                true  "会话ID, 名称, 浏览器返回的凭证"
+// @Success   200   {object}  response.Response{data=system.SysUserPasskey,msg=string}  "绑定通行密钥"
+// @Router    /user/passkeyRegisterFinish [post]
+func (b *BaseApi) PasskeyRegisterFinish(c *gin.Context) {
+	var req systemReq.PasskeyRegister
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.SessionIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	passkey, err := passkeyService.FinishRegistration(utils.GetUserID(c), req.SessionId, req.Name, req.Credential, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("绑定失败!", zap.Error(err))
+		response.FailWithMessage("绑定失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(passkey, "绑定成功", c)
+}
+
+// GetPasskeyList
+// @Tags      SysUser
+// @Summary   获取自身绑定的通行密钥
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysUserPasskey,msg=string}  "获取自身通行密钥"
+// @Router    /user/getPasskeyList [get]
+func (b *BaseApi) GetPasskeyList(c *gin.Context) {
+	list, err := passkeyService.GetPasskeyList(utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// UpdatePasskey
+// @Tags      SysUser
+// @Summary   修改自身通行密钥的名称
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.UpdatePasskey        true  "通行密钥ID, 名称"
+// @Success   200   {object}  response.Response{msg=string}  "修改通行密钥名称"
+// @Router    /user/updatePasskey [put]
+func (b *BaseApi) UpdatePasskey(c *gin.Context) {
+	var req systemReq.UpdatePasskey
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.UpdatePasskeyVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = passkeyService.UpdatePasskey(utils.GetUserID(c), req.ID, req.Name)
+	if err != nil {
+		global.GVA_LOG.Error("修改失败!", zap.Error(err))
+		response.FailWithMessage("修改失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("修改成功", c)
+}
+
+// DeletePasskey
+// @Tags      SysUser
+// @Summary   删除自身的通行密钥
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "通行密钥ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除通行密钥"
+// @Router    /user/deletePasskey [delete]
+func (b *BaseApi) DeletePasskey(c *gin.Context) {
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能删除通行密钥", c)
+		return
+	}
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = passkeyService.DeletePasskey(utils.GetUserID(c), req.Uint(), c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// PasskeyLoginBegin
+// @Tags     Base
+// @Summary  获取通行密钥登录参数
+// @Produce   application/json
+// @Param    data  body      systemReq.PasskeyLoginBegin                                          true  "用户名 可为空"
+// @Success  200   {object}  response.Response{data=systemRes.PasskeyRequestResponse,msg=string}  "返回会话ID与navigator.credentials.get参数"
+// @Router   /base/passkeyLoginBegin [post]
+func (b *BaseApi) PasskeyLoginBegin(c *gin.Context) {
+	var req systemReq.PasskeyLoginBegin
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	sessionId, options, err := passkeyService.BeginLogin(req.Username)
+	if err != nil {
+		if !errors.Is(err, systemService.ErrPasskeyDisabled) {
+			global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		}
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.PasskeyRequestResponse{SessionId: sessionId, PublicKey: options}, "获取成功", c)
+}
+
+// PasskeyLogin
+// @Tags     Base
+// @Summary  使用通行密钥登录 无需密码与二次验证
+// @Produce   application/json
+// @Param    data  body      systemReq.PasskeyLogin                                      true  "会话ID, 浏览器返回的断言"
+// @Success  200   {object}  response.Response{data=systemRes.LoginResponse,msg=string}  "返回包括用户信息,token,过期时间"
+// @Router   /base/passkeyLogin [post]
+func (b *BaseApi) PasskeyLogin(c *gin.Context) {
+	var req systemReq.PasskeyLogin
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.SessionIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, err := passkeyService.FinishLogin(req.SessionId, req.Credential)
+	if err != nil {
+		recordLogin(c, 0, "", "通行密钥登录失败: "+err.Error())
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if user.Enable != 1 {
+		global.GVA_LOG.Error("登陆失败! 用户被禁止登录!")
+		reason := userService.DisabledReason(user.ID)
+		recordLogin(c, user.ID, user.Username, reason)
+		response.FailWithMessage(reason, c)
+		return
+	}
+	menuService.UserAuthorityDefaultRouter(user)
+	// 通行密钥已要求用户验证 本身即为多因素 不再要求密码相关的后续步骤
+	b.TokenNext(c, *user)
+}
+
+// PasskeyTwoFactorBegin
+// @Tags     Base
+// @Summary  获取使用通行密钥进行二次验证的参数
+// @Produce   application/json
+// @Param    data  body      systemReq.TwoFactorTicket                                            true  "二次验证票据"
+// @Success  200   {object}  response.Response{data=systemRes.PasskeyRequestResponse,msg=string}  "返回会话ID与navigator.credentials.get参数"
+// @Router   /base/passkeyTwoFactorBegin [post]
+func (b *BaseApi) PasskeyTwoFactorBegin(c *gin.Context) {
+	var req systemReq.TwoFactorTicket
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, _, err := userService.GetTwoFactorTicketUser(req.TwoFactorToken)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	sessionId, options, err := passkeyService.BeginTwoFactor(user.ID)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.PasskeyRequestResponse{SessionId: sessionId, PublicKey: options}, "获取成功", c)
+}
@@ -0,0 +1,109 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ChangeExpiredPassword
+// @Tags     Base
+// @Summary  登录过程中修改已过期或被要求修改的密码
+// @Produce   application/json
+// @Param    data  body      systemReq.ChangeExpiredPassword                             true  "修改密码票据, 新密码"
+// @Success  200   {object}  response.Response{data=systemRes.LoginResponse,msg=string}  "返回包括用户信息,token,过期时间"
+// @Router   /base/changeExpiredPassword [post]
+func (b *BaseApi) ChangeExpiredPassword(c *gin.Context) {
+	var req systemReq.ChangeExpiredPassword
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.PasswordTicketVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, err := userService.GetPasswordTicketUser(req.PasswordToken)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if user.Enable != 1 {
+		response.FailWithMessage("用户被禁止登录", c)
+		return
+	}
+	if err = userService.ChangeExpiredPassword(user, req.NewPassword); err != nil {
+		global.GVA_LOG.Error("修改失败!", zap.String("username", user.Username), zap.Error(err))
+		response.FailWithMessage("修改失败: "+err.Error(), c)
+		return
+	}
+	userService.DeletePasswordTicket(req.PasswordToken)
+	menuService.UserAuthorityDefaultRouter(user)
+	b.TokenNext(c, *user)
+}
+
+// ForgotPassword
+// @Tags     Base
+// @Summary  申请找回密码 向账号绑定的邮箱发送重置链接
+// @Produce   application/json
+// @Param    data  body      systemReq.ForgotPassword       true  "用户名或邮箱"
+// @Success  200   {object}  response.Response{msg=string}  "申请找回密码"
+// @Router   /base/forgotPassword [post]
+func (b *BaseApi) ForgotPassword(c *gin.Context) {
+	var req systemReq.ForgotPassword
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.ForgotPasswordVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.ForgotPassword(req.Account, c.ClientIP())
+	if errors.Is(err, systemService.ErrForgotPasswordDisabled) {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err != nil {
+		global.GVA_LOG.Error("申请找回密码失败!", zap.Error(err))
+	}
+	response.OkWithMessage("如果账号存在且绑定了邮箱，重置链接已发送，请查收邮件", c)
+}
+
+// ResetPasswordByToken
+// @Tags     Base
+// @Summary  通过找回密码邮件中的链接重置密码
+// @Produce   application/json
+// @Param    data  body      systemReq.ResetPasswordByToken  true  "重置令牌, 新密码"
+// @Success  200   {object}  response.Response{msg=string}   "重置密码"
+// @Router   /base/resetPasswordByToken [post]
+func (b *BaseApi) ResetPasswordByToken(c *gin.Context) {
+	var req systemReq.ResetPasswordByToken
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.ResetByTokenVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.ResetPasswordByToken(req.Token, req.NewPassword, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("重置密码失败!", zap.Error(err))
+		response.FailWithMessage("重置失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("重置成功，请使用新密码登录", c)
+}
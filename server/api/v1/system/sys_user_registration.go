@@ -0,0 +1,149 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SelfRegister
+// @Tags     Base
+// @Summary  用户自助注册 注册后需验证邮箱 按配置还需管理员审核
+// @Produce   application/json
+// @Param    data  body      systemReq.SelfRegister         true  "用户名, 密码, 昵称, 邮箱, 验证码"
+// @Success  200   {object}  response.Response{msg=string}  "用户自助注册"
+// @Router   /base/register [post]
+func (b *BaseApi) SelfRegister(c *gin.Context) {
+	var req systemReq.SelfRegister
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.SelfRegisterVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if !global.GVA_CONFIG.Register.Enable {
+		response.FailWithMessage(systemService.ErrRegisterDisabled.Error(), c)
+		return
+	}
+	if !store.Verify(req.CaptchaId, req.Captcha, true) {
+		response.FailWithMessage("验证码错误", c)
+		return
+	}
+	err = userService.SelfRegister(req, c.ClientIP())
+	if err != nil {
+		if !errors.Is(err, systemService.ErrRegisterDisabled) {
+			global.GVA_LOG.Error("注册失败!", zap.Error(err))
+		}
+		response.FailWithMessage("注册失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("注册成功，请查收验证邮件完成邮箱验证", c)
+}
+
+// VerifyRegisterEmail
+// @Tags     Base
+// @Summary  通过注册验证邮件中的链接验证邮箱
+// @Produce   application/json
+// @Param    data  body      systemReq.VerifyRegisterEmail  true  "验证令牌"
+// @Success  200   {object}  response.Response{data=map[string]string,msg=string}  "验证注册邮箱,返回注册状态"
+// @Router   /base/verifyRegisterEmail [post]
+func (b *BaseApi) VerifyRegisterEmail(c *gin.Context) {
+	var req systemReq.VerifyRegisterEmail
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.RegisterTokenVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	status, err := userService.VerifyRegisterEmail(req.Token, c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("验证失败!", zap.Error(err))
+		response.FailWithMessage("验证失败: "+err.Error(), c)
+		return
+	}
+	msg := "邮箱验证成功，请登录"
+	if status == system.RegisterPending {
+		msg = "邮箱验证成功，请等待管理员审核"
+	}
+	response.OkWithDetailed(gin.H{"status": status}, msg, c)
+}
+
+// GetRegistrationList
+// @Tags      SysUser
+// @Summary   分页获取自助注册记录
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.RegistrationSearch                            true  "页码, 每页大小, 注册状态"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取自助注册记录"
+// @Router    /user/getRegistrationList [post]
+func (b *BaseApi) GetRegistrationList(c *gin.Context) {
+	var pageInfo systemReq.RegistrationSearch
+	err := c.ShouldBindJSON(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(pageInfo, utils.PageInfoVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := userService.GetRegistrationList(c.Request.Context(), pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// ReviewRegistration
+// @Tags      SysUser
+// @Summary   审核自助注册 通过后用户可登录 拒绝时删除该用户
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.ReviewRegistration   true  "注册记录ID, 是否通过, 备注"
+// @Success   200   {object}  response.Response{msg=string}  "审核自助注册"
+// @Router    /user/reviewRegistration [post]
+func (b *BaseApi) ReviewRegistration(c *gin.Context) {
+	var req systemReq.ReviewRegistration
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.ReviewRegistration(c.Request.Context(), req, utils.GetUserID(c), c.ClientIP())
+	if err != nil {
+		global.GVA_LOG.Error("审核失败!", zap.Error(err))
+		response.FailWithMessage("审核失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("审核成功", c)
+}
@@ -0,0 +1,128 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetSessionList
+// @Tags      SysUser
+// @Summary   获取自身当前有效的登录会话
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysUserSession,msg=string}  "获取自身登录会话"
+// @Router    /user/getSessionList [get]
+func (b *BaseApi) GetSessionList(c *gin.Context) {
+	claims := utils.GetUserInfo(c)
+	if claims == nil {
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	list, err := sessionService.GetSessionList(claims.BaseClaims.ID, claims.SessionID)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// RevokeSession
+// @Tags      SysUser
+// @Summary   注销自身的某个登录会话
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.RevokeSession        true  "会话ID"
+// @Success   200   {object}  response.Response{msg=string}  "注销自身登录会话"
+// @Router    /user/revokeSession [post]
+func (b *BaseApi) RevokeSession(c *gin.Context) {
+	var req systemReq.RevokeSession
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.SessionIdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = sessionService.RevokeUserSession(utils.GetUserID(c), req.SessionId)
+	if err != nil {
+		global.GVA_LOG.Error("注销失败!", zap.Error(err))
+		response.FailWithMessage("注销失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("注销成功", c)
+}
+
+// GetUserSessionList
+// @Tags      SysUser
+// @Summary   获取用户当前有效的登录会话
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                                             true  "用户ID"
+// @Success   200   {object}  response.Response{data=[]system.SysUserSession,msg=string}  "获取用户登录会话"
+// @Router    /user/getUserSessionList [post]
+func (b *BaseApi) GetUserSessionList(c *gin.Context) {
+	var req request.GetById
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	claims := utils.GetUserInfo(c)
+	currentSessionID := ""
+	if claims != nil {
+		currentSessionID = claims.SessionID
+	}
+	list, err := sessionService.GetUserSessionList(c.Request.Context(), req.Uint(), currentSessionID)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// RevokeUserSession
+// @Tags      SysUser
+// @Summary   注销用户的登录会话 不传会话ID时注销该用户全部会话
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      systemReq.RevokeSession        true  "用户ID, 会话ID"
+// @Success   200   {object}  response.Response{msg=string}  "注销用户登录会话"
+// @Router    /user/revokeUserSession [post]
+func (b *BaseApi) RevokeUserSession(c *gin.Context) {
+	var req systemReq.RevokeSession
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(req, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = sessionService.RevokeTenantUserSession(c.Request.Context(), req.ID, req.SessionId)
+	if err != nil {
+		global.GVA_LOG.Error("注销失败!", zap.Error(err))
+		response.FailWithMessage("注销失败: "+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("注销成功", c)
+}
@@ -0,0 +1,214 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LoginTwoFactor
+// @Tags     Base
+// @Summary  登录二次验证
+// @Produce   application/json
+// @Param    data  body      systemReq.TwoFactorLogin                                    true  "二次验证票据, 验证码、恢复码或通行密钥断言"
+// @Success  200   {object}  response.Response{data=systemRes.LoginResponse,msg=string}  "返回包括用户信息,token,过期时间"
+// @Router   /base/loginTwoFactor [post]
+func (b *BaseApi) LoginTwoFactor(c *gin.Context) {
+	var req systemReq.TwoFactorLogin
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, external, err := userService.GetTwoFactorTicketUser(req.TwoFactorToken)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if user.Enable != 1 {
+		recordLogin(c, user.ID, user.Username, "用户被禁止登录")
+		response.FailWithMessage("用户被禁止登录", c)
+		return
+	}
+	if req.Passkey != nil {
+		err = passkeyService.VerifyTwoFactor(user.ID, req.PasskeySessionId, *req.Passkey)
+	} else {
+		err = userService.VerifyTwoFactor(user, req.Code, req.RecoveryCode)
+	}
+	if err != nil {
+		global.GVA_LOG.Error("两步验证失败!", zap.String("username", user.Username), zap.Error(err))
+		recordLogin(c, user.ID, user.Username, "两步验证失败: "+err.Error())
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	userService.DeleteTwoFactorTicket(req.TwoFactorToken)
+	menuService.UserAuthorityDefaultRouter(user)
+	b.passwordNext(c, user, external)
+}
+
+// TwoFactorSetup
+// @Tags     Base
+// @Summary  登录过程中绑定两步验证(角色强制开启且用户尚未绑定时使用)
+// @Produce   application/json
+// @Param    data  body      systemReq.TwoFactorTicket                                       true  "二次验证票据"
+// @Success  200   {object}  response.Response{data=systemRes.TotpSetupResponse,msg=string}  "返回密钥,otpauth地址,恢复码"
+// @Router   /base/twoFactorSetup [post]
+func (b *BaseApi) TwoFactorSetup(c *gin.Context) {
+	var req systemReq.TwoFactorTicket
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	user, _, err := userService.GetTwoFactorTicketUser(req.TwoFactorToken)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	secret, url, codes, err := userService.SetupTotp(user.ID)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.TotpSetupResponse{Secret: secret, Url: url, RecoveryCodes: codes}, "获取成功", c)
+}
+
+// TotpSetup
+// @Tags      SysUser
+// @Summary   获取两步验证绑定信息
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=systemRes.TotpSetupResponse,msg=string}  "返回密钥,otpauth地址,恢复码"
+// @Router    /user/totpSetup [post]
+func (b *BaseApi) TotpSetup(c *gin.Context) {
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能绑定两步验证", c)
+		return
+	}
+	secret, url, codes, err := userService.SetupTotp(utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.TotpSetupResponse{Secret: secret, Url: url, RecoveryCodes: codes}, "获取成功", c)
+}
+
+// TotpEnable
+// @Tags      SysUser
+// @Summary   校验验证码并开启两步验证
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  body      systemReq.TotpCode             true  "验证码"
+// @Success   200   {object}  response.Response{msg=string}  "开启两步验证"
+// @Router    /user/totpEnable [post]
+func (b *BaseApi) TotpEnable(c *gin.Context) {
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能开启两步验证", c)
+		return
+	}
+	var req systemReq.TotpCode
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.EnableTotp(utils.GetUserID(c), req.Code)
+	if err != nil {
+		global.GVA_LOG.Error("开启失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithMessage("开启成功", c)
+}
+
+// TotpDisable
+// @Tags      SysUser
+// @Summary   关闭两步验证
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  body      systemReq.TotpCode             true  "验证码或恢复码"
+// @Success   200   {object}  response.Response{msg=string}  "关闭两步验证"
+// @Router    /user/totpDisable [post]
+func (b *BaseApi) TotpDisable(c *gin.Context) {
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能关闭两步验证", c)
+		return
+	}
+	var req systemReq.TotpCode
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.DisableTotp(utils.GetUserID(c), req.Code, req.RecoveryCode)
+	if err != nil {
+		global.GVA_LOG.Error("关闭失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithMessage("关闭成功", c)
+}
+
+// RegenerateRecoveryCodes
+// @Tags      SysUser
+// @Summary   重新生成恢复码
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  body      systemReq.TotpCode                                                  true  "验证码"
+// @Success   200   {object}  response.Response{data=systemRes.RecoveryCodesResponse,msg=string}  "返回新的恢复码"
+// @Router    /user/regenerateRecoveryCodes [post]
+func (b *BaseApi) RegenerateRecoveryCodes(c *gin.Context) {
+	if claims := utils.GetUserInfo(c); claims != nil && claims.Impersonator != nil {
+		response.FailWithMessage("模拟登录期间不能生成恢复码", c)
+		return
+	}
+	var req systemReq.TotpCode
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	codes, err := userService.RegenerateRecoveryCodes(utils.GetUserID(c), req.Code)
+	if err != nil {
+		global.GVA_LOG.Error("生成失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(systemRes.RecoveryCodesResponse{RecoveryCodes: codes}, "生成成功", c)
+}
+
+// ResetTotp
+// @Tags      SysUser
+// @Summary   重置用户两步验证
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "用户ID"
+// @Success   200   {object}  response.Response{msg=string}  "重置用户两步验证"
+// @Router    /user/resetTotp [post]
+func (b *BaseApi) ResetTotp(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(reqId, utils.IdVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = userService.ResetTotp(c.Request.Context(), reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("重置失败!", zap.Error(err))
+		response.FailWithMessage("重置失败", c)
+		return
+	}
+	response.OkWithMessage("重置成功", c)
+}
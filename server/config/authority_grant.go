@@ -0,0 +1,5 @@
+package config
+
+type AuthorityGrant struct {
+	NotifyBefore int `mapstructure:"notify-before" json:"notify-before" yaml:"notify-before"` // 限时角色到期前多少分钟向用户发送提醒邮件 为0时不提醒
+}
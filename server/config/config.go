@@ -1,14 +1,23 @@
 package config
 
 type Server struct {
-	JWT       JWT     `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
-	Zap       Zap     `mapstructure:"zap" json:"zap" yaml:"zap"`
-	Redis     Redis   `mapstructure:"redis" json:"redis" yaml:"redis"`
-	RedisList []Redis `mapstructure:"redis-list" json:"redis-list" yaml:"redis-list"`
-	Mongo     Mongo   `mapstructure:"mongo" json:"mongo" yaml:"mongo"`
-	Email     Email   `mapstructure:"email" json:"email" yaml:"email"`
-	System    System  `mapstructure:"system" json:"system" yaml:"system"`
-	Captcha   Captcha `mapstructure:"captcha" json:"captcha" yaml:"captcha"`
+	JWT            JWT            `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
+	Zap            Zap            `mapstructure:"zap" json:"zap" yaml:"zap"`
+	Redis          Redis          `mapstructure:"redis" json:"redis" yaml:"redis"`
+	RedisList      []Redis        `mapstructure:"redis-list" json:"redis-list" yaml:"redis-list"`
+	Mongo          Mongo          `mapstructure:"mongo" json:"mongo" yaml:"mongo"`
+	Email          Email          `mapstructure:"email" json:"email" yaml:"email"`
+	System         System         `mapstructure:"system" json:"system" yaml:"system"`
+	Captcha        Captcha        `mapstructure:"captcha" json:"captcha" yaml:"captcha"`
+	MFA            MFA            `mapstructure:"mfa" json:"mfa" yaml:"mfa"`
+	OIDC           OIDC           `mapstructure:"oidc" json:"oidc" yaml:"oidc"`
+	LDAP           LDAP           `mapstructure:"ldap" json:"ldap" yaml:"ldap"`
+	Password       Password       `mapstructure:"password" json:"password" yaml:"password"`
+	Lockout        Lockout        `mapstructure:"lockout" json:"lockout" yaml:"lockout"`
+	Register       Register       `mapstructure:"register" json:"register" yaml:"register"`
+	WebAuthn       WebAuthn       `mapstructure:"webauthn" json:"webauthn" yaml:"webauthn"`
+	GeoIP          GeoIP          `mapstructure:"geoip" json:"geoip" yaml:"geoip"`
+	AuthorityGrant AuthorityGrant `mapstructure:"authority-grant" json:"authority-grant" yaml:"authority-grant"`
 	// auto
 	AutoCode Autocode `mapstructure:"autocode" json:"autocode" yaml:"autocode"`
 	// gorm
@@ -32,6 +41,12 @@ type Server struct {
 
 	DiskList []DiskList `mapstructure:"disk-list" json:"disk-list" yaml:"disk-list"`
 
+	// 权限变更审批
+	PermissionApproval PermissionApproval `mapstructure:"permission-approval" json:"permission-approval" yaml:"permission-approval"`
+
+	// 多租户
+	Tenant Tenant `mapstructure:"tenant" json:"tenant" yaml:"tenant"`
+
 	// 跨域配置
 	Cors CORS `mapstructure:"cors" json:"cors" yaml:"cors"`
 }
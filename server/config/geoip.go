@@ -0,0 +1,6 @@
+package config
+
+type GeoIP struct {
+	DBPath   string `mapstructure:"db-path" json:"db-path" yaml:"db-path"`    // 本地IP地理位置库路径 mmdb格式 如 GeoLite2-City.mmdb 为空时不解析地理位置
+	Language string `mapstructure:"language" json:"language" yaml:"language"` // 地名语言 如 zh-CN en 缺失时回退为英文
+}
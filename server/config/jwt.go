@@ -1,8 +1,11 @@
 package config
 
 type JWT struct {
-	SigningKey  string `mapstructure:"signing-key" json:"signing-key" yaml:"signing-key"`    // jwt签名
-	ExpiresTime string `mapstructure:"expires-time" json:"expires-time" yaml:"expires-time"` // 过期时间
-	BufferTime  string `mapstructure:"buffer-time" json:"buffer-time" yaml:"buffer-time"`    // 缓冲时间
-	Issuer      string `mapstructure:"issuer" json:"issuer" yaml:"issuer"`                   // 签发者
+	SigningKey         string `mapstructure:"signing-key" json:"signing-key" yaml:"signing-key"`                            // jwt签名
+	ExpiresTime        string `mapstructure:"expires-time" json:"expires-time" yaml:"expires-time"`                         // 访问令牌过期时间
+	RefreshExpiresTime string `mapstructure:"refresh-expires-time" json:"refresh-expires-time" yaml:"refresh-expires-time"` // 刷新令牌过期时间
+	Issuer             string `mapstructure:"issuer" json:"issuer" yaml:"issuer"`                                           // 签发者
+	Algorithm          string `mapstructure:"algorithm" json:"algorithm" yaml:"algorithm"`                                  // 签名算法 HS256 RS256 ES256 EdDSA
+	KeyRotation        string `mapstructure:"key-rotation" json:"key-rotation" yaml:"key-rotation"`                         // 非对称密钥轮换周期
+	KeyGracePeriod     string `mapstructure:"key-grace-period" json:"key-grace-period" yaml:"key-grace-period"`             // 旧密钥停止签发后继续验签的时长
 }
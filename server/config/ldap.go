@@ -0,0 +1,24 @@
+package config
+
+type LDAP struct {
+	Enable             bool           `mapstructure:"enable" json:"enable" yaml:"enable"`                                           // 是否开启LDAP登录 开启后本地账号仍可登录
+	Url                string         `mapstructure:"url" json:"url" yaml:"url"`                                                    // 服务地址 如 ldap://127.0.0.1:389 或 ldaps://127.0.0.1:636
+	StartTLS           bool           `mapstructure:"start-tls" json:"start-tls" yaml:"start-tls"`                                  // 是否使用 StartTLS
+	InsecureSkipVerify bool           `mapstructure:"insecure-skip-verify" json:"insecure-skip-verify" yaml:"insecure-skip-verify"` // 跳过证书校验 仅用于测试环境
+	Timeout            int            `mapstructure:"timeout" json:"timeout" yaml:"timeout"`                                        // 超时时间，单位：s(秒)
+	UserDNTemplates    []string       `mapstructure:"user-dn-templates" json:"user-dn-templates" yaml:"user-dn-templates"`          // 用户DN模板 %s 替换为用户名 依次尝试绑定
+	BindDN             string         `mapstructure:"bind-dn" json:"bind-dn" yaml:"bind-dn"`                                        // 未配置DN模板时 使用该账号查询用户DN
+	BindPassword       string         `mapstructure:"bind-password" json:"bind-password" yaml:"bind-password"`                      // 查询账号密码
+	BaseDN             string         `mapstructure:"base-dn" json:"base-dn" yaml:"base-dn"`                                        // 查询用户条目的根DN
+	UserFilter         string         `mapstructure:"user-filter" json:"user-filter" yaml:"user-filter"`                            // 查询用户的过滤条件 %s 替换为用户名
+	SubjectAttr        string         `mapstructure:"subject-attr" json:"subject-attr" yaml:"subject-attr"`                         // 用户唯一标识属性 如 entryUUID objectGUID 为空时使用DN
+	NicknameAttr       string         `mapstructure:"nickname-attr" json:"nickname-attr" yaml:"nickname-attr"`                      // 映射到昵称的属性
+	EmailAttr          string         `mapstructure:"email-attr" json:"email-attr" yaml:"email-attr"`                               // 映射到邮箱的属性
+	PhoneAttr          string         `mapstructure:"phone-attr" json:"phone-attr" yaml:"phone-attr"`                               // 映射到手机号的属性
+	GroupAttr          string         `mapstructure:"group-attr" json:"group-attr" yaml:"group-attr"`                               // 用户条目上记录所属组的属性 如 memberOf
+	GroupBaseDN        string         `mapstructure:"group-base-dn" json:"group-base-dn" yaml:"group-base-dn"`                      // 查询组的根DN 服务端不支持 memberOf 时使用
+	GroupFilter        string         `mapstructure:"group-filter" json:"group-filter" yaml:"group-filter"`                         // 查询组的过滤条件 %s 替换为用户DN
+	AutoProvision      bool           `mapstructure:"auto-provision" json:"auto-provision" yaml:"auto-provision"`                   // 首次登录时自动创建用户
+	DefaultAuthorityId uint           `mapstructure:"default-authority-id" json:"default-authority-id" yaml:"default-authority-id"` // 未匹配到组映射时的默认角色
+	GroupMapping       []GroupMapping `mapstructure:"group-mapping" json:"group-mapping" yaml:"group-mapping"`                      // LDAP组与角色的映射 组可填写DN或CN
+}
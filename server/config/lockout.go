@@ -0,0 +1,8 @@
+package config
+
+type Lockout struct {
+	MaxAttempts     int `mapstructure:"max-attempts" json:"max-attempts" yaml:"max-attempts"`                // 窗口期内连续登录失败多少次后锁定账号 0为不锁定
+	Window          int `mapstructure:"window" json:"window" yaml:"window"`                                  // 失败次数统计窗口，单位：s(秒)
+	LockDuration    int `mapstructure:"lock-duration" json:"lock-duration" yaml:"lock-duration"`             // 首次锁定时长，单位：s(秒) 之后每次锁定时长翻倍
+	MaxLockDuration int `mapstructure:"max-lock-duration" json:"max-lock-duration" yaml:"max-lock-duration"` // 最长锁定时长，单位：s(秒)
+}
@@ -0,0 +1,7 @@
+package config
+
+type MFA struct {
+	Issuer        string `mapstructure:"issuer" json:"issuer" yaml:"issuer"`                         // 验证器App中显示的签发者名称
+	TicketTimeout int    `mapstructure:"ticket-timeout" json:"ticket-timeout" yaml:"ticket-timeout"` // 二次验证票据有效期，单位：s(秒)
+	RecoveryCodes int    `mapstructure:"recovery-codes" json:"recovery-codes" yaml:"recovery-codes"` // 生成的恢复码数量
+}
@@ -0,0 +1,21 @@
+package config
+
+type OIDC struct {
+	Enable             bool           `mapstructure:"enable" json:"enable" yaml:"enable"`                                           // 是否开启OIDC单点登录
+	Issuer             string         `mapstructure:"issuer" json:"issuer" yaml:"issuer"`                                           // 身份提供方地址，用于读取 /.well-known/openid-configuration
+	ClientID           string         `mapstructure:"client-id" json:"client-id" yaml:"client-id"`                                  // 客户端ID
+	ClientSecret       string         `mapstructure:"client-secret" json:"client-secret" yaml:"client-secret"`                      // 客户端密钥
+	RedirectURL        string         `mapstructure:"redirect-url" json:"redirect-url" yaml:"redirect-url"`                         // 前端回调地址，需与身份提供方登记的一致
+	Scopes             []string       `mapstructure:"scopes" json:"scopes" yaml:"scopes"`                                           // 申请的 scope
+	AutoProvision      bool           `mapstructure:"auto-provision" json:"auto-provision" yaml:"auto-provision"`                   // 首次登录时自动创建用户
+	DefaultAuthorityId uint           `mapstructure:"default-authority-id" json:"default-authority-id" yaml:"default-authority-id"` // 未匹配到组映射时的默认角色
+	UsernameClaim      string         `mapstructure:"username-claim" json:"username-claim" yaml:"username-claim"`                   // 用户名取自的声明
+	NicknameClaim      string         `mapstructure:"nickname-claim" json:"nickname-claim" yaml:"nickname-claim"`                   // 昵称取自的声明
+	GroupsClaim        string         `mapstructure:"groups-claim" json:"groups-claim" yaml:"groups-claim"`                         // 组信息取自的声明
+	GroupMapping       []GroupMapping `mapstructure:"group-mapping" json:"group-mapping" yaml:"group-mapping"`                      // 身份提供方组与角色的映射
+}
+
+type GroupMapping struct {
+	Group       string `mapstructure:"group" json:"group" yaml:"group"`                      // 外部身份源中的组名
+	AuthorityId uint   `mapstructure:"authority-id" json:"authority-id" yaml:"authority-id"` // 对应的角色ID
+}
@@ -0,0 +1,15 @@
+package config
+
+type Password struct {
+	MinLength          int    `mapstructure:"min-length" json:"min-length" yaml:"min-length"`                               // 最小长度
+	RequireUpper       bool   `mapstructure:"require-upper" json:"require-upper" yaml:"require-upper"`                      // 必须包含大写字母
+	RequireLower       bool   `mapstructure:"require-lower" json:"require-lower" yaml:"require-lower"`                      // 必须包含小写字母
+	RequireDigit       bool   `mapstructure:"require-digit" json:"require-digit" yaml:"require-digit"`                      // 必须包含数字
+	RequireSymbol      bool   `mapstructure:"require-symbol" json:"require-symbol" yaml:"require-symbol"`                   // 必须包含特殊字符
+	NotContainUsername bool   `mapstructure:"not-contain-username" json:"not-contain-username" yaml:"not-contain-username"` // 不能包含用户名(不区分大小写)
+	HistoryCount       int    `mapstructure:"history-count" json:"history-count" yaml:"history-count"`                      // 不能与最近几次使用过的密码相同 0为不限制
+	MaxAge             int    `mapstructure:"max-age" json:"max-age" yaml:"max-age"`                                        // 密码有效期，单位：天 过期后登录时强制修改 0为永不过期
+	ResetLength        int    `mapstructure:"reset-length" json:"reset-length" yaml:"reset-length"`                         // 管理员重置密码时生成的随机密码长度
+	ForgotUrl          string `mapstructure:"forgot-url" json:"forgot-url" yaml:"forgot-url"`                               // 找回密码邮件中的重置地址 %s 替换为重置令牌 为空时不开启找回密码
+	ForgotTimeout      int    `mapstructure:"forgot-timeout" json:"forgot-timeout" yaml:"forgot-timeout"`                   // 重置链接有效期，单位：s(秒)
+}
@@ -0,0 +1,6 @@
+package config
+
+type PermissionApproval struct {
+	Enable      bool `mapstructure:"enable" json:"enable" yaml:"enable"`                   // 是否开启权限变更审批 开启后角色api、菜单、资源权限及用户角色的变更需另一名管理员审批后生效
+	ExpireHours int  `mapstructure:"expire-hours" json:"expire-hours" yaml:"expire-hours"` // 变更申请的有效期 单位小时 超时未审批自动失效
+}
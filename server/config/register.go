@@ -0,0 +1,9 @@
+package config
+
+type Register struct {
+	Enable          bool   `mapstructure:"enable" json:"enable" yaml:"enable"`                               // 是否开放用户自助注册
+	AuthorityId     uint   `mapstructure:"authority-id" json:"authority-id" yaml:"authority-id"`             // 自助注册用户的默认角色ID
+	RequireApproval bool   `mapstructure:"require-approval" json:"require-approval" yaml:"require-approval"` // 邮箱验证后是否还需管理员审核才能登录
+	VerifyUrl       string `mapstructure:"verify-url" json:"verify-url" yaml:"verify-url"`                   // 验证邮件中的验证地址 %s 替换为验证令牌
+	VerifyTimeout   int    `mapstructure:"verify-timeout" json:"verify-timeout" yaml:"verify-timeout"`       // 验证链接有效期，单位：s(秒)
+}
@@ -0,0 +1,5 @@
+package config
+
+type Tenant struct {
+	SuperAuthorityId uint `mapstructure:"super-authority-id" json:"super-authority-id" yaml:"super-authority-id"` // 超级管理员角色ID 默认租户中持有该角色的用户可创建及切换租户 为0时使用888
+}
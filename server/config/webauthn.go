@@ -0,0 +1,10 @@
+package config
+
+type WebAuthn struct {
+	Enable           bool     `mapstructure:"enable" json:"enable" yaml:"enable"`                                  // 是否开启通行密钥(Passkey)登录
+	RPID             string   `mapstructure:"rp-id" json:"rp-id" yaml:"rp-id"`                                     // 依赖方ID 一般为前端站点域名 不含协议与端口
+	RPName           string   `mapstructure:"rp-name" json:"rp-name" yaml:"rp-name"`                               // 依赖方名称 认证器中显示
+	Origins          []string `mapstructure:"origins" json:"origins" yaml:"origins"`                               // 允许发起认证的前端来源 如 https://admin.example.com
+	Timeout          int      `mapstructure:"timeout" json:"timeout" yaml:"timeout"`                               // 等待用户操作认证器的时长，单位：s(秒)
+	UserVerification string   `mapstructure:"user-verification" json:"user-verification" yaml:"user-verification"` // 用户验证要求 required preferred discouraged
+}
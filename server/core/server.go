@@ -28,6 +28,11 @@ func RunWindowsServer() {
 	// 从db加载jwt数据
 	if global.GVA_DB != nil {
 		system.LoadAll()
+		system.LoadRevokedSessions()
+		system.LoadJwtKeys()
+		system.WatchCasbinPolicy()
+		system.LoadDefaultTenant()
+		system.LoadAuthorityInheritance()
 	}
 
 	Router := initialize.Routers()
@@ -6727,18 +6727,30 @@ const docTemplate = `{
         "config.JWT": {
             "type": "object",
             "properties": {
-                "buffer-time": {
-                    "description": "缓冲时间",
+                "algorithm": {
+                    "description": "签名算法 HS256 RS256 ES256 EdDSA",
                     "type": "string"
                 },
                 "expires-time": {
-                    "description": "过期时间",
+                    "description": "访问令牌过期时间",
                     "type": "string"
                 },
                 "issuer": {
                     "description": "签发者",
                     "type": "string"
                 },
+                "key-grace-period": {
+                    "description": "旧密钥停止签发后继续验签的时长",
+                    "type": "string"
+                },
+                "key-rotation": {
+                    "description": "非对称密钥轮换周期",
+                    "type": "string"
+                },
+                "refresh-expires-time": {
+                    "description": "刷新令牌过期时间",
+                    "type": "string"
+                },
                 "signing-key": {
                     "description": "jwt签名",
                     "type": "string"
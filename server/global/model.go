@@ -10,5 +10,6 @@ type GVA_MODEL struct {
 	ID        uint           `gorm:"primarykey" json:"ID"` // 主键ID
 	CreatedAt time.Time      // 创建时间
 	UpdatedAt time.Time      // 更新时间
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // 删除时间
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`                               // 删除时间
+	TenantId  uint           `gorm:"index;default:1;comment:租户ID" json:"tenantId"` // 租户ID 由租户隔离回调维护
 }
@@ -0,0 +1,14 @@
+package initialize
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+)
+
+// DataScope 为系统库及业务库注册数据权限回调
+func DataScope() {
+	system.RegisterDataScope(global.GVA_DB)
+	for _, db := range global.GVA_DBList {
+		system.RegisterDataScope(db)
+	}
+}
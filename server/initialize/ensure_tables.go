@@ -53,6 +53,24 @@ func (e *ensureTables) MigrateTable(ctx context.Context) (context.Context, error
 		sysModel.Condition{},
 		sysModel.JoinTemplate{},
 		sysModel.SysParams{},
+		sysModel.SysUserRecoveryCode{},
+		sysModel.SysUserPasswordHistory{},
+		sysModel.SysLoginLockout{},
+		sysModel.SysAuditLog{},
+		sysModel.SysApiKey{},
+		sysModel.SysJwtKey{},
+		sysModel.SysUserRegistration{},
+		sysModel.SysUserPasskey{},
+		sysModel.SysLoginLog{},
+		sysModel.SysUserAuthority{},
+		sysModel.SysAuthorityFieldRule{},
+		sysModel.SysDept{},
+		sysModel.SysUserDept{},
+		sysModel.SysPermissionChange{},
+		sysModel.SysTenant{},
+		sysModel.SysUserIdentity{},
+		sysModel.SysRefreshToken{},
+		sysModel.SysUserSession{},
 
 		adapter.CasbinRule{},
 
@@ -94,6 +112,24 @@ func (e *ensureTables) TableCreated(ctx context.Context) bool {
 		sysModel.SysExportTemplate{},
 		sysModel.Condition{},
 		sysModel.JoinTemplate{},
+		sysModel.SysUserRecoveryCode{},
+		sysModel.SysUserPasswordHistory{},
+		sysModel.SysLoginLockout{},
+		sysModel.SysAuditLog{},
+		sysModel.SysApiKey{},
+		sysModel.SysJwtKey{},
+		sysModel.SysUserRegistration{},
+		sysModel.SysUserPasskey{},
+		sysModel.SysLoginLog{},
+		sysModel.SysUserAuthority{},
+		sysModel.SysAuthorityFieldRule{},
+		sysModel.SysDept{},
+		sysModel.SysUserDept{},
+		sysModel.SysPermissionChange{},
+		sysModel.SysTenant{},
+		sysModel.SysUserIdentity{},
+		sysModel.SysRefreshToken{},
+		sysModel.SysUserSession{},
 
 		adapter.CasbinRule{},
 
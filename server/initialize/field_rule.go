@@ -0,0 +1,15 @@
+package initialize
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/fieldrule"
+)
+
+// FieldRule 注册支持按角色配置字段规则的模型
+func FieldRule() {
+	fieldrule.Register(
+		system.SysUser{},
+		example.ExaCustomer{},
+	)
+}
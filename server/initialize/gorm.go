@@ -56,6 +56,24 @@ func RegisterTables() {
 		system.Condition{},
 		system.JoinTemplate{},
 		system.SysParams{},
+		system.SysUserRecoveryCode{},
+		system.SysUserPasswordHistory{},
+		system.SysLoginLockout{},
+		system.SysAuditLog{},
+		system.SysApiKey{},
+		system.SysJwtKey{},
+		system.SysUserRegistration{},
+		system.SysUserPasskey{},
+		system.SysLoginLog{},
+		system.SysUserAuthority{},
+		system.SysAuthorityFieldRule{},
+		system.SysDept{},
+		system.SysUserDept{},
+		system.SysPermissionChange{},
+		system.SysTenant{},
+		system.SysUserIdentity{},
+		system.SysRefreshToken{},
+		system.SysUserSession{},
 
 		example.ExaFile{},
 		example.ExaCustomer{},
@@ -15,7 +15,10 @@ func OtherInit() {
 	if err != nil {
 		panic(err)
 	}
-	_, err = utils.ParseDuration(global.GVA_CONFIG.JWT.BufferTime)
+	if global.GVA_CONFIG.JWT.RefreshExpiresTime == "" {
+		global.GVA_CONFIG.JWT.RefreshExpiresTime = "7d"
+	}
+	_, err = utils.ParseDuration(global.GVA_CONFIG.JWT.RefreshExpiresTime)
 	if err != nil {
 		panic(err)
 	}
@@ -92,6 +92,11 @@ func Routers() *gin.Engine {
 		systemRouter.InitAuthorityBtnRouterRouter(PrivateGroup)             // 按钮权限管理
 		systemRouter.InitSysExportTemplateRouter(PrivateGroup, PublicGroup) // 导出模板
 		systemRouter.InitSysParamsRouter(PrivateGroup, PublicGroup)         // 参数管理
+		systemRouter.InitAuditLogRouter(PrivateGroup)                       // 审计日志
+		systemRouter.InitLoginLogRouter(PrivateGroup)                       // 登录日志
+		systemRouter.InitDeptRouter(PrivateGroup)                           // 部门管理
+		systemRouter.InitPermissionChangeRouter(PrivateGroup)               // 权限变更审批
+		systemRouter.InitTenantRouter(PrivateGroup)                         // 租户
 		exampleRouter.InitCustomerRouter(PrivateGroup)                      // 客户路由
 		exampleRouter.InitFileUploadAndDownloadRouter(PrivateGroup)         // 文件上传下载功能路由
 		exampleRouter.InitAttachmentCategoryRouterRouter(PrivateGroup)      // 文件上传下载分类
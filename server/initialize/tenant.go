@@ -0,0 +1,14 @@
+package initialize
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+)
+
+// Tenant 为系统库及业务库注册租户隔离回调
+func Tenant() {
+	system.RegisterTenant(global.GVA_DB)
+	for _, db := range global.GVA_DBList {
+		system.RegisterTenant(db)
+	}
+}
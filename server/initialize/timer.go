@@ -2,6 +2,7 @@ package initialize
 
 import (
 	"fmt"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/task"
 
 	"github.com/robfig/cron/v3"
@@ -24,6 +25,48 @@ func Timer() {
 			fmt.Println("add timer error:", err)
 		}
 
+		// 轮换jwt签名密钥 仅在配置了非对称签名算法时生效
+		_, err = global.GVA_Timer.AddTaskByFunc("RotateJwtKey", "@hourly", func() {
+			if global.GVA_DB == nil {
+				return
+			}
+			err := system.JwtKeyServiceApp.RotateIfDue()
+			if err != nil {
+				fmt.Println("timer error:", err)
+			}
+		}, "定时轮换jwt签名密钥", option...)
+		if err != nil {
+			fmt.Println("add timer error:", err)
+		}
+
+		// 提醒并收回到期的限时角色
+		_, err = global.GVA_Timer.AddTaskByFunc("ExpireAuthorityGrant", "@every 1m", func() {
+			if global.GVA_DB == nil {
+				return
+			}
+			err := system.UserServiceApp.ExpireAuthorityGrants()
+			if err != nil {
+				fmt.Println("timer error:", err)
+			}
+		}, "定时提醒并收回到期的限时角色", option...)
+		if err != nil {
+			fmt.Println("add timer error:", err)
+		}
+
+		// 将超时未审批的权限变更申请置为失效
+		_, err = global.GVA_Timer.AddTaskByFunc("ExpirePermissionChange", "@every 1m", func() {
+			if global.GVA_DB == nil {
+				return
+			}
+			err := system.PermissionChangeServiceApp.ExpireChanges()
+			if err != nil {
+				fmt.Println("timer error:", err)
+			}
+		}, "定时将超时未审批的权限变更申请置为失效", option...)
+		if err != nil {
+			fmt.Println("add timer error:", err)
+		}
+
 		// 其他定时任务定在这里 参考上方使用方法
 
 		//_, err := global.GVA_Timer.AddTaskByFunc("定时任务标识", "corn表达式", func() {
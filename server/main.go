@@ -35,6 +35,9 @@ func main() {
 	global.GVA_DB = initialize.Gorm() // gorm连接数据库
 	initialize.Timer()
 	initialize.DBList()
+	initialize.DataScope() // 注册数据权限回调
+	initialize.Tenant()    // 注册租户隔离回调
+	initialize.FieldRule() // 注册字段权限模型
 	if global.GVA_DB != nil {
 		initialize.RegisterTables() // 初始化表
 		// 程序结束前关闭数据库链接
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/service"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,16 +18,28 @@ var casbinService = service.ServiceGroupApp.SystemServiceGroup.CasbinService
 // CasbinHandler 拦截器
 func CasbinHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		waitUse, _ := utils.GetClaims(c)
+		waitUse := utils.GetUserInfo(c)
+		if waitUse == nil {
+			response.NoAuth("未登录或非法访问", c)
+			c.Abort()
+			return
+		}
 		//获取请求的PATH
 		path := c.Request.URL.Path
 		obj := strings.TrimPrefix(path, global.GVA_CONFIG.System.RouterPrefix)
 		// 获取请求方法
 		act := c.Request.Method
+		// 模拟登录期间始终允许结束模拟 不受被模拟用户的角色限制
+		if waitUse.Impersonator != nil && obj == "/user/endImpersonate" && act == http.MethodPost {
+			c.Next()
+			return
+		}
 		// 获取用户的角色
 		sub := strconv.Itoa(int(waitUse.AuthorityId))
+		// 按当前租户的域校验
+		dom := tenant.Domain(c.Request.Context())
 		e := casbinService.Casbin() // 判断策略中是否存在
-		success, _ := e.Enforce(sub, obj, act)
+		success, _ := e.Enforce(sub, obj, act, dom)
 		if !success {
 			response.FailWithDetailed(gin.H{}, "权限不足", c)
 			c.Abort()
@@ -21,8 +21,8 @@ var userService = service.ServiceGroupApp.SystemServiceGroup.UserService
 func ErrorToEmail() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var username string
-		claims, _ := utils2.GetClaims(c)
-		if claims.Username != "" {
+		claims := utils2.GetUserInfo(c)
+		if claims != nil && claims.Username != "" {
 			username = claims.Username
 		} else {
 			id, _ := strconv.Atoi(c.Request.Header.Get("x-user-id"))
@@ -2,21 +2,34 @@ package middleware
 
 import (
 	"errors"
+	"net/url"
+	"strings"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
-	"github.com/golang-jwt/jwt/v5"
-	"strconv"
-	"time"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/datascope"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/service"
 	"github.com/gin-gonic/gin"
 )
 
-var jwtService = service.ServiceGroupApp.SystemServiceGroup.JwtService
+var (
+	jwtService     = service.ServiceGroupApp.SystemServiceGroup.JwtService
+	sessionService = service.ServiceGroupApp.SystemServiceGroup.SessionService
+	apiKeyService  = service.ServiceGroupApp.SystemServiceGroup.ApiKeyService
+	tenantService  = service.ServiceGroupApp.SystemServiceGroup.TenantService
+)
 
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 机器客户端使用 x-api-key 请求头中的API密钥调用 以所属用户当前角色鉴权
+		if key := c.Request.Header.Get("x-api-key"); key != "" {
+			apiKeyAuth(c, key)
+			return
+		}
 		// 我们这里jwt鉴权取头部信息 x-token 登录时回返回token信息 这里前端需要把token存储到cookie或者本地localStorage中 不过需要跟后端协商过期时间 可以约定刷新令牌或者重新登录
 		token := utils.GetToken(c)
 		if token == "" {
@@ -46,35 +59,85 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 已登录用户被管理员禁用 需要使该用户的jwt失效 此处比较消耗性能 如果需要 请自行打开
-		// 用户被删除的逻辑 需要优化 此处比较消耗性能 如果需要 请自行打开
-
-		//if user, err := userService.FindUserByUuid(claims.UUID.String()); err != nil || user.Enable == 2 {
-		//	_ = jwtService.JsonInBlacklist(system.JwtBlacklist{Jwt: token})
-		//	response.FailWithDetailed(gin.H{"reload": true}, err.Error(), c)
-		//	c.Abort()
-		//}
-		c.Set("claims", claims)
-		if claims.ExpiresAt.Unix()-time.Now().Unix() < claims.BufferTime {
-			dr, _ := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
-			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(dr))
-			newToken, _ := j.CreateTokenByOldToken(token, *claims)
-			newClaims, _ := j.ParseToken(newToken)
-			c.Header("new-token", newToken)
-			c.Header("new-expires-at", strconv.FormatInt(newClaims.ExpiresAt.Unix(), 10))
-			utils.SetToken(c, newToken, int(dr.Seconds()))
-			if global.GVA_CONFIG.System.UseMultipoint {
-				// 记录新的活跃jwt
-				_ = jwtService.SetRedisJWT(newToken, newClaims.Username)
-			}
+		// 模拟令牌不绑定被模拟用户的会话 随管理员的会话校验
+		if claims.Impersonator == nil && !sessionService.CheckSession(claims.SessionID, c.ClientIP()) {
+			response.NoAuth("登录会话已注销，请重新登录", c)
+			utils.ClearToken(c)
+			c.Abort()
+			return
 		}
-		c.Next()
 
-		if newToken, exists := c.Get("new-token"); exists {
-			c.Header("new-token", newToken.(string))
+		// 用户被删除、禁用或变更角色后安全版本号递增 已签发的令牌立即失效 版本号有缓存 不会每次请求查询数据库
+		if !userService.CheckSecurityVersion(claims.BaseClaims.ID, claims.SecurityVersion) {
+			response.NoAuth("账号状态已变更，请重新登录", c)
+			utils.ClearToken(c)
+			c.Abort()
+			return
 		}
-		if newExpiresAt, exists := c.Get("new-expires-at"); exists {
-			c.Header("new-expires-at", newExpiresAt.(string))
+		// 访问令牌有效期较短 不再滑动续期 过期后由前端使用刷新令牌调用 /base/refresh 换取
+		setClaims(c, claims)
+		if imp := claims.Impersonator; imp != nil {
+			// 管理员退出登录、被禁用或变更角色后模拟登录随之失效
+			if !sessionService.CheckSession(imp.SessionID, c.ClientIP()) || !userService.CheckSecurityVersion(imp.ID, imp.SecurityVersion) {
+				response.NoAuth("模拟登录已失效，请重新登录", c)
+				utils.ClearToken(c)
+				c.Abort()
+				return
+			}
+			// 前端据此展示模拟登录横幅 模拟期间的全部请求均记录操作日志
+			c.Header("x-impersonator", url.QueryEscape(imp.Username))
+			recordOperation(c)
+		} else {
+			c.Next()
 		}
 	}
 }
+
+// apiKeyAuth 校验API密钥与接口范围 通过后构造所属用户的claims 后续 CasbinHandler 照常鉴权
+func apiKeyAuth(c *gin.Context, key string) {
+	apiKey, user, err := apiKeyService.Authenticate(key, c.ClientIP())
+	if err != nil {
+		response.NoAuth(err.Error(), c)
+		c.Abort()
+		return
+	}
+	// 租户停用后其用户的API密钥随之失效
+	if err = tenantService.CheckTenant(user.TenantId); err != nil {
+		response.NoAuth(err.Error(), c)
+		c.Abort()
+		return
+	}
+	path := strings.TrimPrefix(c.Request.URL.Path, global.GVA_CONFIG.System.RouterPrefix)
+	if !utils.MatchApiScope(apiKey.Scopes, c.Request.Method, path) {
+		response.FailWithDetailed(gin.H{}, "API密钥无权访问该接口", c)
+		c.Abort()
+		return
+	}
+	setClaims(c, &systemReq.CustomClaims{
+		BaseClaims: systemReq.BaseClaims{
+			UUID:            user.UUID,
+			ID:              user.ID,
+			Username:        user.Username,
+			NickName:        user.NickName,
+			AuthorityId:     user.AuthorityId,
+			SecurityVersion: user.SecurityVersion,
+			TenantId:        user.TenantId,
+		},
+	})
+	c.Set("api_key_id", apiKey.ID)
+	c.Next()
+}
+
+// setClaims 保存当前用户 并写入请求的context 供数据权限及租户隔离回调使用
+func setClaims(c *gin.Context, claims *systemReq.CustomClaims) {
+	c.Set("claims", claims)
+	if claims.TenantId == 0 {
+		// 升级前签发的令牌不含租户 按默认租户处理
+		claims.TenantId = tenant.Default
+	}
+	ctx := datascope.WithSubject(c.Request.Context(), datascope.Subject{
+		UserID:      claims.BaseClaims.ID,
+		AuthorityID: claims.AuthorityId,
+	})
+	c.Request = c.Request.WithContext(tenant.WithTenant(ctx, claims.TenantId))
+}
@@ -15,6 +15,9 @@ type ExaFile struct {
 	IsFinish     bool
 }
 
+// TenantShared 断点续传文件按文件摘要共用
+func (ExaFile) TenantShared() {}
+
 // file chunk struct, 切片结构体
 type ExaFileChunk struct {
 	global.GVA_MODEL
@@ -22,3 +25,6 @@ type ExaFileChunk struct {
 	FileChunkNumber int
 	FileChunkPath   string
 }
+
+// TenantShared 切片随文件共用
+func (ExaFileChunk) TenantShared() {}
@@ -12,4 +12,10 @@ type ExaCustomer struct {
 	SysUserID          uint           `json:"sysUserId" form:"sysUserId" gorm:"comment:管理ID"`                     // 管理ID
 	SysUserAuthorityID uint           `json:"sysUserAuthorityID" form:"sysUserAuthorityID" gorm:"comment:管理角色ID"` // 管理角色ID
 	SysUser            system.SysUser `json:"sysUser" form:"sysUser" gorm:"comment:管理详情"`                         // 管理详情
+	CreatedBy          uint           `json:"-" gorm:"column:created_by;index;comment:创建者"`                       // 创建者
+}
+
+// DataScopeColumn 客户按创建者接入数据权限 查询时需通过WithContext传入请求的context
+func (ExaCustomer) DataScopeColumn() string {
+	return "created_by"
 }
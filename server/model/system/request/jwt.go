@@ -8,14 +8,31 @@ import (
 // CustomClaims structure
 type CustomClaims struct {
 	BaseClaims
-	BufferTime int64
 	jwt.RegisteredClaims
 }
 
 type BaseClaims struct {
-	UUID        uuid.UUID
-	ID          uint
-	Username    string
-	NickName    string
-	AuthorityId uint
+	UUID            uuid.UUID
+	ID              uint
+	Username        string
+	NickName        string
+	AuthorityId     uint
+	SessionID       string        // 登录会话ID 会话被注销后令牌失效
+	SecurityVersion uint          // 用户安全版本号 与用户当前版本号不一致时令牌失效
+	TenantId        uint          // 当前租户ID 超级管理员切换租户后为切换到的租户
+	Impersonator    *Impersonator `json:",omitempty"` // 管理员模拟登录时的原管理员身份
+}
+
+// Impersonator 模拟登录的管理员身份 管理员会话注销或安全版本号变更时模拟登录一并失效
+type Impersonator struct {
+	ID              uint
+	Username        string
+	AuthorityId     uint
+	SessionID       string
+	SecurityVersion uint
+}
+
+// RefreshToken 使用刷新令牌换取新令牌
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken"` // 登录或上次刷新时下发的刷新令牌
 }
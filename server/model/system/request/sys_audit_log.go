@@ -0,0 +1,12 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+type SysAuditLogSearch struct {
+	request.PageInfo
+	Action   string `json:"action" form:"action"`     // 事件类型
+	UserID   uint   `json:"userId" form:"userId"`     // 用户ID
+	Username string `json:"username" form:"username"` // 用户名
+}
@@ -0,0 +1,28 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+
+// SetAuthorityTwoFactor 设置角色是否强制两步验证
+type SetAuthorityTwoFactor struct {
+	AuthorityId      uint `json:"authorityId"`      // 角色ID
+	RequireTwoFactor bool `json:"requireTwoFactor"` // 是否强制两步验证
+}
+
+// SetAuthorityMaxSessions 设置角色最大同时在线会话数
+type SetAuthorityMaxSessions struct {
+	AuthorityId uint `json:"authorityId"` // 角色ID
+	MaxSessions int  `json:"maxSessions"` // 最大会话数 0为不限制
+}
+
+// SetAuthorityDataScope 设置角色的数据范围
+type SetAuthorityDataScope struct {
+	AuthorityId   uint   `json:"authorityId"`   // 角色ID
+	DataScope     string `json:"dataScope"`     // 数据范围 all self dept dept_tree custom
+	DataScopeRule string `json:"dataScopeRule"` // 自定义条件 数据范围为custom时必填
+}
+
+// SetAuthorityFieldRules 设置角色的字段规则 覆盖角色原有的全部规则
+type SetAuthorityFieldRules struct {
+	AuthorityId uint                           `json:"authorityId"` // 角色ID
+	Rules       []system.SysAuthorityFieldRule `json:"rules"`       // 字段规则
+}
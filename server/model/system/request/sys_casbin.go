@@ -1,5 +1,7 @@
 package request
 
+import "time"
+
 // CasbinInfo Casbin info structure
 type CasbinInfo struct {
 	Path   string `json:"path"`   // 路径
@@ -12,6 +14,41 @@ type CasbinInReceive struct {
 	CasbinInfos []CasbinInfo `json:"casbinInfos"`
 }
 
+// CasbinExplain 权限判定说明的查询条件 用户ID与角色ID二选一
+type CasbinExplain struct {
+	UserID      uint   `json:"userId"`      // 用户ID 按用户当前角色判定
+	AuthorityId uint   `json:"authorityId"` // 角色ID
+	Path        string `json:"path"`        // 路径
+	Method      string `json:"method"`      // 方法
+}
+
+// CasbinPolicyFileVersion 策略文件格式版本
+const CasbinPolicyFileVersion = 1
+
+// CasbinPolicyFile 可在环境间迁移的策略文件 包含全部p策略及sys_apis
+type CasbinPolicyFile struct {
+	Version     int               `json:"version" yaml:"version"`         // 文件格式版本
+	ExportedAt  time.Time         `json:"exportedAt" yaml:"exportedAt"`   // 导出时间
+	Authorities []uint            `json:"authorities" yaml:"authorities"` // 导出时存在的角色 导入时仅同步这些角色的策略
+	Apis        []CasbinPolicyApi `json:"apis" yaml:"apis"`               // api列表
+	Policies    []CasbinPolicy    `json:"policies" yaml:"policies"`       // p策略
+}
+
+// CasbinPolicyApi 策略文件中的api 以路径和方法标识
+type CasbinPolicyApi struct {
+	Path        string `json:"path" yaml:"path"`               // 路径
+	Method      string `json:"method" yaml:"method"`           // 方法
+	ApiGroup    string `json:"apiGroup" yaml:"apiGroup"`       // 分组
+	Description string `json:"description" yaml:"description"` // 描述
+}
+
+// CasbinPolicy 策略文件中的p策略
+type CasbinPolicy struct {
+	AuthorityId uint   `json:"authorityId" yaml:"authorityId"` // 角色ID
+	Path        string `json:"path" yaml:"path"`               // 路径
+	Method      string `json:"method" yaml:"method"`           // 方法
+}
+
 func DefaultCasbin() []CasbinInfo {
 	return []CasbinInfo{
 		{Path: "/menu/getMenu", Method: "POST"},
@@ -0,0 +1,20 @@
+package request
+
+// MoveDept 调整部门的上级部门
+type MoveDept struct {
+	ID       uint `json:"ID"`       // 部门ID
+	ParentId uint `json:"parentId"` // 新的上级部门ID 0为顶级部门
+}
+
+// MergeDept 将部门合并到另一部门 下级部门及成员迁移后删除原部门
+type MergeDept struct {
+	SourceId uint `json:"sourceId"` // 被合并的部门ID
+	TargetId uint `json:"targetId"` // 合并到的部门ID
+}
+
+// SetUserDepts 设置用户所属部门 覆盖用户原有的全部部门
+type SetUserDepts struct {
+	ID            uint   `json:"ID"`            // 用户ID
+	PrimaryDeptId uint   `json:"primaryDeptId"` // 主部门ID 0为不设置
+	DeptIds       []uint `json:"deptIds"`       // 次部门ID
+}
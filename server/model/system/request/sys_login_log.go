@@ -0,0 +1,13 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+type SysLoginLogSearch struct {
+	request.PageInfo
+	UserID   uint   `json:"userId" form:"userId"`     // 用户ID
+	Username string `json:"username" form:"username"` // 用户名
+	Ip       string `json:"ip" form:"ip"`             // 登录IP
+	Success  *bool  `json:"success" form:"success"`   // 是否登录成功 为空时不限
+}
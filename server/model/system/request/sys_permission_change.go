@@ -0,0 +1,16 @@
+package request
+
+import "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+
+// PermissionChangeSearch 权限变更申请的查询条件
+type PermissionChangeSearch struct {
+	request.PageInfo
+	Kind   string `json:"kind" form:"kind"`     // 变更类型
+	Status string `json:"status" form:"status"` // 状态
+}
+
+// DecidePermissionChange 审批权限变更
+type DecidePermissionChange struct {
+	ID      uint   `json:"ID"`      // 变更申请ID
+	Comment string `json:"comment"` // 审批意见
+}
@@ -0,0 +1,16 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+// TenantSearch 租户分页查询
+type TenantSearch struct {
+	request.PageInfo
+	Name string `json:"name" form:"name"` // 租户名称或编码
+}
+
+// SwitchTenant 超级管理员切换当前租户
+type SwitchTenant struct {
+	TenantId uint `json:"tenantId"` // 租户ID
+}
@@ -1,8 +1,11 @@
 package request
 
 import (
+	"time"
+
 	common "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/webauthn"
 )
 
 // Register User register structure
@@ -44,6 +47,21 @@ type SetUserAuthorities struct {
 	AuthorityIds []uint `json:"authorityIds"` // 角色ID
 }
 
+// GrantUserAuthority 授予用户限时角色
+type GrantUserAuthority struct {
+	ID          uint       `json:"id"`          // 用户ID
+	AuthorityId uint       `json:"authorityId"` // 角色ID
+	ValidFrom   *time.Time `json:"validFrom"`   // 生效时间 为空时立即生效
+	ValidUntil  time.Time  `json:"validUntil"`  // 失效时间
+	Reason      string     `json:"reason"`      // 授权原因
+}
+
+// RevokeUserAuthority 提前收回用户的限时角色
+type RevokeUserAuthority struct {
+	ID          uint `json:"id"`          // 用户ID
+	AuthorityId uint `json:"authorityId"` // 角色ID
+}
+
 type ChangeUserInfo struct {
 	ID           uint                  `gorm:"primarykey"`                                                                           // 主键ID
 	NickName     string                `json:"nickName" gorm:"default:系统用户;comment:用户昵称"`                                            // 用户昵称
@@ -53,6 +71,7 @@ type ChangeUserInfo struct {
 	HeaderImg    string                `json:"headerImg" gorm:"default:https://qmplusimg.henrongyi.top/gva_header.jpg;comment:用户头像"` // 用户头像
 	SideMode     string                `json:"sideMode"  gorm:"comment:用户侧边主题"`                                                      // 用户侧边主题
 	Enable       int                   `json:"enable" gorm:"comment:冻结用户"`                                                           //冻结用户
+	Password     string                `json:"password" gorm:"-"`                                                                    // 管理员设置的新密码 为空时不修改
 	Authorities  []system.SysAuthority `json:"-" gorm:"many2many:sys_user_authority;"`
 }
 
@@ -62,4 +81,114 @@ type GetUserList struct {
 	NickName string `json:"nickName" form:"nickName"`
 	Phone    string `json:"phone" form:"phone"`
 	Email    string `json:"email" form:"email"`
+	DeptId   uint   `json:"deptId" form:"deptId"`   // 所属部门 包含以该部门为次部门的用户
+	WithSub  bool   `json:"withSub" form:"withSub"` // 是否包含下级部门的用户
+}
+
+// TwoFactorLogin 登录二次验证
+type TwoFactorLogin struct {
+	TwoFactorToken   string                      `json:"twoFactorToken"`   // 密码校验通过后下发的二次验证票据
+	Code             string                      `json:"code"`             // 验证器App中的6位验证码
+	RecoveryCode     string                      `json:"recoveryCode"`     // 恢复码 无法使用验证器时使用
+	PasskeySessionId string                      `json:"passkeySessionId"` // 使用通行密钥验证时 passkeyTwoFactorBegin 下发的会话ID
+	Passkey          *webauthn.AssertionResponse `json:"passkey"`          // 使用通行密钥验证时浏览器返回的断言
+}
+
+// TwoFactorTicket 使用二次验证票据进行绑定
+type TwoFactorTicket struct {
+	TwoFactorToken string `json:"twoFactorToken"` // 二次验证票据
+}
+
+// TotpCode 两步验证码
+type TotpCode struct {
+	Code         string `json:"code"`         // 验证器App中的6位验证码
+	RecoveryCode string `json:"recoveryCode"` // 恢复码
+}
+
+// OidcLogin OIDC登录回调
+type OidcLogin struct {
+	Code  string `json:"code"`  // 身份提供方回调的授权码
+	State string `json:"state"` // 获取授权地址时下发的 state
+}
+
+// ChangeExpiredPassword 登录过程中修改密码
+type ChangeExpiredPassword struct {
+	PasswordToken string `json:"passwordToken"` // 需要修改密码时下发的票据
+	NewPassword   string `json:"newPassword"`   // 新密码
+}
+
+// ForgotPassword 申请找回密码
+type ForgotPassword struct {
+	Account string `json:"account"` // 用户名或邮箱
+}
+
+// ResetPasswordByToken 通过找回密码邮件重置密码
+type ResetPasswordByToken struct {
+	Token       string `json:"token"`       // 邮件链接中的重置令牌
+	NewPassword string `json:"newPassword"` // 新密码
+}
+
+// CreateApiKey 创建API密钥
+type CreateApiKey struct {
+	Name       string   `json:"name"`       // 名称
+	Scopes     []string `json:"scopes"`     // 可访问的接口范围 如 "*" "GET /user/getUserInfo" "/sysDictionary/*"
+	ExpireDays int      `json:"expireDays"` // 有效期 单位:天
+}
+
+// RevokeSession 注销登录会话
+type RevokeSession struct {
+	ID        uint   `json:"id"`        // 用户ID 管理员注销他人会话时使用
+	SessionId string `json:"sessionId"` // 会话ID
+}
+
+// SelfRegister 用户自助注册
+type SelfRegister struct {
+	Username  string `json:"userName"`  // 用户名
+	Password  string `json:"passWord"`  // 密码
+	NickName  string `json:"nickName"`  // 昵称
+	Email     string `json:"email"`     // 邮箱 用于接收验证邮件
+	Captcha   string `json:"captcha"`   // 验证码
+	CaptchaId string `json:"captchaId"` // 验证码ID
+}
+
+// VerifyRegisterEmail 验证自助注册邮箱
+type VerifyRegisterEmail struct {
+	Token string `json:"token"` // 验证邮件中的令牌
+}
+
+// RegistrationSearch 分页获取自助注册记录
+type RegistrationSearch struct {
+	common.PageInfo
+	Status string `json:"status" form:"status"` // 注册状态
+}
+
+// ReviewRegistration 审核自助注册
+type ReviewRegistration struct {
+	ID      uint   `json:"id"`      // 注册记录ID
+	Approve bool   `json:"approve"` // 是否通过 拒绝时删除该用户
+	Remark  string `json:"remark"`  // 审核备注
+}
+
+// PasskeyRegister 完成通行密钥注册
+type PasskeyRegister struct {
+	SessionId  string                       `json:"sessionId"`  // 开始注册时下发的会话ID
+	Name       string                       `json:"name"`       // 名称 便于区分设备
+	Credential webauthn.AttestationResponse `json:"credential"` // 浏览器返回的凭证
+}
+
+// PasskeyLoginBegin 开始通行密钥登录
+type PasskeyLoginBegin struct {
+	Username string `json:"username"` // 用户名 为空时由认证器选择可发现凭证
+}
+
+// PasskeyLogin 完成通行密钥登录
+type PasskeyLogin struct {
+	SessionId  string                     `json:"sessionId"`  // 开始登录时下发的会话ID
+	Credential webauthn.AssertionResponse `json:"credential"` // 浏览器返回的断言
+}
+
+// UpdatePasskey 修改通行密钥名称
+type UpdatePasskey struct {
+	ID   uint   `json:"id"`   // 通行密钥ID
+	Name string `json:"name"` // 名称
 }
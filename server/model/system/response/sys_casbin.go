@@ -1,9 +1,38 @@
 package response
 
 import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 )
 
 type PolicyPathResponse struct {
 	Paths []request.CasbinInfo `json:"paths"`
 }
+
+// CasbinExplainResponse 权限判定说明
+type CasbinExplainResponse struct {
+	AuthorityId   uint                    `json:"authorityId"`   // 参与判定的角色ID
+	Path          string                  `json:"path"`          // 路径
+	Method        string                  `json:"method"`        // 方法
+	Allowed       bool                    `json:"allowed"`       // 是否允许访问
+	Reason        string                  `json:"reason"`        // 判定说明
+	MatchedPolicy []string                `json:"matchedPolicy"` // 命中的策略 sub,obj,act 未命中时为空
+	Inherited     bool                    `json:"inherited"`     // 命中的策略是否继承自父角色
+	ApiExists     bool                    `json:"apiExists"`     // 是否存在于sys_apis
+	Api           *system.SysApi          `json:"api"`           // sys_apis中匹配的api
+	Ignored       bool                    `json:"ignored"`       // 是否存在于sys_ignore_apis
+	Menus         []system.SysMenu        `json:"menus"`         // 角色被授予的菜单
+	Btns          []system.SysBaseMenuBtn `json:"btns"`          // 角色被授予的按钮
+}
+
+// CasbinPolicyDiff 策略文件与当前环境的差异
+type CasbinPolicyDiff struct {
+	AddedApis          []request.CasbinPolicyApi `json:"addedApis"`          // 文件中新增的api
+	RemovedApis        []request.CasbinPolicyApi `json:"removedApis"`        // 文件中不存在的api
+	ChangedApis        []request.CasbinPolicyApi `json:"changedApis"`        // 分组或描述有变化的api 内容为文件中的值
+	AddedPolicies      []request.CasbinPolicy    `json:"addedPolicies"`      // 文件中新增的策略
+	RemovedPolicies    []request.CasbinPolicy    `json:"removedPolicies"`    // 文件中不存在的策略
+	UnknownAuthorities []uint                    `json:"unknownAuthorities"` // 当前环境不存在的角色 其策略不会导入
+	ApisSkipped        bool                      `json:"apisSkipped"`        // 是否跳过api同步 仅超级管理员在默认租户导入时同步共享的api
+	Applied            bool                      `json:"applied"`            // 是否已应用到当前环境
+}
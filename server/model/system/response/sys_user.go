@@ -2,6 +2,7 @@ package response
 
 import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/webauthn"
 )
 
 type SysUserResponse struct {
@@ -9,7 +10,86 @@ type SysUserResponse struct {
 }
 
 type LoginResponse struct {
+	User             system.SysUser `json:"user"`
+	Token            string         `json:"token"`
+	ExpiresAt        int64          `json:"expiresAt"`
+	RefreshToken     string         `json:"refreshToken"`     // 刷新令牌 访问令牌过期后用于换取新令牌
+	RefreshExpiresAt int64          `json:"refreshExpiresAt"` // 刷新令牌过期时间
+}
+
+// ImpersonateResponse 开始或结束模拟登录后签发的令牌 不下发刷新令牌
+type ImpersonateResponse struct {
 	User      system.SysUser `json:"user"`
 	Token     string         `json:"token"`
 	ExpiresAt int64          `json:"expiresAt"`
 }
+
+// TokenResponse 切换角色或租户后签发的新访问令牌 刷新令牌不变
+type TokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// RefreshTokenResponse 刷新令牌换取的新令牌
+type RefreshTokenResponse struct {
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken"` // 轮换后的刷新令牌 旧令牌已作废
+	RefreshExpiresAt int64  `json:"refreshExpiresAt"`
+}
+
+// TwoFactorResponse 密码校验通过但需要二次验证时返回
+type TwoFactorResponse struct {
+	NeedTwoFactor  bool   `json:"needTwoFactor"`  // 需要二次验证
+	NeedSetup      bool   `json:"needSetup"`      // 角色强制两步验证但用户尚未绑定 需要先绑定
+	Passkey        bool   `json:"passkey"`        // 可使用通行密钥完成二次验证
+	TwoFactorToken string `json:"twoFactorToken"` // 二次验证票据
+}
+
+// PasswordChangeResponse 登录时需要先修改密码时返回
+type PasswordChangeResponse struct {
+	NeedChangePassword bool   `json:"needChangePassword"` // 需要修改密码
+	Expired            bool   `json:"expired"`            // 密码已过期 否则为管理员要求修改
+	PasswordToken      string `json:"passwordToken"`      // 修改密码票据
+}
+
+// ResetPasswordResponse 重置后的一次性密码
+type ResetPasswordResponse struct {
+	Password string `json:"password"` // 一次性密码 仅展示一次 用户登录后必须修改
+}
+
+// TotpSetupResponse 两步验证绑定信息
+type TotpSetupResponse struct {
+	Secret        string   `json:"secret"`        // 密钥 供无法扫码时手动输入
+	Url           string   `json:"url"`           // otpauth地址 前端据此生成二维码
+	RecoveryCodes []string `json:"recoveryCodes"` // 恢复码 仅展示一次
+}
+
+// RecoveryCodesResponse 恢复码
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"` // 恢复码 仅展示一次
+}
+
+// CreateApiKeyResponse 创建的API密钥
+type CreateApiKeyResponse struct {
+	ApiKey system.SysApiKey `json:"apiKey"`
+	Key    string           `json:"key"` // 密钥明文 仅展示一次 调用时放在请求头 x-api-key 中
+}
+
+// OidcAuthUrlResponse OIDC授权地址
+type OidcAuthUrlResponse struct {
+	Url   string `json:"url"`   // 身份提供方授权地址 前端跳转至此
+	State string `json:"state"` // 回调时需原样提交
+}
+
+// PasskeyCreationResponse 通行密钥注册参数
+type PasskeyCreationResponse struct {
+	SessionId string                   `json:"sessionId"` // 完成注册时需原样提交
+	PublicKey webauthn.CreationOptions `json:"publicKey"` // navigator.credentials.create 的 publicKey 参数 二进制字段为base64url
+}
+
+// PasskeyRequestResponse 通行密钥登录参数
+type PasskeyRequestResponse struct {
+	SessionId string                  `json:"sessionId"` // 完成登录时需原样提交
+	PublicKey webauthn.RequestOptions `json:"publicKey"` // navigator.credentials.get 的 publicKey 参数 二进制字段为base64url
+}
@@ -16,6 +16,9 @@ func (SysApi) TableName() string {
 	return "sys_apis"
 }
 
+// TenantShared api定义为全部租户共用 各租户通过casbin域区分权限
+func (SysApi) TenantShared() {}
+
 type SysIgnoreApi struct {
 	global.GVA_MODEL
 	Path   string `json:"path" gorm:"comment:api路径"`             // api路径
@@ -26,3 +29,6 @@ type SysIgnoreApi struct {
 func (SysIgnoreApi) TableName() string {
 	return "sys_ignore_apis"
 }
+
+// TenantShared 忽略的api为全部租户共用
+func (SysIgnoreApi) TenantShared() {}
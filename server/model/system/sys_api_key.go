@@ -0,0 +1,27 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysApiKey 个人访问令牌 供脚本等机器客户端代替账号密码调用接口 仅保存摘要
+type SysApiKey struct {
+	global.GVA_MODEL
+	UserID     uint       `json:"userId" gorm:"index;comment:所属用户ID"`                       // 所属用户ID 调用时使用该用户当前角色鉴权
+	Name       string     `json:"name" gorm:"comment:名称"`                                   // 名称
+	Prefix     string     `json:"prefix" gorm:"size:16;comment:密钥前缀"`                       // 密钥前缀 用于识别 不可用于调用
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex;comment:密钥摘要"`                // 密钥摘要
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:text;comment:可访问的接口范围"` // 可访问的接口范围 如 "*" "GET /user/getUserInfo" "/sysDictionary/*"
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"comment:过期时间"`                            // 过期时间
+	LastUsedAt *time.Time `json:"lastUsedAt" gorm:"comment:最后使用时间"`                         // 最后使用时间
+	LastUsedIp string     `json:"lastUsedIp" gorm:"comment:最后使用IP"`                         // 最后使用IP
+}
+
+func (SysApiKey) TableName() string {
+	return "sys_api_keys"
+}
+
+// TenantShared API密钥按用户隔离 使用用户所属的租户
+func (SysApiKey) TenantShared() {}
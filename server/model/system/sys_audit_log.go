@@ -0,0 +1,48 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// 审计事件类型
+const (
+	AuditLoginFailed     = "login_failed"     // 登录失败
+	AuditLoginBlocked    = "login_blocked"    // 账号锁定期间尝试登录
+	AuditAccountLocked   = "account_locked"   // 账号因连续登录失败被锁定
+	AuditAccountUnlocked = "account_unlocked" // 管理员解锁账号
+	AuditPasswordForgot  = "password_forgot"  // 申请找回密码
+	AuditPasswordReset   = "password_reset"   // 通过找回密码邮件重置密码
+	AuditJwtKeyRotated   = "jwt_key_rotated"  // 轮换jwt签名密钥
+	AuditImpersonate     = "impersonate"      // 管理员模拟登录为该用户
+	AuditImpersonateEnd  = "impersonate_end"  // 管理员结束模拟登录
+	AuditRegistered      = "registered"       // 用户自助注册
+	AuditEmailVerified   = "email_verified"   // 自助注册用户验证邮箱
+	AuditUserApproved    = "user_approved"    // 管理员通过自助注册
+	AuditUserRejected    = "user_rejected"    // 管理员拒绝自助注册
+	AuditPasskeyAdded    = "passkey_added"    // 绑定通行密钥
+	AuditPasskeyRemoved  = "passkey_removed"  // 删除通行密钥
+	AuditRoleGranted     = "role_granted"     // 授予或续期限时角色
+	AuditRoleRevoked     = "role_revoked"     // 提前收回限时角色
+	AuditRoleExpired     = "role_expired"     // 限时角色到期自动收回
+	AuditPolicyImported  = "policy_imported"  // 导入casbin策略文件
+	AuditChangeRequested = "change_requested" // 提交权限变更申请
+	AuditChangeApproved  = "change_approved"  // 审批通过权限变更
+	AuditChangeRejected  = "change_rejected"  // 驳回或撤回权限变更
+	AuditTenantCreated   = "tenant_created"   // 创建租户
+	AuditTenantSwitched  = "tenant_switched"  // 超级管理员切换租户
+)
+
+// SysAuditLog 安全审计日志
+type SysAuditLog struct {
+	global.GVA_MODEL
+	Action     string `json:"action" form:"action" gorm:"size:64;index;comment:事件类型"`     // 事件类型
+	UserID     uint   `json:"userId" form:"userId" gorm:"index;comment:事件涉及的用户ID"`        // 事件涉及的用户ID 用户不存在时为0
+	Username   string `json:"username" form:"username" gorm:"size:191;index;comment:用户名"` // 事件涉及的用户名
+	OperatorID uint   `json:"operatorId" form:"operatorId" gorm:"comment:操作人ID"`          // 操作人ID 用户自身触发时为0
+	Ip         string `json:"ip" form:"ip" gorm:"comment:请求ip"`                           // 请求ip
+	Detail     string `json:"detail" form:"detail" gorm:"type:text;comment:详情"`           // 详情
+}
+
+func (SysAuditLog) TableName() string {
+	return "sys_audit_logs"
+}
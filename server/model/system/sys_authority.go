@@ -5,17 +5,21 @@ import (
 )
 
 type SysAuthority struct {
-	CreatedAt       time.Time       // 创建时间
-	UpdatedAt       time.Time       // 更新时间
-	DeletedAt       *time.Time      `sql:"index"`
-	AuthorityId     uint            `json:"authorityId" gorm:"not null;unique;primary_key;comment:角色ID;size:90"` // 角色ID
-	AuthorityName   string          `json:"authorityName" gorm:"comment:角色名"`                                    // 角色名
-	ParentId        *uint           `json:"parentId" gorm:"comment:父角色ID"`                                       // 父角色ID
-	DataAuthorityId []*SysAuthority `json:"dataAuthorityId" gorm:"many2many:sys_data_authority_id;"`
-	Children        []SysAuthority  `json:"children" gorm:"-"`
-	SysBaseMenus    []SysBaseMenu   `json:"menus" gorm:"many2many:sys_authority_menus;"`
-	Users           []SysUser       `json:"-" gorm:"many2many:sys_user_authority;"`
-	DefaultRouter   string          `json:"defaultRouter" gorm:"comment:默认菜单;default:dashboard"` // 默认菜单(默认dashboard)
+	CreatedAt        time.Time       // 创建时间
+	UpdatedAt        time.Time       // 更新时间
+	DeletedAt        *time.Time      `sql:"index"`
+	AuthorityId      uint            `json:"authorityId" gorm:"not null;unique;primary_key;comment:角色ID;size:90"` // 角色ID
+	AuthorityName    string          `json:"authorityName" gorm:"comment:角色名"`                                    // 角色名
+	ParentId         *uint           `json:"parentId" gorm:"comment:父角色ID"`                                       // 父角色ID
+	DataAuthorityId  []*SysAuthority `json:"dataAuthorityId" gorm:"many2many:sys_data_authority_id;"`
+	Children         []SysAuthority  `json:"children" gorm:"-"`
+	SysBaseMenus     []SysBaseMenu   `json:"menus" gorm:"many2many:sys_authority_menus;"`
+	Users            []SysUser       `json:"-" gorm:"many2many:sys_user_authority;"`
+	DefaultRouter    string          `json:"defaultRouter" gorm:"comment:默认菜单;default:dashboard"`    // 默认菜单(默认dashboard)
+	RequireTwoFactor bool            `json:"requireTwoFactor" gorm:"default:false;comment:是否强制两步验证"` // 是否强制该角色用户开启两步验证
+	MaxSessions      int             `json:"maxSessions" gorm:"default:0;comment:最大同时在线会话数"`         // 该角色用户最多同时保持的登录会话数 0为不限制 超出时注销最早的会话
+	DataScope        string          `json:"dataScope" gorm:"size:20;default:all;comment:数据范围"`      // 数据范围 all self dept dept_tree custom 对接入数据权限的模型生效
+	DataScopeRule    string          `json:"dataScopeRule" gorm:"size:500;comment:自定义数据范围条件"`        // 数据范围为custom时的查询条件 可使用 @userId @authorityId
 }
 
 func (SysAuthority) TableName() string {
@@ -0,0 +1,13 @@
+package system
+
+// SysAuthorityFieldRule 角色的字段规则 控制角色对模型字段的可见性及可修改性
+type SysAuthorityFieldRule struct {
+	AuthorityId uint   `json:"authorityId" gorm:"uniqueIndex:idx_authority_field;comment:角色ID"`
+	Model       string `json:"model" gorm:"uniqueIndex:idx_authority_field;size:64;comment:模型名"`
+	Field       string `json:"field" gorm:"uniqueIndex:idx_authority_field;size:64;comment:字段json名"`
+	Rule        string `json:"rule" gorm:"size:20;comment:规则 hidden masked readonly"`
+}
+
+func (SysAuthorityFieldRule) TableName() string {
+	return "sys_authority_field_rules"
+}
@@ -66,3 +66,6 @@ func (s *SysAutoCodeHistory) BeforeCreate(db *gorm.DB) error {
 func (s *SysAutoCodeHistory) TableName() string {
 	return "sys_auto_code_histories"
 }
+
+// TenantShared 代码生成历史为开发期记录 不区分租户
+func (SysAutoCodeHistory) TenantShared() {}
@@ -16,3 +16,6 @@ type SysAutoCodePackage struct {
 func (s *SysAutoCodePackage) TableName() string {
 	return "sys_auto_code_packages"
 }
+
+// TenantShared 代码生成包为开发期配置 不区分租户
+func (SysAutoCodePackage) TenantShared() {}
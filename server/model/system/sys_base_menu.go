@@ -38,6 +38,12 @@ type SysBaseMenuParameter struct {
 	Value         string `json:"value" gorm:"comment:地址栏携带参数的值"`             // 地址栏携带参数的值
 }
 
+// TenantShared 菜单参数随菜单共用
+func (SysBaseMenuParameter) TenantShared() {}
+
 func (SysBaseMenu) TableName() string {
 	return "sys_base_menus"
 }
+
+// TenantShared 菜单定义为全部租户共用
+func (SysBaseMenu) TenantShared() {}
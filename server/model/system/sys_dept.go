@@ -0,0 +1,30 @@
+package system
+
+import "github.com/flipped-aurora/gin-vue-admin/server/global"
+
+// SysDept 部门 与角色相互独立 用于描述组织架构
+type SysDept struct {
+	global.GVA_MODEL
+	ParentId uint      `json:"parentId" form:"parentId" gorm:"index;default:0;comment:上级部门ID"` // 上级部门ID 0为顶级部门
+	Name     string    `json:"name" form:"name" gorm:"size:64;comment:部门名称"`                   // 部门名称
+	Sort     int       `json:"sort" form:"sort" gorm:"default:0;comment:排序"`                   // 排序
+	LeaderId uint      `json:"leaderId" form:"leaderId" gorm:"default:0;comment:部门负责人ID"`      // 部门负责人ID 0为未设置
+	Leader   *SysUser  `json:"leader,omitempty" gorm:"foreignKey:LeaderId"`                    // 部门负责人
+	Children []SysDept `json:"children" gorm:"-"`                                              // 下级部门
+}
+
+func (SysDept) TableName() string {
+	return "sys_depts"
+}
+
+// SysUserDept 是 sysUser 和 sysDept 的连接表 每个用户至多一个主部门
+type SysUserDept struct {
+	SysUserId uint    `json:"sysUserId" gorm:"primaryKey;comment:用户ID"`
+	SysDeptId uint    `json:"sysDeptId" gorm:"primaryKey;index;comment:部门ID"`
+	IsPrimary bool    `json:"isPrimary" gorm:"default:false;comment:是否为主部门"`
+	Dept      SysDept `json:"dept" gorm:"foreignKey:SysDeptId"`
+}
+
+func (SysUserDept) TableName() string {
+	return "sys_user_depts"
+}
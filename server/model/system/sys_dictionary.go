@@ -18,3 +18,6 @@ type SysDictionary struct {
 func (SysDictionary) TableName() string {
 	return "sys_dictionaries"
 }
+
+// TenantShared 字典为全部租户共用的系统配置
+func (SysDictionary) TenantShared() {}
@@ -19,3 +19,6 @@ type SysDictionaryDetail struct {
 func (SysDictionaryDetail) TableName() string {
 	return "sys_dictionary_details"
 }
+
+// TenantShared 字典详情随字典共用
+func (SysDictionaryDetail) TenantShared() {}
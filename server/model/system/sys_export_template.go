@@ -19,6 +19,9 @@ type SysExportTemplate struct {
 	JoinTemplate []JoinTemplate `json:"joinTemplate" form:"joinTemplate" gorm:"foreignKey:TemplateID;references:TemplateID;comment:关联"`
 }
 
+// TenantShared 导出模板为全部租户共用
+func (SysExportTemplate) TenantShared() {}
+
 type JoinTemplate struct {
 	global.GVA_MODEL
 	TemplateID string `json:"templateID" form:"templateID" gorm:"column:template_id;comment:模板标识"`
@@ -31,6 +34,9 @@ func (JoinTemplate) TableName() string {
 	return "sys_export_template_join"
 }
 
+// TenantShared 关联条件随导出模板共用
+func (JoinTemplate) TenantShared() {}
+
 type Condition struct {
 	global.GVA_MODEL
 	TemplateID string `json:"templateID" form:"templateID" gorm:"column:template_id;comment:模板标识"`
@@ -42,3 +48,6 @@ type Condition struct {
 func (Condition) TableName() string {
 	return "sys_export_template_condition"
 }
+
+// TenantShared 查询条件随导出模板共用
+func (Condition) TenantShared() {}
@@ -8,3 +8,6 @@ type JwtBlacklist struct {
 	global.GVA_MODEL
 	Jwt string `gorm:"type:text;comment:jwt"`
 }
+
+// TenantShared 令牌黑名单按令牌隔离
+func (JwtBlacklist) TenantShared() {}
@@ -0,0 +1,25 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysJwtKey jwt非对称签名密钥 多实例共享 轮换后旧密钥在宽限期内仍可验签
+type SysJwtKey struct {
+	global.GVA_MODEL
+	Kid        string     `json:"kid" gorm:"size:64;uniqueIndex;comment:密钥ID"` // 密钥ID 写入jwt头部
+	Algorithm  string     `json:"algorithm" gorm:"size:16;comment:签名算法"`       // 签名算法
+	PrivateKey string     `json:"-" gorm:"type:text;comment:私钥"`               // PKCS8 私钥PEM
+	PublicKey  string     `json:"publicKey" gorm:"type:text;comment:公钥"`       // PKIX 公钥PEM
+	RetiredAt  *time.Time `json:"retiredAt" gorm:"index;comment:停止签发时间"`       // 停止签发时间 为空时为当前签发密钥
+	ExpiresAt  *time.Time `json:"expiresAt" gorm:"comment:停止验签时间"`             // 停止验签时间 宽限期结束
+}
+
+func (SysJwtKey) TableName() string {
+	return "sys_jwt_keys"
+}
+
+// TenantShared 签名密钥为全部租户共用
+func (SysJwtKey) TenantShared() {}
@@ -0,0 +1,24 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysLoginLockout 按用户名记录的登录失败与锁定状态
+type SysLoginLockout struct {
+	global.GVA_MODEL
+	Username     string     `json:"username" gorm:"size:191;uniqueIndex;comment:登录用户名"` // 登录用户名 不存在的用户名同样记录 避免据此探测账号
+	FailedCount  int        `json:"failedCount" gorm:"comment:窗口期内连续失败次数"`              // 窗口期内连续失败次数
+	LockCount    int        `json:"lockCount" gorm:"comment:连续锁定次数"`                    // 连续锁定次数 用于计算退避时长 登录成功后清零
+	LastFailedAt *time.Time `json:"lastFailedAt" gorm:"comment:最后失败时间"`                 // 最后失败时间
+	LockedUntil  *time.Time `json:"lockedUntil" gorm:"index;comment:锁定截止时间"`            // 锁定截止时间
+}
+
+func (SysLoginLockout) TableName() string {
+	return "sys_login_lockouts"
+}
+
+// TenantShared 登录锁定在登录前按用户名查询 不区分租户
+func (SysLoginLockout) TenantShared() {}
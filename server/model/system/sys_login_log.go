@@ -0,0 +1,26 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysLoginLog 登录日志 每次登录尝试记录一条
+type SysLoginLog struct {
+	global.GVA_MODEL
+	UserID    uint   `json:"userId" form:"userId" gorm:"index;comment:用户ID"`             // 用户ID 用户不存在时为0
+	Username  string `json:"username" form:"username" gorm:"size:191;index;comment:用户名"` // 登录时填写的用户名
+	Success   bool   `json:"success" form:"success" gorm:"comment:是否登录成功"`               // 是否登录成功
+	Reason    string `json:"reason" form:"reason" gorm:"size:255;comment:失败原因"`          // 失败原因
+	Ip        string `json:"ip" form:"ip" gorm:"size:64;comment:登录IP"`                   // 登录IP
+	Country   string `json:"country" gorm:"size:64;comment:国家"`                          // 国家
+	Province  string `json:"province" gorm:"size:64;comment:省份"`                         // 省份
+	City      string `json:"city" gorm:"size:64;comment:城市"`                             // 城市
+	UserAgent string `json:"userAgent" gorm:"size:512;comment:客户端标识"`                    // 客户端标识
+	Browser   string `json:"browser" gorm:"size:64;comment:浏览器"`                         // 浏览器
+	Os        string `json:"os" gorm:"size:64;comment:操作系统"`                             // 操作系统
+	Device    string `json:"device" gorm:"size:16;comment:设备类型"`                         // 设备类型 desktop mobile tablet bot other
+}
+
+func (SysLoginLog) TableName() string {
+	return "sys_login_logs"
+}
@@ -8,3 +8,6 @@ type SysBaseMenuBtn struct {
 	Desc          string `json:"desc" gorm:"按钮备注"`
 	SysBaseMenuID uint   `json:"sysBaseMenuID" gorm:"comment:菜单ID"`
 }
+
+// TenantShared 菜单按钮随菜单共用
+func (SysBaseMenuBtn) TenantShared() {}
@@ -10,15 +10,17 @@ import (
 // 如果含有time.Time 请自行import time包
 type SysOperationRecord struct {
 	global.GVA_MODEL
-	Ip           string        `json:"ip" form:"ip" gorm:"column:ip;comment:请求ip"`                                   // 请求ip
-	Method       string        `json:"method" form:"method" gorm:"column:method;comment:请求方法"`                       // 请求方法
-	Path         string        `json:"path" form:"path" gorm:"column:path;comment:请求路径"`                             // 请求路径
-	Status       int           `json:"status" form:"status" gorm:"column:status;comment:请求状态"`                       // 请求状态
-	Latency      time.Duration `json:"latency" form:"latency" gorm:"column:latency;comment:延迟" swaggertype:"string"` // 延迟
-	Agent        string        `json:"agent" form:"agent" gorm:"type:text;column:agent;comment:代理"`                  // 代理
-	ErrorMessage string        `json:"error_message" form:"error_message" gorm:"column:error_message;comment:错误信息"`  // 错误信息
-	Body         string        `json:"body" form:"body" gorm:"type:text;column:body;comment:请求Body"`                 // 请求Body
-	Resp         string        `json:"resp" form:"resp" gorm:"type:text;column:resp;comment:响应Body"`                 // 响应Body
-	UserID       int           `json:"user_id" form:"user_id" gorm:"column:user_id;comment:用户id"`                    // 用户id
-	User         SysUser       `json:"user"`
+	Ip             string        `json:"ip" form:"ip" gorm:"column:ip;comment:请求ip"`                                   // 请求ip
+	Method         string        `json:"method" form:"method" gorm:"column:method;comment:请求方法"`                       // 请求方法
+	Path           string        `json:"path" form:"path" gorm:"column:path;comment:请求路径"`                             // 请求路径
+	Status         int           `json:"status" form:"status" gorm:"column:status;comment:请求状态"`                       // 请求状态
+	Latency        time.Duration `json:"latency" form:"latency" gorm:"column:latency;comment:延迟" swaggertype:"string"` // 延迟
+	Agent          string        `json:"agent" form:"agent" gorm:"type:text;column:agent;comment:代理"`                  // 代理
+	ErrorMessage   string        `json:"error_message" form:"error_message" gorm:"column:error_message;comment:错误信息"`  // 错误信息
+	Body           string        `json:"body" form:"body" gorm:"type:text;column:body;comment:请求Body"`                 // 请求Body
+	Resp           string        `json:"resp" form:"resp" gorm:"type:text;column:resp;comment:响应Body"`                 // 响应Body
+	UserID         int           `json:"user_id" form:"user_id" gorm:"column:user_id;comment:用户id"`                    // 用户id
+	User           SysUser       `json:"user"`
+	ImpersonatorID int           `json:"impersonator_id" form:"impersonator_id" gorm:"column:impersonator_id;comment:模拟登录的管理员id"` // 模拟登录的管理员id 为0时为用户本人操作
+	Impersonator   SysUser       `json:"impersonator" gorm:"foreignKey:ImpersonatorID"`
 }
@@ -18,3 +18,6 @@ type SysParams struct {
 func (SysParams) TableName() string {
 	return "sys_params"
 }
+
+// TenantShared 参数为全部租户共用的系统配置
+func (SysParams) TenantShared() {}
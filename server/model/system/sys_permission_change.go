@@ -0,0 +1,48 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// 权限变更类型
+const (
+	PermissionChangeCasbin        = "casbin"         // 角色api权限
+	PermissionChangeMenu          = "menu"           // 角色菜单权限
+	PermissionChangeDataAuthority = "data_authority" // 角色资源权限
+	PermissionChangeUserAuthority = "user_authority" // 用户角色
+)
+
+// 权限变更申请状态
+const (
+	PermissionChangePending  = "pending"  // 待审批
+	PermissionChangeApproved = "approved" // 已通过并生效
+	PermissionChangeRejected = "rejected" // 已驳回或撤回
+	PermissionChangeExpired  = "expired"  // 超时未审批
+	PermissionChangeFailed   = "failed"   // 审批通过但应用失败
+)
+
+// SysPermissionChange 待审批的权限变更 开启权限变更审批后由另一名管理员审批后生效
+type SysPermissionChange struct {
+	global.GVA_MODEL
+	Kind          string     `json:"kind" gorm:"size:32;index;comment:变更类型"`                 // 变更类型
+	TargetId      uint       `json:"targetId" gorm:"comment:变更对象ID"`                         // 变更对象 角色ID或用户ID
+	Payload       string     `json:"-" gorm:"type:text;comment:变更内容"`                        // 变更内容 审批通过时按此执行
+	BeforeDigest  string     `json:"-" gorm:"size:64;comment:申请时的权限摘要"`                      // 申请时的权限摘要 审批时权限已被修改则不再应用
+	Added         []string   `json:"added" gorm:"serializer:json;type:text;comment:新增的权限"`   // 新增的权限
+	Removed       []string   `json:"removed" gorm:"serializer:json;type:text;comment:移除的权限"` // 移除的权限
+	Status        string     `json:"status" gorm:"size:20;index;default:pending;comment:状态"` // 状态
+	RequesterId   uint       `json:"requesterId" gorm:"comment:申请人ID"`                       // 申请人ID
+	RequesterName string     `json:"requesterName" gorm:"comment:申请人用户名"`                    // 申请人用户名
+	ApproverId    uint       `json:"approverId" gorm:"default:0;comment:审批人ID"`              // 审批人ID
+	ApproverName  string     `json:"approverName" gorm:"comment:审批人用户名"`                     // 审批人用户名
+	Comment       string     `json:"comment" gorm:"comment:审批意见"`                            // 审批意见
+	Error         string     `json:"error" gorm:"type:text;comment:应用失败的原因"`                 // 应用失败的原因
+	ExpiresAt     time.Time  `json:"expiresAt" gorm:"index;comment:失效时间"`                    // 失效时间
+	DecidedAt     *time.Time `json:"decidedAt" gorm:"comment:审批时间"`                          // 审批时间
+}
+
+func (SysPermissionChange) TableName() string {
+	return "sys_permission_changes"
+}
@@ -0,0 +1,25 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysRefreshToken 刷新令牌 仅保存摘要 每次刷新轮换 同一次登录产生的令牌属于同一家族
+type SysRefreshToken struct {
+	global.GVA_MODEL
+	UserID    uint       `json:"userId" gorm:"index;comment:用户ID"`           // 用户ID
+	FamilyID  string     `json:"familyId" gorm:"index;size:64;comment:令牌家族"` // 令牌家族 登录时生成 轮换时沿用
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;comment:令牌摘要"`  // 令牌摘要
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"index;comment:过期时间"`        // 过期时间
+	UsedAt    *time.Time `json:"usedAt" gorm:"comment:轮换时间"`                 // 轮换时间 已轮换的令牌再次使用视为泄露
+	RevokedAt *time.Time `json:"revokedAt" gorm:"comment:吊销时间"`              // 吊销时间
+}
+
+func (SysRefreshToken) TableName() string {
+	return "sys_refresh_tokens"
+}
+
+// TenantShared 刷新令牌按用户隔离
+func (SysRefreshToken) TenantShared() {}
@@ -0,0 +1,21 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysTenant 租户 默认租户在初始化数据库时创建 租户内的数据通过tenant_id隔离
+type SysTenant struct {
+	global.GVA_MODEL
+	Name   string `json:"name" gorm:"size:64;comment:租户名称"`               // 租户名称
+	Code   string `json:"code" gorm:"uniqueIndex;size:64;comment:租户编码"`   // 租户编码
+	Enable int    `json:"enable" gorm:"default:1;comment:租户是否启用 1启用 2停用"` // 租户是否启用 停用后租户内的用户不能登录
+	Remark string `json:"remark" gorm:"size:255;comment:备注"`              // 备注
+}
+
+func (SysTenant) TableName() string {
+	return "sys_tenants"
+}
+
+// TenantShared 租户表本身不做租户隔离
+func (SysTenant) TenantShared() {}
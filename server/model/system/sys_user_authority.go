@@ -1,9 +1,16 @@
 package system
 
+import "time"
+
 // SysUserAuthority 是 sysUser 和 sysAuthority 的连接表
 type SysUserAuthority struct {
-	SysUserId               uint `gorm:"column:sys_user_id"`
-	SysAuthorityAuthorityId uint `gorm:"column:sys_authority_authority_id"`
+	SysUserId               uint       `json:"sysUserId" gorm:"column:sys_user_id"`
+	SysAuthorityAuthorityId uint       `json:"authorityId" gorm:"column:sys_authority_authority_id"`
+	ValidFrom               *time.Time `json:"validFrom" gorm:"comment:生效时间 为空时立即生效"`         // 生效时间
+	ValidUntil              *time.Time `json:"validUntil" gorm:"index;comment:失效时间 为空时长期有效"`  // 失效时间 为空时为长期角色
+	GrantedBy               uint       `json:"grantedBy" gorm:"default:0;comment:限时授权的操作人ID"` // 限时授权的操作人
+	Reason                  string     `json:"reason" gorm:"comment:限时授权的原因"`                 // 限时授权的原因
+	Notified                bool       `json:"-" gorm:"default:false;comment:是否已发送到期提醒"`
 }
 
 func (s *SysUserAuthority) TableName() string {
@@ -0,0 +1,20 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysUserIdentity 外部身份源账号与系统用户的绑定关系
+type SysUserIdentity struct {
+	global.GVA_MODEL
+	UserID   uint   `json:"userId" gorm:"index;comment:用户ID"`                                         // 用户ID
+	Provider string `json:"provider" gorm:"size:32;uniqueIndex:idx_provider_subject;comment:身份源"`     // 身份源 如 oidc ldap
+	Subject  string `json:"subject" gorm:"size:191;uniqueIndex:idx_provider_subject;comment:身份源用户标识"` // 身份源中的唯一标识
+}
+
+func (SysUserIdentity) TableName() string {
+	return "sys_user_identities"
+}
+
+// TenantShared 第三方身份在登录前查询 按用户隔离
+func (SysUserIdentity) TenantShared() {}
@@ -0,0 +1,27 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysUserPasskey 用户绑定的通行密钥(WebAuthn凭证)
+type SysUserPasskey struct {
+	global.GVA_MODEL
+	UserID       uint       `json:"userId" gorm:"index;comment:用户ID"`                      // 用户ID
+	Name         string     `json:"name" gorm:"size:64;comment:名称"`                        // 用户自定义的名称 便于区分设备
+	CredentialID string     `json:"credentialId" gorm:"size:255;uniqueIndex;comment:凭证ID"` // 凭证ID base64url
+	PublicKey    string     `json:"-" gorm:"type:text;comment:公钥"`                         // COSE格式公钥 base64
+	SignCount    uint32     `json:"-" gorm:"comment:签名计数"`                                 // 认证器签名计数 用于发现被克隆的认证器
+	AAGUID       string     `json:"aaguid" gorm:"column:aaguid;size:36;comment:认证器型号标识"`   // 认证器型号标识
+	Transports   string     `json:"transports" gorm:"size:64;comment:传输方式"`                // 认证器支持的传输方式 逗号分隔
+	LastUsedAt   *time.Time `json:"lastUsedAt" gorm:"comment:最后使用时间"`                      // 最后使用时间
+}
+
+func (SysUserPasskey) TableName() string {
+	return "sys_user_passkeys"
+}
+
+// TenantShared 通行密钥按用户隔离
+func (SysUserPasskey) TenantShared() {}
@@ -0,0 +1,22 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysUserRecoveryCode 两步验证恢复码 仅保存摘要
+type SysUserRecoveryCode struct {
+	global.GVA_MODEL
+	UserID   uint       `json:"userId" gorm:"index;comment:用户ID"` // 用户ID
+	CodeHash string     `json:"-" gorm:"size:64;comment:恢复码摘要"`   // 恢复码摘要
+	UsedAt   *time.Time `json:"usedAt" gorm:"comment:使用时间"`       // 使用时间 为空代表未使用
+}
+
+func (SysUserRecoveryCode) TableName() string {
+	return "sys_user_recovery_codes"
+}
+
+// TenantShared 恢复码按用户隔离
+func (SysUserRecoveryCode) TenantShared() {}
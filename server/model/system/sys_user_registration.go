@@ -0,0 +1,33 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// 自助注册状态
+const (
+	RegisterUnverified = "unverified" // 待验证邮箱
+	RegisterPending    = "pending"    // 待管理员审核
+	RegisterApproved   = "approved"   // 已通过
+	RegisterRejected   = "rejected"   // 已拒绝
+)
+
+// SysUserRegistration 用户自助注册记录 通过前对应用户处于冻结状态
+type SysUserRegistration struct {
+	global.GVA_MODEL
+	UserID     uint       `json:"userId" gorm:"uniqueIndex;comment:用户ID"`   // 用户ID
+	Username   string     `json:"username" gorm:"size:191;comment:用户名"`     // 用户名
+	Email      string     `json:"email" gorm:"size:191;comment:注册邮箱"`       // 注册邮箱
+	Ip         string     `json:"ip" gorm:"size:64;comment:注册IP"`           // 注册IP
+	Status     string     `json:"status" gorm:"size:16;index;comment:注册状态"` // 注册状态
+	VerifiedAt *time.Time `json:"verifiedAt" gorm:"comment:邮箱验证时间"`         // 邮箱验证时间
+	ReviewerID uint       `json:"reviewerId" gorm:"comment:审核人ID"`          // 审核人ID
+	ReviewedAt *time.Time `json:"reviewedAt" gorm:"comment:审核时间"`           // 审核时间
+	Remark     string     `json:"remark" gorm:"comment:审核备注"`               // 审核备注
+}
+
+func (SysUserRegistration) TableName() string {
+	return "sys_user_registrations"
+}
@@ -0,0 +1,27 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysUserSession 用户登录会话 每次登录产生一个会话 会话ID同时作为刷新令牌家族
+type SysUserSession struct {
+	global.GVA_MODEL
+	UserID     uint       `json:"userId" gorm:"index;comment:用户ID"`                  // 用户ID
+	SessionID  string     `json:"sessionId" gorm:"uniqueIndex;size:64;comment:会话ID"` // 会话ID
+	IP         string     `json:"ip" gorm:"size:64;comment:登录IP"`                    // 登录IP
+	UserAgent  string     `json:"userAgent" gorm:"size:512;comment:客户端标识"`           // 客户端标识
+	LastSeenAt time.Time  `json:"lastSeenAt" gorm:"comment:最后活跃时间"`                  // 最后活跃时间
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"index;comment:过期时间"`               // 过期时间 随刷新令牌延长
+	RevokedAt  *time.Time `json:"revokedAt" gorm:"comment:注销时间"`                     // 注销时间
+	Current    bool       `json:"current" gorm:"-"`                                  // 是否为当前请求所在的会话
+}
+
+func (SysUserSession) TableName() string {
+	return "sys_user_sessions"
+}
+
+// TenantShared 会话按用户隔离 tenant_id记录会话当前所在的租户 超级管理员切换租户后随之变更
+func (SysUserSession) TenantShared() {}
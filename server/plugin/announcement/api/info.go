@@ -29,7 +29,7 @@ func (a *info) CreateInfo(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = serviceInfo.CreateInfo(&info)
+	err = serviceInfo.CreateInfo(c.Request.Context(), &info)
 	if err != nil {
 		global.GVA_LOG.Error("创建失败!", zap.Error(err))
 		response.FailWithMessage("创建失败", c)
@@ -49,7 +49,7 @@ func (a *info) CreateInfo(c *gin.Context) {
 // @Router /info/deleteInfo [delete]
 func (a *info) DeleteInfo(c *gin.Context) {
 	ID := c.Query("ID")
-	err := serviceInfo.DeleteInfo(ID)
+	err := serviceInfo.DeleteInfo(c.Request.Context(), ID)
 	if err != nil {
 		global.GVA_LOG.Error("删除失败!", zap.Error(err))
 		response.FailWithMessage("删除失败", c)
@@ -68,7 +68,7 @@ func (a *info) DeleteInfo(c *gin.Context) {
 // @Router /info/deleteInfoByIds [delete]
 func (a *info) DeleteInfoByIds(c *gin.Context) {
 	IDs := c.QueryArray("IDs[]")
-	if err := serviceInfo.DeleteInfoByIds(IDs); err != nil {
+	if err := serviceInfo.DeleteInfoByIds(c.Request.Context(), IDs); err != nil {
 		global.GVA_LOG.Error("批量删除失败!", zap.Error(err))
 		response.FailWithMessage("批量删除失败", c)
 		return
@@ -92,7 +92,7 @@ func (a *info) UpdateInfo(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = serviceInfo.UpdateInfo(info)
+	err = serviceInfo.UpdateInfo(c.Request.Context(), info)
 	if err != nil {
 		global.GVA_LOG.Error("更新失败!", zap.Error(err))
 		response.FailWithMessage("更新失败", c)
@@ -112,7 +112,7 @@ func (a *info) UpdateInfo(c *gin.Context) {
 // @Router /info/findInfo [get]
 func (a *info) FindInfo(c *gin.Context) {
 	ID := c.Query("ID")
-	reinfo, err := serviceInfo.GetInfo(ID)
+	reinfo, err := serviceInfo.GetInfo(c.Request.Context(), ID)
 	if err != nil {
 		global.GVA_LOG.Error("查询失败!", zap.Error(err))
 		response.FailWithMessage("查询失败", c)
@@ -137,7 +137,7 @@ func (a *info) GetInfoList(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	list, total, err := serviceInfo.GetInfoInfoList(pageInfo)
+	list, total, err := serviceInfo.GetInfoInfoList(c.Request.Context(), pageInfo)
 	if err != nil {
 		global.GVA_LOG.Error("获取失败!", zap.Error(err))
 		response.FailWithMessage("获取失败", c)
@@ -160,7 +160,7 @@ func (a *info) GetInfoList(c *gin.Context) {
 // @Router /info/getInfoDataSource [get]
 func (a *info) GetInfoDataSource(c *gin.Context) {
 	// 此接口为获取数据源定义的数据
-	dataSource, err := serviceInfo.GetInfoDataSource()
+	dataSource, err := serviceInfo.GetInfoDataSource(c.Request.Context())
 	if err != nil {
 		global.GVA_LOG.Error("查询失败!", zap.Error(err))
 		response.FailWithMessage("查询失败", c)
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement/model"
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement/model/request"
 )
@@ -12,46 +15,46 @@ type info struct{}
 
 // CreateInfo 创建公告记录
 // Author [piexlmax](https://github.com/piexlmax)
-func (s *info) CreateInfo(info *model.Info) (err error) {
-	err = global.GVA_DB.Create(info).Error
+func (s *info) CreateInfo(ctx context.Context, info *model.Info) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Create(info).Error
 	return err
 }
 
 // DeleteInfo 删除公告记录
 // Author [piexlmax](https://github.com/piexlmax)
-func (s *info) DeleteInfo(ID string) (err error) {
-	err = global.GVA_DB.Delete(&model.Info{}, "id = ?", ID).Error
+func (s *info) DeleteInfo(ctx context.Context, ID string) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Delete(&model.Info{}, "id = ?", ID).Error
 	return err
 }
 
 // DeleteInfoByIds 批量删除公告记录
 // Author [piexlmax](https://github.com/piexlmax)
-func (s *info) DeleteInfoByIds(IDs []string) (err error) {
-	err = global.GVA_DB.Delete(&[]model.Info{}, "id in ?", IDs).Error
+func (s *info) DeleteInfoByIds(ctx context.Context, IDs []string) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Delete(&[]model.Info{}, "id in ?", IDs).Error
 	return err
 }
 
 // UpdateInfo 更新公告记录
 // Author [piexlmax](https://github.com/piexlmax)
-func (s *info) UpdateInfo(info model.Info) (err error) {
-	err = global.GVA_DB.Model(&model.Info{}).Where("id = ?", info.ID).Updates(&info).Error
+func (s *info) UpdateInfo(ctx context.Context, info model.Info) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Model(&model.Info{}).Where("id = ?", info.ID).Updates(&info).Error
 	return err
 }
 
 // GetInfo 根据ID获取公告记录
 // Author [piexlmax](https://github.com/piexlmax)
-func (s *info) GetInfo(ID string) (info model.Info, err error) {
-	err = global.GVA_DB.Where("id = ?", ID).First(&info).Error
+func (s *info) GetInfo(ctx context.Context, ID string) (info model.Info, err error) {
+	err = global.GVA_DB.WithContext(ctx).Where("id = ?", ID).First(&info).Error
 	return
 }
 
 // GetInfoInfoList 分页获取公告记录
 // Author [piexlmax](https://github.com/piexlmax)
-func (s *info) GetInfoInfoList(info request.InfoSearch) (list []model.Info, total int64, err error) {
+func (s *info) GetInfoInfoList(ctx context.Context, info request.InfoSearch) (list []model.Info, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
 	// 创建db
-	db := global.GVA_DB.Model(&model.Info{})
+	db := global.GVA_DB.WithContext(ctx).Model(&model.Info{})
 	var infos []model.Info
 	// 如果有条件搜索 下方会自动创建搜索语句
 	if info.StartCreatedAt != nil && info.EndCreatedAt != nil {
@@ -68,11 +71,11 @@ func (s *info) GetInfoInfoList(info request.InfoSearch) (list []model.Info, tota
 	err = db.Find(&infos).Error
 	return infos, total, err
 }
-func (s *info) GetInfoDataSource() (res map[string][]map[string]any, err error) {
+func (s *info) GetInfoDataSource(ctx context.Context) (res map[string][]map[string]any, err error) {
 	res = make(map[string][]map[string]any)
 
 	userID := make([]map[string]any, 0)
-	global.GVA_DB.Table("sys_users").Select("nick_name as label,id as value").Scan(&userID)
+	global.GVA_DB.WithContext(ctx).Model(&system.SysUser{}).Select("nick_name as label,id as value").Scan(&userID)
 	res["userID"] = userID
 	return
 }
@@ -9,7 +9,7 @@
 // {{.FuncName}} {{.FuncDesc}}
 // Author [yourname](https://github.com/yourname)
 func (s *{{.Abbreviation}}) {{.FuncName}}(ctx context.Context) (err error) {
-	db := {{$db}}.Model(&model.{{.StructName}}{})
+	db := {{$db}}.WithContext(ctx).Model(&model.{{.StructName}}{})
     return db.Error
 }
 
@@ -19,7 +19,7 @@ func (s *{{.Abbreviation}}) {{.FuncName}}(ctx context.Context) (err error) {
 // Author [yourname](https://github.com/yourname)
 func ({{.Abbreviation}}Service *{{.StructName}}Service){{.FuncName}}(ctx context.Context) (err error) {
 	// 请在这里实现自己的业务逻辑
-	db := {{$db}}.Model(&{{.Package}}.{{.StructName}}{})
+	db := {{$db}}.WithContext(ctx).Model(&{{.Package}}.{{.StructName}}{})
     return db.Error
 }
 {{end}}
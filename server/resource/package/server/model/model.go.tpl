@@ -86,6 +86,13 @@ func ({{.StructName}}) TableName() string {
 }
 {{ end }}
 
+{{ if .AutoCreateResource }}
+// DataScopeColumn {{.Description}} {{.StructName}}按创建者接入数据权限 查询时需通过WithContext传入请求的context
+func ({{.StructName}}) DataScopeColumn() string {
+    return "created_by"
+}
+{{ end }}
+
 {{if .IsTree }}
 // GetChildren 实现TreeNode接口
 func (s *{{.StructName}}) GetChildren() []*{{.StructName}} {
@@ -19,6 +19,11 @@ type RouterGroup struct {
 	AuthorityBtnRouter
 	SysExportTemplateRouter
 	SysParamsRouter
+	AuditLogRouter
+	LoginLogRouter
+	DeptRouter
+	PermissionChangeRouter
+	TenantRouter
 }
 
 var (
@@ -41,4 +46,9 @@ var (
 	dictionaryDetailApi = api.ApiGroupApp.SystemApiGroup.DictionaryDetailApi
 	autoCodeTemplateApi = api.ApiGroupApp.SystemApiGroup.AutoCodeTemplateApi
 	exportTemplateApi   = api.ApiGroupApp.SystemApiGroup.SysExportTemplateApi
+	auditLogApi         = api.ApiGroupApp.SystemApiGroup.AuditLogApi
+	loginLogApi         = api.ApiGroupApp.SystemApiGroup.LoginLogApi
+	deptApi             = api.ApiGroupApp.SystemApiGroup.DeptApi
+	permissionChangeApi = api.ApiGroupApp.SystemApiGroup.PermissionChangeApi
+	tenantApi           = api.ApiGroupApp.SystemApiGroup.TenantApi
 )
@@ -0,0 +1,14 @@
+package system
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogRouter struct{}
+
+func (s *AuditLogRouter) InitAuditLogRouter(Router *gin.RouterGroup) {
+	auditLogRouter := Router.Group("auditLog")
+	{
+		auditLogRouter.GET("getAuditLogList", auditLogApi.GetAuditLogList) // 分页获取审计日志
+	}
+}
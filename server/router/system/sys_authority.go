@@ -11,13 +11,19 @@ func (s *AuthorityRouter) InitAuthorityRouter(Router *gin.RouterGroup) {
 	authorityRouter := Router.Group("authority").Use(middleware.OperationRecord())
 	authorityRouterWithoutRecord := Router.Group("authority")
 	{
-		authorityRouter.POST("createAuthority", authorityApi.CreateAuthority)   // 创建角色
-		authorityRouter.POST("deleteAuthority", authorityApi.DeleteAuthority)   // 删除角色
-		authorityRouter.PUT("updateAuthority", authorityApi.UpdateAuthority)    // 更新角色
-		authorityRouter.POST("copyAuthority", authorityApi.CopyAuthority)       // 拷贝角色
-		authorityRouter.POST("setDataAuthority", authorityApi.SetDataAuthority) // 设置角色资源权限
+		authorityRouter.POST("createAuthority", authorityApi.CreateAuthority)        // 创建角色
+		authorityRouter.POST("deleteAuthority", authorityApi.DeleteAuthority)        // 删除角色
+		authorityRouter.PUT("updateAuthority", authorityApi.UpdateAuthority)         // 更新角色
+		authorityRouter.POST("copyAuthority", authorityApi.CopyAuthority)            // 拷贝角色
+		authorityRouter.POST("setDataAuthority", authorityApi.SetDataAuthority)      // 设置角色资源权限
+		authorityRouter.POST("setTwoFactor", authorityApi.SetAuthorityTwoFactor)     // 设置角色是否强制两步验证
+		authorityRouter.POST("setMaxSessions", authorityApi.SetAuthorityMaxSessions) // 设置角色最大同时在线会话数
+		authorityRouter.POST("setDataScope", authorityApi.SetAuthorityDataScope)     // 设置角色的数据范围
+		authorityRouter.POST("setFieldRules", authorityApi.SetAuthorityFieldRules)   // 设置角色的字段规则
 	}
 	{
-		authorityRouterWithoutRecord.POST("getAuthorityList", authorityApi.GetAuthorityList) // 获取角色列表
+		authorityRouterWithoutRecord.POST("getAuthorityList", authorityApi.GetAuthorityList)    // 获取角色列表
+		authorityRouterWithoutRecord.POST("getFieldRules", authorityApi.GetAuthorityFieldRules) // 获取角色的字段规则
+		authorityRouterWithoutRecord.GET("getFieldRuleModels", authorityApi.GetFieldRuleModels) // 获取支持字段规则的模型
 	}
 }
@@ -11,6 +11,20 @@ func (s *BaseRouter) InitBaseRouter(Router *gin.RouterGroup) (R gin.IRoutes) {
 	{
 		baseRouter.POST("login", baseApi.Login)
 		baseRouter.POST("captcha", baseApi.Captcha)
+		baseRouter.POST("loginTwoFactor", baseApi.LoginTwoFactor)               // 登录二次验证
+		baseRouter.POST("twoFactorSetup", baseApi.TwoFactorSetup)               // 登录过程中绑定两步验证
+		baseRouter.GET("oidcAuthUrl", baseApi.OidcAuthUrl)                      // 获取OIDC授权地址
+		baseRouter.POST("oidcLogin", baseApi.OidcLogin)                         // OIDC登录回调
+		baseRouter.POST("refresh", baseApi.RefreshToken)                        // 刷新令牌换取新令牌
+		baseRouter.POST("changeExpiredPassword", baseApi.ChangeExpiredPassword) // 登录过程中修改密码
+		baseRouter.POST("forgotPassword", baseApi.ForgotPassword)               // 申请找回密码
+		baseRouter.POST("resetPasswordByToken", baseApi.ResetPasswordByToken)   // 通过找回密码邮件重置密码
+		baseRouter.GET("jwks", baseApi.Jwks)                                    // 发布jwt验签公钥
+		baseRouter.POST("register", baseApi.SelfRegister)                       // 用户自助注册
+		baseRouter.POST("verifyRegisterEmail", baseApi.VerifyRegisterEmail)     // 验证注册邮箱
+		baseRouter.POST("passkeyLoginBegin", baseApi.PasskeyLoginBegin)         // 获取通行密钥登录参数
+		baseRouter.POST("passkeyLogin", baseApi.PasskeyLogin)                   // 使用通行密钥登录
+		baseRouter.POST("passkeyTwoFactorBegin", baseApi.PasskeyTwoFactorBegin) // 获取通行密钥二次验证参数
 	}
 	return baseRouter
 }
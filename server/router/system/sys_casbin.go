@@ -12,8 +12,12 @@ func (s *CasbinRouter) InitCasbinRouter(Router *gin.RouterGroup) {
 	casbinRouterWithoutRecord := Router.Group("casbin")
 	{
 		casbinRouter.POST("updateCasbin", casbinApi.UpdateCasbin)
+		casbinRouter.POST("importPolicy", casbinApi.ImportPolicy) // 导入策略文件
 	}
 	{
 		casbinRouterWithoutRecord.POST("getPolicyPathByAuthorityId", casbinApi.GetPolicyPathByAuthorityId)
+		casbinRouterWithoutRecord.POST("explainPermission", casbinApi.ExplainPermission)
+		casbinRouterWithoutRecord.GET("exportPolicy", casbinApi.ExportPolicy) // 导出策略文件
+		casbinRouterWithoutRecord.POST("diffPolicy", casbinApi.DiffPolicy)    // 比较策略文件与当前环境
 	}
 }
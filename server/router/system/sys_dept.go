@@ -0,0 +1,26 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type DeptRouter struct{}
+
+// InitDeptRouter 初始化 部门 路由信息
+func (s *DeptRouter) InitDeptRouter(Router *gin.RouterGroup) {
+	deptRouter := Router.Group("dept").Use(middleware.OperationRecord())
+	deptRouterWithoutRecord := Router.Group("dept")
+	{
+		deptRouter.POST("createDept", deptApi.CreateDept)     // 新建部门
+		deptRouter.PUT("updateDept", deptApi.UpdateDept)      // 更新部门
+		deptRouter.POST("deleteDept", deptApi.DeleteDept)     // 删除部门
+		deptRouter.POST("moveDept", deptApi.MoveDept)         // 调整上级部门
+		deptRouter.POST("mergeDept", deptApi.MergeDept)       // 合并部门
+		deptRouter.POST("setUserDepts", deptApi.SetUserDepts) // 设置用户所属部门
+	}
+	{
+		deptRouterWithoutRecord.GET("getDeptTree", deptApi.GetDeptTree)    // 获取部门树
+		deptRouterWithoutRecord.POST("getUserDepts", deptApi.GetUserDepts) // 获取用户所属部门
+	}
+}
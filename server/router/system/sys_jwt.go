@@ -10,5 +10,7 @@ func (s *JwtRouter) InitJwtRouter(Router *gin.RouterGroup) {
 	jwtRouter := Router.Group("jwt")
 	{
 		jwtRouter.POST("jsonInBlacklist", jwtApi.JsonInBlacklist) // jwt加入黑名单
+		jwtRouter.POST("rotateKey", jwtApi.RotateKey)             // 轮换jwt签名密钥
+		jwtRouter.GET("getKeyList", jwtApi.GetKeyList)            // 获取jwt签名密钥
 	}
 }
@@ -0,0 +1,15 @@
+package system
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type LoginLogRouter struct{}
+
+func (s *LoginLogRouter) InitLoginLogRouter(Router *gin.RouterGroup) {
+	loginLogRouter := Router.Group("loginLog")
+	{
+		loginLogRouter.GET("getLoginLogList", loginLogApi.GetLoginLogList) // 分页获取登录日志
+		loginLogRouter.GET("getRecentLogins", loginLogApi.GetRecentLogins) // 获取自身最近的登录记录
+	}
+}
@@ -0,0 +1,21 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionChangeRouter struct{}
+
+// InitPermissionChangeRouter 初始化 权限变更审批 路由信息
+func (s *PermissionChangeRouter) InitPermissionChangeRouter(Router *gin.RouterGroup) {
+	changeRouter := Router.Group("permissionChange").Use(middleware.OperationRecord())
+	changeRouterWithoutRecord := Router.Group("permissionChange")
+	{
+		changeRouter.POST("approveChange", permissionChangeApi.ApproveChange) // 审批通过权限变更
+		changeRouter.POST("rejectChange", permissionChangeApi.RejectChange)   // 驳回权限变更
+	}
+	{
+		changeRouterWithoutRecord.GET("getChangeList", permissionChangeApi.GetChangeList) // 分页获取权限变更申请
+	}
+}
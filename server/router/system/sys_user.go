@@ -11,18 +11,46 @@ func (s *UserRouter) InitUserRouter(Router *gin.RouterGroup) {
 	userRouter := Router.Group("user").Use(middleware.OperationRecord())
 	userRouterWithoutRecord := Router.Group("user")
 	{
-		userRouter.POST("admin_register", baseApi.Register)               // 管理员注册账号
-		userRouter.POST("changePassword", baseApi.ChangePassword)         // 用户修改密码
-		userRouter.POST("setUserAuthority", baseApi.SetUserAuthority)     // 设置用户权限
-		userRouter.DELETE("deleteUser", baseApi.DeleteUser)               // 删除用户
-		userRouter.PUT("setUserInfo", baseApi.SetUserInfo)                // 设置用户信息
-		userRouter.PUT("setSelfInfo", baseApi.SetSelfInfo)                // 设置自身信息
-		userRouter.POST("setUserAuthorities", baseApi.SetUserAuthorities) // 设置用户权限组
-		userRouter.POST("resetPassword", baseApi.ResetPassword)           // 设置用户权限组
-		userRouter.PUT("setSelfSetting", baseApi.SetSelfSetting)          // 用户界面配置
+		userRouter.POST("admin_register", baseApi.Register)                         // 管理员注册账号
+		userRouter.POST("changePassword", baseApi.ChangePassword)                   // 用户修改密码
+		userRouter.POST("setUserAuthority", baseApi.SetUserAuthority)               // 设置用户权限
+		userRouter.DELETE("deleteUser", baseApi.DeleteUser)                         // 删除用户
+		userRouter.PUT("setUserInfo", baseApi.SetUserInfo)                          // 设置用户信息
+		userRouter.PUT("setSelfInfo", baseApi.SetSelfInfo)                          // 设置自身信息
+		userRouter.POST("setUserAuthorities", baseApi.SetUserAuthorities)           // 设置用户权限组
+		userRouter.POST("resetPassword", baseApi.ResetPassword)                     // 设置用户权限组
+		userRouter.PUT("setSelfSetting", baseApi.SetSelfSetting)                    // 用户界面配置
+		userRouter.POST("totpSetup", baseApi.TotpSetup)                             // 获取两步验证绑定信息
+		userRouter.POST("totpEnable", baseApi.TotpEnable)                           // 开启两步验证
+		userRouter.POST("totpDisable", baseApi.TotpDisable)                         // 关闭两步验证
+		userRouter.POST("regenerateRecoveryCodes", baseApi.RegenerateRecoveryCodes) // 重新生成恢复码
+		userRouter.POST("resetTotp", baseApi.ResetTotp)                             // 重置用户两步验证
+		userRouter.POST("revokeSession", baseApi.RevokeSession)                     // 注销自身登录会话
+		userRouter.POST("revokeUserSession", baseApi.RevokeUserSession)             // 注销用户登录会话
+		userRouter.POST("unlockUser", baseApi.UnlockUser)                           // 解锁被锁定的账号
+		userRouter.POST("createApiKey", baseApi.CreateApiKey)                       // 创建自身API密钥
+		userRouter.DELETE("deleteApiKey", baseApi.DeleteApiKey)                     // 删除自身API密钥
+		userRouter.DELETE("deleteUserApiKey", baseApi.DeleteUserApiKey)             // 删除用户API密钥
+		userRouter.POST("impersonate", baseApi.Impersonate)                         // 模拟登录为指定用户
+		userRouter.POST("endImpersonate", baseApi.EndImpersonate)                   // 结束模拟登录
+		userRouter.POST("reviewRegistration", baseApi.ReviewRegistration)           // 审核自助注册
+		userRouter.POST("passkeyRegisterBegin", baseApi.PasskeyRegisterBegin)       // 获取通行密钥注册参数
+		userRouter.POST("passkeyRegisterFinish", baseApi.PasskeyRegisterFinish)     // 绑定通行密钥
+		userRouter.PUT("updatePasskey", baseApi.UpdatePasskey)                      // 修改通行密钥名称
+		userRouter.DELETE("deletePasskey", baseApi.DeletePasskey)                   // 删除通行密钥
+		userRouter.POST("grantAuthority", baseApi.GrantAuthority)                   // 授予用户限时角色
+		userRouter.POST("revokeAuthorityGrant", baseApi.RevokeAuthorityGrant)       // 收回用户限时角色
 	}
 	{
-		userRouterWithoutRecord.POST("getUserList", baseApi.GetUserList) // 分页获取用户列表
-		userRouterWithoutRecord.GET("getUserInfo", baseApi.GetUserInfo)  // 获取自身信息
+		userRouterWithoutRecord.POST("getUserList", baseApi.GetUserList)                 // 分页获取用户列表
+		userRouterWithoutRecord.GET("getUserInfo", baseApi.GetUserInfo)                  // 获取自身信息
+		userRouterWithoutRecord.GET("getSessionList", baseApi.GetSessionList)            // 获取自身登录会话
+		userRouterWithoutRecord.POST("getUserSessionList", baseApi.GetUserSessionList)   // 获取用户登录会话
+		userRouterWithoutRecord.POST("getLockoutList", baseApi.GetLockoutList)           // 获取被锁定的账号
+		userRouterWithoutRecord.GET("getApiKeyList", baseApi.GetApiKeyList)              // 获取自身API密钥
+		userRouterWithoutRecord.POST("getUserApiKeyList", baseApi.GetUserApiKeyList)     // 获取用户API密钥
+		userRouterWithoutRecord.POST("getRegistrationList", baseApi.GetRegistrationList) // 分页获取自助注册记录
+		userRouterWithoutRecord.GET("getPasskeyList", baseApi.GetPasskeyList)            // 获取自身通行密钥
+		userRouterWithoutRecord.POST("getAuthorityGrants", baseApi.GetAuthorityGrants)   // 获取用户的角色授权
 	}
 }
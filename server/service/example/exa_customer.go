@@ -1,10 +1,11 @@
 package example
 
 import (
+	"context"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
-	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	systemService "github.com/flipped-aurora/gin-vue-admin/server/service/system"
 )
 
@@ -15,73 +16,63 @@ var CustomerServiceApp = new(CustomerService)
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: CreateExaCustomer
 //@description: 创建客户
-//@param: e model.ExaCustomer
+//@param: ctx context.Context, e model.ExaCustomer
 //@return: err error
 
-func (exa *CustomerService) CreateExaCustomer(e example.ExaCustomer) (err error) {
-	err = global.GVA_DB.Create(&e).Error
+func (exa *CustomerService) CreateExaCustomer(ctx context.Context, e example.ExaCustomer) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Create(&e).Error
 	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteFileChunk
 //@description: 删除客户
-//@param: e model.ExaCustomer
+//@param: ctx context.Context, e model.ExaCustomer
 //@return: err error
 
-func (exa *CustomerService) DeleteExaCustomer(e example.ExaCustomer) (err error) {
-	err = global.GVA_DB.Delete(&e).Error
+func (exa *CustomerService) DeleteExaCustomer(ctx context.Context, e example.ExaCustomer) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Delete(&e).Error
 	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: UpdateExaCustomer
-//@description: 更新客户
-//@param: e *model.ExaCustomer
+//@description: 更新客户 操作者角色受限的字段及创建者不会被修改
+//@param: ctx context.Context, authorityID uint, e *model.ExaCustomer
 //@return: err error
 
-func (exa *CustomerService) UpdateExaCustomer(e *example.ExaCustomer) (err error) {
-	err = global.GVA_DB.Save(e).Error
+func (exa *CustomerService) UpdateExaCustomer(ctx context.Context, authorityID uint, e *example.ExaCustomer) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Scopes(systemService.AuthorityFieldRuleServiceApp.Protect(authorityID)).Omit("created_by").Save(e).Error
 	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetExaCustomer
 //@description: 获取客户信息
-//@param: id uint
+//@param: ctx context.Context, id uint
 //@return: customer model.ExaCustomer, err error
 
-func (exa *CustomerService) GetExaCustomer(id uint) (customer example.ExaCustomer, err error) {
-	err = global.GVA_DB.Where("id = ?", id).First(&customer).Error
+func (exa *CustomerService) GetExaCustomer(ctx context.Context, id uint) (customer example.ExaCustomer, err error) {
+	err = global.GVA_DB.WithContext(ctx).Where("id = ?", id).First(&customer).Error
 	return
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetCustomerInfoList
-//@description: 分页获取客户列表
-//@param: sysUserAuthorityID string, info request.PageInfo
+//@description: 分页获取客户列表 按角色的数据范围过滤
+//@param: ctx context.Context, info request.PageInfo
 //@return: list interface{}, total int64, err error
 
-func (exa *CustomerService) GetCustomerInfoList(sysUserAuthorityID uint, info request.PageInfo) (list interface{}, total int64, err error) {
+func (exa *CustomerService) GetCustomerInfoList(ctx context.Context, info request.PageInfo) (list interface{}, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
-	db := global.GVA_DB.Model(&example.ExaCustomer{})
-	var a system.SysAuthority
-	a.AuthorityId = sysUserAuthorityID
-	auth, err := systemService.AuthorityServiceApp.GetAuthorityInfo(a)
-	if err != nil {
-		return
-	}
-	var dataId []uint
-	for _, v := range auth.DataAuthorityId {
-		dataId = append(dataId, v.AuthorityId)
-	}
+	db := global.GVA_DB.WithContext(ctx).Model(&example.ExaCustomer{})
 	var CustomerList []example.ExaCustomer
-	err = db.Where("sys_user_authority_id in ?", dataId).Count(&total).Error
+	err = db.Count(&total).Error
 	if err != nil {
 		return CustomerList, total, err
 	} else {
-		err = db.Limit(limit).Offset(offset).Preload("SysUser").Where("sys_user_authority_id in ?", dataId).Find(&CustomerList).Error
+		err = db.Limit(limit).Offset(offset).Preload("SysUser").Find(&CustomerList).Error
 	}
 	return CustomerList, total, err
 }
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"context"
 	"errors"
 	"mime/multipart"
 	"strings"
@@ -14,34 +15,34 @@ import (
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: Upload
 //@description: 创建文件上传记录
-//@param: file model.ExaFileUploadAndDownload
+//@param: ctx context.Context, file model.ExaFileUploadAndDownload
 //@return: error
 
-func (e *FileUploadAndDownloadService) Upload(file example.ExaFileUploadAndDownload) error {
-	return global.GVA_DB.Create(&file).Error
+func (e *FileUploadAndDownloadService) Upload(ctx context.Context, file example.ExaFileUploadAndDownload) error {
+	return global.GVA_DB.WithContext(ctx).Create(&file).Error
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: FindFile
 //@description: 查询文件记录
-//@param: id uint
+//@param: ctx context.Context, id uint
 //@return: model.ExaFileUploadAndDownload, error
 
-func (e *FileUploadAndDownloadService) FindFile(id uint) (example.ExaFileUploadAndDownload, error) {
+func (e *FileUploadAndDownloadService) FindFile(ctx context.Context, id uint) (example.ExaFileUploadAndDownload, error) {
 	var file example.ExaFileUploadAndDownload
-	err := global.GVA_DB.Where("id = ?", id).First(&file).Error
+	err := global.GVA_DB.WithContext(ctx).Where("id = ?", id).First(&file).Error
 	return file, err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteFile
 //@description: 删除文件记录
-//@param: file model.ExaFileUploadAndDownload
+//@param: ctx context.Context, file model.ExaFileUploadAndDownload
 //@return: err error
 
-func (e *FileUploadAndDownloadService) DeleteFile(file example.ExaFileUploadAndDownload) (err error) {
+func (e *FileUploadAndDownloadService) DeleteFile(ctx context.Context, file example.ExaFileUploadAndDownload) (err error) {
 	var fileFromDb example.ExaFileUploadAndDownload
-	fileFromDb, err = e.FindFile(file.ID)
+	fileFromDb, err = e.FindFile(ctx, file.ID)
 	if err != nil {
 		return
 	}
@@ -49,26 +50,26 @@ func (e *FileUploadAndDownloadService) DeleteFile(file example.ExaFileUploadAndD
 	if err = oss.DeleteFile(fileFromDb.Key); err != nil {
 		return errors.New("文件删除失败")
 	}
-	err = global.GVA_DB.Where("id = ?", file.ID).Unscoped().Delete(&file).Error
+	err = global.GVA_DB.WithContext(ctx).Where("id = ?", file.ID).Unscoped().Delete(&file).Error
 	return err
 }
 
 // EditFileName 编辑文件名或者备注
-func (e *FileUploadAndDownloadService) EditFileName(file example.ExaFileUploadAndDownload) (err error) {
+func (e *FileUploadAndDownloadService) EditFileName(ctx context.Context, file example.ExaFileUploadAndDownload) (err error) {
 	var fileFromDb example.ExaFileUploadAndDownload
-	return global.GVA_DB.Where("id = ?", file.ID).First(&fileFromDb).Update("name", file.Name).Error
+	return global.GVA_DB.WithContext(ctx).Where("id = ?", file.ID).First(&fileFromDb).Update("name", file.Name).Error
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetFileRecordInfoList
 //@description: 分页获取数据
-//@param: info request.ExaAttachmentCategorySearch
+//@param: ctx context.Context, info request.ExaAttachmentCategorySearch
 //@return: list interface{}, total int64, err error
 
-func (e *FileUploadAndDownloadService) GetFileRecordInfoList(info request.ExaAttachmentCategorySearch) (list []example.ExaFileUploadAndDownload, total int64, err error) {
+func (e *FileUploadAndDownloadService) GetFileRecordInfoList(ctx context.Context, info request.ExaAttachmentCategorySearch) (list []example.ExaFileUploadAndDownload, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
-	db := global.GVA_DB.Model(&example.ExaFileUploadAndDownload{})
+	db := global.GVA_DB.WithContext(ctx).Model(&example.ExaFileUploadAndDownload{})
 
 	if len(info.Keyword) > 0 {
 		db = db.Where("name LIKE ?", "%"+info.Keyword+"%")
@@ -89,10 +90,10 @@ func (e *FileUploadAndDownloadService) GetFileRecordInfoList(info request.ExaAtt
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: UploadFile
 //@description: 根据配置文件判断是文件上传到本地或者七牛云
-//@param: header *multipart.FileHeader, noSave string
+//@param: ctx context.Context, header *multipart.FileHeader, noSave string
 //@return: file model.ExaFileUploadAndDownload, err error
 
-func (e *FileUploadAndDownloadService) UploadFile(header *multipart.FileHeader, noSave string, classId int) (file example.ExaFileUploadAndDownload, err error) {
+func (e *FileUploadAndDownloadService) UploadFile(ctx context.Context, header *multipart.FileHeader, noSave string, classId int) (file example.ExaFileUploadAndDownload, err error) {
 	oss := upload.NewOss()
 	filePath, key, uploadErr := oss.UploadFile(header)
 	if uploadErr != nil {
@@ -107,7 +108,7 @@ func (e *FileUploadAndDownloadService) UploadFile(header *multipart.FileHeader,
 		Key:     key,
 	}
 	if noSave == "0" {
-		return f, e.Upload(f)
+		return f, e.Upload(ctx, f)
 	}
 	return f, nil
 }
@@ -115,9 +116,9 @@ func (e *FileUploadAndDownloadService) UploadFile(header *multipart.FileHeader,
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: ImportURL
 //@description: 导入URL
-//@param: file model.ExaFileUploadAndDownload
+//@param: ctx context.Context, file model.ExaFileUploadAndDownload
 //@return: error
 
-func (e *FileUploadAndDownloadService) ImportURL(file *[]example.ExaFileUploadAndDownload) error {
-	return global.GVA_DB.Create(&file).Error
+func (e *FileUploadAndDownloadService) ImportURL(ctx context.Context, file *[]example.ExaFileUploadAndDownload) error {
+	return global.GVA_DB.WithContext(ctx).Create(&file).Error
 }
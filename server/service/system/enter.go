@@ -17,6 +17,18 @@ type ServiceGroup struct {
 	AuthorityBtnService
 	SysExportTemplateService
 	SysParamsService
+	OidcService
+	SessionService
+	LockoutService
+	AuditLogService
+	LoginLogService
+	ApiKeyService
+	JwtKeyService
+	PasskeyService
+	AuthorityFieldRuleService
+	DeptService
+	PermissionChangeService
+	TenantService
 	AutoCodePlugin   autoCodePlugin
 	AutoCodePackage  autoCodePackage
 	AutoCodeHistory  autoCodeHistory
@@ -0,0 +1,111 @@
+package system
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/songzhibin97/gkit/cache/local_cache"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB 以内存sqlite替换全局数据库、日志、缓存与配置 迁移用户、角色、会话等基础表及models 测试结束后还原
+func setupTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	models = append([]interface{}{
+		&system.SysTenant{},
+		&system.SysAuthority{},
+		&system.SysUser{},
+		&system.SysUserAuthority{},
+		&system.SysUserSession{},
+		&system.SysRefreshToken{},
+		&system.SysAuditLog{},
+	}, models...)
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	RegisterTenant(db)
+
+	oldDB, oldLog, oldCache, oldConfig := global.GVA_DB, global.GVA_LOG, global.BlackCache, global.GVA_CONFIG
+	global.GVA_DB = db
+	global.GVA_LOG = zap.NewNop()
+	global.BlackCache = local_cache.NewCache()
+	global.GVA_CONFIG = config.Server{}
+	global.GVA_CONFIG.JWT.ExpiresTime = "1h"
+	global.GVA_CONFIG.JWT.RefreshExpiresTime = "7d"
+	t.Cleanup(func() {
+		global.GVA_DB, global.GVA_LOG, global.BlackCache, global.GVA_CONFIG = oldDB, oldLog, oldCache, oldConfig
+		_ = sqlDB.Close()
+	})
+
+	if err = db.Create(&system.SysTenant{Name: "默认租户", Code: "default", Enable: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createTestUser 在默认租户创建用户并关联角色 第一个角色为当前角色
+func createTestUser(t *testing.T, username string, authorityIds ...uint) system.SysUser {
+	t.Helper()
+	user := system.SysUser{Username: username, NickName: username, Enable: 1}
+	if len(authorityIds) > 0 {
+		user.AuthorityId = authorityIds[0]
+	}
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	if err := global.GVA_DB.WithContext(ctx).Omit("Authorities", "Authority").Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range authorityIds {
+		if err := global.GVA_DB.FirstOrCreate(&system.SysAuthority{AuthorityId: id}, "authority_id = ?", id).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := global.GVA_DB.Create(&system.SysUserAuthority{SysUserId: user.ID, SysAuthorityAuthorityId: id}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return user
+}
+
+// setupTestCasbin 基于当前测试数据库重建casbin执行器 rules的每一项为角色、路径、方法及租户域
+func setupTestCasbin(t *testing.T, rules ...[]string) {
+	t.Helper()
+	if err := global.GVA_DB.AutoMigrate(&gormadapter.CasbinRule{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := CasbinServiceApp.AddPolicies(global.GVA_DB, rules); err != nil {
+		t.Fatal(err)
+	}
+	once, syncedCachedEnforcer = sync.Once{}, nil
+	t.Cleanup(func() {
+		once, syncedCachedEnforcer = sync.Once{}, nil
+	})
+	if CasbinServiceApp.Casbin() == nil {
+		t.Fatal("初始化casbin失败")
+	}
+}
+
+// moveTestUserTenant 将用户移动到指定租户
+func moveTestUserTenant(t *testing.T, userID uint, tenantID uint) {
+	t.Helper()
+	if err := tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", userID).Update("tenant_id", tenantID).Error; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -234,7 +235,7 @@ func (apiService *ApiService) GetAPIInfoList(api system.SysApi, info request.Pag
 //@description: 获取所有的api
 //@return:  apis []model.SysApi, err error
 
-func (apiService *ApiService) GetAllApis(authorityID uint) (apis []system.SysApi, err error) {
+func (apiService *ApiService) GetAllApis(ctx context.Context, authorityID uint) (apis []system.SysApi, err error) {
 	parentAuthorityID, err := AuthorityServiceApp.GetParentAuthorityID(authorityID)
 	if err != nil {
 		return nil, err
@@ -243,7 +244,7 @@ func (apiService *ApiService) GetAllApis(authorityID uint) (apis []system.SysApi
 	if parentAuthorityID == 0 || !global.GVA_CONFIG.System.UseStrictAuth {
 		return
 	}
-	paths := CasbinServiceApp.GetPolicyPathByAuthorityId(authorityID)
+	paths := CasbinServiceApp.GetImplicitPolicyPathByAuthorityId(ctx, authorityID)
 	// 挑选 apis里面的path和method也在paths里面的api
 	var authApis []system.SysApi
 	for i := range apis {
@@ -0,0 +1,127 @@
+package system
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix        = "gva_"
+	apiKeyPrefixLength  = 12              // 展示用前缀长度
+	apiKeyMaxExpireDays = 366             // 最长有效期 单位:天
+	apiKeySeenPrefix    = "api_key_seen:" // 最后使用时间最多每分钟写入一次
+	apiKeySeenInterval  = time.Minute
+)
+
+var ErrApiKeyInvalid = errors.New("API密钥无效或已过期")
+
+type ApiKeyService struct{}
+
+var ApiKeyServiceApp = new(ApiKeyService)
+
+// CreateApiKey 创建API密钥 明文仅在创建时返回一次
+func (apiKeyService *ApiKeyService) CreateApiKey(userID uint, req systemReq.CreateApiKey) (apiKey system.SysApiKey, key string, err error) {
+	if req.ExpireDays <= 0 || req.ExpireDays > apiKeyMaxExpireDays {
+		return apiKey, "", errors.New("有效期需在1到" + strconv.Itoa(apiKeyMaxExpireDays) + "天之间")
+	}
+	var scopes []string
+	for _, s := range req.Scopes {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	if len(scopes) == 0 {
+		return apiKey, "", errors.New("请至少指定一个接口范围 全部接口请填写 *")
+	}
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return apiKey, "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	apiKey = system.SysApiKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   utils.SHA256V([]byte(key)),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, req.ExpireDays),
+	}
+	err = global.GVA_DB.Create(&apiKey).Error
+	return apiKey, key, err
+}
+
+// GetApiKeyList 获取用户的API密钥
+func (apiKeyService *ApiKeyService) GetApiKeyList(userID uint) (list []system.SysApiKey, err error) {
+	err = global.GVA_DB.Where("user_id = ?", userID).Order("id desc").Find(&list).Error
+	return list, err
+}
+
+// GetUserApiKeyList 管理员获取当前租户用户的API密钥
+func (apiKeyService *ApiKeyService) GetUserApiKeyList(ctx context.Context, userID uint) (list []system.SysApiKey, err error) {
+	if _, err = UserServiceApp.tenantUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return apiKeyService.GetApiKeyList(userID)
+}
+
+// DeleteApiKey 删除API密钥 userID为0时为管理员操作 仅校验密钥所属用户在当前租户
+func (apiKeyService *ApiKeyService) DeleteApiKey(ctx context.Context, userID uint, id uint) error {
+	if userID == 0 {
+		var apiKey system.SysApiKey
+		if err := global.GVA_DB.Where("id = ?", id).First(&apiKey).Error; err != nil {
+			return errors.New("API密钥不存在")
+		}
+		if _, err := UserServiceApp.tenantUser(ctx, apiKey.UserID); err != nil {
+			return errors.New("API密钥不存在")
+		}
+		userID = apiKey.UserID
+	}
+	result := global.GVA_DB.Where("id = ? AND user_id = ?", id, userID).Delete(&system.SysApiKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("API密钥不存在")
+	}
+	return nil
+}
+
+// Authenticate 校验API密钥 返回密钥及其所属用户
+func (apiKeyService *ApiKeyService) Authenticate(key string, ip string) (*system.SysApiKey, *system.SysUser, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, nil, ErrApiKeyInvalid
+	}
+	var apiKey system.SysApiKey
+	err := global.GVA_DB.Where("key_hash = ?", utils.SHA256V([]byte(key))).First(&apiKey).Error
+	if err != nil || time.Now().After(apiKey.ExpiresAt) {
+		return nil, nil, ErrApiKeyInvalid
+	}
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", apiKey.UserID).First(&user).Error; err != nil {
+		return nil, nil, ErrApiKeyInvalid
+	}
+	if user.Enable != 1 {
+		return nil, nil, errors.New("用户被禁止登录")
+	}
+	seenKey := apiKeySeenPrefix + strconv.Itoa(int(apiKey.ID))
+	if _, ok := global.BlackCache.Get(seenKey); !ok {
+		global.BlackCache.Set(seenKey, struct{}{}, apiKeySeenInterval)
+		err = global.GVA_DB.Model(&apiKey).Updates(map[string]interface{}{"last_used_at": time.Now(), "last_used_ip": ip}).Error
+		if err != nil {
+			global.GVA_LOG.Error("更新API密钥使用时间失败!", zap.Error(err))
+		}
+	}
+	return &apiKey, &user, nil
+}
@@ -0,0 +1,63 @@
+package system
+
+import (
+	"context"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+)
+
+type AuditLogService struct{}
+
+var AuditLogServiceApp = new(AuditLogService)
+
+// Record 写入审计日志 失败时仅记录错误日志 不影响业务流程 日志归属被操作用户所在的租户
+func (auditLogService *AuditLogService) Record(log system.SysAuditLog) {
+	if log.TenantId == 0 {
+		log.TenantId = userTenant(log.UserID, log.OperatorID)
+	}
+	if err := global.GVA_DB.WithContext(tenant.WithTenant(context.Background(), log.TenantId)).Create(&log).Error; err != nil {
+		global.GVA_LOG.Error("写入审计日志失败!", zap.String("action", log.Action), zap.String("username", log.Username), zap.Error(err))
+	}
+}
+
+// GetAuditLogList 分页获取审计日志
+func (auditLogService *AuditLogService) GetAuditLogList(ctx context.Context, info systemReq.SysAuditLogSearch) (list interface{}, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysAuditLog{})
+	var logs []system.SysAuditLog
+	if info.Action != "" {
+		db = db.Where("action = ?", info.Action)
+	}
+	if info.UserID != 0 {
+		db = db.Where("user_id = ?", info.UserID)
+	}
+	if info.Username != "" {
+		db = db.Where("username LIKE ?", "%"+info.Username+"%")
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	err = db.Order("id desc").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, total, err
+}
+
+// userTenant 依次查询用户所属的租户 用户均不存在时为默认租户
+func userTenant(userIDs ...uint) uint {
+	for _, id := range userIDs {
+		if id == 0 {
+			continue
+		}
+		var tenantID uint
+		err := tenant.Skip(global.GVA_DB).Unscoped().Model(&system.SysUser{}).Select(tenant.Column).Where("id = ?", id).Scan(&tenantID).Error
+		if err == nil && tenantID != 0 {
+			return tenantID
+		}
+	}
+	return tenant.Default
+}
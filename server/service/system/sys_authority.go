@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"strconv"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/datascope"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 	"gorm.io/gorm"
 )
 
@@ -25,12 +28,18 @@ type AuthorityService struct{}
 
 var AuthorityServiceApp = new(AuthorityService)
 
-func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthority) (authority system.SysAuthority, err error) {
+func (authorityService *AuthorityService) CreateAuthority(ctx context.Context, auth system.SysAuthority) (authority system.SysAuthority, err error) {
 
 	if err = global.GVA_DB.Where("authority_id = ?", auth.AuthorityId).First(&system.SysAuthority{}).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
 		return auth, ErrRoleExistence
 	}
 
+	if auth.DataScope != "" {
+		if auth.DataScopeRule, err = checkDataScope(auth.DataScope, auth.DataScopeRule); err != nil {
+			return auth, err
+		}
+	}
+
 	e := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
 
 		if err = tx.Create(&auth).Error; err != nil {
@@ -43,11 +52,15 @@ func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthori
 		}
 		casbinInfos := systemReq.DefaultCasbin()
 		authorityId := strconv.Itoa(int(auth.AuthorityId))
+		domain := tenant.Domain(ctx)
 		rules := [][]string{}
 		for _, v := range casbinInfos {
-			rules = append(rules, []string{authorityId, v.Path, v.Method})
+			rules = append(rules, []string{authorityId, v.Path, v.Method, domain})
+		}
+		if err = CasbinServiceApp.AddPolicies(tx, rules); err != nil {
+			return err
 		}
-		return CasbinServiceApp.AddPolicies(tx, rules)
+		return CasbinServiceApp.SetAuthorityParent(tx, auth.AuthorityId, parentAuthorityID(auth))
 	})
 
 	return auth, e
@@ -55,11 +68,14 @@ func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthori
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: CopyAuthority
-//@description: 复制一个角色
+//@description: 复制一个角色 开启权限变更审批时不可用
 //@param: copyInfo response.SysAuthorityCopyResponse
 //@return: authority system.SysAuthority, err error
 
-func (authorityService *AuthorityService) CopyAuthority(adminAuthorityID uint, copyInfo response.SysAuthorityCopyResponse) (authority system.SysAuthority, err error) {
+func (authorityService *AuthorityService) CopyAuthority(ctx context.Context, adminAuthorityID uint, copyInfo response.SysAuthorityCopyResponse) (authority system.SysAuthority, err error) {
+	if err = checkApprovalDisabled(); err != nil {
+		return authority, err
+	}
 	var authorityBox system.SysAuthority
 	if !errors.Is(global.GVA_DB.Where("authority_id = ?", copyInfo.Authority.AuthorityId).First(&authorityBox).Error, gorm.ErrRecordNotFound) {
 		return authority, ErrRoleExistence
@@ -76,6 +92,11 @@ func (authorityService *AuthorityService) CopyAuthority(adminAuthorityID uint, c
 		baseMenu = append(baseMenu, v.SysBaseMenu)
 	}
 	copyInfo.Authority.SysBaseMenus = baseMenu
+	if copyInfo.Authority.DataScope != "" {
+		if copyInfo.Authority.DataScopeRule, err = checkDataScope(copyInfo.Authority.DataScope, copyInfo.Authority.DataScopeRule); err != nil {
+			return
+		}
+	}
 	err = global.GVA_DB.Create(&copyInfo.Authority).Error
 	if err != nil {
 		return
@@ -97,17 +118,33 @@ func (authorityService *AuthorityService) CopyAuthority(adminAuthorityID uint, c
 			return
 		}
 	}
-	paths := CasbinServiceApp.GetPolicyPathByAuthorityId(copyInfo.OldAuthorityId)
-	err = CasbinServiceApp.UpdateCasbin(adminAuthorityID, copyInfo.Authority.AuthorityId, paths)
+	var fieldRules []system.SysAuthorityFieldRule
+	if err = global.GVA_DB.Find(&fieldRules, "authority_id = ?", copyInfo.OldAuthorityId).Error; err != nil {
+		return
+	}
+	if len(fieldRules) > 0 {
+		for i := range fieldRules {
+			fieldRules[i].AuthorityId = copyInfo.Authority.AuthorityId
+		}
+		if err = global.GVA_DB.Create(&fieldRules).Error; err != nil {
+			return
+		}
+	}
+	paths := CasbinServiceApp.GetPolicyPathByAuthorityId(ctx, copyInfo.OldAuthorityId)
+	err = CasbinServiceApp.UpdateCasbin(ctx, adminAuthorityID, copyInfo.Authority.AuthorityId, paths)
+	if err == nil {
+		err = CasbinServiceApp.SetAuthorityParent(global.GVA_DB, copyInfo.Authority.AuthorityId, parentAuthorityID(copyInfo.Authority))
+	}
 	if err != nil {
 		_ = authorityService.DeleteAuthority(&copyInfo.Authority)
+		return copyInfo.Authority, err
 	}
-	return copyInfo.Authority, err
+	return copyInfo.Authority, CasbinServiceApp.FreshCasbin()
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: UpdateAuthority
-//@description: 更改一个角色
+//@description: 更改一个角色 开启权限变更审批时不能修改父角色
 //@param: auth model.SysAuthority
 //@return: authority system.SysAuthority, err error
 
@@ -118,10 +155,49 @@ func (authorityService *AuthorityService) UpdateAuthority(auth system.SysAuthori
 		global.GVA_LOG.Debug(err.Error())
 		return system.SysAuthority{}, errors.New("查询角色数据失败")
 	}
-	err = global.GVA_DB.Model(&oldAuthority).Updates(&auth).Error
+	if auth.ParentId == nil || *auth.ParentId == parentAuthorityID(oldAuthority) {
+		err = global.GVA_DB.Model(&oldAuthority).Omit("data_scope", "data_scope_rule").Updates(&auth).Error
+		return auth, err
+	}
+	// 开启casbin继承时子角色随即继承父角色的api权限 不能绕过审批
+	if err = checkApprovalDisabled(); err != nil {
+		return auth, err
+	}
+	if err = authorityService.checkParentAuthority(auth.AuthorityId, *auth.ParentId); err != nil {
+		return auth, err
+	}
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&oldAuthority).Omit("data_scope", "data_scope_rule").Updates(&auth).Error; err != nil {
+			return err
+		}
+		return CasbinServiceApp.SetAuthorityParent(tx, auth.AuthorityId, *auth.ParentId)
+	})
 	return auth, err
 }
 
+// checkParentAuthority 校验父角色存在 且不是角色自身或其子孙角色 避免形成继承环
+func (authorityService *AuthorityService) checkParentAuthority(authorityID, parentID uint) error {
+	for id := parentID; id != 0; {
+		if id == authorityID {
+			return errors.New("父角色不能是自身或其子角色")
+		}
+		var parent system.SysAuthority
+		if err := global.GVA_DB.Select("authority_id", "parent_id").Where("authority_id = ?", id).First(&parent).Error; err != nil {
+			return errors.New("父角色不存在")
+		}
+		id = parentAuthorityID(parent)
+	}
+	return nil
+}
+
+// parentAuthorityID 获取父角色ID 未设置时视为顶级角色
+func parentAuthorityID(auth system.SysAuthority) uint {
+	if auth.ParentId == nil {
+		return 0
+	}
+	return *auth.ParentId
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteAuthority
 //@description: 删除角色
@@ -129,13 +205,14 @@ func (authorityService *AuthorityService) UpdateAuthority(auth system.SysAuthori
 //@return: err error
 
 func (authorityService *AuthorityService) DeleteAuthority(auth *system.SysAuthority) error {
-	if errors.Is(global.GVA_DB.Debug().Preload("Users").First(&auth).Error, gorm.ErrRecordNotFound) {
+	// 角色为全部租户共用 需检查全部租户的用户
+	if errors.Is(tenant.Skip(global.GVA_DB).Debug().Preload("Users").First(&auth).Error, gorm.ErrRecordNotFound) {
 		return errors.New("该角色不存在")
 	}
 	if len(auth.Users) != 0 {
 		return errors.New("此角色有用户正在使用禁止删除")
 	}
-	if !errors.Is(global.GVA_DB.Where("authority_id = ?", auth.AuthorityId).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
+	if !errors.Is(tenant.Skip(global.GVA_DB).Where("authority_id = ?", auth.AuthorityId).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
 		return errors.New("此角色有用户正在使用禁止删除")
 	}
 	if !errors.Is(global.GVA_DB.Where("parent_id = ?", auth.AuthorityId).First(&system.SysAuthority{}).Error, gorm.ErrRecordNotFound) {
@@ -166,12 +243,20 @@ func (authorityService *AuthorityService) DeleteAuthority(auth *system.SysAuthor
 		if err = tx.Where("authority_id = ?", auth.AuthorityId).Delete(&[]system.SysAuthorityBtn{}).Error; err != nil {
 			return err
 		}
+		if err = tx.Where("authority_id = ?", auth.AuthorityId).Delete(&system.SysAuthorityFieldRule{}).Error; err != nil {
+			return err
+		}
+		authorityFieldRules.Delete(auth.AuthorityId)
 
 		authorityId := strconv.Itoa(int(auth.AuthorityId))
 
+		// 角色为全部租户共用 仅可在默认租户删除 删除后清理其在各租户域内的策略
 		if err = CasbinServiceApp.RemoveFilteredPolicy(tx, authorityId); err != nil {
 			return err
 		}
+		if err = CasbinServiceApp.RemoveGroupingPolicy(tx, authorityId); err != nil {
+			return err
+		}
 
 		return nil
 	})
@@ -328,3 +413,53 @@ func (authorityService *AuthorityService) GetParentAuthorityID(authorityID uint)
 	err = global.GVA_DB.Where("authority_id = ?", authorityID).First(&authority).Error
 	return *authority.ParentId, err
 }
+
+// SetAuthorityTwoFactor 设置角色是否强制两步验证 开启后该角色用户下次登录时必须完成两步验证
+func (authorityService *AuthorityService) SetAuthorityTwoFactor(adminAuthorityID, authorityID uint, require bool) error {
+	if err := authorityService.CheckAuthorityIDAuth(adminAuthorityID, authorityID); err != nil {
+		return err
+	}
+	return global.GVA_DB.Model(&system.SysAuthority{}).Where("authority_id = ?", authorityID).Update("require_two_factor", require).Error
+}
+
+// SetAuthorityMaxSessions 设置角色用户最多同时保持的登录会话数 0为不限制 新登录超出上限时注销最早活跃的会话
+func (authorityService *AuthorityService) SetAuthorityMaxSessions(adminAuthorityID, authorityID uint, maxSessions int) error {
+	if maxSessions < 0 {
+		return errors.New("会话数不能小于0")
+	}
+	if err := authorityService.CheckAuthorityIDAuth(adminAuthorityID, authorityID); err != nil {
+		return err
+	}
+	return global.GVA_DB.Model(&system.SysAuthority{}).Where("authority_id = ?", authorityID).Update("max_sessions", maxSessions).Error
+}
+
+// SetAuthorityDataScope 设置角色的数据范围 对实现了datascope.Model的模型生效 开启权限变更审批时不可用
+func (authorityService *AuthorityService) SetAuthorityDataScope(adminAuthorityID uint, req systemReq.SetAuthorityDataScope) (err error) {
+	if err = checkApprovalDisabled(); err != nil {
+		return err
+	}
+	if req.DataScopeRule, err = checkDataScope(req.DataScope, req.DataScopeRule); err != nil {
+		return err
+	}
+	if err = authorityService.CheckAuthorityIDAuth(adminAuthorityID, req.AuthorityId); err != nil {
+		return err
+	}
+	err = global.GVA_DB.Model(&system.SysAuthority{}).Where("authority_id = ?", req.AuthorityId).
+		Updates(map[string]interface{}{"data_scope": req.DataScope, "data_scope_rule": req.DataScopeRule}).Error
+	if err != nil {
+		return err
+	}
+	dataScopeRules.Delete(req.AuthorityId)
+	return nil
+}
+
+// checkDataScope 校验数据范围 非自定义范围时清空自定义条件
+func checkDataScope(scope, rule string) (string, error) {
+	switch scope {
+	case datascope.ScopeAll, datascope.ScopeSelf, datascope.ScopeDept, datascope.ScopeDeptTree:
+		return "", nil
+	case datascope.ScopeCustom:
+		return rule, datascope.ValidateRule(rule)
+	}
+	return "", errors.New("数据范围不合法")
+}
@@ -0,0 +1,93 @@
+package system
+
+import (
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/fieldrule"
+	"gorm.io/gorm"
+)
+
+type AuthorityFieldRuleService struct{}
+
+var AuthorityFieldRuleServiceApp = new(AuthorityFieldRuleService)
+
+// authorityFieldRules 角色字段规则缓存 修改角色字段规则时清除
+var authorityFieldRules sync.Map
+
+// SetFieldRules 设置角色的字段规则 覆盖角色原有的全部规则 开启权限变更审批时不可用
+func (fieldRuleService *AuthorityFieldRuleService) SetFieldRules(adminAuthorityID uint, req systemReq.SetAuthorityFieldRules) error {
+	if err := checkApprovalDisabled(); err != nil {
+		return err
+	}
+	if err := AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, req.AuthorityId); err != nil {
+		return err
+	}
+	for i := range req.Rules {
+		if err := fieldrule.Validate(req.Rules[i].Model, req.Rules[i].Field, req.Rules[i].Rule); err != nil {
+			return err
+		}
+		req.Rules[i].AuthorityId = req.AuthorityId
+	}
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("authority_id = ?", req.AuthorityId).Delete(&system.SysAuthorityFieldRule{}).Error; err != nil {
+			return err
+		}
+		if len(req.Rules) == 0 {
+			return nil
+		}
+		return tx.Create(&req.Rules).Error
+	})
+	authorityFieldRules.Delete(req.AuthorityId)
+	return err
+}
+
+// GetFieldRules 获取角色的字段规则
+func (fieldRuleService *AuthorityFieldRuleService) GetFieldRules(authorityID uint) (list []system.SysAuthorityFieldRule, err error) {
+	err = global.GVA_DB.Where("authority_id = ?", authorityID).Order("model, field").Find(&list).Error
+	return list, err
+}
+
+// Rules 获取角色按模型分组的字段规则
+func (fieldRuleService *AuthorityFieldRuleService) Rules(authorityID uint) (fieldrule.Rules, error) {
+	if rules, ok := authorityFieldRules.Load(authorityID); ok {
+		return rules.(fieldrule.Rules), nil
+	}
+	list, err := fieldRuleService.GetFieldRules(authorityID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(fieldrule.Rules)
+	for i := range list {
+		if rules[list[i].Model] == nil {
+			rules[list[i].Model] = make(map[string]string)
+		}
+		rules[list[i].Model][list[i].Field] = list[i].Rule
+	}
+	authorityFieldRules.Store(authorityID, rules)
+	return rules, nil
+}
+
+// Apply 按角色的字段规则隐藏、脱敏返回结果 v需为指针或切片
+func (fieldRuleService *AuthorityFieldRuleService) Apply(authorityID uint, v interface{}) error {
+	rules, err := fieldRuleService.Rules(authorityID)
+	if err != nil {
+		return err
+	}
+	fieldrule.Apply(v, rules)
+	return nil
+}
+
+// Protect 更新时忽略角色受限的字段 读取规则失败时中止更新
+func (fieldRuleService *AuthorityFieldRuleService) Protect(authorityID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		rules, err := fieldRuleService.Rules(authorityID)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return fieldrule.Protect(rules)(db)
+	}
+}
@@ -1,17 +1,26 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"gorm.io/gorm"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	common "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/casbinwatch"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 	_ "github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
 )
@@ -19,14 +28,14 @@ import (
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: UpdateCasbin
 //@description: 更新casbin权限
-//@param: authorityId string, casbinInfos []request.CasbinInfo
+//@param: ctx context.Context, authorityId string, casbinInfos []request.CasbinInfo
 //@return: error
 
 type CasbinService struct{}
 
 var CasbinServiceApp = new(CasbinService)
 
-func (casbinService *CasbinService) UpdateCasbin(adminAuthorityID, AuthorityID uint, casbinInfos []request.CasbinInfo) error {
+func (casbinService *CasbinService) UpdateCasbin(ctx context.Context, adminAuthorityID, AuthorityID uint, casbinInfos []request.CasbinInfo) error {
 
 	err := AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, AuthorityID)
 	if err != nil {
@@ -34,7 +43,7 @@ func (casbinService *CasbinService) UpdateCasbin(adminAuthorityID, AuthorityID u
 	}
 
 	if global.GVA_CONFIG.System.UseStrictAuth {
-		apis, e := ApiServiceApp.GetAllApis(adminAuthorityID)
+		apis, e := ApiServiceApp.GetAllApis(ctx, adminAuthorityID)
 		if e != nil {
 			return e
 		}
@@ -54,7 +63,16 @@ func (casbinService *CasbinService) UpdateCasbin(adminAuthorityID, AuthorityID u
 	}
 
 	authorityId := strconv.Itoa(int(AuthorityID))
-	casbinService.ClearCasbin(0, authorityId)
+	domain := tenant.Domain(ctx)
+	for i := range casbinInfos {
+		if err = checkPlatformApi(domain, casbinInfos[i].Path); err != nil {
+			return err
+		}
+	}
+	e := casbinService.Casbin()
+	_, _ = e.RemoveFilteredPolicy(0, authorityId, "", "", domain)
+	// 清除与添加完成后统一通知其他实例 避免其加载到中间状态
+	defer casbinService.notifyPolicyChange()
 	rules := [][]string{}
 	//做权限去重处理
 	deduplicateMap := make(map[string]bool)
@@ -62,13 +80,12 @@ func (casbinService *CasbinService) UpdateCasbin(adminAuthorityID, AuthorityID u
 		key := authorityId + v.Path + v.Method
 		if _, ok := deduplicateMap[key]; !ok {
 			deduplicateMap[key] = true
-			rules = append(rules, []string{authorityId, v.Path, v.Method})
+			rules = append(rules, []string{authorityId, v.Path, v.Method, domain})
 		}
 	}
 	if len(rules) == 0 {
 		return nil
 	} // 设置空权限无需调用 AddPolicies 方法
-	e := casbinService.Casbin()
 	success, _ := e.AddPolicies(rules)
 	if !success {
 		return errors.New("存在相同api,添加失败,请联系管理员")
@@ -92,19 +109,23 @@ func (casbinService *CasbinService) UpdateCasbinApi(oldPath string, newPath stri
 	}
 
 	e := casbinService.Casbin()
-	return e.LoadPolicy()
+	if err = e.LoadPolicy(); err != nil {
+		return err
+	}
+	casbinService.notifyPolicyChange()
+	return nil
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetPolicyPathByAuthorityId
 //@description: 获取权限列表
-//@param: authorityId string
+//@param: ctx context.Context, authorityId string
 //@return: pathMaps []request.CasbinInfo
 
-func (casbinService *CasbinService) GetPolicyPathByAuthorityId(AuthorityID uint) (pathMaps []request.CasbinInfo) {
+func (casbinService *CasbinService) GetPolicyPathByAuthorityId(ctx context.Context, AuthorityID uint) (pathMaps []request.CasbinInfo) {
 	e := casbinService.Casbin()
 	authorityId := strconv.Itoa(int(AuthorityID))
-	list, _ := e.GetFilteredPolicy(0, authorityId)
+	list, _ := e.GetFilteredPolicy(0, authorityId, "", "", tenant.Domain(ctx))
 	for _, v := range list {
 		pathMaps = append(pathMaps, request.CasbinInfo{
 			Path:   v[1],
@@ -114,6 +135,24 @@ func (casbinService *CasbinService) GetPolicyPathByAuthorityId(AuthorityID uint)
 	return pathMaps
 }
 
+// GetImplicitPolicyPathByAuthorityId 获取角色在当前租户的全部权限 包括通过分组继承自父角色的权限
+func (casbinService *CasbinService) GetImplicitPolicyPathByAuthorityId(ctx context.Context, AuthorityID uint) (pathMaps []request.CasbinInfo) {
+	e := casbinService.Casbin()
+	authorityId := strconv.Itoa(int(AuthorityID))
+	domain := tenant.Domain(ctx)
+	list, _ := e.GetImplicitPermissionsForUser(authorityId)
+	for _, v := range list {
+		if len(v) < 4 || v[3] != domain {
+			continue
+		}
+		pathMaps = append(pathMaps, request.CasbinInfo{
+			Path:   v[1],
+			Method: v[2],
+		})
+	}
+	return pathMaps
+}
+
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: ClearCasbin
 //@description: 清除匹配的权限
@@ -123,6 +162,9 @@ func (casbinService *CasbinService) GetPolicyPathByAuthorityId(AuthorityID uint)
 func (casbinService *CasbinService) ClearCasbin(v int, p ...string) bool {
 	e := casbinService.Casbin()
 	success, _ := e.RemoveFilteredPolicy(v, p...)
+	if success {
+		casbinService.notifyPolicyChange()
+	}
 	return success
 }
 
@@ -133,17 +175,74 @@ func (casbinService *CasbinService) ClearCasbin(v int, p ...string) bool {
 //@return: error
 
 func (casbinService *CasbinService) RemoveFilteredPolicy(db *gorm.DB, authorityId string) error {
-	return db.Delete(&gormadapter.CasbinRule{}, "v0 = ?", authorityId).Error
+	return db.Delete(&gormadapter.CasbinRule{}, "ptype = ? AND v0 = ?", "p", authorityId).Error
+}
+
+// RemoveDomainPolicy 清理角色在指定租户域内的p策略 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
+func (casbinService *CasbinService) RemoveDomainPolicy(db *gorm.DB, authorityId, domain string) error {
+	return db.Delete(&gormadapter.CasbinRule{}, "ptype = ? AND v0 = ? AND v3 = ?", "p", authorityId, domain).Error
+}
+
+// RemoveGroupingPolicy 清理角色作为子角色或父角色的g分组策略 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
+func (casbinService *CasbinService) RemoveGroupingPolicy(db *gorm.DB, authorityId string) error {
+	return db.Delete(&gormadapter.CasbinRule{}, "ptype = ? AND (v0 = ? OR v1 = ?)", "g", authorityId, authorityId).Error
+}
+
+// SetAuthorityParent 按父角色重建角色的g分组策略 未开启继承或没有父角色时仅清理 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
+func (casbinService *CasbinService) SetAuthorityParent(db *gorm.DB, authorityID, parentID uint) error {
+	authorityId := strconv.Itoa(int(authorityID))
+	err := db.Delete(&gormadapter.CasbinRule{}, "ptype = ? AND v0 = ?", "g", authorityId).Error
+	if err != nil || !global.GVA_CONFIG.System.UseCasbinInherit || parentID == 0 {
+		return err
+	}
+	return db.Create(&gormadapter.CasbinRule{Ptype: "g", V0: authorityId, V1: strconv.Itoa(int(parentID))}).Error
+}
+
+// SyncAuthorityInheritance 按全部角色的父子关系重建g分组策略 未开启继承时清除全部分组策略 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
+func (casbinService *CasbinService) SyncAuthorityInheritance(db *gorm.DB) error {
+	if err := db.Delete(&gormadapter.CasbinRule{}, "ptype = ?", "g").Error; err != nil {
+		return err
+	}
+	if !global.GVA_CONFIG.System.UseCasbinInherit {
+		return nil
+	}
+	var authorities []system.SysAuthority
+	if err := db.Select("authority_id", "parent_id").Where("parent_id <> 0").Find(&authorities).Error; err != nil {
+		return err
+	}
+	var casbinRules []gormadapter.CasbinRule
+	for i := range authorities {
+		casbinRules = append(casbinRules, gormadapter.CasbinRule{
+			Ptype: "g",
+			V0:    strconv.Itoa(int(authorities[i].AuthorityId)),
+			V1:    strconv.Itoa(int(parentAuthorityID(authorities[i]))),
+		})
+	}
+	if len(casbinRules) == 0 {
+		return nil
+	}
+	return db.Create(&casbinRules).Error
+}
+
+// LoadAuthorityInheritance 启动时按配置同步角色继承关系 使开关的变更即刻生效
+func LoadAuthorityInheritance() {
+	if err := CasbinServiceApp.SyncAuthorityInheritance(global.GVA_DB); err != nil {
+		global.GVA_LOG.Error("同步角色继承关系失败!", zap.Error(err))
+		return
+	}
+	if err := CasbinServiceApp.FreshCasbin(); err != nil {
+		global.GVA_LOG.Error("刷新casbin权限失败!", zap.Error(err))
+	}
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: SyncPolicy
-//@description: 同步目前数据库的policy 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
-//@param: db *gorm.DB, authorityId string, rules [][]string
+//@description: 同步角色在租户域内的policy 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
+//@param: db *gorm.DB, authorityId string, domain string, rules [][]string
 //@return: error
 
-func (casbinService *CasbinService) SyncPolicy(db *gorm.DB, authorityId string, rules [][]string) error {
-	err := casbinService.RemoveFilteredPolicy(db, authorityId)
+func (casbinService *CasbinService) SyncPolicy(db *gorm.DB, authorityId, domain string, rules [][]string) error {
+	err := casbinService.RemoveDomainPolicy(db, authorityId, domain)
 	if err != nil {
 		return err
 	}
@@ -152,9 +251,9 @@ func (casbinService *CasbinService) SyncPolicy(db *gorm.DB, authorityId string,
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: AddPolicies
-//@description: 添加匹配的权限
-//@param: v int, p ...string
-//@return: bool
+//@description: 添加匹配的权限 rules的每一项为角色、路径、方法及租户域
+//@param: db *gorm.DB, rules [][]string
+//@return: error
 
 func (casbinService *CasbinService) AddPolicies(db *gorm.DB, rules [][]string) error {
 	var casbinRules []gormadapter.CasbinRule
@@ -164,14 +263,92 @@ func (casbinService *CasbinService) AddPolicies(db *gorm.DB, rules [][]string) e
 			V0:    rules[i][0],
 			V1:    rules[i][1],
 			V2:    rules[i][2],
+			V3:    rules[i][3],
 		})
 	}
+	if len(casbinRules) == 0 {
+		return nil
+	}
 	return db.Create(&casbinRules).Error
 }
 
+// ExplainPermission 模拟CasbinHandler对角色的判定 说明命中的策略 api的登记情况及角色被授予的菜单与按钮
+func (casbinService *CasbinService) ExplainPermission(ctx context.Context, adminAuthorityID uint, info request.CasbinExplain) (res systemRes.CasbinExplainResponse, err error) {
+	res.AuthorityId = info.AuthorityId
+	if info.UserID != 0 {
+		var user system.SysUser
+		if err = global.GVA_DB.WithContext(ctx).Select("id", "authority_id").Where("id = ?", info.UserID).First(&user).Error; err != nil {
+			return res, errors.New("用户不存在")
+		}
+		res.AuthorityId = user.AuthorityId
+	}
+	if res.AuthorityId == 0 {
+		return res, errors.New("用户ID与角色ID不能同时为空")
+	}
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, res.AuthorityId); err != nil {
+		return res, err
+	}
+	res.Path = strings.TrimPrefix(info.Path, global.GVA_CONFIG.System.RouterPrefix)
+	res.Method = strings.ToUpper(info.Method)
+
+	authorityId := strconv.Itoa(int(res.AuthorityId))
+	allowed, matched, err := casbinService.Casbin().EnforceEx(authorityId, res.Path, res.Method, tenant.Domain(ctx))
+	if err != nil {
+		return res, err
+	}
+	res.Allowed = allowed
+	if allowed && len(matched) > 0 {
+		res.MatchedPolicy = matched
+		res.Inherited = matched[0] != authorityId
+	}
+
+	var apis []system.SysApi
+	if err = global.GVA_DB.Where("method = ?", res.Method).Find(&apis).Error; err != nil {
+		return res, err
+	}
+	for i := range apis {
+		if apis[i].Path == res.Path || (res.Api == nil && util.KeyMatch2(res.Path, apis[i].Path)) {
+			res.Api = &apis[i]
+		}
+	}
+	res.ApiExists = res.Api != nil
+	var ignored int64
+	if err = global.GVA_DB.Model(&system.SysIgnoreApi{}).Where("path = ? AND method = ?", res.Path, res.Method).Count(&ignored).Error; err != nil {
+		return res, err
+	}
+	res.Ignored = ignored > 0
+
+	switch {
+	case res.Inherited:
+		res.Reason = "命中父角色" + res.MatchedPolicy[0] + "的策略"
+	case allowed:
+		res.Reason = "命中角色自身的策略"
+	case !res.ApiExists:
+		res.Reason = "没有匹配的策略 且该api未在api管理中登记"
+	default:
+		res.Reason = "没有匹配的策略 需在角色的api权限中勾选该api"
+	}
+
+	res.Menus, err = MenuServiceApp.GetMenuAuthority(&common.GetAuthorityId{AuthorityId: res.AuthorityId})
+	if err != nil {
+		return res, err
+	}
+	var btns []system.SysAuthorityBtn
+	if err = global.GVA_DB.Preload("SysBaseMenuBtn").Where("authority_id = ?", res.AuthorityId).Find(&btns).Error; err != nil {
+		return res, err
+	}
+	for i := range btns {
+		res.Btns = append(res.Btns, btns[i].SysBaseMenuBtn)
+	}
+	return res, nil
+}
+
 func (casbinService *CasbinService) FreshCasbin() (err error) {
 	e := casbinService.Casbin()
 	err = e.LoadPolicy()
+	if err == nil {
+		casbinService.notifyPolicyChange()
+	}
 	return err
 }
 
@@ -194,10 +371,10 @@ func (casbinService *CasbinService) Casbin() *casbin.SyncedCachedEnforcer {
 		}
 		text := `
 		[request_definition]
-		r = sub, obj, act
+		r = sub, obj, act, dom
 		
 		[policy_definition]
-		p = sub, obj, act
+		p = sub, obj, act, dom
 		
 		[role_definition]
 		g = _, _
@@ -206,7 +383,7 @@ func (casbinService *CasbinService) Casbin() *casbin.SyncedCachedEnforcer {
 		e = some(where (p.eft == allow))
 		
 		[matchers]
-		m = r.sub == p.sub && keyMatch2(r.obj,p.obj) && r.act == p.act
+		m = g(r.sub, p.sub) && r.dom == p.dom && keyMatch2(r.obj,p.obj) && r.act == p.act
 		`
 		m, err := model.NewModelFromString(text)
 		if err != nil {
@@ -219,3 +396,47 @@ func (casbinService *CasbinService) Casbin() *casbin.SyncedCachedEnforcer {
 	})
 	return syncedCachedEnforcer
 }
+
+// casbinWatcher 开启redis时用于在多个实例间同步策略变更
+var casbinWatcher atomic.Pointer[casbinwatch.Watcher]
+
+// WatchCasbinPolicy 开启redis时订阅其他实例的策略变更并重新加载 未开启redis或订阅失败时策略变更仅在本实例即刻生效
+func WatchCasbinPolicy() {
+	if global.GVA_REDIS == nil {
+		return
+	}
+	e := CasbinServiceApp.Casbin()
+	if e == nil {
+		return
+	}
+	w, err := casbinwatch.New(context.Background(), global.GVA_REDIS)
+	if err != nil {
+		global.GVA_LOG.Error("订阅casbin策略变更失败 策略变更仅在本实例即刻生效!", zap.Error(err))
+		return
+	}
+	if err = e.SetWatcher(w); err != nil {
+		w.Close()
+		global.GVA_LOG.Error("设置casbin策略监听失败!", zap.Error(err))
+		return
+	}
+	// 由CasbinService在变更完成后统一通知 不在每次增删策略时通知
+	e.EnableAutoNotifyWatcher(false)
+	// 默认回调绕过了SyncedCachedEnforcer的锁与缓存 需替换为其LoadPolicy
+	_ = w.SetUpdateCallback(func(string) {
+		if err := e.LoadPolicy(); err != nil {
+			global.GVA_LOG.Error("同步其他实例的casbin策略失败!", zap.Error(err))
+		}
+	})
+	casbinWatcher.Store(w)
+}
+
+// notifyPolicyChange 通知其他实例重新加载策略 未开启redis时跳过
+func (casbinService *CasbinService) notifyPolicyChange() {
+	w := casbinWatcher.Load()
+	if w == nil {
+		return
+	}
+	if err := w.Update(); err != nil {
+		global.GVA_LOG.Error("通知其他实例刷新casbin策略失败!", zap.Error(err))
+	}
+}
@@ -0,0 +1,191 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// createTestAuthority 创建角色 parentID为0时为顶级角色
+func createTestAuthority(t *testing.T, authorityID, parentID uint) {
+	t.Helper()
+	parent := parentID
+	err := global.GVA_DB.Create(&system.SysAuthority{AuthorityId: authorityID, AuthorityName: "test", ParentId: &parent}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCasbinService_SyncAuthorityInheritance(t *testing.T) {
+	setupTestDB(t)
+	setupTestCasbin(t,
+		[]string{"888", "/user/list", "GET", "1"},
+		[]string{"8881", "/user/self", "GET", "1"},
+		[]string{"888", "/tenant/other", "GET", "2"},
+	)
+	createTestAuthority(t, 888, 0)
+	createTestAuthority(t, 8881, 888)
+	createTestAuthority(t, 88811, 8881)
+
+	global.GVA_CONFIG.System.UseCasbinInherit = true
+	if err := CasbinServiceApp.SyncAuthorityInheritance(global.GVA_DB); err != nil {
+		t.Fatal(err)
+	}
+	if err := CasbinServiceApp.FreshCasbin(); err != nil {
+		t.Fatal(err)
+	}
+	e := CasbinServiceApp.Casbin()
+	tests := []struct {
+		sub, obj, dom string
+		want          bool
+	}{
+		{"8881", "/user/list", "1", true},
+		{"88811", "/user/list", "1", true},
+		{"88811", "/user/self", "1", true},
+		{"888", "/user/self", "1", false},
+		{"8881", "/tenant/other", "1", false},
+	}
+	for _, tt := range tests {
+		if ok, _ := e.Enforce(tt.sub, tt.obj, "GET", tt.dom); ok != tt.want {
+			t.Errorf("Enforce(%s, %s, %s) = %v, want %v", tt.sub, tt.obj, tt.dom, ok, tt.want)
+		}
+	}
+	// 隐式权限只包含当前租户域的策略
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	if paths := CasbinServiceApp.GetImplicitPolicyPathByAuthorityId(ctx, 88811); len(paths) != 2 {
+		t.Errorf("GetImplicitPolicyPathByAuthorityId() = %v, want 2 paths", paths)
+	}
+	if paths := CasbinServiceApp.GetPolicyPathByAuthorityId(ctx, 88811); len(paths) != 0 {
+		t.Errorf("GetPolicyPathByAuthorityId() = %v, want no own paths", paths)
+	}
+
+	// 关闭继承后清除全部分组策略
+	global.GVA_CONFIG.System.UseCasbinInherit = false
+	if err := CasbinServiceApp.SyncAuthorityInheritance(global.GVA_DB); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	global.GVA_DB.Model(&gormadapter.CasbinRule{}).Where("ptype = ?", "g").Count(&count)
+	if count != 0 {
+		t.Errorf("grouping policies = %d, want 0", count)
+	}
+}
+
+func TestCasbinService_SetAuthorityParent(t *testing.T) {
+	setupTestDB(t)
+	setupTestCasbin(t)
+	global.GVA_CONFIG.System.UseCasbinInherit = true
+	groups := func() (rules []gormadapter.CasbinRule) {
+		global.GVA_DB.Where("ptype = ? AND v0 = ?", "g", "8881").Find(&rules)
+		return rules
+	}
+	if err := CasbinServiceApp.SetAuthorityParent(global.GVA_DB, 8881, 888); err != nil {
+		t.Fatal(err)
+	}
+	if err := CasbinServiceApp.SetAuthorityParent(global.GVA_DB, 8881, 9528); err != nil {
+		t.Fatal(err)
+	}
+	if rules := groups(); len(rules) != 1 || rules[0].V1 != "9528" {
+		t.Errorf("SetAuthorityParent() rules = %v, want single parent 9528", rules)
+	}
+	if err := CasbinServiceApp.SetAuthorityParent(global.GVA_DB, 8881, 0); err != nil {
+		t.Fatal(err)
+	}
+	if rules := groups(); len(rules) != 0 {
+		t.Errorf("SetAuthorityParent(0) rules = %v, want none", rules)
+	}
+}
+
+func TestAuthorityService_CheckParentAuthority(t *testing.T) {
+	setupTestDB(t)
+	createTestAuthority(t, 888, 0)
+	createTestAuthority(t, 8881, 888)
+	createTestAuthority(t, 88811, 8881)
+	createTestAuthority(t, 9528, 0)
+
+	tests := []struct {
+		name                  string
+		authorityID, parentID uint
+		wantErr               bool
+	}{
+		{"top level", 8881, 0, false},
+		{"other branch", 8881, 9528, false},
+		{"self", 8881, 8881, true},
+		{"descendant", 888, 88811, true},
+		{"missing", 8881, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AuthorityServiceApp.checkParentAuthority(tt.authorityID, tt.parentID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkParentAuthority(%d, %d) error = %v, wantErr %v", tt.authorityID, tt.parentID, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCasbinService_ExplainPermission(t *testing.T) {
+	setupTestDB(t, &system.SysApi{}, &system.SysIgnoreApi{}, &system.SysBaseMenu{}, &system.SysBaseMenuBtn{},
+		&system.SysAuthorityMenu{}, &system.SysAuthorityBtn{})
+	setupTestCasbin(t,
+		[]string{"888", "/user/list", "GET", "1"},
+		[]string{"8881", "/user/:id", "GET", "1"},
+	)
+	global.GVA_CONFIG.System.RouterPrefix = "/api"
+	global.GVA_CONFIG.System.UseCasbinInherit = true
+	createTestAuthority(t, 888, 0)
+	createTestAuthority(t, 8881, 888)
+	if err := CasbinServiceApp.SyncAuthorityInheritance(global.GVA_DB); err != nil {
+		t.Fatal(err)
+	}
+	if err := CasbinServiceApp.FreshCasbin(); err != nil {
+		t.Fatal(err)
+	}
+	global.GVA_DB.Create(&[]system.SysApi{
+		{Path: "/user/list", Method: "GET"},
+		{Path: "/user/:id", Method: "GET"},
+		{Path: "/user/delete", Method: "DELETE"},
+	})
+	user := createTestUser(t, "alice", 8881)
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+
+	tests := []struct {
+		name          string
+		info          request.CasbinExplain
+		wantAllowed   bool
+		wantInherited bool
+		wantApi       string
+		wantReason    string
+	}{
+		{"own policy", request.CasbinExplain{AuthorityId: 8881, Path: "/api/user/7", Method: "get"}, true, false, "/user/:id", "命中角色自身的策略"},
+		{"inherited by user", request.CasbinExplain{UserID: user.ID, Path: "/user/list", Method: "GET"}, true, true, "/user/list", "命中父角色888的策略"},
+		{"not granted", request.CasbinExplain{AuthorityId: 888, Path: "/user/delete", Method: "DELETE"}, false, false, "/user/delete", "没有匹配的策略 需在角色的api权限中勾选该api"},
+		{"not registered", request.CasbinExplain{AuthorityId: 888, Path: "/unknown", Method: "POST"}, false, false, "", "没有匹配的策略 且该api未在api管理中登记"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := CasbinServiceApp.ExplainPermission(ctx, 888, tt.info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed != tt.wantAllowed || res.Inherited != tt.wantInherited || res.Reason != tt.wantReason {
+				t.Errorf("ExplainPermission() = allowed %v inherited %v reason %q", res.Allowed, res.Inherited, res.Reason)
+			}
+			if (tt.wantApi == "") != (res.Api == nil) || (res.Api != nil && res.Api.Path != tt.wantApi) {
+				t.Errorf("ExplainPermission() api = %+v, want %q", res.Api, tt.wantApi)
+			}
+		})
+	}
+
+	if _, err := CasbinServiceApp.ExplainPermission(ctx, 888, request.CasbinExplain{Path: "/user/list", Method: "GET"}); err == nil {
+		t.Error("ExplainPermission() should require a user or authority")
+	}
+	if _, err := CasbinServiceApp.ExplainPermission(ctx, 888, request.CasbinExplain{UserID: 999, Path: "/user/list", Method: "GET"}); err == nil {
+		t.Error("ExplainPermission() should reject unknown user")
+	}
+}
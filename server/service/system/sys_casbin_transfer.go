@@ -0,0 +1,284 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// ExportPolicy 导出当前租户的全部p策略及sys_apis 用于在环境间迁移权限
+func (casbinService *CasbinService) ExportPolicy(ctx context.Context) (file request.CasbinPolicyFile, err error) {
+	file.Version = request.CasbinPolicyFileVersion
+	file.ExportedAt = time.Now()
+	if err = global.GVA_DB.Model(&system.SysAuthority{}).Order("authority_id").Pluck("authority_id", &file.Authorities).Error; err != nil {
+		return file, err
+	}
+	var apis []system.SysApi
+	if err = global.GVA_DB.Order("path, method").Find(&apis).Error; err != nil {
+		return file, err
+	}
+	file.Apis = make([]request.CasbinPolicyApi, 0, len(apis))
+	for i := range apis {
+		file.Apis = append(file.Apis, request.CasbinPolicyApi{
+			Path:        apis[i].Path,
+			Method:      apis[i].Method,
+			ApiGroup:    apis[i].ApiGroup,
+			Description: apis[i].Description,
+		})
+	}
+	file.Policies, err = casbinService.livePolicies(tenant.Domain(ctx))
+	return file, err
+}
+
+// MarshalPolicyFile 按格式序列化策略文件 format为yaml或json
+func MarshalPolicyFile(file request.CasbinPolicyFile, format string) ([]byte, error) {
+	if format == "yaml" || format == "yml" {
+		return yaml.Marshal(file)
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// UnmarshalPolicyFile 按文件扩展名解析策略文件 .yaml/.yml按yaml解析 其余按json解析
+func UnmarshalPolicyFile(name string, data []byte) (file request.CasbinPolicyFile, err error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return file, errors.New("策略文件解析失败: " + err.Error())
+	}
+	if file.Version != request.CasbinPolicyFileVersion {
+		return file, fmt.Errorf("不支持的策略文件版本: %d", file.Version)
+	}
+	for i := range file.Apis {
+		file.Apis[i].Method = strings.ToUpper(file.Apis[i].Method)
+		if file.Apis[i].Path == "" || file.Apis[i].Method == "" {
+			return file, errors.New("策略文件中存在路径或方法为空的api")
+		}
+	}
+	for i := range file.Policies {
+		file.Policies[i].Method = strings.ToUpper(file.Policies[i].Method)
+		if file.Policies[i].AuthorityId == 0 || file.Policies[i].Path == "" || file.Policies[i].Method == "" {
+			return file, errors.New("策略文件中存在不完整的策略")
+		}
+		if !slices.Contains(file.Authorities, file.Policies[i].AuthorityId) {
+			file.Authorities = append(file.Authorities, file.Policies[i].AuthorityId)
+		}
+	}
+	return file, nil
+}
+
+// DiffPolicy 比较策略文件与当前租户 仅比较文件中列出且当前环境存在的角色的策略 非默认租户忽略仅在默认租户授权的接口
+func (casbinService *CasbinService) DiffPolicy(ctx context.Context, file request.CasbinPolicyFile) (diff systemRes.CasbinPolicyDiff, err error) {
+	file.Policies = tenantPolicies(tenant.Domain(ctx), file.Policies)
+	var apis []system.SysApi
+	if err = global.GVA_DB.Order("path, method").Find(&apis).Error; err != nil {
+		return diff, err
+	}
+	liveApis := make(map[string]system.SysApi, len(apis))
+	for i := range apis {
+		liveApis[apis[i].Path+" "+apis[i].Method] = apis[i]
+	}
+	fileApis := make(map[string]bool, len(file.Apis))
+	for _, api := range file.Apis {
+		key := api.Path + " " + api.Method
+		if fileApis[key] {
+			continue
+		}
+		fileApis[key] = true
+		live, ok := liveApis[key]
+		if !ok {
+			diff.AddedApis = append(diff.AddedApis, api)
+		} else if live.ApiGroup != api.ApiGroup || live.Description != api.Description {
+			diff.ChangedApis = append(diff.ChangedApis, api)
+		}
+	}
+	for i := range apis {
+		if !fileApis[apis[i].Path+" "+apis[i].Method] {
+			diff.RemovedApis = append(diff.RemovedApis, request.CasbinPolicyApi{
+				Path:        apis[i].Path,
+				Method:      apis[i].Method,
+				ApiGroup:    apis[i].ApiGroup,
+				Description: apis[i].Description,
+			})
+		}
+	}
+
+	var localAuthorities []uint
+	if err = global.GVA_DB.Model(&system.SysAuthority{}).Pluck("authority_id", &localAuthorities).Error; err != nil {
+		return diff, err
+	}
+	synced := make(map[uint]bool, len(file.Authorities))
+	for _, id := range file.Authorities {
+		if slices.Contains(localAuthorities, id) {
+			synced[id] = true
+		} else if !slices.Contains(diff.UnknownAuthorities, id) {
+			diff.UnknownAuthorities = append(diff.UnknownAuthorities, id)
+		}
+	}
+	policies, err := casbinService.livePolicies(tenant.Domain(ctx))
+	if err != nil {
+		return diff, err
+	}
+	livePolicies := make(map[request.CasbinPolicy]bool, len(policies))
+	for _, policy := range policies {
+		livePolicies[policy] = true
+	}
+	filePolicies := make(map[request.CasbinPolicy]bool, len(file.Policies))
+	for _, policy := range file.Policies {
+		if !synced[policy.AuthorityId] || filePolicies[policy] {
+			continue
+		}
+		filePolicies[policy] = true
+		if !livePolicies[policy] {
+			diff.AddedPolicies = append(diff.AddedPolicies, policy)
+		}
+	}
+	for _, policy := range policies {
+		if synced[policy.AuthorityId] && !filePolicies[policy] {
+			diff.RemovedPolicies = append(diff.RemovedPolicies, policy)
+		}
+	}
+	return diff, nil
+}
+
+// ImportPolicy 导入策略文件到当前租户 默认仅新增及更新 prune为true时删除文件中不存在的api及策略 dryRun为true时仅返回将应用的差异
+// sys_apis为全部租户共享 仅超级管理员在默认租户导入时同步 其余情况只导入策略 开启权限变更审批时仅可预演
+func (casbinService *CasbinService) ImportPolicy(ctx context.Context, adminAuthorityID, operatorID uint, file request.CasbinPolicyFile, dryRun, prune bool, ip string) (diff systemRes.CasbinPolicyDiff, err error) {
+	diff, err = casbinService.DiffPolicy(ctx, file)
+	if err != nil {
+		return diff, err
+	}
+	if tenant.Domain(ctx) != strconv.Itoa(int(tenant.Default)) || !TenantServiceApp.IsSuperAdmin(operatorID, adminAuthorityID) {
+		diff.AddedApis, diff.ChangedApis, diff.RemovedApis = nil, nil, nil
+		diff.ApisSkipped = true
+	}
+	if !prune {
+		diff.RemovedApis, diff.RemovedPolicies = nil, nil
+	}
+	if dryRun {
+		return diff, nil
+	}
+	if err = checkApprovalDisabled(); err != nil {
+		return diff, err
+	}
+	var changed []uint
+	for _, policies := range [][]request.CasbinPolicy{diff.AddedPolicies, diff.RemovedPolicies} {
+		for _, policy := range policies {
+			if !slices.Contains(changed, policy.AuthorityId) {
+				changed = append(changed, policy.AuthorityId)
+			}
+		}
+	}
+	for _, id := range changed {
+		if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, id); err != nil {
+			return diff, err
+		}
+	}
+
+	domain := tenant.Domain(ctx)
+	file.Policies = tenantPolicies(domain, file.Policies)
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		for _, api := range diff.AddedApis {
+			if err := tx.Create(&system.SysApi{Path: api.Path, Method: api.Method, ApiGroup: api.ApiGroup, Description: api.Description}).Error; err != nil {
+				return err
+			}
+		}
+		for _, api := range diff.ChangedApis {
+			err := tx.Model(&system.SysApi{}).Where("path = ? AND method = ?", api.Path, api.Method).
+				Updates(map[string]interface{}{"api_group": api.ApiGroup, "description": api.Description}).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, api := range diff.RemovedApis {
+			if err := tx.Where("path = ? AND method = ?", api.Path, api.Method).Delete(&system.SysApi{}).Error; err != nil {
+				return err
+			}
+		}
+		if !prune {
+			var rules [][]string
+			for _, policy := range diff.AddedPolicies {
+				rules = append(rules, []string{strconv.Itoa(int(policy.AuthorityId)), policy.Path, policy.Method, domain})
+			}
+			return casbinService.AddPolicies(tx, rules)
+		}
+		for _, id := range changed {
+			authorityId := strconv.Itoa(int(id))
+			var rules [][]string
+			for _, policy := range file.Policies {
+				rule := []string{authorityId, policy.Path, policy.Method, domain}
+				if policy.AuthorityId == id && !slices.ContainsFunc(rules, func(r []string) bool { return slices.Equal(r, rule) }) {
+					rules = append(rules, rule)
+				}
+			}
+			if err := casbinService.SyncPolicy(tx, authorityId, domain, rules); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return diff, err
+	}
+	diff.Applied = true
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditPolicyImported,
+		OperatorID: operatorID,
+		Ip:         ip,
+		Detail: fmt.Sprintf("新增api%d 更新api%d 删除api%d 新增策略%d 删除策略%d", len(diff.AddedApis), len(diff.ChangedApis),
+			len(diff.RemovedApis), len(diff.AddedPolicies), len(diff.RemovedPolicies)),
+	})
+	return diff, casbinService.FreshCasbin()
+}
+
+// tenantPolicies 去除非默认租户不能授予的策略
+func tenantPolicies(domain string, policies []request.CasbinPolicy) []request.CasbinPolicy {
+	return slices.DeleteFunc(slices.Clone(policies), func(policy request.CasbinPolicy) bool {
+		return checkPlatformApi(domain, policy.Path) != nil
+	})
+}
+
+// livePolicies 租户域内的全部p策略 按角色、路径、方法排序
+func (casbinService *CasbinService) livePolicies(domain string) ([]request.CasbinPolicy, error) {
+	var rules []gormadapter.CasbinRule
+	if err := global.GVA_DB.Where("ptype = ? AND v3 = ?", "p", domain).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	policies := make([]request.CasbinPolicy, 0, len(rules))
+	for i := range rules {
+		id, err := strconv.ParseUint(rules[i].V0, 10, 64)
+		if err != nil {
+			continue
+		}
+		policies = append(policies, request.CasbinPolicy{AuthorityId: uint(id), Path: rules[i].V1, Method: rules[i].V2})
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].AuthorityId != policies[j].AuthorityId {
+			return policies[i].AuthorityId < policies[j].AuthorityId
+		}
+		if policies[i].Path != policies[j].Path {
+			return policies[i].Path < policies[j].Path
+		}
+		return policies[i].Method < policies[j].Method
+	})
+	return policies, nil
+}
@@ -0,0 +1,175 @@
+package system
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// setupTestPolicy 准备api及策略 角色888在默认租户可访问 /user/list 与 /user/delete
+func setupTestPolicy(t *testing.T) {
+	t.Helper()
+	setupTestDB(t, &system.SysApi{})
+	setupTestCasbin(t,
+		[]string{"888", "/user/list", "GET", "1"},
+		[]string{"888", "/user/delete", "DELETE", "1"},
+		[]string{"888", "/user/list", "GET", "2"},
+	)
+	createTestAuthority(t, 888, 0)
+	createTestAuthority(t, 9528, 0)
+	err := global.GVA_DB.Create(&[]system.SysApi{
+		{Path: "/user/list", Method: "GET", ApiGroup: "user"},
+		{Path: "/user/delete", Method: "DELETE", ApiGroup: "user"},
+	}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testPolicyFile() request.CasbinPolicyFile {
+	return request.CasbinPolicyFile{
+		Version:     request.CasbinPolicyFileVersion,
+		Authorities: []uint{888, 9528, 7},
+		Apis: []request.CasbinPolicyApi{
+			{Path: "/user/list", Method: "GET", ApiGroup: "用户"},
+			{Path: "/user/create", Method: "POST", ApiGroup: "user"},
+		},
+		Policies: []request.CasbinPolicy{
+			{AuthorityId: 888, Path: "/user/list", Method: "GET"},
+			{AuthorityId: 9528, Path: "/user/list", Method: "GET"},
+			{AuthorityId: 7, Path: "/user/list", Method: "GET"},
+		},
+	}
+}
+
+func countApis(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	global.GVA_DB.Model(&system.SysApi{}).Count(&count)
+	return count
+}
+
+func TestCasbinService_ImportPolicyDefault(t *testing.T) {
+	setupTestPolicy(t)
+	admin := createTestUser(t, "admin", 888)
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+
+	diff, err := CasbinServiceApp.ImportPolicy(ctx, 888, admin.ID, testPolicyFile(), true, false, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Applied || len(diff.AddedApis) != 1 || len(diff.ChangedApis) != 1 || len(diff.RemovedApis) != 0 ||
+		len(diff.AddedPolicies) != 1 || len(diff.RemovedPolicies) != 0 || !slices.Equal(diff.UnknownAuthorities, []uint{7}) {
+		t.Fatalf("ImportPolicy(dryRun) = %+v", diff)
+	}
+	if countApis(t) != 2 {
+		t.Fatal("dry run should not modify apis")
+	}
+
+	// 默认仅新增及更新 不删除文件中不存在的api及策略
+	if diff, err = CasbinServiceApp.ImportPolicy(ctx, 888, admin.ID, testPolicyFile(), false, false, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Applied || diff.ApisSkipped {
+		t.Errorf("ImportPolicy() = %+v, want applied with apis", diff)
+	}
+	if countApis(t) != 3 {
+		t.Errorf("apis = %d, want 3", countApis(t))
+	}
+	var api system.SysApi
+	global.GVA_DB.Where("path = ? AND method = ?", "/user/list", "GET").First(&api)
+	if api.ApiGroup != "用户" {
+		t.Errorf("changed api group = %q, want 用户", api.ApiGroup)
+	}
+	e := CasbinServiceApp.Casbin()
+	for _, sub := range []string{"888", "9528"} {
+		if ok, _ := e.Enforce(sub, "/user/list", "GET", "1"); !ok {
+			t.Errorf("%s should be allowed after import", sub)
+		}
+	}
+	if ok, _ := e.Enforce("888", "/user/delete", "DELETE", "1"); !ok {
+		t.Error("policies missing from the file should be kept without prune")
+	}
+}
+
+func TestCasbinService_ImportPolicyPrune(t *testing.T) {
+	setupTestPolicy(t)
+	admin := createTestUser(t, "admin", 888)
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+
+	diff, err := CasbinServiceApp.ImportPolicy(ctx, 888, admin.ID, testPolicyFile(), false, true, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.RemovedApis) != 1 || len(diff.RemovedPolicies) != 1 {
+		t.Errorf("ImportPolicy(prune) = %+v, want one removed api and policy", diff)
+	}
+	var api system.SysApi
+	if err = global.GVA_DB.Where("path = ?", "/user/delete").First(&api).Error; err == nil {
+		t.Error("prune should delete apis missing from the file")
+	}
+	e := CasbinServiceApp.Casbin()
+	if ok, _ := e.Enforce("888", "/user/delete", "DELETE", "1"); ok {
+		t.Error("prune should delete policies missing from the file")
+	}
+	if ok, _ := e.Enforce("888", "/user/list", "GET", "2"); !ok {
+		t.Error("import should not touch policies of other tenants")
+	}
+}
+
+func TestCasbinService_ImportPolicyTenant(t *testing.T) {
+	setupTestPolicy(t)
+	global.GVA_DB.Create(&system.SysTenant{Name: "租户2", Code: "t2", Enable: 1})
+	admin := createTestUser(t, "admin", 888)
+	ctx := tenant.WithTenant(context.Background(), 2)
+	if err := tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", admin.ID).Update("tenant_id", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 租户内导入即使文件中的api为空且开启prune 也不修改共享的api
+	file := testPolicyFile()
+	file.Apis = nil
+	diff, err := CasbinServiceApp.ImportPolicy(ctx, 888, admin.ID, file, false, true, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.ApisSkipped || len(diff.AddedApis)+len(diff.ChangedApis)+len(diff.RemovedApis) != 0 {
+		t.Errorf("ImportPolicy(tenant) = %+v, want apis skipped", diff)
+	}
+	if countApis(t) != 2 {
+		t.Errorf("apis = %d, want 2", countApis(t))
+	}
+	e := CasbinServiceApp.Casbin()
+	if ok, _ := e.Enforce("9528", "/user/list", "GET", "2"); !ok {
+		t.Error("tenant import should add policies to its own domain")
+	}
+	if ok, _ := e.Enforce("888", "/user/delete", "DELETE", "1"); !ok {
+		t.Error("tenant import should not touch the default tenant")
+	}
+}
+
+func TestUnmarshalPolicyFile(t *testing.T) {
+	file, err := UnmarshalPolicyFile("policy.yaml", []byte("version: 1\npolicies:\n  - authorityId: 888\n    path: /user/list\n    method: get\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Policies[0].Method != "GET" || !slices.Equal(file.Authorities, []uint{888}) {
+		t.Errorf("UnmarshalPolicyFile() = %+v", file)
+	}
+	invalid := map[string]string{
+		"policy.json":   `{"version": 2}`,
+		"broken.json":   `{`,
+		"missing.json":  `{"version": 1, "policies": [{"authorityId": 888, "path": "/user/list"}]}`,
+		"empty-api.yml": "version: 1\napis:\n  - path: /user/list\n",
+	}
+	for name, data := range invalid {
+		if _, err = UnmarshalPolicyFile(name, []byte(data)); err == nil {
+			t.Errorf("UnmarshalPolicyFile(%s) should fail", name)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package system
+
+import (
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/datascope"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DataScopeService 为数据权限回调提供角色的数据范围与部门成员
+type DataScopeService struct{}
+
+var DataScopeServiceApp = new(DataScopeService)
+
+// dataScopeRules 角色数据范围缓存 修改角色数据范围时清除
+var dataScopeRules sync.Map
+
+// Rule 获取角色的数据范围 角色不存在时按本人处理
+func (dataScopeService *DataScopeService) Rule(authorityID uint) (datascope.Rule, error) {
+	if rule, ok := dataScopeRules.Load(authorityID); ok {
+		return rule.(datascope.Rule), nil
+	}
+	var authority system.SysAuthority
+	err := global.GVA_DB.Select("authority_id", "data_scope", "data_scope_rule").Where("authority_id = ?", authorityID).First(&authority).Error
+	if err != nil {
+		return datascope.Rule{Scope: datascope.ScopeSelf}, nil
+	}
+	rule := datascope.Rule{Scope: authority.DataScope, Custom: authority.DataScopeRule}
+	dataScopeRules.Store(authorityID, rule)
+	return rule, nil
+}
+
+// DeptUserIDs 获取与用户同部门的用户ID 未划分部门时按本人处理
+func (dataScopeService *DataScopeService) DeptUserIDs(userID uint, tree bool) ([]uint, error) {
+	return DeptServiceApp.DeptUserIDs(userID, tree)
+}
+
+// RegisterDataScope 为数据库注册数据权限回调
+func RegisterDataScope(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if err := datascope.Register(db, DataScopeServiceApp); err != nil {
+		global.GVA_LOG.Error("注册数据权限回调失败!", zap.Error(err))
+	}
+}
@@ -0,0 +1,264 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"gorm.io/gorm"
+)
+
+type DeptService struct{}
+
+var DeptServiceApp = new(DeptService)
+
+var ErrDeptNotFound = errors.New("部门不存在")
+
+// CreateDept 创建部门
+func (deptService *DeptService) CreateDept(ctx context.Context, dept system.SysDept) error {
+	if err := deptService.checkDeptRef(ctx, dept.ParentId, dept.LeaderId); err != nil {
+		return err
+	}
+	dept.Leader = nil
+	dept.Children = nil
+	return global.GVA_DB.WithContext(ctx).Create(&dept).Error
+}
+
+// UpdateDept 更新部门名称、排序及负责人 调整上级部门需使用MoveDept
+func (deptService *DeptService) UpdateDept(ctx context.Context, dept system.SysDept) error {
+	if err := deptService.checkDeptRef(ctx, 0, dept.LeaderId); err != nil {
+		return err
+	}
+	result := global.GVA_DB.WithContext(ctx).Model(&system.SysDept{}).Where("id = ?", dept.ID).
+		Select("name", "sort", "leader_id").
+		Updates(map[string]interface{}{"name": dept.Name, "sort": dept.Sort, "leader_id": dept.LeaderId})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeptNotFound
+	}
+	return nil
+}
+
+// DeleteDept 删除部门 存在下级部门或成员时不允许删除
+func (deptService *DeptService) DeleteDept(ctx context.Context, id uint) error {
+	if !errors.Is(global.GVA_DB.WithContext(ctx).Where("parent_id = ?", id).First(&system.SysDept{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("此部门存在下级部门不允许删除")
+	}
+	if !errors.Is(global.GVA_DB.WithContext(ctx).Where("sys_dept_id = ?", id).First(&system.SysUserDept{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("此部门存在成员不允许删除")
+	}
+	result := global.GVA_DB.WithContext(ctx).Delete(&system.SysDept{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeptNotFound
+	}
+	return nil
+}
+
+// GetDeptTree 获取部门树
+func (deptService *DeptService) GetDeptTree(ctx context.Context) (tree []system.SysDept, err error) {
+	var list []system.SysDept
+	err = global.GVA_DB.WithContext(ctx).Preload("Leader", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "uuid", "username", "nick_name", "header_img")
+	}).Order("sort, id").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[uint][]system.SysDept)
+	for i := range list {
+		children[list[i].ParentId] = append(children[list[i].ParentId], list[i])
+	}
+	return buildDeptTree(children, 0), nil
+}
+
+func buildDeptTree(children map[uint][]system.SysDept, parentID uint) []system.SysDept {
+	depts := children[parentID]
+	for i := range depts {
+		depts[i].Children = buildDeptTree(children, depts[i].ID)
+	}
+	return depts
+}
+
+// MoveDept 调整部门的上级部门 不允许移动到自身或下级部门下
+func (deptService *DeptService) MoveDept(ctx context.Context, req systemReq.MoveDept) error {
+	var dept system.SysDept
+	if err := global.GVA_DB.WithContext(ctx).Where("id = ?", req.ID).First(&dept).Error; err != nil {
+		return ErrDeptNotFound
+	}
+	if req.ParentId != 0 {
+		if err := deptService.checkDeptRef(ctx, req.ParentId, 0); err != nil {
+			return err
+		}
+		ids, err := deptService.DescendantIDs(req.ID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(ids, req.ParentId) {
+			return errors.New("不能移动到自身或下级部门下")
+		}
+	}
+	return global.GVA_DB.WithContext(ctx).Model(&system.SysDept{}).Where("id = ?", req.ID).Update("parent_id", req.ParentId).Error
+}
+
+// MergeDept 将部门合并到另一部门 下级部门及成员迁移到目标部门后删除原部门 目标部门未设置负责人时沿用原部门负责人
+func (deptService *DeptService) MergeDept(ctx context.Context, req systemReq.MergeDept) error {
+	if req.SourceId == req.TargetId {
+		return errors.New("不能合并到自身")
+	}
+	var source, target system.SysDept
+	if err := global.GVA_DB.WithContext(ctx).Where("id = ?", req.SourceId).First(&source).Error; err != nil {
+		return ErrDeptNotFound
+	}
+	if err := global.GVA_DB.WithContext(ctx).Where("id = ?", req.TargetId).First(&target).Error; err != nil {
+		return ErrDeptNotFound
+	}
+	ids, err := deptService.DescendantIDs(req.SourceId)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(ids, req.TargetId) {
+		return errors.New("不能合并到下级部门")
+	}
+	return global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&system.SysDept{}).Where("parent_id = ?", source.ID).Update("parent_id", target.ID).Error; err != nil {
+			return err
+		}
+		var members []system.SysUserDept
+		if err := tx.Where("sys_dept_id = ?", source.ID).Find(&members).Error; err != nil {
+			return err
+		}
+		for i := range members {
+			var existing system.SysUserDept
+			err := tx.Where("sys_user_id = ? AND sys_dept_id = ?", members[i].SysUserId, target.ID).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = tx.Create(&system.SysUserDept{SysUserId: members[i].SysUserId, SysDeptId: target.ID, IsPrimary: members[i].IsPrimary}).Error
+			} else if err == nil && members[i].IsPrimary && !existing.IsPrimary {
+				err = tx.Model(&system.SysUserDept{}).Where("sys_user_id = ? AND sys_dept_id = ?", members[i].SysUserId, target.ID).Update("is_primary", true).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&system.SysUserDept{}, "sys_dept_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		if target.LeaderId == 0 && source.LeaderId != 0 {
+			if err := tx.Model(&system.SysDept{}).Where("id = ?", target.ID).Update("leader_id", source.LeaderId).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&system.SysDept{}, "id = ?", source.ID).Error
+	})
+}
+
+// SetUserDepts 设置用户的主部门及次部门 覆盖用户原有的全部部门
+func (deptService *DeptService) SetUserDepts(ctx context.Context, req systemReq.SetUserDepts) error {
+	if errors.Is(global.GVA_DB.WithContext(ctx).Where("id = ?", req.ID).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("用户不存在")
+	}
+	members := make([]system.SysUserDept, 0, len(req.DeptIds)+1)
+	deptIDs := make([]uint, 0, len(req.DeptIds)+1)
+	if req.PrimaryDeptId != 0 {
+		members = append(members, system.SysUserDept{SysUserId: req.ID, SysDeptId: req.PrimaryDeptId, IsPrimary: true})
+		deptIDs = append(deptIDs, req.PrimaryDeptId)
+	}
+	for _, id := range req.DeptIds {
+		if id == 0 || slices.Contains(deptIDs, id) {
+			continue
+		}
+		members = append(members, system.SysUserDept{SysUserId: req.ID, SysDeptId: id})
+		deptIDs = append(deptIDs, id)
+	}
+	if len(deptIDs) > 0 {
+		var count int64
+		if err := global.GVA_DB.WithContext(ctx).Model(&system.SysDept{}).Where("id IN ?", deptIDs).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(deptIDs) {
+			return ErrDeptNotFound
+		}
+	}
+	return global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&system.SysUserDept{}, "sys_user_id = ?", req.ID).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+// GetUserDepts 获取用户所属的部门 主部门在前
+func (deptService *DeptService) GetUserDepts(ctx context.Context, userID uint) (list []system.SysUserDept, err error) {
+	err = global.GVA_DB.WithContext(ctx).Preload("Dept").Where("sys_user_id = ?", userID).Order("is_primary desc, sys_dept_id").Find(&list).Error
+	return list, err
+}
+
+// DescendantIDs 获取部门及其全部下级部门的ID 从给定部门向下查找 结果不会超出其所在租户
+func (deptService *DeptService) DescendantIDs(ids ...uint) ([]uint, error) {
+	var list []system.SysDept
+	if err := tenant.Skip(global.GVA_DB).Select("id", "parent_id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	children := make(map[uint][]uint)
+	for i := range list {
+		children[list[i].ParentId] = append(children[list[i].ParentId], list[i].ID)
+	}
+	result := make([]uint, 0, len(ids))
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+		if slices.Contains(result, id) {
+			continue
+		}
+		result = append(result, id)
+		ids = append(ids, children[id]...)
+	}
+	return result, nil
+}
+
+// DeptUserIDs 获取与用户同部门的用户ID tree为true时包含下级部门的用户 结果包含用户本人
+func (deptService *DeptService) DeptUserIDs(userID uint, tree bool) ([]uint, error) {
+	var deptIDs []uint
+	if err := global.GVA_DB.Model(&system.SysUserDept{}).Where("sys_user_id = ?", userID).Pluck("sys_dept_id", &deptIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(deptIDs) == 0 {
+		return []uint{userID}, nil
+	}
+	if tree {
+		var err error
+		if deptIDs, err = deptService.DescendantIDs(deptIDs...); err != nil {
+			return nil, err
+		}
+	}
+	var userIDs []uint
+	err := global.GVA_DB.Model(&system.SysUserDept{}).Distinct("sys_user_id").Where("sys_dept_id IN ?", deptIDs).Pluck("sys_user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(userIDs, userID) {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+// checkDeptRef 校验上级部门及负责人存在 为0时不校验
+func (deptService *DeptService) checkDeptRef(ctx context.Context, parentID, leaderID uint) error {
+	if parentID != 0 && errors.Is(global.GVA_DB.WithContext(ctx).Where("id = ?", parentID).First(&system.SysDept{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("上级部门不存在")
+	}
+	if leaderID != 0 && errors.Is(global.GVA_DB.WithContext(ctx).Where("id = ?", leaderID).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
+		return errors.New("部门负责人不存在")
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package system
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// createTestDepts 创建部门 总部(1) 下设 研发(2) 与 市场(4) 研发下设 后端(3)
+func createTestDepts(t *testing.T) context.Context {
+	t.Helper()
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	depts := []system.SysDept{
+		{Name: "总部"},
+		{Name: "研发", ParentId: 1, Sort: 2},
+		{Name: "后端", ParentId: 2},
+		{Name: "市场", ParentId: 1, Sort: 1},
+	}
+	for i := range depts {
+		if err := DeptServiceApp.CreateDept(ctx, depts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx
+}
+
+func TestDeptService_GetDeptTree(t *testing.T) {
+	setupTestDB(t, &system.SysDept{}, &system.SysUserDept{})
+	ctx := createTestDepts(t)
+	if err := DeptServiceApp.CreateDept(ctx, system.SysDept{Name: "x", ParentId: 99}); err == nil {
+		t.Error("CreateDept() should reject unknown parent")
+	}
+
+	tree, err := DeptServiceApp.GetDeptTree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 1 || tree[0].Name != "总部" {
+		t.Fatalf("GetDeptTree() roots = %+v, want 总部", tree)
+	}
+	children := tree[0].Children
+	if len(children) != 2 || children[0].Name != "市场" || children[1].Name != "研发" {
+		t.Fatalf("GetDeptTree() children = %+v, want 市场 研发 ordered by sort", children)
+	}
+	if len(children[1].Children) != 1 || children[1].Children[0].Name != "后端" {
+		t.Errorf("GetDeptTree() grandchildren = %+v, want 后端", children[1].Children)
+	}
+
+	// 其他租户看不到本租户的部门
+	tree, err = DeptServiceApp.GetDeptTree(tenant.WithTenant(context.Background(), 2))
+	if err != nil || len(tree) != 0 {
+		t.Errorf("GetDeptTree() other tenant = %+v, %v", tree, err)
+	}
+}
+
+func TestDeptService_MoveDept(t *testing.T) {
+	setupTestDB(t, &system.SysDept{}, &system.SysUserDept{})
+	ctx := createTestDepts(t)
+	for _, parent := range []uint{2, 3} {
+		if err := DeptServiceApp.MoveDept(ctx, systemReq.MoveDept{ID: 2, ParentId: parent}); err == nil {
+			t.Errorf("MoveDept() under %d should be rejected", parent)
+		}
+	}
+	if err := DeptServiceApp.MoveDept(ctx, systemReq.MoveDept{ID: 3, ParentId: 4}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := DeptServiceApp.DescendantIDs(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, []uint{4, 3}) {
+		t.Errorf("DescendantIDs(4) = %v, want [4 3]", ids)
+	}
+	if err = DeptServiceApp.MoveDept(ctx, systemReq.MoveDept{ID: 2, ParentId: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err = DeptServiceApp.DeleteDept(ctx, 1); err == nil {
+		t.Error("DeleteDept() should reject a department with children")
+	}
+}
+
+func TestDeptService_MergeDept(t *testing.T) {
+	setupTestDB(t, &system.SysDept{}, &system.SysUserDept{})
+	ctx := createTestDepts(t)
+	alice := createTestUser(t, "alice", 888)
+	bob := createTestUser(t, "bob", 888)
+	tenant.Skip(global.GVA_DB).Model(&system.SysDept{}).Where("id = ?", 2).Update("leader_id", alice.ID)
+	if err := DeptServiceApp.SetUserDepts(ctx, systemReq.SetUserDepts{ID: alice.ID, PrimaryDeptId: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeptServiceApp.SetUserDepts(ctx, systemReq.SetUserDepts{ID: bob.ID, PrimaryDeptId: 4, DeptIds: []uint{2, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeptServiceApp.DeleteDept(ctx, 4); err == nil {
+		t.Error("DeleteDept() should reject a department with members")
+	}
+	if err := DeptServiceApp.MergeDept(ctx, systemReq.MergeDept{SourceId: 1, TargetId: 3}); err == nil {
+		t.Error("MergeDept() into a descendant should be rejected")
+	}
+
+	if err := DeptServiceApp.MergeDept(ctx, systemReq.MergeDept{SourceId: 2, TargetId: 4}); err != nil {
+		t.Fatal(err)
+	}
+	var backend, market system.SysDept
+	tenant.Skip(global.GVA_DB).First(&backend, 3)
+	tenant.Skip(global.GVA_DB).First(&market, 4)
+	if backend.ParentId != 4 || market.LeaderId != alice.ID {
+		t.Errorf("MergeDept() backend parent = %d leader = %d, want 4 and %d", backend.ParentId, market.LeaderId, alice.ID)
+	}
+	list, err := DeptServiceApp.GetUserDepts(ctx, alice.ID)
+	if err != nil || len(list) != 1 || list[0].SysDeptId != 4 || !list[0].IsPrimary {
+		t.Errorf("GetUserDepts(alice) = %+v, %v, want primary 4", list, err)
+	}
+	if list, _ = DeptServiceApp.GetUserDepts(ctx, bob.ID); len(list) != 1 || !list[0].IsPrimary {
+		t.Errorf("GetUserDepts(bob) = %+v, want single primary 4", list)
+	}
+	var count int64
+	tenant.Skip(global.GVA_DB).Model(&system.SysDept{}).Where("id = ?", 2).Count(&count)
+	if count != 0 {
+		t.Error("merged department should be deleted")
+	}
+}
+
+func TestDeptService_DeptUserIDs(t *testing.T) {
+	setupTestDB(t, &system.SysDept{}, &system.SysUserDept{})
+	ctx := createTestDepts(t)
+	alice := createTestUser(t, "alice", 888)
+	bob := createTestUser(t, "bob", 888)
+	carol := createTestUser(t, "carol", 888)
+	dave := createTestUser(t, "dave", 888)
+	members := map[uint]uint{alice.ID: 2, bob.ID: 2, carol.ID: 3, dave.ID: 4}
+	for userID, deptID := range members {
+		if err := DeptServiceApp.SetUserDepts(ctx, systemReq.SetUserDepts{ID: userID, PrimaryDeptId: deptID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := DeptServiceApp.SetUserDepts(ctx, systemReq.SetUserDepts{ID: dave.ID, DeptIds: []uint{99}}); err == nil {
+		t.Error("SetUserDepts() should reject unknown department")
+	}
+	ghost := createTestUser(t, "ghost", 888)
+
+	tests := []struct {
+		name   string
+		userID uint
+		tree   bool
+		want   []uint
+	}{
+		{"dept", alice.ID, false, []uint{alice.ID, bob.ID}},
+		{"dept tree", alice.ID, true, []uint{alice.ID, bob.ID, carol.ID}},
+		{"leaf", carol.ID, true, []uint{carol.ID}},
+		{"no dept", ghost.ID, true, []uint{ghost.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeptServiceApp.DeptUserIDs(tt.userID, tt.tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DeptUserIDs(%d, %v) = %v, want %v", tt.userID, tt.tree, got, tt.want)
+			}
+		})
+	}
+}
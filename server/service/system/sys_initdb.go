@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 	"gorm.io/gorm"
 	"sort"
 )
@@ -122,6 +123,10 @@ func (initDBService *InitDBService) InitDB(conf request.InitDB) (err error) {
 
 	db := ctx.Value("db").(*gorm.DB)
 	global.GVA_DB = db
+	RegisterDataScope(db)
+	RegisterTenant(db)
+	// 初始化数据归属默认租户
+	ctx = context.WithValue(ctx, "db", db.WithContext(tenant.WithTenant(ctx, tenant.Default)))
 
 	if err = initHandler.InitTables(ctx, initializers); err != nil {
 		return err
@@ -135,6 +140,7 @@ func (initDBService *InitDBService) InitDB(conf request.InitDB) (err error) {
 	}
 	initializers = initSlice{}
 	cache = map[string]*orderedInitializer{}
+	LoadJwtKeys()
 	return nil
 }
 
@@ -0,0 +1,155 @@
+package system
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JwtKeyService struct{}
+
+var JwtKeyServiceApp = new(JwtKeyService)
+
+// jwtAsymmetric 是否配置了非对称签名算法
+func jwtAsymmetric() bool {
+	switch global.GVA_CONFIG.JWT.Algorithm {
+	case utils.JWTAlgRS256, utils.JWTAlgES256, utils.JWTAlgEdDSA:
+		return true
+	}
+	return false
+}
+
+// LoadJwtKeys 启动时加载jwt签名密钥 尚无当前算法的签发密钥时自动生成
+func LoadJwtKeys() {
+	if !jwtAsymmetric() {
+		return
+	}
+	if err := utils.SetJWTKeyLoader(JwtKeyServiceApp.loadKeys); err != nil {
+		global.GVA_LOG.Error("加载jwt签名密钥失败!", zap.Error(err))
+		return
+	}
+	if err := JwtKeyServiceApp.RotateIfDue(); err != nil {
+		global.GVA_LOG.Error("生成jwt签名密钥失败!", zap.Error(err))
+	}
+}
+
+// RotateKey 生成新的签发密钥 原签发密钥停止签发 在宽限期内仍可验签
+func (jwtKeyService *JwtKeyService) RotateKey(operatorID uint, ip string) (key system.SysJwtKey, err error) {
+	if !jwtAsymmetric() {
+		return key, errors.New("当前jwt签名算法不支持密钥轮换")
+	}
+	alg := global.GVA_CONFIG.JWT.Algorithm
+	b := make([]byte, 8)
+	if _, err = rand.Read(b); err != nil {
+		return key, err
+	}
+	kid := time.Now().Format("20060102") + "-" + hex.EncodeToString(b)
+	generated, err := utils.GenerateJWTKey(kid, alg)
+	if err != nil {
+		return key, err
+	}
+	privatePEM, publicPEM, err := utils.MarshalJWTKey(generated)
+	if err != nil {
+		return key, err
+	}
+	key = system.SysJwtKey{Kid: kid, Algorithm: alg, PrivateKey: privatePEM, PublicKey: publicPEM}
+	now := time.Now()
+	expiresAt := now.Add(jwtKeyGracePeriod())
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定当前签发密钥 多实例同时轮换时串行执行
+		var active []system.SysJwtKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("retired_at IS NULL").Find(&active).Error; err != nil {
+			return err
+		}
+		if len(active) > 0 {
+			err := tx.Model(&system.SysJwtKey{}).Where("retired_at IS NULL").
+				Updates(map[string]interface{}{"retired_at": now, "expires_at": expiresAt}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(&key).Error
+	})
+	if err != nil {
+		return key, err
+	}
+	if err = utils.ReloadJWTKeys(); err != nil {
+		global.GVA_LOG.Error("加载jwt签名密钥失败!", zap.Error(err))
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditJwtKeyRotated,
+		OperatorID: operatorID,
+		Ip:         ip,
+		Detail:     alg + " " + kid,
+	})
+	return key, nil
+}
+
+// RotateIfDue 定时任务 签发密钥超过轮换周期或算法变更时轮换 并清理宽限期已过的旧密钥
+func (jwtKeyService *JwtKeyService) RotateIfDue() error {
+	if !jwtAsymmetric() {
+		return nil
+	}
+	err := global.GVA_DB.Unscoped().Where("expires_at < ?", time.Now()).Delete(&system.SysJwtKey{}).Error
+	if err != nil {
+		return err
+	}
+	var active system.SysJwtKey
+	err = global.GVA_DB.Where("retired_at IS NULL").Order("id desc").First(&active).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	due := err != nil || active.Algorithm != global.GVA_CONFIG.JWT.Algorithm
+	if rotation, _ := utils.ParseDuration(global.GVA_CONFIG.JWT.KeyRotation); rotation > 0 && time.Since(active.CreatedAt) > rotation {
+		due = true
+	}
+	if due {
+		_, err = jwtKeyService.RotateKey(0, "")
+		return err
+	}
+	// 同步其他实例轮换出的密钥
+	return utils.ReloadJWTKeys()
+}
+
+// GetJwtKeyList 获取当前可用于验签的密钥
+func (jwtKeyService *JwtKeyService) GetJwtKeyList() (list []system.SysJwtKey, err error) {
+	err = global.GVA_DB.Where("expires_at IS NULL OR expires_at > ?", time.Now()).Order("id desc").Find(&list).Error
+	return list, err
+}
+
+func (jwtKeyService *JwtKeyService) loadKeys() (signing *utils.JWTKey, keys []*utils.JWTKey, err error) {
+	list, err := jwtKeyService.GetJwtKeyList()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, item := range list {
+		key, err := utils.ParseJWTKey(item.Kid, item.Algorithm, item.PrivateKey, item.PublicKey)
+		if err != nil {
+			global.GVA_LOG.Error("解析jwt签名密钥失败!", zap.String("kid", item.Kid), zap.Error(err))
+			continue
+		}
+		if signing == nil && item.RetiredAt == nil && item.Algorithm == global.GVA_CONFIG.JWT.Algorithm {
+			signing = key
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return signing, keys, nil
+}
+
+// jwtKeyGracePeriod 旧密钥宽限期 至少覆盖访问令牌有效期 保证已签发的令牌在过期前可验签
+func jwtKeyGracePeriod() time.Duration {
+	grace, _ := utils.ParseDuration(global.GVA_CONFIG.JWT.KeyGracePeriod)
+	if ep, _ := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime); grace < ep {
+		grace = ep
+	}
+	return grace
+}
@@ -0,0 +1,226 @@
+package system
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const ldapProvider = "ldap"
+
+func init() {
+	RegisterAuthenticator(LdapAuthenticator{})
+}
+
+// LdapAuthenticator LDAP/AD 绑定认证
+type LdapAuthenticator struct{}
+
+// Authenticate 使用用户名密码绑定LDAP 成功后读取用户条目映射为系统用户
+func (LdapAuthenticator) Authenticate(username string, password string) (*system.SysUser, error) {
+	conf := global.GVA_CONFIG.LDAP
+	if !conf.Enable {
+		return nil, ErrAuthenticatorSkip
+	}
+	// 空密码会被当作匿名绑定 必须拒绝
+	if username == "" || password == "" {
+		return nil, errors.New("用户名或密码为空")
+	}
+	conn, err := ldapDial(conf)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	dn, err := ldapBind(conn, conf, username, password)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := ldapUserEntry(conn, conf, username, dn)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := ldapUserGroups(conn, conf, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := ExternalUser{
+		Provider:           ldapProvider,
+		Subject:            ldapSubject(conf, entry),
+		Username:           username,
+		DefaultAuthorityId: conf.DefaultAuthorityId,
+		AutoProvision:      conf.AutoProvision,
+		SyncAuthorities:    len(conf.GroupMapping) > 0,
+	}
+	if conf.NicknameAttr != "" {
+		ext.NickName = entry.GetAttributeValue(conf.NicknameAttr)
+	}
+	if conf.EmailAttr != "" {
+		ext.Email = entry.GetAttributeValue(conf.EmailAttr)
+	}
+	if conf.PhoneAttr != "" {
+		ext.Phone = entry.GetAttributeValue(conf.PhoneAttr)
+	}
+	for _, m := range conf.GroupMapping {
+		for _, g := range groups {
+			if ldapGroupMatch(m.Group, g) {
+				ext.AuthorityIds = append(ext.AuthorityIds, m.AuthorityId)
+				break
+			}
+		}
+	}
+	return UserServiceApp.LoginByExternal(ext)
+}
+
+func ldapDial(conf config.LDAP) (*ldap.Conn, error) {
+	timeout := time.Duration(conf.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: conf.InsecureSkipVerify}
+	if u, err := url.Parse(conf.Url); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+	conn, err := ldap.DialURL(conf.Url, ldap.DialWithDialer(&net.Dialer{Timeout: timeout}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+	if conf.StartTLS {
+		if err = conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// ldapBind 按DN模板依次尝试绑定 未配置模板时使用查询账号查找用户DN后绑定 返回用户DN
+func ldapBind(conn *ldap.Conn, conf config.LDAP, username string, password string) (string, error) {
+	if len(conf.UserDNTemplates) > 0 {
+		var err error
+		for _, tpl := range conf.UserDNTemplates {
+			dn := fmt.Sprintf(tpl, ldap.EscapeDN(username))
+			if err = conn.Bind(dn, password); err == nil {
+				return dn, nil
+			}
+		}
+		return "", err
+	}
+	if conf.BindDN == "" || conf.BaseDN == "" {
+		return "", errors.New("未配置LDAP用户DN模板或查询账号")
+	}
+	if err := conn.Bind(conf.BindDN, conf.BindPassword); err != nil {
+		return "", err
+	}
+	entry, err := ldapSearchUser(conn, conf, username)
+	if err != nil {
+		return "", err
+	}
+	if err = conn.Bind(entry.DN, password); err != nil {
+		return "", err
+	}
+	return entry.DN, nil
+}
+
+// ldapUserEntry 读取用户条目 配置了 base-dn 时按过滤条件查询 否则直接读取绑定DN
+func ldapUserEntry(conn *ldap.Conn, conf config.LDAP, username string, dn string) (*ldap.Entry, error) {
+	if conf.BaseDN != "" {
+		return ldapSearchUser(conn, conf, username)
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", ldapAttributes(conf), nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, errors.New("未找到LDAP用户条目")
+	}
+	return result.Entries[0], nil
+}
+
+func ldapSearchUser(conn *ldap.Conn, conf config.LDAP, username string) (*ldap.Entry, error) {
+	filter := conf.UserFilter
+	if filter == "" {
+		filter = "(uid=%s)"
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(conf.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(filter, ldap.EscapeFilter(username)), ldapAttributes(conf), nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, errors.New("未找到LDAP用户或用户不唯一")
+	}
+	return result.Entries[0], nil
+}
+
+// ldapUserGroups 获取用户所属组的DN 优先读取用户条目上的组属性 配置了组查询时合并查询结果
+func ldapUserGroups(conn *ldap.Conn, conf config.LDAP, entry *ldap.Entry) ([]string, error) {
+	var groups []string
+	if conf.GroupAttr != "" {
+		groups = append(groups, entry.GetAttributeValues(conf.GroupAttr)...)
+	}
+	if conf.GroupBaseDN == "" || conf.GroupFilter == "" {
+		return groups, nil
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(conf.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(conf.GroupFilter, ldap.EscapeFilter(entry.DN)), []string{"dn"}, nil))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range result.Entries {
+		groups = append(groups, e.DN)
+	}
+	return groups, nil
+}
+
+func ldapAttributes(conf config.LDAP) []string {
+	var attrs []string
+	for _, a := range []string{conf.SubjectAttr, conf.NicknameAttr, conf.EmailAttr, conf.PhoneAttr, conf.GroupAttr} {
+		if a != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// ldapSubject 用户唯一标识 二进制属性(如 objectGUID)转为十六进制 未配置时使用小写DN
+func ldapSubject(conf config.LDAP, entry *ldap.Entry) string {
+	if conf.SubjectAttr != "" {
+		if raw := entry.GetRawAttributeValue(conf.SubjectAttr); len(raw) > 0 {
+			if strings.EqualFold(conf.SubjectAttr, "objectGUID") || strings.EqualFold(conf.SubjectAttr, "objectSid") {
+				return hex.EncodeToString(raw)
+			}
+			return string(raw)
+		}
+	}
+	return strings.ToLower(entry.DN)
+}
+
+// ldapGroupMatch 组映射可填写完整DN或CN 不区分大小写
+func ldapGroupMatch(mapping string, groupDN string) bool {
+	if strings.EqualFold(mapping, groupDN) {
+		return true
+	}
+	dn, err := ldap.ParseDN(groupDN)
+	if err != nil || len(dn.RDNs) == 0 {
+		return false
+	}
+	for _, attr := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") && strings.EqualFold(attr.Value, mapping) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package system
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestLdapAuthenticator_Authenticate(t *testing.T) {
+	old := global.GVA_CONFIG.LDAP
+	defer func() { global.GVA_CONFIG.LDAP = old }()
+
+	global.GVA_CONFIG.LDAP = config.LDAP{}
+	if _, err := (LdapAuthenticator{}).Authenticate("alice", "secret"); !errors.Is(err, ErrAuthenticatorSkip) {
+		t.Errorf("Authenticate() disabled error = %v, want ErrAuthenticatorSkip", err)
+	}
+	// 空密码会被LDAP当作匿名绑定 必须在连接前拒绝
+	global.GVA_CONFIG.LDAP = config.LDAP{Enable: true, Url: "ldap://127.0.0.1:1"}
+	if _, err := (LdapAuthenticator{}).Authenticate("alice", ""); err == nil || errors.Is(err, ErrAuthenticatorSkip) {
+		t.Errorf("Authenticate() empty password error = %v", err)
+	}
+}
+
+func TestLdapGroupMatch(t *testing.T) {
+	tests := []struct {
+		mapping string
+		group   string
+		want    bool
+	}{
+		{"cn=admins,ou=groups,dc=example,dc=com", "CN=Admins,OU=Groups,DC=example,DC=com", true},
+		{"admins", "cn=Admins,ou=groups,dc=example,dc=com", true},
+		{"groups", "cn=admins,ou=groups,dc=example,dc=com", false},
+		{"admins", "ou=admins,dc=example,dc=com", false},
+		{"admins", "not a dn", false},
+	}
+	for _, tt := range tests {
+		if got := ldapGroupMatch(tt.mapping, tt.group); got != tt.want {
+			t.Errorf("ldapGroupMatch(%q, %q) = %v, want %v", tt.mapping, tt.group, got, tt.want)
+		}
+	}
+}
+
+func TestLdapSubject(t *testing.T) {
+	entry := ldap.NewEntry("CN=Alice,OU=Users,DC=example,DC=com", map[string][]string{
+		"objectGUID": {string([]byte{0x01, 0xab})},
+		"uid":        {"alice"},
+	})
+	tests := []struct {
+		attr string
+		want string
+	}{
+		{"", "cn=alice,ou=users,dc=example,dc=com"},
+		{"uid", "alice"},
+		{"objectGUID", "01ab"},
+		{"mail", "cn=alice,ou=users,dc=example,dc=com"},
+	}
+	for _, tt := range tests {
+		if got := ldapSubject(config.LDAP{SubjectAttr: tt.attr}, entry); got != tt.want {
+			t.Errorf("ldapSubject(%q) = %q, want %q", tt.attr, got, tt.want)
+		}
+	}
+}
+
+func TestLdapAttributes(t *testing.T) {
+	got := ldapAttributes(config.LDAP{SubjectAttr: "objectGUID", EmailAttr: "mail", GroupAttr: "memberOf"})
+	want := []string{"objectGUID", "mail", "memberOf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ldapAttributes() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,280 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	lockoutFailedPrefix  = "login_failed:"
+	lockoutLockedPrefix  = "login_locked:"
+	lockoutUnknownPrefix = "login_unknown:"
+)
+
+type LockoutService struct{}
+
+var unknownLockoutMu sync.Mutex
+
+var LockoutServiceApp = new(LockoutService)
+
+// CheckLocked 判断用户名是否处于锁定中 开启redis时优先读取redis中的锁定状态 多实例共享 未命中时以数据库为准
+func (lockoutService *LockoutService) CheckLocked(username string) (lockedUntil time.Time, locked bool) {
+	if global.GVA_CONFIG.Lockout.MaxAttempts <= 0 || username == "" {
+		return time.Time{}, false
+	}
+	if global.GVA_REDIS != nil {
+		ttl, err := global.GVA_REDIS.PTTL(context.Background(), lockoutLockedPrefix+username).Result()
+		if err != nil {
+			global.GVA_LOG.Error("读取账号锁定状态失败!", zap.Error(err))
+		} else if ttl > 0 {
+			return time.Now().Add(ttl), true
+		}
+	}
+	if v, ok := global.BlackCache.Get(lockoutUnknownPrefix + username); ok {
+		if until := v.(system.SysLoginLockout).LockedUntil; until != nil && until.After(time.Now()) {
+			return *until, true
+		}
+	}
+	var lockout system.SysLoginLockout
+	err := global.GVA_DB.Where("username = ?", username).First(&lockout).Error
+	if err != nil || lockout.LockedUntil == nil || !lockout.LockedUntil.After(time.Now()) {
+		return time.Time{}, false
+	}
+	return *lockout.LockedUntil, true
+}
+
+// RecordFailure 记录一次登录失败 达到阈值时按指数退避锁定账号
+// 不存在的用户名同样计数锁定 避免据此探测账号 但其计数只保存在缓存中 避免任意用户名的尝试写满数据库
+func (lockoutService *LockoutService) RecordFailure(username string, ip string) (lockedUntil time.Time, locked bool) {
+	conf := global.GVA_CONFIG.Lockout
+	if conf.MaxAttempts <= 0 || username == "" {
+		return time.Time{}, false
+	}
+	window := time.Duration(conf.Window) * time.Second
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	now := time.Now()
+	userID := lockoutService.userID(username)
+	var lockout system.SysLoginLockout
+	var err error
+	if userID == 0 {
+		lockedUntil, locked = lockoutService.recordUnknownFailure(&lockout, username, now, window)
+	} else {
+		err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).First(&lockout).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				lockout = system.SysLoginLockout{Username: username}
+				// 并发首次失败时以唯一索引兜底
+				if err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&lockout).Error; err != nil {
+					return err
+				}
+				err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).First(&lockout).Error
+			}
+			if err != nil {
+				return err
+			}
+			lockedUntil, locked = lockoutService.countFailure(&lockout, now, window)
+			return tx.Save(&lockout).Error
+		})
+	}
+	if err != nil {
+		global.GVA_LOG.Error("记录登录失败次数失败!", zap.String("username", username), zap.Error(err))
+		return time.Time{}, false
+	}
+
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:   system.AuditLoginFailed,
+		UserID:   userID,
+		Username: username,
+		Ip:       ip,
+		Detail:   fmt.Sprintf("连续失败%d次", lockout.FailedCount),
+	})
+	if !locked {
+		return time.Time{}, false
+	}
+	if global.GVA_REDIS != nil {
+		ctx := context.Background()
+		if err = global.GVA_REDIS.Set(ctx, lockoutLockedPrefix+username, lockedUntil.Unix(), time.Until(lockedUntil)).Err(); err != nil {
+			global.GVA_LOG.Error("同步账号锁定状态失败!", zap.Error(err))
+		}
+		global.GVA_REDIS.Del(ctx, lockoutFailedPrefix+username)
+	}
+	global.GVA_LOG.Warn("连续登录失败，账号已锁定", zap.String("username", username), zap.String("ip", ip), zap.Time("lockedUntil", lockedUntil))
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:   system.AuditAccountLocked,
+		UserID:   userID,
+		Username: username,
+		Ip:       ip,
+		Detail:   fmt.Sprintf("第%d次锁定，锁定至%s", lockout.LockCount, lockedUntil.Format(time.DateTime)),
+	})
+	return lockedUntil, true
+}
+
+// recordUnknownFailure 在缓存中记录不存在的用户名的登录失败 保留至最长锁定时长 之后退避等级随之清零
+func (lockoutService *LockoutService) recordUnknownFailure(lockout *system.SysLoginLockout, username string, now time.Time, window time.Duration) (lockedUntil time.Time, locked bool) {
+	conf := global.GVA_CONFIG.Lockout
+	unknownLockoutMu.Lock()
+	defer unknownLockoutMu.Unlock()
+	*lockout = system.SysLoginLockout{Username: username}
+	if v, ok := global.BlackCache.Get(lockoutUnknownPrefix + username); ok {
+		*lockout = v.(system.SysLoginLockout)
+	}
+	lockedUntil, locked = lockoutService.countFailure(lockout, now, window)
+	ttl := window + lockoutDuration(conf.LockDuration, conf.MaxLockDuration, math.MaxInt)
+	global.BlackCache.Set(lockoutUnknownPrefix+username, *lockout, ttl)
+	return lockedUntil, locked
+}
+
+// countFailure 累加失败次数 达到阈值时设置锁定时间并提升退避等级
+func (lockoutService *LockoutService) countFailure(lockout *system.SysLoginLockout, now time.Time, window time.Duration) (lockedUntil time.Time, locked bool) {
+	conf := global.GVA_CONFIG.Lockout
+	if lockout.LastFailedAt == nil || now.Sub(*lockout.LastFailedAt) > window {
+		lockout.FailedCount = 0
+	}
+	lockout.FailedCount++
+	if global.GVA_REDIS != nil {
+		// 多实例时以redis计数为准 保证并发失败不丢失
+		if count, err := lockoutService.incrFailed(lockout.Username, window); err == nil {
+			lockout.FailedCount = count
+		} else {
+			global.GVA_LOG.Error("记录登录失败次数失败!", zap.Error(err))
+		}
+	}
+	lockout.LastFailedAt = &now
+	if lockout.FailedCount < conf.MaxAttempts {
+		return time.Time{}, false
+	}
+	until := now.Add(lockoutDuration(conf.LockDuration, conf.MaxLockDuration, lockout.LockCount))
+	lockout.LockedUntil = &until
+	lockout.LockCount++
+	lockout.FailedCount = 0
+	return until, true
+}
+
+// RecordBlocked 记录锁定期间的登录尝试
+func (lockoutService *LockoutService) RecordBlocked(username string, ip string, lockedUntil time.Time) {
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:   system.AuditLoginBlocked,
+		UserID:   lockoutService.userID(username),
+		Username: username,
+		Ip:       ip,
+		Detail:   fmt.Sprintf("账号锁定至%s", lockedUntil.Format(time.DateTime)),
+	})
+}
+
+// RecordSuccess 登录成功后清空失败次数与退避等级
+func (lockoutService *LockoutService) RecordSuccess(username string) {
+	if global.GVA_CONFIG.Lockout.MaxAttempts <= 0 || username == "" {
+		return
+	}
+	if global.GVA_REDIS != nil {
+		global.GVA_REDIS.Del(context.Background(), lockoutFailedPrefix+username)
+	}
+	err := global.GVA_DB.Model(&system.SysLoginLockout{}).
+		Where("username = ? AND (failed_count > 0 OR lock_count > 0)", username).
+		Updates(map[string]interface{}{"failed_count": 0, "lock_count": 0}).Error
+	if err != nil {
+		global.GVA_LOG.Error("清空登录失败次数失败!", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// Unlock 管理员解锁当前租户的账号
+func (lockoutService *LockoutService) Unlock(ctx context.Context, userID uint, operatorID uint, ip string) error {
+	user, err := UserServiceApp.tenantUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = global.GVA_DB.Model(&system.SysLoginLockout{}).Where("username = ?", user.Username).
+		Updates(map[string]interface{}{"failed_count": 0, "lock_count": 0, "locked_until": nil}).Error
+	if err != nil {
+		return err
+	}
+	if global.GVA_REDIS != nil {
+		err = global.GVA_REDIS.Del(context.Background(), lockoutLockedPrefix+user.Username, lockoutFailedPrefix+user.Username).Err()
+		if err != nil {
+			return err
+		}
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditAccountUnlocked,
+		UserID:     user.ID,
+		Username:   user.Username,
+		OperatorID: operatorID,
+		Ip:         ip,
+		Detail:     "管理员解锁",
+	})
+	return nil
+}
+
+// GetLockoutList 分页获取当前租户处于锁定中的账号
+func (lockoutService *LockoutService) GetLockoutList(ctx context.Context, info request.PageInfo) (list interface{}, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	// 锁定记录不区分租户 按当前租户的用户名过滤
+	usernames := global.GVA_DB.WithContext(ctx).Model(&system.SysUser{}).Select("username")
+	db := global.GVA_DB.Model(&system.SysLoginLockout{}).Where("locked_until > ? AND username IN (?)", time.Now(), usernames)
+	if info.Keyword != "" {
+		db = db.Where("username LIKE ?", "%"+info.Keyword+"%")
+	}
+	var lockouts []system.SysLoginLockout
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	err = db.Order("locked_until desc").Limit(limit).Offset(offset).Find(&lockouts).Error
+	return lockouts, total, err
+}
+
+func (lockoutService *LockoutService) incrFailed(username string, window time.Duration) (int, error) {
+	ctx := context.Background()
+	key := lockoutFailedPrefix + username
+	count, err := global.GVA_REDIS.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		global.GVA_REDIS.Expire(ctx, key, window)
+	}
+	return int(count), nil
+}
+
+func (lockoutService *LockoutService) userID(username string) uint {
+	var ids []uint
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("username = ?", username).Limit(1).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[0]
+}
+
+// lockoutDuration 第n次(从0开始)锁定的时长 每次翻倍 不超过最长锁定时长
+func lockoutDuration(base int, maxSeconds int, n int) time.Duration {
+	d := time.Duration(base) * time.Second
+	if d <= 0 {
+		d = 5 * time.Minute
+	}
+	limit := time.Duration(maxSeconds) * time.Second
+	if limit < d {
+		limit = d
+	}
+	for i := 0; i < n && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	return d
+}
@@ -0,0 +1,170 @@
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		base, max, n int
+		want         time.Duration
+	}{
+		{60, 600, 0, time.Minute},
+		{60, 600, 1, 2 * time.Minute},
+		{60, 600, 3, 8 * time.Minute},
+		{60, 600, 4, 10 * time.Minute},
+		{60, 600, 30, 10 * time.Minute},
+		{0, 0, 0, 5 * time.Minute},
+		{120, 60, 2, 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.base, tt.max, tt.n); got != tt.want {
+			t.Errorf("lockoutDuration(%d, %d, %d) = %v, want %v", tt.base, tt.max, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLockoutService_RecordFailure(t *testing.T) {
+	setupTestDB(t, &system.SysLoginLockout{})
+	global.GVA_CONFIG.Lockout.MaxAttempts = 3
+	global.GVA_CONFIG.Lockout.LockDuration = 60
+	global.GVA_CONFIG.Lockout.MaxLockDuration = 3600
+	createTestUser(t, "alice", 888)
+
+	for i := 0; i < 2; i++ {
+		if _, locked := LockoutServiceApp.RecordFailure("alice", "127.0.0.1"); locked {
+			t.Fatalf("RecordFailure() locked after %d failures", i+1)
+		}
+	}
+	if _, locked := LockoutServiceApp.CheckLocked("alice"); locked {
+		t.Fatal("CheckLocked() should not lock before reaching max attempts")
+	}
+	until, locked := LockoutServiceApp.RecordFailure("alice", "127.0.0.1")
+	if !locked {
+		t.Fatal("RecordFailure() should lock at max attempts")
+	}
+	if d := time.Until(until); d <= 0 || d > time.Minute {
+		t.Errorf("first lock duration = %v, want about 1m", d)
+	}
+	if _, locked = LockoutServiceApp.CheckLocked("alice"); !locked {
+		t.Error("CheckLocked() should report locked account")
+	}
+	// 第二次锁定时长翻倍
+	expireLock(t, "alice")
+	for i := 0; i < 3; i++ {
+		until, locked = LockoutServiceApp.RecordFailure("alice", "127.0.0.1")
+	}
+	if d := time.Until(until); !locked || d <= time.Minute || d > 2*time.Minute {
+		t.Errorf("second lock = %v, %v, want locked about 2m", locked, d)
+	}
+
+	var count int64
+	tenant.Skip(global.GVA_DB).Model(&system.SysAuditLog{}).Where("action = ? AND username = ?", system.AuditAccountLocked, "alice").Count(&count)
+	if count != 2 {
+		t.Errorf("account locked audit logs = %d, want 2", count)
+	}
+}
+
+func TestLockoutService_RecordSuccess(t *testing.T) {
+	setupTestDB(t, &system.SysLoginLockout{})
+	global.GVA_CONFIG.Lockout.MaxAttempts = 2
+	global.GVA_CONFIG.Lockout.LockDuration = 60
+	global.GVA_CONFIG.Lockout.MaxLockDuration = 3600
+	createTestUser(t, "bob", 888)
+
+	LockoutServiceApp.RecordFailure("bob", "127.0.0.1")
+	LockoutServiceApp.RecordFailure("bob", "127.0.0.1")
+	expireLock(t, "bob")
+	// 登录成功后失败次数与退避等级清零 再次锁定时恢复首次锁定时长
+	LockoutServiceApp.RecordSuccess("bob")
+	var lockout system.SysLoginLockout
+	global.GVA_DB.Where("username = ?", "bob").First(&lockout)
+	if lockout.FailedCount != 0 || lockout.LockCount != 0 {
+		t.Errorf("RecordSuccess() left failed=%d lock=%d", lockout.FailedCount, lockout.LockCount)
+	}
+	LockoutServiceApp.RecordFailure("bob", "127.0.0.1")
+	until, locked := LockoutServiceApp.RecordFailure("bob", "127.0.0.1")
+	if d := time.Until(until); !locked || d > time.Minute {
+		t.Errorf("lock after success = %v, %v, want locked about 1m", locked, d)
+	}
+}
+
+func TestLockoutService_UnknownUsername(t *testing.T) {
+	setupTestDB(t, &system.SysLoginLockout{})
+	global.GVA_CONFIG.Lockout.MaxAttempts = 2
+	global.GVA_CONFIG.Lockout.LockDuration = 60
+	global.GVA_CONFIG.Lockout.MaxLockDuration = 3600
+
+	// 不存在的用户名与真实账号同样被锁定 但不写入数据库
+	LockoutServiceApp.RecordFailure("ghost", "127.0.0.1")
+	until, locked := LockoutServiceApp.RecordFailure("ghost", "127.0.0.1")
+	if d := time.Until(until); !locked || d > time.Minute {
+		t.Errorf("RecordFailure() = %v, %v, want locked about 1m", locked, d)
+	}
+	if _, locked = LockoutServiceApp.CheckLocked("ghost"); !locked {
+		t.Error("CheckLocked() should report locked unknown username")
+	}
+	var count int64
+	global.GVA_DB.Model(&system.SysLoginLockout{}).Count(&count)
+	if count != 0 {
+		t.Errorf("lockout records = %d, want 0", count)
+	}
+}
+
+func TestLockoutService_Disabled(t *testing.T) {
+	setupTestDB(t, &system.SysLoginLockout{})
+	for i := 0; i < 10; i++ {
+		if _, locked := LockoutServiceApp.RecordFailure("alice", "127.0.0.1"); locked {
+			t.Fatal("RecordFailure() should never lock when max attempts is 0")
+		}
+	}
+	var count int64
+	global.GVA_DB.Model(&system.SysLoginLockout{}).Count(&count)
+	if count != 0 {
+		t.Errorf("lockout records = %d, want 0", count)
+	}
+}
+
+func TestLockoutService_Tenant(t *testing.T) {
+	setupTestDB(t, &system.SysLoginLockout{})
+	global.GVA_CONFIG.Lockout.MaxAttempts = 1
+	global.GVA_CONFIG.Lockout.LockDuration = 60
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	createTestUser(t, "alice", 888)
+	bob := createTestUser(t, "bob", 888)
+	moveTestUserTenant(t, bob.ID, 2)
+	LockoutServiceApp.RecordFailure("alice", "127.0.0.1")
+	LockoutServiceApp.RecordFailure("bob", "127.0.0.1")
+
+	// 仅列出当前租户用户的锁定记录
+	list, total, err := LockoutServiceApp.GetLockoutList(ctx, request.PageInfo{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockouts := list.([]system.SysLoginLockout); total != 1 || len(lockouts) != 1 || lockouts[0].Username != "alice" {
+		t.Errorf("GetLockoutList() = %+v total %d, want only alice", lockouts, total)
+	}
+	if err = LockoutServiceApp.Unlock(ctx, bob.ID, 1, "127.0.0.1"); err == nil {
+		t.Error("Unlock() should reject user of other tenant")
+	}
+	if _, locked := LockoutServiceApp.CheckLocked("bob"); !locked {
+		t.Error("user of other tenant should stay locked")
+	}
+}
+
+// expireLock 将锁定时间提前到过去 模拟锁定到期
+func expireLock(t *testing.T, username string) {
+	t.Helper()
+	past := time.Now().Add(-time.Second)
+	err := global.GVA_DB.Model(&system.SysLoginLockout{}).Where("username = ?", username).Update("locked_until", past).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,125 @@
+package system
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/geoip"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+)
+
+// recentLoginLimit 最近登录记录条数
+const recentLoginLimit = 20
+
+type LoginLogService struct{}
+
+var LoginLogServiceApp = new(LoginLogService)
+
+var (
+	geoIPMu     sync.Mutex
+	geoIPPath   string
+	geoIPReader *geoip.Reader
+)
+
+// Record 写入登录日志 解析客户端与IP地理位置 失败时仅记录错误日志 不影响登录流程
+func (loginLogService *LoginLogService) Record(log system.SysLoginLog) {
+	if log.UserID == 0 && log.Username != "" {
+		tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Select("id").Where("username = ?", log.Username).Scan(&log.UserID)
+	}
+	if log.TenantId == 0 {
+		log.TenantId = userTenant(log.UserID)
+	}
+	ua := utils.ParseUserAgent(log.UserAgent)
+	log.Browser, log.Os, log.Device = ua.Browser, ua.OS, ua.Device
+	log.UserAgent = truncateRunes(log.UserAgent, 512)
+	log.Username = truncateRunes(log.Username, 191)
+	log.Reason = truncateRunes(log.Reason, 255)
+	loc := locateIP(log.Ip)
+	log.Country, log.Province, log.City = loc.Country, loc.Province, loc.City
+	if err := global.GVA_DB.WithContext(tenant.WithTenant(context.Background(), log.TenantId)).Create(&log).Error; err != nil {
+		global.GVA_LOG.Error("写入登录日志失败!", zap.String("username", log.Username), zap.Error(err))
+	}
+}
+
+// GetLoginLogList 分页获取登录日志
+func (loginLogService *LoginLogService) GetLoginLogList(ctx context.Context, info systemReq.SysLoginLogSearch) (list interface{}, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysLoginLog{})
+	var logs []system.SysLoginLog
+	if info.UserID != 0 {
+		db = db.Where("user_id = ?", info.UserID)
+	}
+	if info.Username != "" {
+		db = db.Where("username LIKE ?", "%"+info.Username+"%")
+	}
+	if info.Ip != "" {
+		db = db.Where("ip = ?", info.Ip)
+	}
+	if info.Success != nil {
+		db = db.Where("success = ?", *info.Success)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	err = db.Order("id desc").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, total, err
+}
+
+// GetRecentLogins 获取用户最近的登录记录 包括失败的尝试
+func (loginLogService *LoginLogService) GetRecentLogins(userID uint) (list []system.SysLoginLog, err error) {
+	err = tenant.Skip(global.GVA_DB).Where("user_id = ?", userID).Order("id desc").Limit(recentLoginLimit).Find(&list).Error
+	return list, err
+}
+
+// locateIP 查询IP地理位置 内网地址不查询 未配置IP库时返回空
+func locateIP(ip string) geoip.Location {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return geoip.Location{}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return geoip.Location{Country: "内网IP"}
+	}
+	reader := geoIPLocator()
+	if reader == nil {
+		return geoip.Location{}
+	}
+	lang := global.GVA_CONFIG.GeoIP.Language
+	if lang == "" {
+		lang = "zh-CN"
+	}
+	loc, err := reader.Locate(addr, lang)
+	if err != nil {
+		global.GVA_LOG.Error("查询IP地理位置失败!", zap.String("ip", ip), zap.Error(err))
+	}
+	return loc
+}
+
+// geoIPLocator 首次使用时加载IP库 配置的路径变化后重新加载 加载失败时不重试
+func geoIPLocator() *geoip.Reader {
+	path := global.GVA_CONFIG.GeoIP.DBPath
+	geoIPMu.Lock()
+	defer geoIPMu.Unlock()
+	if path == geoIPPath {
+		return geoIPReader
+	}
+	geoIPPath, geoIPReader = path, nil
+	if path == "" {
+		return nil
+	}
+	reader, err := geoip.Open(path)
+	if err != nil {
+		global.GVA_LOG.Error("加载IP地理位置库失败!", zap.String("path", path), zap.Error(err))
+		return nil
+	}
+	geoIPReader = reader
+	return reader
+}
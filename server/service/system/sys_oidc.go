@@ -0,0 +1,159 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/oidc"
+)
+
+const (
+	oidcProvider     = "oidc"
+	oidcStatePrefix  = "oidc_state:"
+	oidcStateTimeout = 10 * time.Minute
+)
+
+type OidcService struct{}
+
+var OidcServiceApp = new(OidcService)
+
+type oidcState struct {
+	Nonce        string
+	CodeVerifier string
+}
+
+var (
+	oidcProviderMu     sync.Mutex
+	oidcProviderCached *oidc.Provider
+)
+
+// provider 首次使用时读取身份提供方的 discovery 信息 失败时下次调用重试
+func (oidcService *OidcService) provider(ctx context.Context) (*oidc.Provider, error) {
+	conf := global.GVA_CONFIG.OIDC
+	if !conf.Enable {
+		return nil, errors.New("未开启OIDC登录")
+	}
+	oidcProviderMu.Lock()
+	defer oidcProviderMu.Unlock()
+	if oidcProviderCached != nil {
+		return oidcProviderCached, nil
+	}
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       conf.Issuer,
+		ClientID:     conf.ClientID,
+		ClientSecret: conf.ClientSecret,
+		RedirectURL:  conf.RedirectURL,
+		Scopes:       conf.Scopes,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	oidcProviderCached = p
+	return p, nil
+}
+
+// AuthUrl 生成身份提供方授权地址 state 用于回调时取回 nonce 与 code_verifier
+func (oidcService *OidcService) AuthUrl(ctx context.Context) (url string, state string, err error) {
+	p, err := oidcService.provider(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	var s oidcState
+	if state, err = oidc.RandomString(); err != nil {
+		return
+	}
+	if s.Nonce, err = oidc.RandomString(); err != nil {
+		return
+	}
+	if s.CodeVerifier, err = oidc.RandomString(); err != nil {
+		return
+	}
+	global.BlackCache.Set(oidcStatePrefix+state, &s, oidcStateTimeout)
+	return p.AuthCodeURL(state, s.Nonce, s.CodeVerifier), state, nil
+}
+
+// Login 使用回调中的授权码完成登录 返回映射后的系统用户
+func (oidcService *OidcService) Login(ctx context.Context, code string, state string) (user *system.SysUser, err error) {
+	v, ok := global.BlackCache.Get(oidcStatePrefix + state)
+	if state == "" || !ok {
+		return nil, errors.New("登录请求已过期，请重新登录")
+	}
+	// state 仅可使用一次
+	global.BlackCache.Delete(oidcStatePrefix + state)
+	s := v.(*oidcState)
+
+	p, err := oidcService.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.Exchange(ctx, code, s.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, s.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.UserInfo(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	// id_token 中的声明优先 userinfo 仅用于补充
+	for k, v := range claims {
+		info[k] = v
+	}
+	return UserServiceApp.LoginByExternal(oidcService.externalUser(info))
+}
+
+// externalUser 按配置将身份提供方声明映射为系统用户信息
+func (oidcService *OidcService) externalUser(claims map[string]interface{}) ExternalUser {
+	conf := global.GVA_CONFIG.OIDC
+	ext := ExternalUser{
+		Provider:           oidcProvider,
+		Subject:            claimString(claims, "sub"),
+		Username:           claimString(claims, conf.UsernameClaim, "preferred_username", "email", "sub"),
+		NickName:           claimString(claims, conf.NicknameClaim, "name"),
+		Email:              claimString(claims, "email"),
+		Phone:              claimString(claims, "phone_number"),
+		DefaultAuthorityId: conf.DefaultAuthorityId,
+		AutoProvision:      conf.AutoProvision,
+		SyncAuthorities:    len(conf.GroupMapping) > 0,
+	}
+	groupsClaim := conf.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	groups := map[string]bool{}
+	switch g := claims[groupsClaim].(type) {
+	case []interface{}:
+		for _, v := range g {
+			groups[fmt.Sprint(v)] = true
+		}
+	case string:
+		groups[g] = true
+	}
+	for _, m := range conf.GroupMapping {
+		if groups[m.Group] {
+			ext.AuthorityIds = append(ext.AuthorityIds, m.AuthorityId)
+		}
+	}
+	return ext
+}
+
+// claimString 依次取第一个非空的字符串声明
+func claimString(claims map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if k == "" {
+			continue
+		}
+		if v, ok := claims[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
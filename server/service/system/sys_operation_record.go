@@ -1,6 +1,8 @@
 package system
 
 import (
+	"context"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
@@ -10,15 +12,15 @@ import (
 //@author: [granty1](https://github.com/granty1)
 //@function: CreateSysOperationRecord
 //@description: 创建记录
-//@param: sysOperationRecord model.SysOperationRecord
+//@param: ctx context.Context, sysOperationRecord model.SysOperationRecord
 //@return: err error
 
 type OperationRecordService struct{}
 
 var OperationRecordServiceApp = new(OperationRecordService)
 
-func (operationRecordService *OperationRecordService) CreateSysOperationRecord(sysOperationRecord system.SysOperationRecord) (err error) {
-	err = global.GVA_DB.Create(&sysOperationRecord).Error
+func (operationRecordService *OperationRecordService) CreateSysOperationRecord(ctx context.Context, sysOperationRecord system.SysOperationRecord) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Create(&sysOperationRecord).Error
 	return err
 }
 
@@ -26,33 +28,33 @@ func (operationRecordService *OperationRecordService) CreateSysOperationRecord(s
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteSysOperationRecordByIds
 //@description: 批量删除记录
-//@param: ids request.IdsReq
+//@param: ctx context.Context, ids request.IdsReq
 //@return: err error
 
-func (operationRecordService *OperationRecordService) DeleteSysOperationRecordByIds(ids request.IdsReq) (err error) {
-	err = global.GVA_DB.Delete(&[]system.SysOperationRecord{}, "id in (?)", ids.Ids).Error
+func (operationRecordService *OperationRecordService) DeleteSysOperationRecordByIds(ctx context.Context, ids request.IdsReq) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Delete(&[]system.SysOperationRecord{}, "id in (?)", ids.Ids).Error
 	return err
 }
 
 //@author: [granty1](https://github.com/granty1)
 //@function: DeleteSysOperationRecord
 //@description: 删除操作记录
-//@param: sysOperationRecord model.SysOperationRecord
+//@param: ctx context.Context, sysOperationRecord model.SysOperationRecord
 //@return: err error
 
-func (operationRecordService *OperationRecordService) DeleteSysOperationRecord(sysOperationRecord system.SysOperationRecord) (err error) {
-	err = global.GVA_DB.Delete(&sysOperationRecord).Error
+func (operationRecordService *OperationRecordService) DeleteSysOperationRecord(ctx context.Context, sysOperationRecord system.SysOperationRecord) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Delete(&sysOperationRecord).Error
 	return err
 }
 
 //@author: [granty1](https://github.com/granty1)
 //@function: GetSysOperationRecord
 //@description: 根据id获取单条操作记录
-//@param: id uint
+//@param: ctx context.Context, id uint
 //@return: sysOperationRecord system.SysOperationRecord, err error
 
-func (operationRecordService *OperationRecordService) GetSysOperationRecord(id uint) (sysOperationRecord system.SysOperationRecord, err error) {
-	err = global.GVA_DB.Where("id = ?", id).First(&sysOperationRecord).Error
+func (operationRecordService *OperationRecordService) GetSysOperationRecord(ctx context.Context, id uint) (sysOperationRecord system.SysOperationRecord, err error) {
+	err = global.GVA_DB.WithContext(ctx).Where("id = ?", id).First(&sysOperationRecord).Error
 	return
 }
 
@@ -60,14 +62,14 @@ func (operationRecordService *OperationRecordService) GetSysOperationRecord(id u
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetSysOperationRecordInfoList
 //@description: 分页获取操作记录列表
-//@param: info systemReq.SysOperationRecordSearch
+//@param: ctx context.Context, info systemReq.SysOperationRecordSearch
 //@return: list interface{}, total int64, err error
 
-func (operationRecordService *OperationRecordService) GetSysOperationRecordInfoList(info systemReq.SysOperationRecordSearch) (list interface{}, total int64, err error) {
+func (operationRecordService *OperationRecordService) GetSysOperationRecordInfoList(ctx context.Context, info systemReq.SysOperationRecordSearch) (list interface{}, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
 	// 创建db
-	db := global.GVA_DB.Model(&system.SysOperationRecord{})
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysOperationRecord{})
 	var sysOperationRecords []system.SysOperationRecord
 	// 如果有条件搜索 下方会自动创建搜索语句
 	if info.Method != "" {
@@ -83,6 +85,6 @@ func (operationRecordService *OperationRecordService) GetSysOperationRecordInfoL
 	if err != nil {
 		return
 	}
-	err = db.Order("id desc").Limit(limit).Offset(offset).Preload("User").Find(&sysOperationRecords).Error
+	err = db.Order("id desc").Limit(limit).Offset(offset).Preload("User").Preload("Impersonator").Find(&sysOperationRecords).Error
 	return sysOperationRecords, total, err
 }
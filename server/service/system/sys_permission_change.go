@@ -0,0 +1,423 @@
+package system
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+type PermissionChangeService struct{}
+
+var PermissionChangeServiceApp = new(PermissionChangeService)
+
+var ErrPermissionApprovalRequired = errors.New("已开启权限变更审批 该操作不支持审批 不能直接执行")
+
+// checkApprovalDisabled 开启权限变更审批时拒绝无法提交审批的权限变更 避免绕过审批
+func checkApprovalDisabled() error {
+	if global.GVA_CONFIG.PermissionApproval.Enable {
+		return ErrPermissionApprovalRequired
+	}
+	return nil
+}
+
+// SubmitChange 提交权限变更申请 payload为角色api、菜单、资源权限或用户角色的设置参数 与当前权限无差异时返回nil
+func (permissionChangeService *PermissionChangeService) SubmitChange(ctx context.Context, requesterID, requesterAuthorityID uint, payload interface{}, ip string) (*system.SysPermissionChange, error) {
+	var kind string
+	switch p := payload.(type) {
+	case systemReq.CasbinInReceive:
+		kind = system.PermissionChangeCasbin
+	case systemReq.AddMenuAuthorityInfo:
+		kind = system.PermissionChangeMenu
+		menus := make([]system.SysBaseMenu, len(p.Menus))
+		for i := range p.Menus {
+			menus[i].ID = p.Menus[i].ID
+		}
+		p.Menus = menus
+		payload = p
+	case system.SysAuthority:
+		kind = system.PermissionChangeDataAuthority
+		data := system.SysAuthority{AuthorityId: p.AuthorityId}
+		for i := range p.DataAuthorityId {
+			data.DataAuthorityId = append(data.DataAuthorityId, &system.SysAuthority{AuthorityId: p.DataAuthorityId[i].AuthorityId})
+		}
+		payload = data
+	case systemReq.SetUserAuthorities:
+		kind = system.PermissionChangeUserAuthority
+	default:
+		return nil, errors.New("不支持审批的权限变更")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	change := system.SysPermissionChange{Kind: kind, Payload: string(data), RequesterId: requesterID}
+	targetID, before, after, err := permissionChangeState(ctx, kind, change.Payload)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range changeAuthorities(kind, targetID, after) {
+		if err = AuthorityServiceApp.CheckAuthorityIDAuth(requesterAuthorityID, id); err != nil {
+			return nil, err
+		}
+	}
+	change.TargetId = targetID
+	change.BeforeDigest = permissionDigest(before)
+	change.Added, change.Removed = diffItems(before, after)
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return nil, nil
+	}
+	var requester system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Select("id", "username").Where("id = ?", requesterID).First(&requester).Error; err != nil {
+		return nil, errors.New("申请人不存在")
+	}
+	change.RequesterName = requester.Username
+	expireHours := global.GVA_CONFIG.PermissionApproval.ExpireHours
+	if expireHours <= 0 {
+		expireHours = 72
+	}
+	change.ExpiresAt = time.Now().Add(time.Duration(expireHours) * time.Hour)
+	change.Status = system.PermissionChangePending
+	if err = global.GVA_DB.WithContext(ctx).Create(&change).Error; err != nil {
+		return nil, err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditChangeRequested,
+		UserID:     requesterID,
+		Username:   requester.Username,
+		OperatorID: requesterID,
+		Ip:         ip,
+		Detail:     truncateRunes(changeSummary(change), 255),
+	})
+	return &change, nil
+}
+
+// ApproveChange 审批通过权限变更并按审批人的权限应用 申请人不能审批自己的申请 申请后权限已被修改时不再应用
+func (permissionChangeService *PermissionChangeService) ApproveChange(ctx context.Context, approverID, approverAuthorityID uint, req systemReq.DecidePermissionChange, ip string) error {
+	change, err := permissionChangeService.pendingChange(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if change.RequesterId == approverID {
+		return errors.New("不能审批自己提交的变更")
+	}
+	_, before, _, err := permissionChangeState(ctx, change.Kind, change.Payload)
+	if err != nil {
+		return err
+	}
+	approverName, err := permissionChangeService.decide(ctx, change.ID, approverID, system.PermissionChangeApproved, req.Comment)
+	if err != nil {
+		return err
+	}
+	if permissionDigest(before) != change.BeforeDigest {
+		err = errors.New("申请提交后权限已被修改 请重新提交")
+	} else {
+		err = applyPermissionChange(ctx, change.Kind, change.Payload, approverAuthorityID)
+	}
+	if err != nil {
+		global.GVA_DB.WithContext(ctx).Model(&system.SysPermissionChange{}).Where("id = ?", change.ID).
+			Updates(map[string]interface{}{"status": system.PermissionChangeFailed, "error": err.Error()})
+		return err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditChangeApproved,
+		UserID:     change.RequesterId,
+		Username:   change.RequesterName,
+		OperatorID: approverID,
+		Ip:         ip,
+		Detail:     truncateRunes(approverName+"审批通过 "+changeSummary(change), 255),
+	})
+	return nil
+}
+
+// RejectChange 驳回权限变更 申请人可撤回自己的申请
+func (permissionChangeService *PermissionChangeService) RejectChange(ctx context.Context, operatorID uint, req systemReq.DecidePermissionChange, ip string) error {
+	change, err := permissionChangeService.pendingChange(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	operatorName, err := permissionChangeService.decide(ctx, change.ID, operatorID, system.PermissionChangeRejected, req.Comment)
+	if err != nil {
+		return err
+	}
+	action := "驳回"
+	if operatorID == change.RequesterId {
+		action = "撤回"
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditChangeRejected,
+		UserID:     change.RequesterId,
+		Username:   change.RequesterName,
+		OperatorID: operatorID,
+		Ip:         ip,
+		Detail:     truncateRunes(operatorName+action+" "+changeSummary(change), 255),
+	})
+	return nil
+}
+
+// GetChangeList 分页获取权限变更申请
+func (permissionChangeService *PermissionChangeService) GetChangeList(ctx context.Context, info systemReq.PermissionChangeSearch) (list []system.SysPermissionChange, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysPermissionChange{})
+	if info.Kind != "" {
+		db = db.Where("kind = ?", info.Kind)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+// ExpireChanges 将超时未审批的变更申请置为失效 由定时任务调用 处理全部租户
+func (permissionChangeService *PermissionChangeService) ExpireChanges() error {
+	return tenant.Skip(global.GVA_DB).Model(&system.SysPermissionChange{}).
+		Where("status = ? AND expires_at <= ?", system.PermissionChangePending, time.Now()).
+		Update("status", system.PermissionChangeExpired).Error
+}
+
+// pendingChange 获取当前租户下待审批且未过期的变更申请
+func (permissionChangeService *PermissionChangeService) pendingChange(ctx context.Context, id uint) (change system.SysPermissionChange, err error) {
+	if err = global.GVA_DB.WithContext(ctx).Where("id = ?", id).First(&change).Error; err != nil {
+		return change, errors.New("变更申请不存在")
+	}
+	if change.Status != system.PermissionChangePending {
+		return change, errors.New("变更申请已处理")
+	}
+	if !change.ExpiresAt.After(time.Now()) {
+		return change, errors.New("变更申请已过期")
+	}
+	return change, nil
+}
+
+// decide 以条件更新记录审批结果 保证同一申请只被处理一次
+func (permissionChangeService *PermissionChangeService) decide(ctx context.Context, id, operatorID uint, status, comment string) (operatorName string, err error) {
+	var operator system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Select("id", "username").Where("id = ?", operatorID).First(&operator).Error; err != nil {
+		return "", errors.New("审批人不存在")
+	}
+	result := global.GVA_DB.WithContext(ctx).Model(&system.SysPermissionChange{}).
+		Where("id = ? AND status = ?", id, system.PermissionChangePending).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"approver_id":   operatorID,
+			"approver_name": operator.Username,
+			"comment":       truncateRunes(comment, 255),
+			"decided_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", errors.New("变更申请已处理")
+	}
+	return operator.Username, nil
+}
+
+// permissionChangeState 解析变更内容 返回变更对象及其当前与变更后的权限
+func permissionChangeState(ctx context.Context, kind, payload string) (targetID uint, before, after []string, err error) {
+	switch kind {
+	case system.PermissionChangeCasbin:
+		var p systemReq.CasbinInReceive
+		if err = json.Unmarshal([]byte(payload), &p); err != nil {
+			return
+		}
+		for _, info := range CasbinServiceApp.GetPolicyPathByAuthorityId(ctx, p.AuthorityId) {
+			before = append(before, info.Method+" "+info.Path)
+		}
+		for _, info := range p.CasbinInfos {
+			after = append(after, info.Method+" "+info.Path)
+		}
+		return p.AuthorityId, before, after, nil
+	case system.PermissionChangeMenu:
+		var p systemReq.AddMenuAuthorityInfo
+		if err = json.Unmarshal([]byte(payload), &p); err != nil {
+			return
+		}
+		var menuIds []string
+		err = global.GVA_DB.Model(&system.SysAuthorityMenu{}).Where("sys_authority_authority_id = ?", p.AuthorityId).Pluck("sys_base_menu_id", &menuIds).Error
+		if err != nil {
+			return
+		}
+		ids := make([]uint, 0, len(menuIds)+len(p.Menus))
+		for _, id := range menuIds {
+			if v, e := strconv.ParseUint(id, 10, 64); e == nil {
+				ids = append(ids, uint(v))
+			}
+		}
+		beforeCount := len(ids)
+		for i := range p.Menus {
+			ids = append(ids, p.Menus[i].ID)
+		}
+		var menus []system.SysBaseMenu
+		if err = global.GVA_DB.Select("id", "title").Where("id IN ?", ids).Find(&menus).Error; err != nil {
+			return
+		}
+		names := make(map[uint]string, len(menus))
+		for i := range menus {
+			names[menus[i].ID] = menus[i].Title
+		}
+		for i, id := range ids {
+			item := fmt.Sprintf("%d %s", id, names[id])
+			if i < beforeCount {
+				before = append(before, item)
+			} else {
+				after = append(after, item)
+			}
+		}
+		return p.AuthorityId, before, after, nil
+	case system.PermissionChangeDataAuthority:
+		var p system.SysAuthority
+		if err = json.Unmarshal([]byte(payload), &p); err != nil {
+			return
+		}
+		var current system.SysAuthority
+		if err = global.GVA_DB.Preload("DataAuthorityId").Where("authority_id = ?", p.AuthorityId).First(&current).Error; err != nil {
+			return 0, nil, nil, errors.New("角色不存在")
+		}
+		var beforeIds, afterIds []uint
+		for i := range current.DataAuthorityId {
+			beforeIds = append(beforeIds, current.DataAuthorityId[i].AuthorityId)
+		}
+		for i := range p.DataAuthorityId {
+			afterIds = append(afterIds, p.DataAuthorityId[i].AuthorityId)
+		}
+		before, after, err = authorityItems(beforeIds, afterIds)
+		return p.AuthorityId, before, after, err
+	case system.PermissionChangeUserAuthority:
+		var p systemReq.SetUserAuthorities
+		if err = json.Unmarshal([]byte(payload), &p); err != nil {
+			return
+		}
+		if len(p.AuthorityIds) == 0 {
+			return 0, nil, nil, errors.New("角色不能为空")
+		}
+		if _, err = UserServiceApp.tenantUser(ctx, p.ID); err != nil {
+			return
+		}
+		var beforeIds []uint
+		err = global.GVA_DB.Model(&system.SysUserAuthority{}).Where("sys_user_id = ? AND valid_until IS NULL", p.ID).
+			Pluck("sys_authority_authority_id", &beforeIds).Error
+		if err != nil {
+			return
+		}
+		before, after, err = authorityItems(beforeIds, p.AuthorityIds)
+		return p.ID, before, after, err
+	}
+	return 0, nil, nil, errors.New("不支持审批的权限变更")
+}
+
+// authorityItems 将角色ID转为带角色名的权限项
+func authorityItems(beforeIds, afterIds []uint) (before, after []string, err error) {
+	var authorities []system.SysAuthority
+	err = global.GVA_DB.Select("authority_id", "authority_name").Where("authority_id IN ?", append(slices.Clone(beforeIds), afterIds...)).Find(&authorities).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make(map[uint]string, len(authorities))
+	for i := range authorities {
+		names[authorities[i].AuthorityId] = authorities[i].AuthorityName
+	}
+	for _, id := range beforeIds {
+		before = append(before, fmt.Sprintf("%d %s", id, names[id]))
+	}
+	for _, id := range afterIds {
+		after = append(after, fmt.Sprintf("%d %s", id, names[id]))
+	}
+	return before, after, nil
+}
+
+// changeAuthorities 提交变更时需具备管理权限的角色
+func changeAuthorities(kind string, targetID uint, after []string) []uint {
+	ids := []uint{targetID}
+	switch kind {
+	case system.PermissionChangeDataAuthority:
+	case system.PermissionChangeUserAuthority:
+		ids = nil
+	default:
+		return ids
+	}
+	for _, item := range after {
+		id, _, _ := strings.Cut(item, " ")
+		if v, err := strconv.ParseUint(id, 10, 64); err == nil {
+			ids = append(ids, uint(v))
+		}
+	}
+	return ids
+}
+
+// applyPermissionChange 按审批人的权限执行变更
+func applyPermissionChange(ctx context.Context, kind, payload string, adminAuthorityID uint) error {
+	switch kind {
+	case system.PermissionChangeCasbin:
+		var p systemReq.CasbinInReceive
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		return CasbinServiceApp.UpdateCasbin(ctx, adminAuthorityID, p.AuthorityId, p.CasbinInfos)
+	case system.PermissionChangeMenu:
+		var p systemReq.AddMenuAuthorityInfo
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		return MenuServiceApp.AddMenuAuthority(p.Menus, adminAuthorityID, p.AuthorityId)
+	case system.PermissionChangeDataAuthority:
+		var p system.SysAuthority
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		return AuthorityServiceApp.SetDataAuthority(adminAuthorityID, p)
+	case system.PermissionChangeUserAuthority:
+		var p systemReq.SetUserAuthorities
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return err
+		}
+		return UserServiceApp.SetUserAuthorities(ctx, adminAuthorityID, p.ID, p.AuthorityIds)
+	}
+	return errors.New("不支持审批的权限变更")
+}
+
+// diffItems 比较变更前后的权限项
+func diffItems(before, after []string) (added, removed []string) {
+	for _, item := range after {
+		if !slices.Contains(before, item) && !slices.Contains(added, item) {
+			added = append(added, item)
+		}
+	}
+	for _, item := range before {
+		if !slices.Contains(after, item) && !slices.Contains(removed, item) {
+			removed = append(removed, item)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+// permissionDigest 权限项的摘要 与顺序无关
+func permissionDigest(items []string) string {
+	items = slices.Clone(items)
+	slices.Sort(items)
+	sum := sha256.Sum256([]byte(strings.Join(items, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// changeSummary 变更申请的摘要 用于审计日志
+func changeSummary(change system.SysPermissionChange) string {
+	return fmt.Sprintf("#%d %s 对象%d 新增%d项 移除%d项", change.ID, change.Kind, change.TargetId, len(change.Added), len(change.Removed))
+}
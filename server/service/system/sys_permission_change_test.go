@@ -0,0 +1,218 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// setupTestApproval 开启权限变更审批 创建两名管理员及待调整角色的用户
+func setupTestApproval(t *testing.T) (ctx context.Context, requester, approver, user system.SysUser) {
+	t.Helper()
+	setupTestDB(t, &system.SysPermissionChange{})
+	global.GVA_CONFIG.PermissionApproval.Enable = true
+	global.GVA_DB.Create(&system.SysAuthority{AuthorityId: 9528, AuthorityName: "测试角色"})
+	requester = createTestUser(t, "requester", 888)
+	approver = createTestUser(t, "approver", 888)
+	user = createTestUser(t, "alice", 888)
+	return tenant.WithTenant(context.Background(), tenant.Default), requester, approver, user
+}
+
+func userAuthorityIds(t *testing.T, userID uint) []uint {
+	t.Helper()
+	var ids []uint
+	global.GVA_DB.Model(&system.SysUserAuthority{}).Where("sys_user_id = ?", userID).Order("sys_authority_authority_id").
+		Pluck("sys_authority_authority_id", &ids)
+	return ids
+}
+
+func TestPermissionChangeService_SubmitChange(t *testing.T) {
+	ctx, requester, _, user := setupTestApproval(t)
+	payload := systemReq.SetUserAuthorities{ID: user.ID, AuthorityIds: []uint{9528}}
+	change, err := PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change == nil || change.Status != system.PermissionChangePending || change.Kind != system.PermissionChangeUserAuthority || change.TargetId != user.ID {
+		t.Fatalf("SubmitChange() = %+v", change)
+	}
+	if !slices.Equal(change.Added, []string{"9528 测试角色"}) || len(change.Removed) != 1 {
+		t.Errorf("SubmitChange() added = %v removed = %v", change.Added, change.Removed)
+	}
+	if !time.Now().Before(change.ExpiresAt) {
+		t.Errorf("SubmitChange() expires at %v", change.ExpiresAt)
+	}
+	// 提交后尚未生效
+	if ids := userAuthorityIds(t, user.ID); !slices.Equal(ids, []uint{888}) {
+		t.Errorf("authorities before approval = %v, want [888]", ids)
+	}
+
+	// 与当前权限无差异时不提交
+	payload.AuthorityIds = []uint{888}
+	if change, err = PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1"); err != nil || change != nil {
+		t.Errorf("SubmitChange(no diff) = %+v, %v, want nil", change, err)
+	}
+	if _, err = PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, systemReq.GrantUserAuthority{}, "127.0.0.1"); err == nil {
+		t.Error("SubmitChange() should reject unsupported payload")
+	}
+	payload.ID = 999
+	if _, err = PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1"); err == nil {
+		t.Error("SubmitChange() should reject unknown user")
+	}
+}
+
+func TestPermissionChangeService_ApproveChange(t *testing.T) {
+	ctx, requester, approver, user := setupTestApproval(t)
+	payload := systemReq.SetUserAuthorities{ID: user.ID, AuthorityIds: []uint{9528, 888}}
+	change, err := PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := systemReq.DecidePermissionChange{ID: change.ID, Comment: "ok"}
+
+	// 申请人不能审批自己的申请
+	if err = PermissionChangeServiceApp.ApproveChange(ctx, requester.ID, 888, req, "127.0.0.1"); err == nil {
+		t.Fatal("ApproveChange() should reject self approval")
+	}
+	if ids := userAuthorityIds(t, user.ID); !slices.Equal(ids, []uint{888}) {
+		t.Fatalf("authorities after self approval = %v, want [888]", ids)
+	}
+
+	if err = PermissionChangeServiceApp.ApproveChange(ctx, approver.ID, 888, req, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := userAuthorityIds(t, user.ID); !slices.Equal(ids, []uint{888, 9528}) {
+		t.Errorf("authorities after approval = %v, want [888 9528]", ids)
+	}
+	var got system.SysPermissionChange
+	tenant.Skip(global.GVA_DB).First(&got, change.ID)
+	if got.Status != system.PermissionChangeApproved || got.ApproverId != approver.ID || got.Comment != "ok" || got.DecidedAt == nil {
+		t.Errorf("approved change = %+v", got)
+	}
+	if err = PermissionChangeServiceApp.ApproveChange(ctx, approver.ID, 888, req, "127.0.0.1"); err == nil {
+		t.Error("ApproveChange() should reject a decided change")
+	}
+}
+
+func TestPermissionChangeService_ApproveStaleChange(t *testing.T) {
+	ctx, requester, approver, user := setupTestApproval(t)
+	change, err := PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, systemReq.SetUserAuthorities{ID: user.ID, AuthorityIds: []uint{9528}}, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 申请提交后权限被直接修改 审批时不再应用
+	if err = UserServiceApp.SetUserAuthorities(ctx, 888, user.ID, []uint{888, 9528}); err != nil {
+		t.Fatal(err)
+	}
+	if err = PermissionChangeServiceApp.ApproveChange(ctx, approver.ID, 888, systemReq.DecidePermissionChange{ID: change.ID}, "127.0.0.1"); err == nil {
+		t.Fatal("ApproveChange() should reject a stale change")
+	}
+	var got system.SysPermissionChange
+	tenant.Skip(global.GVA_DB).First(&got, change.ID)
+	if got.Status != system.PermissionChangeFailed || got.Error == "" {
+		t.Errorf("stale change = %+v, want failed", got)
+	}
+	if ids := userAuthorityIds(t, user.ID); !slices.Equal(ids, []uint{888, 9528}) {
+		t.Errorf("authorities = %v, want [888 9528]", ids)
+	}
+}
+
+func TestPermissionChangeService_RejectChange(t *testing.T) {
+	ctx, requester, approver, user := setupTestApproval(t)
+	payload := systemReq.SetUserAuthorities{ID: user.ID, AuthorityIds: []uint{9528}}
+	rejected, err := PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withdrawn, err := PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = PermissionChangeServiceApp.RejectChange(ctx, approver.ID, systemReq.DecidePermissionChange{ID: rejected.ID, Comment: "no"}, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	// 申请人可撤回自己的申请
+	if err = PermissionChangeServiceApp.RejectChange(ctx, requester.ID, systemReq.DecidePermissionChange{ID: withdrawn.ID}, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{rejected.ID, withdrawn.ID} {
+		var got system.SysPermissionChange
+		tenant.Skip(global.GVA_DB).First(&got, id)
+		if got.Status != system.PermissionChangeRejected {
+			t.Errorf("change %d status = %s, want rejected", id, got.Status)
+		}
+		if err = PermissionChangeServiceApp.ApproveChange(ctx, approver.ID, 888, systemReq.DecidePermissionChange{ID: id}, "127.0.0.1"); err == nil {
+			t.Errorf("ApproveChange(%d) should reject a decided change", id)
+		}
+	}
+	if ids := userAuthorityIds(t, user.ID); !slices.Equal(ids, []uint{888}) {
+		t.Errorf("authorities after reject = %v, want [888]", ids)
+	}
+
+	// 超时未审批的申请失效
+	expired, err := PermissionChangeServiceApp.SubmitChange(ctx, requester.ID, 888, payload, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant.Skip(global.GVA_DB).Model(&system.SysPermissionChange{}).Where("id = ?", expired.ID).Update("expires_at", time.Now().Add(-time.Second))
+	if err = PermissionChangeServiceApp.RejectChange(ctx, approver.ID, systemReq.DecidePermissionChange{ID: expired.ID}, "127.0.0.1"); err == nil {
+		t.Error("RejectChange() should reject an expired change")
+	}
+	if err = PermissionChangeServiceApp.ExpireChanges(); err != nil {
+		t.Fatal(err)
+	}
+	var got system.SysPermissionChange
+	tenant.Skip(global.GVA_DB).First(&got, expired.ID)
+	if got.Status != system.PermissionChangeExpired {
+		t.Errorf("expired change status = %s, want expired", got.Status)
+	}
+}
+
+func TestPermissionApprovalRequired(t *testing.T) {
+	ctx, requester, _, user := setupTestApproval(t)
+	_, err := UserServiceApp.GrantAuthority(ctx, 888, requester.ID, systemReq.GrantUserAuthority{ID: user.ID, AuthorityId: 9528, ValidUntil: time.Now().Add(time.Hour)}, "127.0.0.1")
+	if !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("GrantAuthority() error = %v, want ErrPermissionApprovalRequired", err)
+	}
+	_, err = AuthorityServiceApp.CopyAuthority(ctx, 888, response.SysAuthorityCopyResponse{Authority: system.SysAuthority{AuthorityId: 9529}, OldAuthorityId: 888})
+	if !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("CopyAuthority() error = %v, want ErrPermissionApprovalRequired", err)
+	}
+	parentID := uint(888)
+	if _, err = AuthorityServiceApp.UpdateAuthority(system.SysAuthority{AuthorityId: 9528, ParentId: &parentID}); !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("UpdateAuthority(parent) error = %v, want ErrPermissionApprovalRequired", err)
+	}
+	if err = AuthorityBtnServiceApp.SetAuthorityBtn(systemReq.SysAuthorityBtnReq{AuthorityId: 9528, MenuID: 1, Selected: []uint{1}}); !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("SetAuthorityBtn() error = %v, want ErrPermissionApprovalRequired", err)
+	}
+	if err = AuthorityFieldRuleServiceApp.SetFieldRules(888, systemReq.SetAuthorityFieldRules{AuthorityId: 9528}); !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("SetFieldRules() error = %v, want ErrPermissionApprovalRequired", err)
+	}
+	if err = AuthorityServiceApp.SetAuthorityDataScope(888, systemReq.SetAuthorityDataScope{AuthorityId: 9528, DataScope: "all"}); !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("SetAuthorityDataScope() error = %v, want ErrPermissionApprovalRequired", err)
+	}
+
+	if err = global.GVA_DB.AutoMigrate(&system.SysApi{}); err != nil {
+		t.Fatal(err)
+	}
+	setupTestCasbin(t)
+	file := testPolicyFile()
+	if _, err = CasbinServiceApp.ImportPolicy(ctx, 888, requester.ID, file, true, false, "127.0.0.1"); err != nil {
+		t.Errorf("ImportPolicy(dryRun) error = %v", err)
+	}
+	if _, err = CasbinServiceApp.ImportPolicy(ctx, 888, requester.ID, file, false, false, "127.0.0.1"); !errors.Is(err, ErrPermissionApprovalRequired) {
+		t.Errorf("ImportPolicy() error = %v, want ErrPermissionApprovalRequired", err)
+	}
+	if ids := userAuthorityIds(t, user.ID); !slices.Equal(ids, []uint{888}) {
+		t.Errorf("authorities = %v, want [888]", ids)
+	}
+}
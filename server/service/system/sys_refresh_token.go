@@ -0,0 +1,115 @@
+package system
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("刷新令牌无效或已过期，请重新登录")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，为保证安全已注销该登录，请重新登录")
+)
+
+// CreateRefreshToken 签发刷新令牌 familyID 即登录会话ID
+func (jwtService *JwtService) CreateRefreshToken(tx *gorm.DB, userID uint, familyID string) (token string, expiresAt time.Time, err error) {
+	dr, err := utils.ParseDuration(global.GVA_CONFIG.JWT.RefreshExpiresTime)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	expiresAt = time.Now().Add(dr)
+	err = tx.Create(&system.SysRefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.SHA256V([]byte(token)),
+		ExpiresAt: expiresAt,
+	}).Error
+	return token, expiresAt, err
+}
+
+// RotateRefreshToken 使用刷新令牌换取新的刷新令牌 旧令牌作废 已作废的令牌再次使用时注销其所在会话
+func (jwtService *JwtService) RotateRefreshToken(token string) (user *system.SysUser, sessionID string, newToken string, expiresAt time.Time, err error) {
+	if token == "" {
+		return nil, "", "", time.Time{}, ErrRefreshTokenInvalid
+	}
+	var old system.SysRefreshToken
+	err = global.GVA_DB.Where("token_hash = ?", utils.SHA256V([]byte(token))).First(&old).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrRefreshTokenInvalid
+		}
+		return nil, "", "", time.Time{}, err
+	}
+	if old.RevokedAt != nil || time.Now().After(old.ExpiresAt) {
+		return nil, "", "", time.Time{}, ErrRefreshTokenInvalid
+	}
+	if old.UsedAt != nil {
+		return nil, "", "", time.Time{}, jwtService.refreshTokenReused(old)
+	}
+
+	var u system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", old.UserID).Preload("Authorities").Preload("Authority").First(&u).Error; err != nil {
+		return nil, "", "", time.Time{}, ErrRefreshTokenInvalid
+	}
+	if u.Enable != 1 {
+		_ = SessionServiceApp.RevokeSession(old.FamilyID)
+		return nil, "", "", time.Time{}, errors.New("用户被禁止登录")
+	}
+	u.TenantId = SessionServiceApp.SessionTenant(old.FamilyID, u.TenantId)
+	if err = TenantServiceApp.CheckTenant(u.TenantId); err != nil {
+		_ = SessionServiceApp.RevokeSession(old.FamilyID)
+		return nil, "", "", time.Time{}, err
+	}
+	if err = UserServiceApp.ApplyAuthorityGrants(&u); err != nil {
+		if errors.Is(err, ErrNoActiveAuthority) {
+			_ = SessionServiceApp.RevokeSession(old.FamilyID)
+		}
+		return nil, "", "", time.Time{}, err
+	}
+
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		// 以条件更新保证并发刷新时只有一个请求成功
+		result := tx.Model(&old).Where("used_at IS NULL AND revoked_at IS NULL").Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		newToken, expiresAt, err = jwtService.CreateRefreshToken(tx, old.UserID, old.FamilyID)
+		if err != nil {
+			return err
+		}
+		// 会话有效期随刷新令牌延长
+		return tx.Model(&system.SysUserSession{}).Where("session_id = ?", old.FamilyID).
+			Updates(map[string]interface{}{"expires_at": expiresAt, "last_seen_at": time.Now()}).Error
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		return nil, "", "", time.Time{}, jwtService.refreshTokenReused(old)
+	}
+	if err != nil {
+		return nil, "", "", time.Time{}, err
+	}
+	return &u, old.FamilyID, newToken, expiresAt, nil
+}
+
+func (jwtService *JwtService) refreshTokenReused(token system.SysRefreshToken) error {
+	global.GVA_LOG.Warn("检测到刷新令牌重复使用，注销会话", zap.Uint("userId", token.UserID), zap.String("familyId", token.FamilyID))
+	if err := SessionServiceApp.RevokeSession(token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
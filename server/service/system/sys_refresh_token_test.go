@@ -0,0 +1,98 @@
+package system
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+func TestJwtService_RotateRefreshToken(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "alice", 888)
+	session, first, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, sessionID, second, _, err := JwtServiceApp.RotateRefreshToken(first)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	if got.ID != user.ID || sessionID != session.SessionID {
+		t.Errorf("RotateRefreshToken() = user %d session %s, want user %d session %s", got.ID, sessionID, user.ID, session.SessionID)
+	}
+	if second == "" || second == first {
+		t.Fatalf("RotateRefreshToken() should issue a new refresh token")
+	}
+
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken(second); err != nil {
+		t.Fatalf("RotateRefreshToken() second rotation error = %v", err)
+	}
+	if !SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Fatal("session should stay valid while tokens rotate normally")
+	}
+
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken("unknown"); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("RotateRefreshToken(unknown) error = %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestJwtService_RotateRefreshTokenReuse(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "alice", 888)
+	session, first, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, otherToken, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.2", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, second, _, err := JwtServiceApp.RotateRefreshToken(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已轮换的令牌再次使用视为泄露 注销整个会话
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken(first); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("RotateRefreshToken(reused) error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken(second); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("RotateRefreshToken(latest after reuse) error = %v, want ErrRefreshTokenInvalid", err)
+	}
+	if SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Error("session should be revoked after refresh token reuse")
+	}
+	var revoked int64
+	global.GVA_DB.Model(&system.SysRefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", session.SessionID).Count(&revoked)
+	if revoked != 0 {
+		t.Errorf("%d refresh tokens of the reused family are still active", revoked)
+	}
+
+	// 同一用户的其他会话不受影响
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken(otherToken); err != nil {
+		t.Errorf("RotateRefreshToken(other session) error = %v", err)
+	}
+	if !SessionServiceApp.CheckSession(other.SessionID, "127.0.0.2") {
+		t.Error("other session should stay valid")
+	}
+}
+
+func TestJwtService_RotateRefreshTokenDisabledUser(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "alice", 888)
+	session, token, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", user.ID).Update("enable", 2)
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken(token); err == nil {
+		t.Fatal("RotateRefreshToken() should reject disabled user")
+	}
+	if SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Error("session of disabled user should be revoked")
+	}
+}
@@ -0,0 +1,252 @@
+package system
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TenantService struct{}
+
+var TenantServiceApp = new(TenantService)
+
+var ErrTenantNotFound = errors.New("租户不存在")
+
+var ErrPlatformApi = errors.New("该接口管理全部租户共用的数据 仅可在默认租户授权")
+
+// platformApiPrefixes 系统配置、代码生成、导出模板等系统级接口 导出模板直接按表名查询 不经过租户隔离
+var platformApiPrefixes = []string{"/autoCode/", "/system/", "/sysExportTemplate/", "/email/"}
+
+// platformApis 修改全部租户共用的角色、菜单、api、字典、参数及签名密钥的接口 租户内仅可读取
+var platformApis = []string{
+	"/api/createApi", "/api/deleteApi", "/api/updateApi", "/api/deleteApisByIds", "/api/syncApi", "/api/enterSyncApi", "/api/ignoreApi",
+	"/authority/copyAuthority", "/authority/createAuthority", "/authority/deleteAuthority", "/authority/updateAuthority",
+	"/authority/setDataAuthority", "/authority/setTwoFactor", "/authority/setMaxSessions", "/authority/setDataScope", "/authority/setFieldRules",
+	"/authorityBtn/setAuthorityBtn", "/authorityBtn/canRemoveAuthorityBtn",
+	"/menu/addBaseMenu", "/menu/deleteBaseMenu", "/menu/updateBaseMenu", "/menu/addMenuAuthority",
+	"/sysDictionary/createSysDictionary", "/sysDictionary/deleteSysDictionary", "/sysDictionary/updateSysDictionary",
+	"/sysDictionaryDetail/createSysDictionaryDetail", "/sysDictionaryDetail/deleteSysDictionaryDetail", "/sysDictionaryDetail/updateSysDictionaryDetail",
+	"/sysParams/createSysParams", "/sysParams/deleteSysParams", "/sysParams/deleteSysParamsByIds", "/sysParams/updateSysParams",
+	"/jwt/rotateKey", "/jwt/getKeyList",
+	"/tenant/createTenant", "/tenant/updateTenant", "/tenant/getTenantList",
+}
+
+// IsPlatformApi 是否为仅在默认租户的casbin域授权的接口 其他租户的管理员不能借此修改共用数据
+func IsPlatformApi(path string) bool {
+	if slices.Contains(platformApis, path) {
+		return true
+	}
+	for _, prefix := range platformApiPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPlatformApi 非默认租户的域内不能授予仅在默认租户授权的接口
+func checkPlatformApi(domain string, path string) error {
+	if domain != strconv.Itoa(int(tenant.Default)) && IsPlatformApi(path) {
+		return ErrPlatformApi
+	}
+	return nil
+}
+
+// IsSuperAdmin 是否为超级管理员 即默认租户中持有超级管理员角色的用户 可创建及切换租户
+func (tenantService *TenantService) IsSuperAdmin(userID, authorityID uint) bool {
+	superAuthorityID := global.GVA_CONFIG.Tenant.SuperAuthorityId
+	if superAuthorityID == 0 {
+		superAuthorityID = 888
+	}
+	return authorityID == superAuthorityID && userTenant(userID) == tenant.Default
+}
+
+// CheckTenant 校验租户存在且已启用 用于登录及切换租户
+func (tenantService *TenantService) CheckTenant(id uint) error {
+	var t system.SysTenant
+	if err := global.GVA_DB.Where("id = ?", id).First(&t).Error; err != nil {
+		return ErrTenantNotFound
+	}
+	if t.Enable != 1 {
+		return errors.New("租户已停用")
+	}
+	return nil
+}
+
+// CreateTenant 创建租户 新租户的casbin域复制默认租户的api权限 仅在默认租户授权的接口除外
+func (tenantService *TenantService) CreateTenant(operator *systemReq.CustomClaims, t system.SysTenant, ip string) (system.SysTenant, error) {
+	if !errors.Is(global.GVA_DB.Where("code = ?", t.Code).First(&system.SysTenant{}).Error, gorm.ErrRecordNotFound) {
+		return t, errors.New("存在相同租户编码")
+	}
+	t.ID = 0
+	if t.Enable == 0 {
+		t.Enable = 1
+	}
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&t).Error; err != nil {
+			return err
+		}
+		var rules []gormadapter.CasbinRule
+		err := tx.Where("ptype = ? AND v3 = ?", "p", strconv.Itoa(int(tenant.Default))).Find(&rules).Error
+		if err != nil || len(rules) == 0 {
+			return err
+		}
+		domain := strconv.Itoa(int(t.ID))
+		rules = slices.DeleteFunc(rules, func(rule gormadapter.CasbinRule) bool {
+			return IsPlatformApi(rule.V1)
+		})
+		for i := range rules {
+			rules[i].ID = 0
+			rules[i].V3 = domain
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rules, 1000).Error
+	})
+	if err != nil {
+		return t, err
+	}
+	if err = CasbinServiceApp.FreshCasbin(); err != nil {
+		global.GVA_LOG.Error("刷新casbin权限失败!", zap.Error(err))
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditTenantCreated,
+		UserID:     operator.BaseClaims.ID,
+		Username:   operator.Username,
+		OperatorID: operator.BaseClaims.ID,
+		Ip:         ip,
+		Detail:     truncateRunes("创建租户"+t.Name+" 编码"+t.Code, 255),
+	})
+	return t, nil
+}
+
+// UpdateTenant 更新租户名称、状态及备注 默认租户不能停用 停用后租户内的用户不能登录及刷新令牌
+func (tenantService *TenantService) UpdateTenant(t system.SysTenant) error {
+	if t.ID == tenant.Default && t.Enable != 1 {
+		return errors.New("默认租户不能停用")
+	}
+	result := global.GVA_DB.Model(&system.SysTenant{}).Where("id = ?", t.ID).
+		Updates(map[string]interface{}{"name": t.Name, "enable": t.Enable, "remark": t.Remark})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
+
+// GetTenantList 分页获取租户
+func (tenantService *TenantService) GetTenantList(info systemReq.TenantSearch) (list []system.SysTenant, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysTenant{})
+	if info.Name != "" {
+		db = db.Where("name LIKE ? OR code LIKE ?", "%"+info.Name+"%", "%"+info.Name+"%")
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Order("id").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+// SwitchTenant 超级管理员切换会话的当前租户 刷新令牌后仍保持切换后的租户
+func (tenantService *TenantService) SwitchTenant(claims *systemReq.CustomClaims, tenantID uint, ip string) error {
+	if claims.Impersonator != nil {
+		return errors.New("模拟登录期间不能切换租户")
+	}
+	if err := tenantService.CheckTenant(tenantID); err != nil {
+		return err
+	}
+	if claims.SessionID != "" {
+		err := global.GVA_DB.Model(&system.SysUserSession{}).Where("session_id = ?", claims.SessionID).
+			Update(tenant.Column, tenantID).Error
+		if err != nil {
+			return err
+		}
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditTenantSwitched,
+		UserID:     claims.BaseClaims.ID,
+		Username:   claims.Username,
+		OperatorID: claims.BaseClaims.ID,
+		Ip:         ip,
+		Detail:     "从租户" + strconv.Itoa(int(claims.TenantId)) + "切换到租户" + strconv.Itoa(int(tenantID)),
+	})
+	return nil
+}
+
+// LoadDefaultTenant 启动时为升级前的数据库创建默认租户 将未区分租户的casbin策略归入默认租户的域 并清除其他租户域内仅在默认租户授权的接口
+func LoadDefaultTenant() {
+	var count int64
+	if err := global.GVA_DB.Model(&system.SysTenant{}).Count(&count).Error; err != nil {
+		global.GVA_LOG.Error("查询租户失败!", zap.Error(err))
+		return
+	}
+	if count == 0 {
+		// 空表中首条记录的ID即为默认租户ID 不指定ID以免自增序列未同步
+		t := system.SysTenant{Name: "默认租户", Code: "default", Enable: 1}
+		if err := global.GVA_DB.Create(&t).Error; err != nil {
+			global.GVA_LOG.Error("创建默认租户失败!", zap.Error(err))
+			return
+		}
+	}
+	result := global.GVA_DB.Model(&gormadapter.CasbinRule{}).
+		Where("ptype = ? AND (v3 = ? OR v3 IS NULL)", "p", "").
+		Update("v3", strconv.Itoa(int(tenant.Default)))
+	if result.Error != nil {
+		global.GVA_LOG.Error("迁移casbin策略到默认租户失败!", zap.Error(result.Error))
+		return
+	}
+	removed, err := removePlatformPolicies(global.GVA_DB)
+	if err != nil {
+		global.GVA_LOG.Error("清除租户域内的系统级接口权限失败!", zap.Error(err))
+		return
+	}
+	if result.RowsAffected == 0 && removed == 0 {
+		return
+	}
+	if err = CasbinServiceApp.FreshCasbin(); err != nil {
+		global.GVA_LOG.Error("刷新casbin权限失败!", zap.Error(err))
+	}
+}
+
+// removePlatformPolicies 删除非默认租户域内仅在默认租户授权的接口的p策略 此方法需要调用FreshCasbin方法才可以在系统中即刻生效
+func removePlatformPolicies(db *gorm.DB) (int, error) {
+	var rules []gormadapter.CasbinRule
+	err := db.Where("ptype = ? AND v3 <> ?", "p", strconv.Itoa(int(tenant.Default))).Find(&rules).Error
+	if err != nil {
+		return 0, err
+	}
+	var ids []uint
+	for i := range rules {
+		if IsPlatformApi(rules[i].V1) {
+			ids = append(ids, rules[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return len(ids), db.Delete(&gormadapter.CasbinRule{}, ids).Error
+}
+
+// RegisterTenant 为数据库注册租户隔离回调
+func RegisterTenant(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if err := tenant.Register(db); err != nil {
+		global.GVA_LOG.Error("注册租户隔离回调失败!", zap.Error(err))
+	}
+}
@@ -0,0 +1,86 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+func TestTenantService_CreateTenantPolicies(t *testing.T) {
+	setupTestDB(t)
+	setupTestCasbin(t,
+		[]string{"888", "/user/getUserList", "POST", "1"},
+		[]string{"888", "/authority/createAuthority", "POST", "1"},
+		[]string{"888", "/menu/getMenu", "POST", "1"},
+		[]string{"888", "/sysParams/updateSysParams", "PUT", "1"},
+		[]string{"888", "/autoCode/createTemp", "POST", "1"},
+	)
+	operator := &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{ID: 1, Username: "admin", AuthorityId: 888}}
+	created, err := TenantServiceApp.CreateTenant(operator, system.SysTenant{Name: "租户2", Code: "t2"}, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新租户只复制租户内可授权的接口 共用数据的管理接口仅保留在默认租户
+	domain := tenant.Domain(tenant.WithTenant(context.Background(), created.ID))
+	e := CasbinServiceApp.Casbin()
+	tests := []struct {
+		path, method string
+		want         bool
+	}{
+		{"/user/getUserList", "POST", true},
+		{"/menu/getMenu", "POST", true},
+		{"/authority/createAuthority", "POST", false},
+		{"/sysParams/updateSysParams", "PUT", false},
+		{"/autoCode/createTemp", "POST", false},
+	}
+	for _, tt := range tests {
+		if ok, _ := e.Enforce("888", tt.path, tt.method, domain); ok != tt.want {
+			t.Errorf("Enforce(%s) in new tenant = %v, want %v", tt.path, ok, tt.want)
+		}
+		if ok, _ := e.Enforce("888", tt.path, tt.method, "1"); !ok {
+			t.Errorf("Enforce(%s) in default tenant = false, want true", tt.path)
+		}
+	}
+
+	// 租户内不能重新授予这些接口
+	ctx := tenant.WithTenant(context.Background(), created.ID)
+	err = CasbinServiceApp.UpdateCasbin(ctx, 888, 888, []systemReq.CasbinInfo{{Path: "/authority/deleteAuthority", Method: "POST"}})
+	if !errors.Is(err, ErrPlatformApi) {
+		t.Errorf("UpdateCasbin() error = %v, want ErrPlatformApi", err)
+	}
+}
+
+func TestRemovePlatformPolicies(t *testing.T) {
+	setupTestDB(t)
+	setupTestCasbin(t,
+		[]string{"888", "/authority/createAuthority", "POST", "1"},
+		[]string{"888", "/authority/createAuthority", "POST", "2"},
+		[]string{"888", "/user/getUserList", "POST", "2"},
+	)
+	removed, err := removePlatformPolicies(global.GVA_DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("removePlatformPolicies() = %d, want 1", removed)
+	}
+	if err = CasbinServiceApp.FreshCasbin(); err != nil {
+		t.Fatal(err)
+	}
+	e := CasbinServiceApp.Casbin()
+	if ok, _ := e.Enforce("888", "/authority/createAuthority", "POST", "2"); ok {
+		t.Error("platform api should be removed from other tenants")
+	}
+	if ok, _ := e.Enforce("888", "/authority/createAuthority", "POST", "1"); !ok {
+		t.Error("platform api should stay in the default tenant")
+	}
+	if ok, _ := e.Enforce("888", "/user/getUserList", "POST", "2"); !ok {
+		t.Error("tenant api should stay in other tenants")
+	}
+}
@@ -1,94 +1,130 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common"
 	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"slices"
 	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: Register
 //@description: 用户注册
-//@param: u model.SysUser
+//@param: ctx context.Context, u model.SysUser
 //@return: userInter system.SysUser, err error
 
 type UserService struct{}
 
 var UserServiceApp = new(UserService)
 
-func (userService *UserService) Register(u system.SysUser) (userInter system.SysUser, err error) {
+func (userService *UserService) Register(ctx context.Context, u system.SysUser) (userInter system.SysUser, err error) {
 	var user system.SysUser
-	if !errors.Is(global.GVA_DB.Where("username = ?", u.Username).First(&user).Error, gorm.ErrRecordNotFound) { // 判断用户名是否注册
+	if !errors.Is(tenant.Skip(global.GVA_DB).Where("username = ?", u.Username).First(&user).Error, gorm.ErrRecordNotFound) { // 判断用户名是否注册 用户名在全部租户内唯一
 		return userInter, errors.New("用户名已注册")
 	}
+	if err = utils.CheckPasswordPolicy(global.GVA_CONFIG.Password, u.Username, u.Password); err != nil {
+		return userInter, err
+	}
 	// 否则 附加uuid 密码hash加密 注册
+	now := time.Now()
 	u.Password = utils.BcryptHash(u.Password)
+	u.PasswordChangedAt = &now
 	u.UUID = uuid.New()
-	err = global.GVA_DB.Create(&u).Error
+	// 用户名全局唯一 用户归属当前租户
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&u).Error; err != nil {
+			return err
+		}
+		return recordPasswordHistory(tx, u.ID, u.Password)
+	})
 	return u, err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@author: [SliverHorn](https://github.com/SliverHorn)
 //@function: Login
-//@description: 用户登录
+//@description: 用户登录 依次尝试已注册的认证方式 最后使用本地账号密码 external为通过外部认证方式登录
 //@param: u *model.SysUser
-//@return: err error, userInter *model.SysUser
+//@return: err error, userInter *model.SysUser, external bool
 
-func (userService *UserService) Login(u *system.SysUser) (userInter *system.SysUser, err error) {
+func (userService *UserService) Login(u *system.SysUser) (userInter *system.SysUser, external bool, err error) {
 	if nil == global.GVA_DB {
-		return nil, fmt.Errorf("db not init")
+		return nil, false, fmt.Errorf("db not init")
+	}
+
+	for _, a := range authenticators {
+		user, err := a.Authenticate(u.Username, u.Password)
+		if err == nil {
+			return user, true, nil
+		}
+		if !errors.Is(err, ErrAuthenticatorSkip) {
+			global.GVA_LOG.Info("外部认证失败，尝试本地账号登录", zap.String("username", u.Username), zap.Error(err))
+		}
 	}
 
 	var user system.SysUser
-	err = global.GVA_DB.Where("username = ?", u.Username).Preload("Authorities").Preload("Authority").First(&user).Error
+	// 登录前尚未确定租户 按用户名查询后由用户所属租户决定
+	err = tenant.Skip(global.GVA_DB).Where("username = ?", u.Username).Preload("Authorities").Preload("Authority").First(&user).Error
 	if err == nil {
 		if ok := utils.BcryptCheck(u.Password, user.Password); !ok {
-			return nil, errors.New("密码错误")
+			return nil, false, errors.New("密码错误")
 		}
 		MenuServiceApp.UserAuthorityDefaultRouter(&user)
 	}
-	return &user, err
+	return &user, false, err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: ChangePassword
-//@description: 修改用户密码
-//@param: u *model.SysUser, newPassword string
+//@description: 修改用户密码 注销当前会话以外的全部会话并使已签发的令牌失效
+//@param: u *model.SysUser, newPassword string, sessionID string
 //@return: userInter *model.SysUser,err error
 
-func (userService *UserService) ChangePassword(u *system.SysUser, newPassword string) (userInter *system.SysUser, err error) {
+func (userService *UserService) ChangePassword(u *system.SysUser, newPassword string, sessionID string) (userInter *system.SysUser, err error) {
 	var user system.SysUser
-	if err = global.GVA_DB.Where("id = ?", u.ID).First(&user).Error; err != nil {
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", u.ID).First(&user).Error; err != nil {
 		return nil, err
 	}
 	if ok := utils.BcryptCheck(u.Password, user.Password); !ok {
 		return nil, errors.New("原密码错误")
 	}
-	user.Password = utils.BcryptHash(newPassword)
-	err = global.GVA_DB.Save(&user).Error
-	return &user, err
-
+	if err = userService.CheckPassword(&user, newPassword); err != nil {
+		return nil, err
+	}
+	err = tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		if err := userService.setPassword(tx, &user, newPassword, false); err != nil {
+			return err
+		}
+		return userService.bumpSecurityVersion(tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	userService.refreshSecurityVersion(user.ID)
+	return &user, SessionServiceApp.RevokeOtherSessions(user.ID, sessionID)
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: GetUserInfoList
 //@description: 分页获取数据
-//@param: info request.PageInfo
+//@param: ctx context.Context, info request.PageInfo
 //@return: err error, list interface{}, total int64
 
-func (userService *UserService) GetUserInfoList(info systemReq.GetUserList) (list interface{}, total int64, err error) {
+func (userService *UserService) GetUserInfoList(ctx context.Context, info systemReq.GetUserList) (list interface{}, total int64, err error) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
-	db := global.GVA_DB.Model(&system.SysUser{})
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysUser{})
 	var userList []system.SysUser
 
 	if info.NickName != "" {
@@ -103,12 +139,21 @@ func (userService *UserService) GetUserInfoList(info systemReq.GetUserList) (lis
 	if info.Email != "" {
 		db = db.Where("email LIKE ?", "%"+info.Email+"%")
 	}
+	if info.DeptId != 0 {
+		deptIDs := []uint{info.DeptId}
+		if info.WithSub {
+			if deptIDs, err = DeptServiceApp.DescendantIDs(info.DeptId); err != nil {
+				return
+			}
+		}
+		db = db.Where("id IN (?)", global.GVA_DB.Model(&system.SysUserDept{}).Select("sys_user_id").Where("sys_dept_id IN ?", deptIDs))
+	}
 
 	err = db.Count(&total).Error
 	if err != nil {
 		return
 	}
-	err = db.Limit(limit).Offset(offset).Preload("Authorities").Preload("Authority").Find(&userList).Error
+	err = db.Limit(limit).Offset(offset).Preload("Authorities").Preload("Authority").Preload("Depts.Dept").Find(&userList).Error
 	return userList, total, err
 }
 
@@ -120,9 +165,9 @@ func (userService *UserService) GetUserInfoList(info systemReq.GetUserList) (lis
 
 func (userService *UserService) SetUserAuthority(id uint, authorityId uint) (err error) {
 
-	assignErr := global.GVA_DB.Where("sys_user_id = ? AND sys_authority_authority_id = ?", id, authorityId).First(&system.SysUserAuthority{}).Error
+	assignErr := global.GVA_DB.Scopes(activeGrantScope(time.Now())).Where("sys_user_id = ? AND sys_authority_authority_id = ?", id, authorityId).First(&system.SysUserAuthority{}).Error
 	if errors.Is(assignErr, gorm.ErrRecordNotFound) {
-		return errors.New("该用户无此角色")
+		return errors.New("该用户无此角色或角色不在有效期内")
 	}
 
 	var authority system.SysAuthority
@@ -157,25 +202,41 @@ func (userService *UserService) SetUserAuthority(id uint, authorityId uint) (err
 		return errors.New("找不到默认路由,无法切换本角色")
 	}
 
-	err = global.GVA_DB.Model(&system.SysUser{}).Where("id = ?", id).Update("authority_id", authorityId).Error
-	return err
+	// 切换的是当前登录用户自己的角色 超级管理员切换租户后本人仍属于原租户
+	err = tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&system.SysUser{}).Where("id = ?", id).Update("authority_id", authorityId).Error; err != nil {
+			return err
+		}
+		return userService.bumpSecurityVersion(tx, id)
+	})
+	if err != nil {
+		return err
+	}
+	userService.refreshSecurityVersion(id)
+	return nil
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: SetUserAuthorities
 //@description: 设置一个用户的权限
-//@param: id uint, authorityIds []string
+//@param: ctx context.Context, id uint, authorityIds []string
 //@return: err error
 
-func (userService *UserService) SetUserAuthorities(adminAuthorityID, id uint, authorityIds []uint) (err error) {
-	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+func (userService *UserService) SetUserAuthorities(ctx context.Context, adminAuthorityID, id uint, authorityIds []uint) (err error) {
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var user system.SysUser
 		TxErr := tx.Where("id = ?", id).First(&user).Error
 		if TxErr != nil {
 			global.GVA_LOG.Debug(TxErr.Error())
 			return errors.New("查询用户数据失败")
 		}
-		TxErr = tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ?", id).Error
+		// 限时角色由授权接口单独维护 此处仅替换长期角色
+		TxErr = tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ? AND valid_until IS NULL", id).Error
+		if TxErr != nil {
+			return TxErr
+		}
+		var grantedIds []uint
+		TxErr = tx.Model(&system.SysUserAuthority{}).Where("sys_user_id = ?", id).Pluck("sys_authority_authority_id", &grantedIds).Error
 		if TxErr != nil {
 			return TxErr
 		}
@@ -185,59 +246,145 @@ func (userService *UserService) SetUserAuthorities(adminAuthorityID, id uint, au
 			if e != nil {
 				return e
 			}
+			if slices.Contains(grantedIds, v) {
+				continue
+			}
 			useAuthority = append(useAuthority, system.SysUserAuthority{
 				SysUserId: id, SysAuthorityAuthorityId: v,
 			})
 		}
-		TxErr = tx.Create(&useAuthority).Error
+		if len(useAuthority) > 0 {
+			TxErr = tx.Create(&useAuthority).Error
+			if TxErr != nil {
+				return TxErr
+			}
+		}
+		TxErr = tx.Model(&user).Update("authority_id", authorityIds[0]).Error
 		if TxErr != nil {
 			return TxErr
 		}
-		TxErr = tx.Model(&user).Update("authority_id", authorityIds[0]).Error
+		TxErr = userService.bumpSecurityVersion(tx, id)
 		if TxErr != nil {
 			return TxErr
 		}
 		// 返回 nil 提交事务
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	userService.refreshSecurityVersion(id)
+	return nil
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: DeleteUser
 //@description: 删除用户
-//@param: id float64
+//@param: ctx context.Context, id float64
 //@return: err error
 
-func (userService *UserService) DeleteUser(id int) (err error) {
-	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+func (userService *UserService) DeleteUser(ctx context.Context, id int) (err error) {
+	if _, err = userService.tenantUser(ctx, uint(id)); err != nil {
+		return err
+	}
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := userService.bumpSecurityVersion(tx, uint(id)); err != nil {
+			return err
+		}
 		if err := tx.Where("id = ?", id).Delete(&system.SysUser{}).Error; err != nil {
 			return err
 		}
 		if err := tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ?", id).Error; err != nil {
 			return err
 		}
+		if err := tx.Delete(&[]system.SysUserDept{}, "sys_user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&system.SysDept{}).Where("leader_id = ?", id).Update("leader_id", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&[]system.SysUserIdentity{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&[]system.SysRefreshToken{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&[]system.SysUserPasswordHistory{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&[]system.SysApiKey{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&[]system.SysUserRegistration{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&[]system.SysUserPasskey{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	userService.refreshSecurityVersion(uint(id))
+	// 注销该用户的全部登录会话
+	return SessionServiceApp.RevokeUserSessions(uint(id))
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: SetUserInfo
-//@description: 设置用户信息
-//@param: reqUser model.SysUser
+//@description: 设置用户信息 传入密码时按密码策略校验 用户下次登录时必须修改 操作者角色受限的字段不会被修改
+//@param: ctx context.Context, adminAuthorityID uint, reqUser model.SysUser
 //@return: err error, user model.SysUser
 
-func (userService *UserService) SetUserInfo(req system.SysUser) error {
-	return global.GVA_DB.Model(&system.SysUser{}).
-		Select("updated_at", "nick_name", "header_img", "phone", "email", "enable").
-		Where("id=?", req.ID).
-		Updates(map[string]interface{}{
-			"updated_at": time.Now(),
-			"nick_name":  req.NickName,
-			"header_img": req.HeaderImg,
-			"phone":      req.Phone,
-			"email":      req.Email,
-			"enable":     req.Enable,
-		}).Error
+func (userService *UserService) SetUserInfo(ctx context.Context, adminAuthorityID uint, req system.SysUser) error {
+	user, err := userService.tenantUser(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if req.Password != "" {
+		if err := userService.CheckPassword(&user, req.Password); err != nil {
+			return err
+		}
+	}
+	var revoke bool
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Scopes(AuthorityFieldRuleServiceApp.Protect(adminAuthorityID)).Model(&system.SysUser{}).
+			Select("updated_at", "nick_name", "header_img", "phone", "email", "enable").
+			Where("id=?", req.ID).
+			Updates(map[string]interface{}{
+				"updated_at": time.Now(),
+				"nick_name":  req.NickName,
+				"header_img": req.HeaderImg,
+				"phone":      req.Phone,
+				"email":      req.Email,
+				"enable":     req.Enable,
+			}).Error
+		if err != nil {
+			return err
+		}
+		// 仅在启用状态或密码变更时使已签发的令牌失效 修改昵称、头像等资料不影响登录状态
+		var updated system.SysUser
+		if err = tx.Select("id", "enable").Where("id = ?", req.ID).First(&updated).Error; err != nil {
+			return err
+		}
+		revoke = updated.Enable != user.Enable || req.Password != ""
+		if !revoke {
+			return nil
+		}
+		if err = userService.bumpSecurityVersion(tx, req.ID); err != nil || req.Password == "" {
+			return err
+		}
+		return userService.setPassword(tx, &user, req.Password, true)
+	})
+	if err != nil || !revoke {
+		return err
+	}
+	userService.refreshSecurityVersion(req.ID)
+	if req.Password != "" {
+		return SessionServiceApp.RevokeUserSessions(req.ID)
+	}
+	return nil
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -247,7 +394,7 @@ func (userService *UserService) SetUserInfo(req system.SysUser) error {
 //@return: err error, user model.SysUser
 
 func (userService *UserService) SetSelfInfo(req system.SysUser) error {
-	return global.GVA_DB.Model(&system.SysUser{}).
+	return tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).
 		Where("id=?", req.ID).
 		Updates(req).Error
 }
@@ -259,7 +406,7 @@ func (userService *UserService) SetSelfInfo(req system.SysUser) error {
 //@return: err error
 
 func (userService *UserService) SetSelfSetting(req common.JSONMap, uid uint) error {
-	return global.GVA_DB.Model(&system.SysUser{}).Where("id = ?", uid).Update("origin_setting", req).Error
+	return tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", uid).Update("origin_setting", req).Error
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -271,7 +418,7 @@ func (userService *UserService) SetSelfSetting(req common.JSONMap, uid uint) err
 
 func (userService *UserService) GetUserInfo(uuid uuid.UUID) (user system.SysUser, err error) {
 	var reqUser system.SysUser
-	err = global.GVA_DB.Preload("Authorities").Preload("Authority").First(&reqUser, "uuid = ?", uuid).Error
+	err = tenant.Skip(global.GVA_DB).Preload("Authorities").Preload("Authority").First(&reqUser, "uuid = ?", uuid).Error
 	if err != nil {
 		return reqUser, err
 	}
@@ -287,7 +434,7 @@ func (userService *UserService) GetUserInfo(uuid uuid.UUID) (user system.SysUser
 
 func (userService *UserService) FindUserById(id int) (user *system.SysUser, err error) {
 	var u system.SysUser
-	err = global.GVA_DB.Where("id = ?", id).First(&u).Error
+	err = tenant.Skip(global.GVA_DB).Where("id = ?", id).First(&u).Error
 	return &u, err
 }
 
@@ -299,7 +446,7 @@ func (userService *UserService) FindUserById(id int) (user *system.SysUser, err
 
 func (userService *UserService) FindUserByUuid(uuid string) (user *system.SysUser, err error) {
 	var u system.SysUser
-	if err = global.GVA_DB.Where("uuid = ?", uuid).First(&u).Error; err != nil {
+	if err = tenant.Skip(global.GVA_DB).Where("uuid = ?", uuid).First(&u).Error; err != nil {
 		return &u, errors.New("用户不存在")
 	}
 	return &u, nil
@@ -307,11 +454,42 @@ func (userService *UserService) FindUserByUuid(uuid string) (user *system.SysUse
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: ResetPassword
-//@description: 修改用户密码
-//@param: ID uint
-//@return: err error
+//@description: 重置用户密码为一次性随机密码 用户下次登录时必须修改
+//@param: ctx context.Context, ID uint
+//@return: password string, err error
 
-func (userService *UserService) ResetPassword(ID uint) (err error) {
-	err = global.GVA_DB.Model(&system.SysUser{}).Where("id = ?", ID).Update("password", utils.BcryptHash("123456")).Error
-	return err
+func (userService *UserService) ResetPassword(ctx context.Context, ID uint) (password string, err error) {
+	user, err := userService.tenantUser(ctx, ID)
+	if err != nil {
+		return "", err
+	}
+	conf := global.GVA_CONFIG.Password
+	// 随机密码可能恰好包含较短的用户名 重新生成即可
+	for i := 0; i < 10; i++ {
+		if password, err = utils.RandomPassword(conf); err != nil {
+			return "", err
+		}
+		if err = utils.CheckPasswordPolicy(conf, user.Username, password); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return userService.setPassword(tx, &user, password, true)
+	})
+	if err != nil {
+		return "", err
+	}
+	// 重置后原有登录会话全部失效
+	return password, SessionServiceApp.RevokeUserSessions(ID)
+}
+
+// tenantUser 获取当前租户下的用户 其他租户的用户视为不存在
+func (userService *UserService) tenantUser(ctx context.Context, id uint) (user system.SysUser, err error) {
+	if err = global.GVA_DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		return user, errors.New("用户不存在")
+	}
+	return user, nil
 }
@@ -0,0 +1,23 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+)
+
+// Authenticator 用户名密码登录的认证方式
+type Authenticator interface {
+	// Authenticate 认证通过时返回系统用户 未启用时返回 ErrAuthenticatorSkip
+	Authenticate(username string, password string) (*system.SysUser, error)
+}
+
+// ErrAuthenticatorSkip 认证方式未启用 交由下一个认证方式处理
+var ErrAuthenticatorSkip = errors.New("authenticator skipped")
+
+var authenticators []Authenticator
+
+// RegisterAuthenticator 注册登录认证方式 登录时按注册顺序依次尝试 全部失败后使用本地账号密码认证
+func RegisterAuthenticator(a Authenticator) {
+	authenticators = append(authenticators, a)
+}
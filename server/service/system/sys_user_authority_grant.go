@@ -0,0 +1,285 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	emailUtils "github.com/flipped-aurora/gin-vue-admin/server/plugin/email/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var ErrNoActiveAuthority = errors.New("用户没有处于有效期内的角色")
+
+// activeGrantScope 仅保留处于有效期内的角色授权
+func activeGrantScope(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(valid_from IS NULL OR valid_from <= ?) AND (valid_until IS NULL OR valid_until > ?)", now, now)
+	}
+}
+
+// GrantAuthority 授予用户限时角色 已有该角色的限时授权时更新有效期 开启权限变更审批时不可用
+func (userService *UserService) GrantAuthority(ctx context.Context, adminAuthorityID, operatorID uint, req systemReq.GrantUserAuthority, ip string) (grant system.SysUserAuthority, err error) {
+	if err = checkApprovalDisabled(); err != nil {
+		return grant, err
+	}
+	if err = AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, req.AuthorityId); err != nil {
+		return grant, err
+	}
+	if !req.ValidUntil.After(time.Now()) {
+		return grant, errors.New("失效时间必须晚于当前时间")
+	}
+	if req.ValidFrom != nil && !req.ValidFrom.Before(req.ValidUntil) {
+		return grant, errors.New("生效时间必须早于失效时间")
+	}
+	user, err := userService.tenantUser(ctx, req.ID)
+	if err != nil {
+		return grant, err
+	}
+	var authority system.SysAuthority
+	if err = global.GVA_DB.Where("authority_id = ?", req.AuthorityId).First(&authority).Error; err != nil {
+		return grant, errors.New("角色不存在")
+	}
+
+	grant = system.SysUserAuthority{
+		SysUserId:               req.ID,
+		SysAuthorityAuthorityId: req.AuthorityId,
+		ValidFrom:               req.ValidFrom,
+		ValidUntil:              &req.ValidUntil,
+		GrantedBy:               operatorID,
+		Reason:                  truncateRunes(req.Reason, 255),
+	}
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var existing system.SysUserAuthority
+		err := tx.Where("sys_user_id = ? AND sys_authority_authority_id = ?", req.ID, req.AuthorityId).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&grant).Error
+		}
+		if err != nil {
+			return err
+		}
+		if existing.ValidUntil == nil {
+			return errors.New("用户已长期拥有该角色")
+		}
+		return tx.Model(&system.SysUserAuthority{}).
+			Where("sys_user_id = ? AND sys_authority_authority_id = ?", req.ID, req.AuthorityId).
+			Updates(map[string]interface{}{
+				"valid_from":  grant.ValidFrom,
+				"valid_until": grant.ValidUntil,
+				"granted_by":  grant.GrantedBy,
+				"reason":      grant.Reason,
+				"notified":    false,
+			}).Error
+	})
+	if err != nil {
+		return grant, err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditRoleGranted,
+		UserID:     user.ID,
+		Username:   user.Username,
+		OperatorID: operatorID,
+		Ip:         ip,
+		Detail:     truncateRunes(fmt.Sprintf("授予角色%s(%d) 有效期至%s %s", authority.AuthorityName, authority.AuthorityId, req.ValidUntil.Format(time.DateTime), grant.Reason), 255),
+	})
+	return grant, nil
+}
+
+// RevokeAuthorityGrant 提前收回用户的限时角色 长期角色需通过设置用户角色移除
+func (userService *UserService) RevokeAuthorityGrant(ctx context.Context, adminAuthorityID, operatorID uint, userID, authorityID uint, ip string) error {
+	if err := AuthorityServiceApp.CheckAuthorityIDAuth(adminAuthorityID, authorityID); err != nil {
+		return err
+	}
+	if _, err := userService.tenantUser(ctx, userID); err != nil {
+		return err
+	}
+	result := global.GVA_DB.Where("sys_user_id = ? AND sys_authority_authority_id = ? AND valid_until IS NOT NULL", userID, authorityID).
+		Delete(&system.SysUserAuthority{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该用户没有此限时角色")
+	}
+	username := userService.grantRemoved(userID, authorityID)
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditRoleRevoked,
+		UserID:     userID,
+		Username:   username,
+		OperatorID: operatorID,
+		Ip:         ip,
+		Detail:     fmt.Sprintf("收回角色%d", authorityID),
+	})
+	return nil
+}
+
+// GetAuthorityGrants 获取当前租户用户全部的角色授权及有效期
+func (userService *UserService) GetAuthorityGrants(ctx context.Context, userID uint) (list []system.SysUserAuthority, err error) {
+	if _, err = userService.tenantUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	err = global.GVA_DB.Where("sys_user_id = ?", userID).Order("sys_authority_authority_id").Find(&list).Error
+	return list, err
+}
+
+// ApplyAuthorityGrants 剔除用户不在有效期内的角色 当前角色失效时切换到其他有效角色 优先选择长期角色 没有有效角色时返回ErrNoActiveAuthority
+func (userService *UserService) ApplyAuthorityGrants(user *system.SysUser) error {
+	var grants []system.SysUserAuthority
+	err := global.GVA_DB.Scopes(activeGrantScope(time.Now())).Where("sys_user_id = ?", user.ID).Find(&grants).Error
+	if err != nil {
+		return err
+	}
+	active := make(map[uint]bool, len(grants))
+	for i := range grants {
+		active[grants[i].SysAuthorityAuthorityId] = true
+	}
+	authorities := make([]system.SysAuthority, 0, len(user.Authorities))
+	for i := range user.Authorities {
+		if active[user.Authorities[i].AuthorityId] {
+			authorities = append(authorities, user.Authorities[i])
+		}
+	}
+	user.Authorities = authorities
+	if active[user.AuthorityId] {
+		return nil
+	}
+	next := pickAuthority(grants)
+	if next == 0 {
+		return ErrNoActiveAuthority
+	}
+	if err = userService.switchAuthority(user.ID, next); err != nil {
+		return err
+	}
+	for i := range authorities {
+		if authorities[i].AuthorityId == next {
+			user.Authority = authorities[i]
+		}
+	}
+	user.AuthorityId = next
+	if version, err := userService.loadSecurityVersion(user.ID); err == nil && version >= 0 {
+		user.SecurityVersion = uint(version)
+	}
+	MenuServiceApp.UserAuthorityDefaultRouter(user)
+	return nil
+}
+
+// ExpireAuthorityGrants 向即将到期的限时角色发送提醒 并收回已到期的授权 由定时任务调用
+func (userService *UserService) ExpireAuthorityGrants() error {
+	now := time.Now()
+	if before := global.GVA_CONFIG.AuthorityGrant.NotifyBefore; before > 0 {
+		var expiring []system.SysUserAuthority
+		err := global.GVA_DB.Where("notified = ? AND valid_until > ? AND valid_until <= ?", false, now, now.Add(time.Duration(before)*time.Minute)).
+			Find(&expiring).Error
+		if err != nil {
+			return err
+		}
+		for i := range expiring {
+			// 以条件更新保证多实例部署时只发送一次
+			result := global.GVA_DB.Model(&system.SysUserAuthority{}).
+				Where("sys_user_id = ? AND sys_authority_authority_id = ? AND notified = ?", expiring[i].SysUserId, expiring[i].SysAuthorityAuthorityId, false).
+				Update("notified", true)
+			if result.Error == nil && result.RowsAffected == 1 {
+				notifyAuthorityExpiring(expiring[i])
+			}
+		}
+	}
+
+	var expired []system.SysUserAuthority
+	if err := global.GVA_DB.Where("valid_until <= ?", now).Find(&expired).Error; err != nil {
+		return err
+	}
+	for i := range expired {
+		result := global.GVA_DB.Where("sys_user_id = ? AND sys_authority_authority_id = ? AND valid_until <= ?", expired[i].SysUserId, expired[i].SysAuthorityAuthorityId, now).
+			Delete(&system.SysUserAuthority{})
+		if result.Error != nil {
+			global.GVA_LOG.Error("收回到期角色失败!", zap.Uint("userId", expired[i].SysUserId), zap.Error(result.Error))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		username := userService.grantRemoved(expired[i].SysUserId, expired[i].SysAuthorityAuthorityId)
+		AuditLogServiceApp.Record(system.SysAuditLog{
+			Action:   system.AuditRoleExpired,
+			UserID:   expired[i].SysUserId,
+			Username: username,
+			Detail:   fmt.Sprintf("角色%d到期", expired[i].SysAuthorityAuthorityId),
+		})
+	}
+	return nil
+}
+
+// grantRemoved 角色授权移除后 若其为用户的当前角色则切换到其他有效角色并使已签发的令牌失效 没有有效角色时清空当前角色
+func (userService *UserService) grantRemoved(userID, authorityID uint) (username string) {
+	var user system.SysUser
+	if err := tenant.Skip(global.GVA_DB).Select("id", "username", "authority_id").Where("id = ?", userID).First(&user).Error; err != nil {
+		return ""
+	}
+	if user.AuthorityId != authorityID {
+		return user.Username
+	}
+	var grants []system.SysUserAuthority
+	err := global.GVA_DB.Scopes(activeGrantScope(time.Now())).Where("sys_user_id = ?", userID).Find(&grants).Error
+	if err == nil {
+		err = userService.switchAuthority(userID, pickAuthority(grants))
+	}
+	if err != nil {
+		global.GVA_LOG.Error("切换用户角色失败!", zap.Uint("userId", userID), zap.Error(err))
+	}
+	return user.Username
+}
+
+// switchAuthority 切换用户的当前角色并递增安全版本号 authorityID为0时清空当前角色 用户在被重新授予角色前无法登录
+func (userService *UserService) switchAuthority(userID, authorityID uint) error {
+	err := tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&system.SysUser{}).Where("id = ?", userID).Update("authority_id", authorityID).Error; err != nil {
+			return err
+		}
+		return userService.bumpSecurityVersion(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+	userService.refreshSecurityVersion(userID)
+	return nil
+}
+
+// pickAuthority 从有效授权中选择角色 优先选择长期角色 没有时返回0
+func pickAuthority(grants []system.SysUserAuthority) uint {
+	var next uint
+	for i := range grants {
+		if grants[i].ValidUntil == nil {
+			return grants[i].SysAuthorityAuthorityId
+		}
+		if next == 0 {
+			next = grants[i].SysAuthorityAuthorityId
+		}
+	}
+	return next
+}
+
+// notifyAuthorityExpiring 邮件提醒用户限时角色即将到期 用户未设置邮箱时跳过
+func notifyAuthorityExpiring(grant system.SysUserAuthority) {
+	var user system.SysUser
+	if err := tenant.Skip(global.GVA_DB).Select("id", "username", "nick_name", "email").Where("id = ?", grant.SysUserId).First(&user).Error; err != nil || user.Email == "" {
+		return
+	}
+	var authority system.SysAuthority
+	if err := global.GVA_DB.Where("authority_id = ?", grant.SysAuthorityAuthorityId).First(&authority).Error; err != nil {
+		return
+	}
+	body := fmt.Sprintf(`<p>%s，您好：</p><p>您被临时授予的角色「%s」将于%s到期，到期后将自动收回。如需继续使用请联系管理员续期。</p>`,
+		html.EscapeString(user.NickName), html.EscapeString(authority.AuthorityName), grant.ValidUntil.Format(time.DateTime))
+	go func() {
+		if err := emailUtils.Email(user.Email, "角色即将到期", body); err != nil {
+			global.GVA_LOG.Error("发送角色到期提醒邮件失败!", zap.String("username", user.Username), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,158 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// createTestGrant 授予用户限时角色
+func createTestGrant(t *testing.T, userID, authorityID uint, validUntil time.Time) {
+	t.Helper()
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	global.GVA_DB.FirstOrCreate(&system.SysAuthority{AuthorityId: authorityID}, "authority_id = ?", authorityID)
+	_, err := UserServiceApp.GrantAuthority(ctx, 888, 1, systemReq.GrantUserAuthority{ID: userID, AuthorityId: authorityID, ValidUntil: validUntil}, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expireGrant 将限时角色的失效时间提前到过去 模拟授权到期
+func expireGrant(t *testing.T, userID, authorityID uint) {
+	t.Helper()
+	err := global.GVA_DB.Model(&system.SysUserAuthority{}).
+		Where("sys_user_id = ? AND sys_authority_authority_id = ?", userID, authorityID).
+		Update("valid_until", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadTestUser(t *testing.T, id uint) system.SysUser {
+	t.Helper()
+	var user system.SysUser
+	if err := tenant.Skip(global.GVA_DB).Preload("Authorities").Preload("Authority").Where("id = ?", id).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestUserService_GrantAuthority(t *testing.T) {
+	setupTestDB(t)
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	user := createTestUser(t, "alice", 888)
+	global.GVA_DB.Create(&system.SysAuthority{AuthorityId: 9528})
+
+	req := systemReq.GrantUserAuthority{ID: user.ID, AuthorityId: 888, ValidUntil: time.Now().Add(time.Hour)}
+	if _, err := UserServiceApp.GrantAuthority(ctx, 888, 1, req, "127.0.0.1"); err == nil {
+		t.Error("GrantAuthority() should reject a permanent role")
+	}
+	req.AuthorityId = 9528
+	req.ValidUntil = time.Now().Add(-time.Minute)
+	if _, err := UserServiceApp.GrantAuthority(ctx, 888, 1, req, "127.0.0.1"); err == nil {
+		t.Error("GrantAuthority() should reject a past expiry")
+	}
+	req.ValidUntil = time.Now().Add(time.Hour)
+	req.ID = 999
+	if _, err := UserServiceApp.GrantAuthority(ctx, 888, 1, req, "127.0.0.1"); err == nil {
+		t.Error("GrantAuthority() should reject unknown user")
+	}
+	req.ID = user.ID
+	if _, err := UserServiceApp.GrantAuthority(ctx, 888, 1, req, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	// 重复授予时更新有效期
+	req.ValidUntil = time.Now().Add(2 * time.Hour)
+	if _, err := UserServiceApp.GrantAuthority(ctx, 888, 1, req, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := UserServiceApp.GetAuthorityGrants(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[1].ValidUntil == nil || time.Until(*list[1].ValidUntil) <= time.Hour {
+		t.Errorf("GetAuthorityGrants() = %+v, want renewed grant", list)
+	}
+
+	// 不能查看或撤销其他租户用户的授权
+	moveTestUserTenant(t, user.ID, 2)
+	if _, err = UserServiceApp.GetAuthorityGrants(ctx, user.ID); err == nil {
+		t.Error("GetAuthorityGrants() should reject user of other tenant")
+	}
+	if err = UserServiceApp.RevokeAuthorityGrant(ctx, 888, 1, user.ID, 9528, "127.0.0.1"); err == nil {
+		t.Error("RevokeAuthorityGrant() should reject user of other tenant")
+	}
+}
+
+func TestUserService_ApplyAuthorityGrants(t *testing.T) {
+	setupTestDB(t, &system.SysBaseMenu{}, &system.SysAuthorityMenu{})
+	alice := createTestUser(t, "alice", 888)
+	createTestGrant(t, alice.ID, 9528, time.Now().Add(time.Hour))
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", alice.ID).Update("authority_id", 9528)
+	expireGrant(t, alice.ID, 9528)
+
+	// 当前的限时角色到期后切换到长期角色
+	user := loadTestUser(t, alice.ID)
+	if err := UserServiceApp.ApplyAuthorityGrants(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.AuthorityId != 888 || len(user.Authorities) != 1 {
+		t.Errorf("ApplyAuthorityGrants() authority = %d authorities = %d, want 888 and 1", user.AuthorityId, len(user.Authorities))
+	}
+	if loadTestUser(t, alice.ID).AuthorityId != 888 {
+		t.Error("ApplyAuthorityGrants() should persist the switched authority")
+	}
+
+	// 仅有的限时角色到期后拒绝登录 即使未加载多角色关系
+	bob := createTestUser(t, "bob")
+	createTestGrant(t, bob.ID, 9528, time.Now().Add(time.Hour))
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", bob.ID).Update("authority_id", 9528)
+	expireGrant(t, bob.ID, 9528)
+	user = system.SysUser{AuthorityId: 9528}
+	user.ID = bob.ID
+	if err := UserServiceApp.ApplyAuthorityGrants(&user); !errors.Is(err, ErrNoActiveAuthority) {
+		t.Errorf("ApplyAuthorityGrants() error = %v, want ErrNoActiveAuthority", err)
+	}
+}
+
+func TestUserService_ExpireAuthorityGrants(t *testing.T) {
+	setupTestDB(t)
+	alice := createTestUser(t, "alice")
+	createTestGrant(t, alice.ID, 9528, time.Now().Add(time.Hour))
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", alice.ID).Update("authority_id", 9528)
+	_, refreshToken, _, err := SessionServiceApp.CreateSession(&alice, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, _ := UserServiceApp.loadSecurityVersion(alice.ID)
+
+	expireGrant(t, alice.ID, 9528)
+	if err = UserServiceApp.ExpireAuthorityGrants(); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	global.GVA_DB.Model(&system.SysUserAuthority{}).Where("sys_user_id = ?", alice.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expired grants = %d, want 0", count)
+	}
+	// 没有其他有效角色时清空当前角色并使令牌失效
+	if got := loadTestUser(t, alice.ID).AuthorityId; got != 0 {
+		t.Errorf("authority after expiry = %d, want 0", got)
+	}
+	if got, _ := UserServiceApp.loadSecurityVersion(alice.ID); got <= version {
+		t.Errorf("security version = %d, want greater than %d", got, version)
+	}
+	if _, _, _, _, err = JwtServiceApp.RotateRefreshToken(refreshToken); !errors.Is(err, ErrNoActiveAuthority) {
+		t.Errorf("RotateRefreshToken() error = %v, want ErrNoActiveAuthority", err)
+	}
+	tenant.Skip(global.GVA_DB).Model(&system.SysAuditLog{}).Where("action = ? AND user_id = ?", system.AuditRoleExpired, alice.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("role expired audit logs = %d, want 1", count)
+	}
+}
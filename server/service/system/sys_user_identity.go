@@ -0,0 +1,216 @@
+package system
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExternalUser 外部身份源认证通过后的用户信息
+type ExternalUser struct {
+	Provider           string // 身份源 如 oidc
+	Subject            string // 身份源中的唯一标识
+	Username           string
+	NickName           string
+	Email              string
+	Phone              string
+	AuthorityIds       []uint // 由组映射得到的角色
+	DefaultAuthorityId uint   // 未映射到角色时使用
+	AutoProvision      bool   // 未绑定时是否自动创建用户
+	SyncAuthorities    bool   // 每次登录按组映射同步已绑定用户的角色 未映射到角色时同步为默认角色
+}
+
+// LoginByExternal 外部身份登录 按绑定关系查找用户 未绑定时按配置自动创建 已绑定时同步资料 开启同步时同步角色
+func (userService *UserService) LoginByExternal(ext ExternalUser) (userInter *system.SysUser, err error) {
+	if ext.Provider == "" || ext.Subject == "" {
+		return nil, errors.New("外部身份信息不完整")
+	}
+	if global.GVA_CONFIG.PermissionApproval.Enable {
+		// 组映射得到的角色未经审批 开启权限变更审批时不据此授予或同步角色 新用户仅获得默认角色
+		ext.AuthorityIds = nil
+		ext.SyncAuthorities = false
+	}
+	authorityIds, err := existingAuthorityIds(ext.AuthorityIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(authorityIds) == 0 && ext.SyncAuthorities && ext.DefaultAuthorityId != 0 {
+		authorityIds = []uint{ext.DefaultAuthorityId}
+	}
+	var userID uint
+	var changed bool
+	// 外部身份按 provider、subject 全局唯一 登录前尚未确定租户
+	err = tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		var identity system.SysUserIdentity
+		err := tx.Where("provider = ? AND subject = ?", ext.Provider, ext.Subject).First(&identity).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			userID, err = userService.provisionExternalUser(tx, ext, authorityIds)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		userID = identity.UserID
+		changed, err = userService.syncExternalUser(tx, userID, ext, authorityIds)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		userService.refreshSecurityVersion(userID)
+	}
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", userID).Preload("Authorities").Preload("Authority").First(&user).Error; err != nil {
+		return nil, err
+	}
+	MenuServiceApp.UserAuthorityDefaultRouter(&user)
+	return &user, nil
+}
+
+func (userService *UserService) provisionExternalUser(tx *gorm.DB, ext ExternalUser, authorityIds []uint) (uint, error) {
+	if !ext.AutoProvision {
+		return 0, errors.New("该账号未绑定系统用户，请联系管理员")
+	}
+	if ext.Username == "" {
+		return 0, errors.New("外部身份未提供用户名")
+	}
+	if !errors.Is(tx.Where("username = ?", ext.Username).First(&system.SysUser{}).Error, gorm.ErrRecordNotFound) {
+		// 不按用户名自动关联已有账号 避免外部身份接管本地账号
+		return 0, errors.New("用户名已存在，请联系管理员绑定")
+	}
+	if len(authorityIds) == 0 {
+		if ext.DefaultAuthorityId == 0 {
+			return 0, errors.New("未配置默认角色")
+		}
+		authorityIds = []uint{ext.DefaultAuthorityId}
+	}
+	// 外部身份用户不使用本地密码 设置一个无人知晓的随机密码
+	user := system.SysUser{
+		UUID:        uuid.New(),
+		Username:    ext.Username,
+		Password:    utils.BcryptHash(uuid.New().String()),
+		NickName:    ext.NickName,
+		Email:       ext.Email,
+		Phone:       ext.Phone,
+		AuthorityId: authorityIds[0],
+		Enable:      1,
+	}
+	if user.NickName == "" {
+		user.NickName = ext.Username
+	}
+	if err := tx.Omit("Authorities").Create(&user).Error; err != nil {
+		return 0, err
+	}
+	if _, err := setUserAuthorityRows(tx, user.ID, authorityIds); err != nil {
+		return 0, err
+	}
+	return user.ID, tx.Create(&system.SysUserIdentity{UserID: user.ID, Provider: ext.Provider, Subject: ext.Subject}).Error
+}
+
+// syncExternalUser 同步已绑定用户的资料与角色 返回角色是否发生变化 变化时递增安全版本号使已签发的令牌失效
+func (userService *UserService) syncExternalUser(tx *gorm.DB, id uint, ext ExternalUser, authorityIds []uint) (changed bool, err error) {
+	var user system.SysUser
+	if err = tx.Where("id = ?", id).First(&user).Error; err != nil {
+		return false, err
+	}
+	updates := map[string]interface{}{}
+	if ext.NickName != "" {
+		updates["nick_name"] = ext.NickName
+	}
+	if ext.Email != "" {
+		updates["email"] = ext.Email
+	}
+	if ext.Phone != "" {
+		updates["phone"] = ext.Phone
+	}
+	if ext.SyncAuthorities && len(authorityIds) > 0 {
+		if changed, err = setUserAuthorityRows(tx, id, authorityIds); err != nil {
+			return false, err
+		}
+		if !slices.Contains(authorityIds, user.AuthorityId) {
+			// 当前角色仍然有效时保留用户的选择
+			updates["authority_id"] = authorityIds[0]
+			changed = true
+		}
+	}
+	if len(updates) > 0 {
+		if err = tx.Model(&user).Updates(updates).Error; err != nil {
+			return false, err
+		}
+	}
+	if changed {
+		err = userService.bumpSecurityVersion(tx, id)
+	}
+	return changed, err
+}
+
+// existingAuthorityIds 过滤掉不存在的角色并去重 保持原有顺序
+func existingAuthorityIds(ids []uint) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var found []uint
+	if err := global.GVA_DB.Model(&system.SysAuthority{}).Where("authority_id in ?", ids).Pluck("authority_id", &found).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[uint]bool, len(found))
+	for _, v := range found {
+		exists[v] = true
+	}
+	result := make([]uint, 0, len(ids))
+	for _, v := range ids {
+		if exists[v] {
+			result = append(result, v)
+			exists[v] = false
+		}
+	}
+	return result, nil
+}
+
+// setUserAuthorityRows 替换用户的长期角色 限时角色由授权接口单独维护 返回长期角色是否发生变化
+func setUserAuthorityRows(tx *gorm.DB, id uint, authorityIds []uint) (bool, error) {
+	var rows []system.SysUserAuthority
+	if err := tx.Where("sys_user_id = ?", id).Find(&rows).Error; err != nil {
+		return false, err
+	}
+	var permanentIds, grantedIds []uint
+	for i := range rows {
+		if rows[i].ValidUntil == nil {
+			permanentIds = append(permanentIds, rows[i].SysAuthorityAuthorityId)
+		} else {
+			grantedIds = append(grantedIds, rows[i].SysAuthorityAuthorityId)
+		}
+	}
+	if sameAuthorityIds(permanentIds, authorityIds) {
+		return false, nil
+	}
+	if err := tx.Delete(&[]system.SysUserAuthority{}, "sys_user_id = ? AND valid_until IS NULL", id).Error; err != nil {
+		return false, err
+	}
+	newRows := make([]system.SysUserAuthority, 0, len(authorityIds))
+	for _, v := range authorityIds {
+		if slices.Contains(grantedIds, v) {
+			continue
+		}
+		newRows = append(newRows, system.SysUserAuthority{SysUserId: id, SysAuthorityAuthorityId: v})
+	}
+	if len(newRows) == 0 {
+		return true, nil
+	}
+	return true, tx.Create(&newRows).Error
+}
+
+// sameAuthorityIds 两组角色ID是否相同 不考虑顺序
+func sameAuthorityIds(a, b []uint) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
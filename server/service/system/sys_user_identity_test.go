@@ -0,0 +1,68 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+)
+
+func TestUserService_LoginByExternalSyncAuthorities(t *testing.T) {
+	setupTestDB(t, &system.SysUserIdentity{}, &system.SysBaseMenu{}, &system.SysAuthorityMenu{})
+	user := createTestUser(t, "alice", 888)
+	createTestGrant(t, user.ID, 9528, time.Now().Add(time.Hour))
+	global.GVA_DB.Create(&system.SysAuthority{AuthorityId: 8881})
+	global.GVA_DB.Create(&system.SysUserIdentity{UserID: user.ID, Provider: "oidc", Subject: "alice"})
+	ext := ExternalUser{Provider: "oidc", Subject: "alice", AuthorityIds: []uint{8881}, SyncAuthorities: true}
+	version, _ := UserServiceApp.loadSecurityVersion(user.ID)
+
+	// 同步替换长期角色 保留限时授权 并使已签发的令牌失效
+	if _, err := UserServiceApp.LoginByExternal(ext); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[uint]bool{}
+	for _, a := range loadTestUser(t, user.ID).Authorities {
+		ids[a.AuthorityId] = true
+	}
+	if len(ids) != 2 || !ids[8881] || !ids[9528] {
+		t.Errorf("authorities after sync = %v, want 8881 and granted 9528", ids)
+	}
+	synced, _ := UserServiceApp.loadSecurityVersion(user.ID)
+	if synced <= version {
+		t.Errorf("security version = %d, want greater than %d", synced, version)
+	}
+
+	// 角色未变化时不使令牌失效
+	if _, err := UserServiceApp.LoginByExternal(ext); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := UserServiceApp.loadSecurityVersion(user.ID); got != synced {
+		t.Errorf("security version = %d, want unchanged %d", got, synced)
+	}
+}
+
+func TestUserService_LoginByExternalApproval(t *testing.T) {
+	setupTestDB(t, &system.SysUserIdentity{}, &system.SysBaseMenu{}, &system.SysAuthorityMenu{})
+	global.GVA_CONFIG.PermissionApproval.Enable = true
+	user := createTestUser(t, "alice", 888)
+	global.GVA_DB.Create(&system.SysAuthority{AuthorityId: 8881})
+	global.GVA_DB.Create(&system.SysUserIdentity{UserID: user.ID, Provider: "oidc", Subject: "alice"})
+
+	// 开启审批时组映射的角色不生效 已绑定用户保留原角色 新用户仅获得默认角色
+	ext := ExternalUser{Provider: "oidc", Subject: "alice", AuthorityIds: []uint{8881}, SyncAuthorities: true}
+	got, err := UserServiceApp.LoginByExternal(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Authorities) != 1 || got.Authorities[0].AuthorityId != 888 {
+		t.Errorf("synced authorities = %+v, want only 888", got.Authorities)
+	}
+	ext = ExternalUser{Provider: "oidc", Subject: "bob", Username: "bob", AuthorityIds: []uint{8881}, DefaultAuthorityId: 888, AutoProvision: true}
+	if got, err = UserServiceApp.LoginByExternal(ext); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Authorities) != 1 || got.Authorities[0].AuthorityId != 888 {
+		t.Errorf("provisioned authorities = %+v, want only 888", got.Authorities)
+	}
+}
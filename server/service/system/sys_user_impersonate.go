@@ -0,0 +1,81 @@
+package system
+
+import (
+	"errors"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// Impersonate 管理员模拟登录为指定用户 校验后返回目标用户与写入令牌的管理员身份
+func (userService *UserService) Impersonate(admin *systemReq.CustomClaims, targetID uint, ip string) (*system.SysUser, *systemReq.Impersonator, error) {
+	if admin.Impersonator != nil {
+		return nil, nil, errors.New("模拟登录期间不能再次模拟其他用户")
+	}
+	if targetID == admin.BaseClaims.ID {
+		return nil, nil, errors.New("不能模拟登录自己")
+	}
+	var user system.SysUser
+	// 只能模拟当前租户内的用户
+	if err := tenant.Skip(global.GVA_DB).Where("id = ?", targetID).Preload("Authorities").First(&user).Error; err != nil || user.TenantId != admin.TenantId {
+		return nil, nil, errors.New("用户不存在")
+	}
+	if user.Enable != 1 {
+		return nil, nil, errors.New("用户被禁止登录")
+	}
+	// 目标用户的全部角色均需在管理员可管理的范围内
+	if err := AuthorityServiceApp.CheckAuthorityIDAuth(admin.AuthorityId, user.AuthorityId); err != nil {
+		return nil, nil, err
+	}
+	for i := range user.Authorities {
+		if err := AuthorityServiceApp.CheckAuthorityIDAuth(admin.AuthorityId, user.Authorities[i].AuthorityId); err != nil {
+			return nil, nil, err
+		}
+	}
+	impersonator := &systemReq.Impersonator{
+		ID:              admin.BaseClaims.ID,
+		Username:        admin.Username,
+		AuthorityId:     admin.AuthorityId,
+		SessionID:       admin.SessionID,
+		SecurityVersion: admin.SecurityVersion,
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditImpersonate,
+		UserID:     user.ID,
+		Username:   user.Username,
+		OperatorID: admin.BaseClaims.ID,
+		Ip:         ip,
+		Detail:     "管理员" + admin.Username + "模拟登录",
+	})
+	return &user, impersonator, nil
+}
+
+// EndImpersonate 结束模拟登录 作废模拟令牌并返回原管理员
+func (userService *UserService) EndImpersonate(claims *systemReq.CustomClaims, token string, ip string) (*system.SysUser, error) {
+	imp := claims.Impersonator
+	if imp == nil {
+		return nil, errors.New("当前未处于模拟登录")
+	}
+	var admin system.SysUser
+	if err := tenant.Skip(global.GVA_DB).Where("id = ?", imp.ID).First(&admin).Error; err != nil {
+		return nil, errors.New("管理员账号不存在")
+	}
+	if admin.Enable != 1 {
+		return nil, errors.New("用户被禁止登录")
+	}
+	admin.TenantId = SessionServiceApp.SessionTenant(imp.SessionID, admin.TenantId)
+	if err := JwtServiceApp.JsonInBlacklist(system.JwtBlacklist{Jwt: token}); err != nil {
+		return nil, err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditImpersonateEnd,
+		UserID:     claims.BaseClaims.ID,
+		Username:   claims.Username,
+		OperatorID: imp.ID,
+		Ip:         ip,
+		Detail:     "管理员" + imp.Username + "结束模拟登录",
+	})
+	return &admin, nil
+}
@@ -0,0 +1,47 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
+func TestUserService_Impersonate(t *testing.T) {
+	setupTestDB(t)
+	global.GVA_CONFIG.System.UseStrictAuth = true
+	root, child := uint(0), uint(100)
+	for _, a := range []system.SysAuthority{
+		{AuthorityId: 100, AuthorityName: "管理员", ParentId: &root},
+		{AuthorityId: 101, AuthorityName: "下级角色", ParentId: &child},
+		{AuthorityId: 200, AuthorityName: "其他角色", ParentId: &root},
+	} {
+		if err := global.GVA_DB.Create(&a).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	admin := createTestUser(t, "admin", 100)
+	member := createTestUser(t, "member", 101)
+	// 当前角色在管理范围内 但还拥有管理员无权管理的角色
+	mixed := createTestUser(t, "mixed", 101, 200)
+
+	claims := &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{ID: admin.ID, Username: admin.Username, AuthorityId: 100, TenantId: 1}}
+	user, imp, err := UserServiceApp.Impersonate(claims, member.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Impersonate(member) error = %v", err)
+	}
+	if user.ID != member.ID || imp.ID != admin.ID {
+		t.Errorf("Impersonate(member) = user %d impersonator %d", user.ID, imp.ID)
+	}
+	if _, _, err = UserServiceApp.Impersonate(claims, mixed.ID, "127.0.0.1"); err == nil {
+		t.Error("Impersonate() should reject target holding a role outside the admin's reach")
+	}
+	if _, _, err = UserServiceApp.Impersonate(claims, admin.ID, "127.0.0.1"); err == nil {
+		t.Error("Impersonate() should reject impersonating oneself")
+	}
+	claims.Impersonator = imp
+	if _, _, err = UserServiceApp.Impersonate(claims, member.ID, "127.0.0.1"); err == nil {
+		t.Error("Impersonate() should reject nested impersonation")
+	}
+}
@@ -0,0 +1,360 @@
+package system
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/webauthn"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	passkeySessionPrefix = "passkey_session:"
+	passkeyMaxPerUser    = 10 // 每个用户最多绑定的通行密钥数量
+)
+
+// 通行密钥会话用途
+const (
+	passkeyKindRegister  = "register"
+	passkeyKindLogin     = "login"
+	passkeyKindTwoFactor = "two_factor"
+)
+
+var (
+	ErrPasskeyDisabled = errors.New("未开启通行密钥登录")
+	ErrPasskeySession  = errors.New("通行密钥验证已过期，请重试")
+	ErrPasskeyInvalid  = errors.New("通行密钥验证失败")
+)
+
+type PasskeyService struct{}
+
+var PasskeyServiceApp = new(PasskeyService)
+
+type passkeySession struct {
+	Challenge string
+	UserID    uint
+	Kind      string
+}
+
+// BeginRegistration 开始为用户注册通行密钥 返回会话ID与浏览器所需的注册参数
+func (passkeyService *PasskeyService) BeginRegistration(userID uint) (sessionID string, options webauthn.CreationOptions, err error) {
+	conf, err := webauthnConfig()
+	if err != nil {
+		return "", options, err
+	}
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", options, err
+	}
+	passkeys, err := passkeyService.GetPasskeyList(userID)
+	if err != nil {
+		return "", options, err
+	}
+	if len(passkeys) >= passkeyMaxPerUser {
+		return "", options, errors.New("通行密钥数量已达上限，请先删除不再使用的通行密钥")
+	}
+	sessionID, challenge, err := createPasskeySession(userID, passkeyKindRegister)
+	if err != nil {
+		return "", options, err
+	}
+	// 用户标识使用UUID 不包含用户名等个人信息
+	return sessionID, conf.NewCreationOptions(challenge, user.UUID[:], user.Username, user.NickName,
+		passkeyDescriptors(passkeys), passkeyUserVerification()), nil
+}
+
+// FinishRegistration 校验浏览器返回的凭证并保存通行密钥
+func (passkeyService *PasskeyService) FinishRegistration(userID uint, sessionID string, name string, resp webauthn.AttestationResponse, ip string) (passkey system.SysUserPasskey, err error) {
+	conf, err := webauthnConfig()
+	if err != nil {
+		return passkey, err
+	}
+	session, err := takePasskeySession(sessionID, passkeyKindRegister)
+	if err != nil {
+		return passkey, err
+	}
+	if session.UserID != userID {
+		return passkey, ErrPasskeySession
+	}
+	cred, err := conf.VerifyRegistration(resp, session.Challenge, passkeyUserVerification() == "required")
+	if err != nil {
+		global.GVA_LOG.Warn("通行密钥注册校验失败", zap.Uint("userId", userID), zap.Error(err))
+		return passkey, ErrPasskeyInvalid
+	}
+	credentialID := base64.RawURLEncoding.EncodeToString(cred.ID)
+	var count int64
+	if err = global.GVA_DB.Model(&system.SysUserPasskey{}).Where("credential_id = ?", credentialID).Count(&count).Error; err != nil {
+		return passkey, err
+	}
+	if count > 0 {
+		return passkey, errors.New("该通行密钥已绑定")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "通行密钥 " + time.Now().Format(time.DateOnly)
+	}
+	aaguid, _ := uuid.FromBytes(cred.AAGUID)
+	passkey = system.SysUserPasskey{
+		UserID:       userID,
+		Name:         truncateRunes(name, 64),
+		CredentialID: credentialID,
+		PublicKey:    base64.StdEncoding.EncodeToString(cred.PublicKey),
+		SignCount:    cred.SignCount,
+		AAGUID:       aaguid.String(),
+		Transports:   truncateRunes(strings.Join(cred.Transports, ","), 64),
+	}
+	if err = global.GVA_DB.Create(&passkey).Error; err != nil {
+		return passkey, err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditPasskeyAdded,
+		UserID:     userID,
+		OperatorID: userID,
+		Ip:         ip,
+		Detail:     "绑定通行密钥 " + passkey.Name,
+	})
+	return passkey, nil
+}
+
+// GetPasskeyList 获取用户绑定的通行密钥
+func (passkeyService *PasskeyService) GetPasskeyList(userID uint) (list []system.SysUserPasskey, err error) {
+	err = global.GVA_DB.Where("user_id = ?", userID).Order("id desc").Find(&list).Error
+	return list, err
+}
+
+// HasPasskey 用户是否绑定了通行密钥 未开启通行密钥登录时视为未绑定
+func (passkeyService *PasskeyService) HasPasskey(userID uint) bool {
+	if !global.GVA_CONFIG.WebAuthn.Enable {
+		return false
+	}
+	var count int64
+	global.GVA_DB.Model(&system.SysUserPasskey{}).Where("user_id = ?", userID).Count(&count)
+	return count > 0
+}
+
+// UpdatePasskey 修改自身通行密钥的名称
+func (passkeyService *PasskeyService) UpdatePasskey(userID uint, id uint, name string) error {
+	result := global.GVA_DB.Model(&system.SysUserPasskey{}).Where("id = ? AND user_id = ?", id, userID).
+		Update("name", truncateRunes(strings.TrimSpace(name), 64))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("通行密钥不存在")
+	}
+	return nil
+}
+
+// DeletePasskey 删除自身的通行密钥
+func (passkeyService *PasskeyService) DeletePasskey(userID uint, id uint, ip string) error {
+	var passkey system.SysUserPasskey
+	if err := global.GVA_DB.Where("id = ? AND user_id = ?", id, userID).First(&passkey).Error; err != nil {
+		return errors.New("通行密钥不存在")
+	}
+	if err := global.GVA_DB.Unscoped().Delete(&passkey).Error; err != nil {
+		return err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     system.AuditPasskeyRemoved,
+		UserID:     userID,
+		OperatorID: userID,
+		Ip:         ip,
+		Detail:     "删除通行密钥 " + passkey.Name,
+	})
+	return nil
+}
+
+// BeginLogin 开始通行密钥登录 指定用户名时仅允许该用户的凭证 否则由认证器选择可发现凭证
+// 用户不存在时同样返回参数 避免据此判断用户名是否存在
+func (passkeyService *PasskeyService) BeginLogin(username string) (sessionID string, options webauthn.RequestOptions, err error) {
+	conf, err := webauthnConfig()
+	if err != nil {
+		return "", options, err
+	}
+	var allow []webauthn.CredentialDescriptor
+	if username != "" {
+		var passkeys []system.SysUserPasskey
+		err = global.GVA_DB.Where("user_id = (?)", tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Select("id").Where("username = ?", username)).
+			Find(&passkeys).Error
+		if err != nil {
+			return "", options, err
+		}
+		allow = passkeyDescriptors(passkeys)
+	}
+	sessionID, challenge, err := createPasskeySession(0, passkeyKindLogin)
+	if err != nil {
+		return "", options, err
+	}
+	// 通行密钥作为唯一凭证登录 始终要求用户验证
+	return sessionID, conf.NewRequestOptions(challenge, allow, "required"), nil
+}
+
+// FinishLogin 校验通行密钥登录断言 成功时返回对应用户
+func (passkeyService *PasskeyService) FinishLogin(sessionID string, resp webauthn.AssertionResponse) (*system.SysUser, error) {
+	session, err := takePasskeySession(sessionID, passkeyKindLogin)
+	if err != nil {
+		return nil, err
+	}
+	passkey, err := passkeyService.verifyAssertion(0, session, resp, true)
+	if err != nil {
+		return nil, err
+	}
+	var user system.SysUser
+	err = tenant.Skip(global.GVA_DB).Where("id = ?", passkey.UserID).Preload("Authorities").Preload("Authority").First(&user).Error
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	// 认证器返回的用户标识须与凭证所属用户一致
+	if handle, err := resp.UserHandle(); err != nil || (len(handle) > 0 && string(handle) != string(user.UUID[:])) {
+		return nil, ErrPasskeyInvalid
+	}
+	return &user, nil
+}
+
+// BeginTwoFactor 密码校验通过后 开始使用通行密钥进行二次验证
+func (passkeyService *PasskeyService) BeginTwoFactor(userID uint) (sessionID string, options webauthn.RequestOptions, err error) {
+	conf, err := webauthnConfig()
+	if err != nil {
+		return "", options, err
+	}
+	passkeys, err := passkeyService.GetPasskeyList(userID)
+	if err != nil {
+		return "", options, err
+	}
+	if len(passkeys) == 0 {
+		return "", options, errors.New("未绑定通行密钥")
+	}
+	sessionID, challenge, err := createPasskeySession(userID, passkeyKindTwoFactor)
+	if err != nil {
+		return "", options, err
+	}
+	return sessionID, conf.NewRequestOptions(challenge, passkeyDescriptors(passkeys), passkeyUserVerification()), nil
+}
+
+// VerifyTwoFactor 校验二次验证时的通行密钥断言
+func (passkeyService *PasskeyService) VerifyTwoFactor(userID uint, sessionID string, resp webauthn.AssertionResponse) error {
+	session, err := takePasskeySession(sessionID, passkeyKindTwoFactor)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return ErrPasskeySession
+	}
+	_, err = passkeyService.verifyAssertion(userID, session, resp, passkeyUserVerification() == "required")
+	return err
+}
+
+// verifyAssertion 查找凭证并校验签名 userID不为0时凭证须属于该用户 成功后更新签名计数与最后使用时间
+func (passkeyService *PasskeyService) verifyAssertion(userID uint, session *passkeySession, resp webauthn.AssertionResponse, requireUV bool) (*system.SysUserPasskey, error) {
+	conf, err := webauthnConfig()
+	if err != nil {
+		return nil, err
+	}
+	id, err := resp.CredentialID()
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	var passkey system.SysUserPasskey
+	db := global.GVA_DB.Where("credential_id = ?", base64.RawURLEncoding.EncodeToString(id))
+	if userID != 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	if err = db.First(&passkey).Error; err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(passkey.PublicKey)
+	if err != nil {
+		return nil, ErrPasskeyInvalid
+	}
+	signCount, err := conf.VerifyAssertion(resp, session.Challenge, publicKey, passkey.SignCount, requireUV)
+	if err != nil {
+		global.GVA_LOG.Warn("通行密钥断言校验失败", zap.Uint("userId", passkey.UserID), zap.Uint("passkeyId", passkey.ID), zap.Error(err))
+		return nil, ErrPasskeyInvalid
+	}
+	// 以条件更新保证并发请求下同一签名计数只会成功一次
+	now := time.Now()
+	result := global.GVA_DB.Model(&system.SysUserPasskey{}).Where("id = ? AND sign_count = ?", passkey.ID, passkey.SignCount).
+		Updates(map[string]interface{}{"sign_count": signCount, "last_used_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrPasskeyInvalid
+	}
+	passkey.SignCount = signCount
+	passkey.LastUsedAt = &now
+	return &passkey, nil
+}
+
+func webauthnConfig() (webauthn.Config, error) {
+	conf := global.GVA_CONFIG.WebAuthn
+	if !conf.Enable || conf.RPID == "" || len(conf.Origins) == 0 {
+		return webauthn.Config{}, ErrPasskeyDisabled
+	}
+	name := conf.RPName
+	if name == "" {
+		name = conf.RPID
+	}
+	return webauthn.Config{RPID: conf.RPID, RPName: name, Origins: conf.Origins, Timeout: conf.Timeout * 1000}, nil
+}
+
+func passkeyUserVerification() string {
+	switch uv := global.GVA_CONFIG.WebAuthn.UserVerification; uv {
+	case "required", "discouraged":
+		return uv
+	}
+	return "preferred"
+}
+
+func passkeyDescriptors(passkeys []system.SysUserPasskey) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(passkeys))
+	for i := range passkeys {
+		var transports []string
+		if passkeys[i].Transports != "" {
+			transports = strings.Split(passkeys[i].Transports, ",")
+		}
+		descriptors = append(descriptors, webauthn.CredentialDescriptor{Type: "public-key", ID: passkeys[i].CredentialID, Transports: transports})
+	}
+	return descriptors
+}
+
+// createPasskeySession 保存挑战 有效期与浏览器等待时长一致
+func createPasskeySession(userID uint, kind string) (sessionID string, challenge string, err error) {
+	challenge, err = webauthn.NewChallenge()
+	if err != nil {
+		return "", "", err
+	}
+	timeout := time.Second * time.Duration(global.GVA_CONFIG.WebAuthn.Timeout)
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	sessionID = uuid.New().String()
+	global.BlackCache.Set(passkeySessionPrefix+sessionID, &passkeySession{Challenge: challenge, UserID: userID, Kind: kind}, timeout)
+	return sessionID, challenge, nil
+}
+
+// takePasskeySession 取出并作废会话 每个挑战只能使用一次
+func takePasskeySession(sessionID string, kind string) (*passkeySession, error) {
+	v, ok := global.BlackCache.Get(passkeySessionPrefix + sessionID)
+	if sessionID == "" || !ok {
+		return nil, ErrPasskeySession
+	}
+	global.BlackCache.Delete(passkeySessionPrefix + sessionID)
+	session := v.(*passkeySession)
+	if session.Kind != kind {
+		return nil, ErrPasskeySession
+	}
+	return session, nil
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
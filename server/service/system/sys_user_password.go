@@ -0,0 +1,239 @@
+package system
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	emailUtils "github.com/flipped-aurora/gin-vue-admin/server/plugin/email/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordTicketPrefix         = "password_change_ticket:"
+	forgotPasswordCooldownPrefix = "password_forgot:"
+	forgotPasswordCooldown       = time.Minute // 同一用户发送重置邮件的最小间隔
+)
+
+var (
+	ErrPasswordTicketInvalid  = errors.New("修改密码已超时，请重新登录")
+	ErrPasswordReused         = errors.New("不能使用最近使用过的密码")
+	ErrForgotPasswordDisabled = errors.New("未开启找回密码")
+	ErrForgotTokenInvalid     = errors.New("重置链接无效或已过期，请重新申请")
+)
+
+// CheckPassword 校验新密码是否符合密码策略且未在近期使用过
+func (userService *UserService) CheckPassword(user *system.SysUser, password string) error {
+	conf := global.GVA_CONFIG.Password
+	if err := utils.CheckPasswordPolicy(conf, user.Username, password); err != nil {
+		return err
+	}
+	if conf.HistoryCount <= 0 || user.ID == 0 {
+		return nil
+	}
+	if user.Password != "" && utils.BcryptCheck(password, user.Password) {
+		return ErrPasswordReused
+	}
+	var history []system.SysUserPasswordHistory
+	err := global.GVA_DB.Where("user_id = ?", user.ID).Order("id desc").Limit(conf.HistoryCount).Find(&history).Error
+	if err != nil {
+		return err
+	}
+	for i := range history {
+		if utils.BcryptCheck(password, history[i].Password) {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// NeedChangePassword 判断使用本地密码登录时是否必须先修改密码 管理员重置或设置过密码 或密码已超过有效期
+func (userService *UserService) NeedChangePassword(user *system.SysUser) (need bool, expired bool) {
+	if user.MustChangePassword {
+		return true, false
+	}
+	maxAge := global.GVA_CONFIG.Password.MaxAge
+	if maxAge <= 0 {
+		return false, false
+	}
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	if time.Since(changedAt) > time.Duration(maxAge)*24*time.Hour {
+		return true, true
+	}
+	return false, false
+}
+
+// CreatePasswordTicket 登录时需要修改密码 生成修改密码票据
+func (userService *UserService) CreatePasswordTicket(userID uint) string {
+	return createLoginTicket(passwordTicketPrefix, userID, false)
+}
+
+// GetPasswordTicketUser 通过修改密码票据获取用户
+func (userService *UserService) GetPasswordTicketUser(token string) (user *system.SysUser, err error) {
+	user, _, err = getLoginTicketUser(passwordTicketPrefix, token, ErrPasswordTicketInvalid)
+	return user, err
+}
+
+// DeletePasswordTicket 修改密码完成后作废票据
+func (userService *UserService) DeletePasswordTicket(token string) {
+	global.BlackCache.Delete(passwordTicketPrefix + token)
+}
+
+// ChangeExpiredPassword 登录过程中修改已过期或被要求修改的密码
+func (userService *UserService) ChangeExpiredPassword(user *system.SysUser, newPassword string) error {
+	if err := userService.CheckPassword(user, newPassword); err != nil {
+		return err
+	}
+	return tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		return userService.setPassword(tx, user, newPassword, false)
+	})
+}
+
+// setPassword 保存新密码 记录历史并清理超出保留数量的历史密码
+func (userService *UserService) setPassword(tx *gorm.DB, user *system.SysUser, password string, mustChange bool) error {
+	now := time.Now()
+	user.Password = utils.BcryptHash(password)
+	user.PasswordChangedAt = &now
+	user.MustChangePassword = mustChange
+	err := tx.Model(&system.SysUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password":             user.Password,
+		"password_changed_at":  now,
+		"must_change_password": mustChange,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return recordPasswordHistory(tx, user.ID, user.Password)
+}
+
+func recordPasswordHistory(tx *gorm.DB, userID uint, hash string) error {
+	keep := global.GVA_CONFIG.Password.HistoryCount
+	if keep <= 0 {
+		return nil
+	}
+	if err := tx.Create(&system.SysUserPasswordHistory{UserID: userID, Password: hash}).Error; err != nil {
+		return err
+	}
+	var ids []uint
+	err := tx.Model(&system.SysUserPasswordHistory{}).Where("user_id = ?", userID).Order("id desc").Pluck("id", &ids).Error
+	if err != nil || len(ids) <= keep {
+		return err
+	}
+	return tx.Unscoped().Delete(&system.SysUserPasswordHistory{}, ids[keep:]).Error
+}
+
+// ForgotPassword 按用户名或邮箱发送重置密码邮件 账号不存在时同样返回成功 避免据此探测账号
+// 绑定了外部身份的用户同样可以通过邮件设置本地密码 与其使用本地密码登录时同样校验密码策略一致
+func (userService *UserService) ForgotPassword(account string, ip string) error {
+	conf := global.GVA_CONFIG.Password
+	if conf.ForgotUrl == "" {
+		return ErrForgotPasswordDisabled
+	}
+	var users []system.SysUser
+	err := tenant.Skip(global.GVA_DB).Where("username = ? OR email = ?", account, account).Find(&users).Error
+	if err != nil {
+		return err
+	}
+	timeout := time.Second * time.Duration(conf.ForgotTimeout)
+	if timeout <= 0 {
+		timeout = 30 * time.Minute
+	}
+	for i := range users {
+		user := users[i]
+		if user.Email == "" || user.Enable != 1 {
+			continue
+		}
+		if _, ok := global.BlackCache.Get(forgotPasswordCooldownPrefix + strconv.Itoa(int(user.ID))); ok {
+			continue
+		}
+		global.BlackCache.Set(forgotPasswordCooldownPrefix+strconv.Itoa(int(user.ID)), struct{}{}, forgotPasswordCooldown)
+		link := strings.ReplaceAll(conf.ForgotUrl, "%s", forgotPasswordToken(&user, time.Now().Add(timeout)))
+		body := fmt.Sprintf(`<p>%s，您好：</p><p>您正在申请重置登录密码，请在%d分钟内点击以下链接完成重置，链接仅可使用一次：</p><p><a href="%s">%s</a></p><p>如果这不是您本人的操作，请忽略本邮件。</p>`,
+			html.EscapeString(user.NickName), int(timeout.Minutes()), html.EscapeString(link), html.EscapeString(link))
+		// 异步发送 避免响应耗时暴露账号是否存在
+		go func(to string, username string) {
+			if err := emailUtils.Email(to, "重置密码", body); err != nil {
+				global.GVA_LOG.Error("发送重置密码邮件失败!", zap.String("username", username), zap.Error(err))
+			}
+		}(user.Email, user.Username)
+		AuditLogServiceApp.Record(system.SysAuditLog{
+			Action:   system.AuditPasswordForgot,
+			UserID:   user.ID,
+			Username: user.Username,
+			Ip:       ip,
+			Detail:   "发送重置密码邮件",
+		})
+	}
+	return nil
+}
+
+// ResetPasswordByToken 使用重置邮件中的令牌设置新密码 密码修改后令牌随之失效
+func (userService *UserService) ResetPasswordByToken(token string, newPassword string, ip string) error {
+	payload, err := utils.VerifySignedToken(forgotPasswordKey(), token)
+	if err != nil {
+		return ErrForgotTokenInvalid
+	}
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 {
+		return ErrForgotTokenInvalid
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return ErrForgotTokenInvalid
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrForgotTokenInvalid
+	}
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", id).First(&user).Error; err != nil {
+		return ErrForgotTokenInvalid
+	}
+	if user.Enable != 1 || subtle.ConstantTimeCompare([]byte(parts[2]), []byte(passwordFingerprint(user.Password))) != 1 {
+		return ErrForgotTokenInvalid
+	}
+	if err = userService.CheckPassword(&user, newPassword); err != nil {
+		return err
+	}
+	err = tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		return userService.setPassword(tx, &user, newPassword, false)
+	})
+	if err != nil {
+		return err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:   system.AuditPasswordReset,
+		UserID:   user.ID,
+		Username: user.Username,
+		Ip:       ip,
+		Detail:   "通过找回密码邮件重置密码",
+	})
+	// 重置后原有登录会话全部失效
+	return SessionServiceApp.RevokeUserSessions(user.ID)
+}
+
+// forgotPasswordToken 重置令牌载荷为 用户ID:过期时间:当前密码指纹 密码变更后指纹不再匹配 保证令牌只能使用一次
+func forgotPasswordToken(user *system.SysUser, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d:%d:%s", user.ID, expiresAt.Unix(), passwordFingerprint(user.Password))
+	return utils.SignToken(forgotPasswordKey(), payload)
+}
+
+func forgotPasswordKey() []byte {
+	return []byte("password_forgot:" + global.GVA_CONFIG.JWT.SigningKey)
+}
+
+func passwordFingerprint(hash string) string {
+	return utils.SHA256V([]byte(hash))[:16]
+}
@@ -0,0 +1,84 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+func TestUserService_NeedChangePassword(t *testing.T) {
+	setupTestDB(t, &system.SysUserIdentity{})
+	global.GVA_CONFIG.Password.MaxAge = 90
+	user := createTestUser(t, "alice", 888)
+	// 绑定外部身份的用户使用本地密码登录时同样校验密码策略
+	if err := global.GVA_DB.Create(&system.SysUserIdentity{UserID: user.ID, Provider: ldapProvider, Subject: "alice"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recent := time.Now().Add(-24 * time.Hour)
+	old := time.Now().Add(-91 * 24 * time.Hour)
+	tests := []struct {
+		name        string
+		mustChange  bool
+		changedAt   *time.Time
+		wantNeed    bool
+		wantExpired bool
+	}{
+		{"valid", false, &recent, false, false},
+		{"must change", true, &recent, true, false},
+		{"expired", false, &old, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user.MustChangePassword = tt.mustChange
+			user.PasswordChangedAt = tt.changedAt
+			need, expired := UserServiceApp.NeedChangePassword(&user)
+			if need != tt.wantNeed || expired != tt.wantExpired {
+				t.Errorf("NeedChangePassword() = %v, %v, want %v, %v", need, expired, tt.wantNeed, tt.wantExpired)
+			}
+		})
+	}
+}
+
+func TestUserService_ChangePasswordRevokesOtherSessions(t *testing.T) {
+	setupTestDB(t, &system.SysUserPasswordHistory{})
+	user := createTestUser(t, "alice", 888)
+	if err := tenant.Skip(global.GVA_DB).Model(&user).Update("password", utils.BcryptHash("Old-pass-123")).Error; err != nil {
+		t.Fatal(err)
+	}
+	current, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := UserServiceApp.SecurityVersion(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := &system.SysUser{GVA_MODEL: global.GVA_MODEL{ID: user.ID}, Password: "Old-pass-123"}
+	if _, err = UserServiceApp.ChangePassword(u, "New-pass-456", current.SessionID); err != nil {
+		t.Fatal(err)
+	}
+	if !SessionServiceApp.CheckSession(current.SessionID, "127.0.0.1") {
+		t.Error("current session should stay valid")
+	}
+	if SessionServiceApp.CheckSession(other.SessionID, "127.0.0.1") {
+		t.Error("other session should be revoked")
+	}
+	var active int64
+	global.GVA_DB.Model(&system.SysRefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", other.SessionID).Count(&active)
+	if active != 0 {
+		t.Error("other session's refresh tokens should be revoked")
+	}
+	if UserServiceApp.CheckSecurityVersion(user.ID, uint(version)) {
+		t.Error("tokens issued before the change should be invalidated")
+	}
+}
@@ -0,0 +1,238 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	emailUtils "github.com/flipped-aurora/gin-vue-admin/server/plugin/email/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRegisterDisabled     = errors.New("未开放用户注册")
+	ErrRegisterTokenInvalid = errors.New("验证链接无效或已过期")
+)
+
+// SelfRegister 用户自助注册 创建冻结状态的用户并发送验证邮件
+func (userService *UserService) SelfRegister(req systemReq.SelfRegister, ip string) error {
+	conf := global.GVA_CONFIG.Register
+	if !conf.Enable || conf.VerifyUrl == "" {
+		return ErrRegisterDisabled
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil {
+		return errors.New("邮箱格式不正确")
+	}
+	var count int64
+	if err = tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("email = ?", addr.Address).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("该邮箱已被注册")
+	}
+	nickName := req.NickName
+	if nickName == "" {
+		nickName = req.Username
+	}
+	// 自助注册的用户归属默认租户
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	user, err := userService.Register(ctx, system.SysUser{
+		Username:    req.Username,
+		NickName:    nickName,
+		Password:    req.Password,
+		Email:       addr.Address,
+		Enable:      2,
+		AuthorityId: conf.AuthorityId,
+		Authorities: []system.SysAuthority{{AuthorityId: conf.AuthorityId}},
+	})
+	if err != nil {
+		return err
+	}
+	registration := system.SysUserRegistration{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Ip:       ip,
+		Status:   system.RegisterUnverified,
+	}
+	if err = global.GVA_DB.WithContext(ctx).Create(&registration).Error; err != nil {
+		_ = userService.DeleteUser(ctx, int(user.ID))
+		return err
+	}
+
+	timeout := time.Second * time.Duration(conf.VerifyTimeout)
+	if timeout <= 0 {
+		timeout = 24 * time.Hour
+	}
+	token := utils.SignToken(registerVerifyKey(), fmt.Sprintf("%d:%d", user.ID, time.Now().Add(timeout).Unix()))
+	link := strings.ReplaceAll(conf.VerifyUrl, "%s", token)
+	body := fmt.Sprintf(`<p>%s，您好：</p><p>感谢注册，请在%d小时内点击以下链接验证邮箱：</p><p><a href="%s">%s</a></p><p>如果这不是您本人的操作，请忽略本邮件。</p>`,
+		html.EscapeString(user.NickName), int(timeout.Hours()), html.EscapeString(link), html.EscapeString(link))
+	go func(to string, username string) {
+		if err := emailUtils.Email(to, "验证注册邮箱", body); err != nil {
+			global.GVA_LOG.Error("发送注册验证邮件失败!", zap.String("username", username), zap.Error(err))
+		}
+	}(user.Email, user.Username)
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:   system.AuditRegistered,
+		UserID:   user.ID,
+		Username: user.Username,
+		Ip:       ip,
+		Detail:   "自助注册 邮箱" + user.Email,
+	})
+	return nil
+}
+
+// VerifyRegisterEmail 验证自助注册邮箱 无需审核时直接启用用户 返回验证后的注册状态
+func (userService *UserService) VerifyRegisterEmail(token string, ip string) (status string, err error) {
+	payload, err := utils.VerifySignedToken(registerVerifyKey(), token)
+	if err != nil {
+		return "", ErrRegisterTokenInvalid
+	}
+	idPart, expPart, ok := strings.Cut(payload, ":")
+	if !ok {
+		return "", ErrRegisterTokenInvalid
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return "", ErrRegisterTokenInvalid
+	}
+	exp, err := strconv.ParseInt(expPart, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return "", ErrRegisterTokenInvalid
+	}
+	var registration system.SysUserRegistration
+	if err = tenant.Skip(global.GVA_DB).Where("user_id = ?", id).First(&registration).Error; err != nil {
+		return "", ErrRegisterTokenInvalid
+	}
+	// 重复点击链接时直接返回当前状态
+	if registration.Status != system.RegisterUnverified {
+		return registration.Status, nil
+	}
+	now := time.Now()
+	status = system.RegisterApproved
+	if global.GVA_CONFIG.Register.RequireApproval {
+		status = system.RegisterPending
+	}
+	err = tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&registration).Where("status = ?", system.RegisterUnverified).
+			Updates(map[string]interface{}{"status": status, "verified_at": now}).Error
+		if err != nil || status != system.RegisterApproved {
+			return err
+		}
+		return tx.Model(&system.SysUser{}).Where("id = ?", registration.UserID).Update("enable", 1).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	userService.refreshSecurityVersion(registration.UserID)
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:   system.AuditEmailVerified,
+		UserID:   registration.UserID,
+		Username: registration.Username,
+		Ip:       ip,
+		Detail:   "验证邮箱" + registration.Email,
+	})
+	return status, nil
+}
+
+// GetRegistrationList 分页获取自助注册记录
+func (userService *UserService) GetRegistrationList(ctx context.Context, info systemReq.RegistrationSearch) (list interface{}, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.WithContext(ctx).Model(&system.SysUserRegistration{})
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if info.Keyword != "" {
+		db = db.Where("username LIKE ? OR email LIKE ?", "%"+info.Keyword+"%", "%"+info.Keyword+"%")
+	}
+	var registrations []system.SysUserRegistration
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	err = db.Order("id desc").Limit(limit).Offset(offset).Find(&registrations).Error
+	return registrations, total, err
+}
+
+// ReviewRegistration 管理员审核已验证邮箱的自助注册 通过时启用用户 拒绝时删除用户
+func (userService *UserService) ReviewRegistration(ctx context.Context, req systemReq.ReviewRegistration, reviewerID uint, ip string) error {
+	var registration system.SysUserRegistration
+	if err := global.GVA_DB.WithContext(ctx).Where("id = ?", req.ID).First(&registration).Error; err != nil {
+		return errors.New("注册记录不存在")
+	}
+	if registration.Status != system.RegisterPending {
+		return errors.New("该注册不在待审核状态")
+	}
+	now := time.Now()
+	status, action := system.RegisterApproved, system.AuditUserApproved
+	if !req.Approve {
+		status, action = system.RegisterRejected, system.AuditUserRejected
+	}
+	err := global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&registration).Where("status = ?", system.RegisterPending).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"reviewed_at": now,
+			"remark":      req.Remark,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("该注册不在待审核状态")
+		}
+		if !req.Approve {
+			return nil
+		}
+		return tx.Model(&system.SysUser{}).Where("id = ?", registration.UserID).Update("enable", 1).Error
+	})
+	if err != nil {
+		return err
+	}
+	if req.Approve {
+		userService.refreshSecurityVersion(registration.UserID)
+	} else if err = userService.DeleteUser(ctx, int(registration.UserID)); err != nil {
+		return err
+	}
+	AuditLogServiceApp.Record(system.SysAuditLog{
+		Action:     action,
+		UserID:     registration.UserID,
+		Username:   registration.Username,
+		OperatorID: reviewerID,
+		Ip:         ip,
+		Detail:     req.Remark,
+	})
+	return nil
+}
+
+// DisabledReason 用户未启用时的登录提示 区分自助注册尚未完成的情况
+func (userService *UserService) DisabledReason(userID uint) string {
+	var registration system.SysUserRegistration
+	if err := tenant.Skip(global.GVA_DB).Where("user_id = ?", userID).First(&registration).Error; err == nil {
+		switch registration.Status {
+		case system.RegisterUnverified:
+			return "请先点击注册邮件中的链接验证邮箱"
+		case system.RegisterPending:
+			return "账号正在等待管理员审核"
+		}
+	}
+	return "用户被禁止登录"
+}
+
+func registerVerifyKey() []byte {
+	return []byte("register_verify:" + global.GVA_CONFIG.JWT.SigningKey)
+}
@@ -0,0 +1,125 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+// createTestRegistration 创建待验证邮箱的自助注册用户 返回验证令牌
+func createTestRegistration(t *testing.T, username string, expiresAt time.Time) (system.SysUser, string) {
+	t.Helper()
+	user := createTestUser(t, username, 888)
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", user.ID).Update("enable", 2)
+	err := tenant.Skip(global.GVA_DB).Create(&system.SysUserRegistration{
+		UserID:   user.ID,
+		Username: username,
+		Email:    username + "@example.com",
+		Status:   system.RegisterUnverified,
+	}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, utils.SignToken(registerVerifyKey(), fmt.Sprintf("%d:%d", user.ID, expiresAt.Unix()))
+}
+
+func userEnabled(t *testing.T, id uint) bool {
+	t.Helper()
+	var user system.SysUser
+	if err := tenant.Skip(global.GVA_DB).Select("enable").Where("id = ?", id).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.Enable == 1
+}
+
+func TestUserService_SelfRegisterDisabled(t *testing.T) {
+	setupTestDB(t, &system.SysUserRegistration{})
+	err := UserServiceApp.SelfRegister(systemReq.SelfRegister{Username: "alice", Password: "Passw0rd!", Email: "alice@example.com"}, "127.0.0.1")
+	if !errors.Is(err, ErrRegisterDisabled) {
+		t.Errorf("SelfRegister() error = %v, want ErrRegisterDisabled", err)
+	}
+}
+
+func TestUserService_VerifyRegisterEmail(t *testing.T) {
+	setupTestDB(t, &system.SysUserRegistration{})
+	global.GVA_CONFIG.JWT.SigningKey = "test"
+	user, token := createTestRegistration(t, "alice", time.Now().Add(time.Hour))
+	if reason := UserServiceApp.DisabledReason(user.ID); reason != "请先点击注册邮件中的链接验证邮箱" {
+		t.Errorf("DisabledReason() = %q", reason)
+	}
+
+	if _, err := UserServiceApp.VerifyRegisterEmail(token+"x", "127.0.0.1"); !errors.Is(err, ErrRegisterTokenInvalid) {
+		t.Errorf("VerifyRegisterEmail(tampered) error = %v", err)
+	}
+	status, err := UserServiceApp.VerifyRegisterEmail(token, "127.0.0.1")
+	if err != nil || status != system.RegisterApproved {
+		t.Fatalf("VerifyRegisterEmail() = %q, %v, want approved", status, err)
+	}
+	if !userEnabled(t, user.ID) {
+		t.Error("user should be enabled after verification without approval")
+	}
+	// 重复点击链接返回当前状态
+	if status, err = UserServiceApp.VerifyRegisterEmail(token, "127.0.0.1"); err != nil || status != system.RegisterApproved {
+		t.Errorf("VerifyRegisterEmail() again = %q, %v", status, err)
+	}
+
+	_, expired := createTestRegistration(t, "bob", time.Now().Add(-time.Minute))
+	if _, err = UserServiceApp.VerifyRegisterEmail(expired, "127.0.0.1"); !errors.Is(err, ErrRegisterTokenInvalid) {
+		t.Errorf("VerifyRegisterEmail(expired) error = %v", err)
+	}
+}
+
+func TestUserService_ReviewRegistration(t *testing.T) {
+	setupTestDB(t, &system.SysUserRegistration{}, &system.SysUserDept{}, &system.SysDept{}, &system.SysUserIdentity{},
+		&system.SysUserPasswordHistory{}, &system.SysApiKey{}, &system.SysUserPasskey{})
+	global.GVA_CONFIG.JWT.SigningKey = "test"
+	global.GVA_CONFIG.Register.RequireApproval = true
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+
+	alice, aliceToken := createTestRegistration(t, "alice", time.Now().Add(time.Hour))
+	bob, bobToken := createTestRegistration(t, "bob", time.Now().Add(time.Hour))
+	for _, token := range []string{aliceToken, bobToken} {
+		if status, err := UserServiceApp.VerifyRegisterEmail(token, "127.0.0.1"); err != nil || status != system.RegisterPending {
+			t.Fatalf("VerifyRegisterEmail() = %q, %v, want pending", status, err)
+		}
+	}
+	if userEnabled(t, alice.ID) {
+		t.Fatal("user should stay disabled until approved")
+	}
+	if reason := UserServiceApp.DisabledReason(alice.ID); reason != "账号正在等待管理员审核" {
+		t.Errorf("DisabledReason() = %q", reason)
+	}
+
+	registrationID := func(userID uint) uint {
+		var r system.SysUserRegistration
+		tenant.Skip(global.GVA_DB).Where("user_id = ?", userID).First(&r)
+		return r.ID
+	}
+	err := UserServiceApp.ReviewRegistration(ctx, systemReq.ReviewRegistration{ID: registrationID(alice.ID), Approve: true}, 1, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !userEnabled(t, alice.ID) {
+		t.Error("approved user should be enabled")
+	}
+	if err = UserServiceApp.ReviewRegistration(ctx, systemReq.ReviewRegistration{ID: registrationID(alice.ID), Approve: false}, 1, "127.0.0.1"); err == nil {
+		t.Error("ReviewRegistration() should reject reviewing twice")
+	}
+
+	if err = UserServiceApp.ReviewRegistration(ctx, systemReq.ReviewRegistration{ID: registrationID(bob.ID), Remark: "spam"}, 1, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ?", bob.ID).Count(&count)
+	if count != 0 {
+		t.Error("rejected user should be deleted")
+	}
+}
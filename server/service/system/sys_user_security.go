@@ -0,0 +1,94 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	securityVersionPrefix   = "user_sec_ver:"
+	securityVersionRevoked  = -1               // 用户已删除或被禁用
+	securityVersionRedisTTL = time.Hour        // 开启redis时变更即时同步到各实例
+	securityVersionLocalTTL = 30 * time.Second // 未开启redis时其他实例最多延迟该时长感知变更
+)
+
+// CheckSecurityVersion 校验令牌中的安全版本号 用户已删除、被禁用或版本号已变更时返回false
+func (userService *UserService) CheckSecurityVersion(id uint, version uint) bool {
+	current, err := userService.SecurityVersion(id)
+	if err != nil {
+		global.GVA_LOG.Error("读取用户安全版本号失败!", zap.Uint("id", id), zap.Error(err))
+		return false
+	}
+	return current == int64(version)
+}
+
+// SecurityVersion 获取用户当前的安全版本号 优先读取缓存 用户已删除或被禁用时为-1
+func (userService *UserService) SecurityVersion(id uint) (int64, error) {
+	key := securityVersionPrefix + strconv.Itoa(int(id))
+	if global.GVA_REDIS != nil {
+		v, err := global.GVA_REDIS.Get(context.Background(), key).Int64()
+		if err == nil {
+			return v, nil
+		}
+	} else if v, ok := global.BlackCache.Get(key); ok {
+		return v.(int64), nil
+	}
+	version, err := userService.loadSecurityVersion(id)
+	if err != nil {
+		return 0, err
+	}
+	// 仅在缓存不存在时写入 避免覆盖并发变更后刷新的新版本号
+	if global.GVA_REDIS != nil {
+		global.GVA_REDIS.SetNX(context.Background(), key, version, securityVersionRedisTTL)
+	} else {
+		_ = global.BlackCache.Add(key, version, securityVersionLocalTTL)
+	}
+	return version, nil
+}
+
+// bumpSecurityVersion 递增用户安全版本号 需在事务提交后调用 refreshSecurityVersion
+func (userService *UserService) bumpSecurityVersion(tx *gorm.DB, id uint) error {
+	return tx.Unscoped().Model(&system.SysUser{}).Where("id = ?", id).
+		UpdateColumn("security_version", gorm.Expr("security_version + 1")).Error
+}
+
+// refreshSecurityVersion 以数据库中的最新状态覆盖缓存
+func (userService *UserService) refreshSecurityVersion(id uint) {
+	version, err := userService.loadSecurityVersion(id)
+	if err != nil {
+		global.GVA_LOG.Error("刷新用户安全版本号失败!", zap.Uint("id", id), zap.Error(err))
+		version = securityVersionRevoked
+	}
+	key := securityVersionPrefix + strconv.Itoa(int(id))
+	if global.GVA_REDIS != nil {
+		err = global.GVA_REDIS.Set(context.Background(), key, version, securityVersionRedisTTL).Err()
+		if err != nil {
+			global.GVA_LOG.Error("刷新用户安全版本号失败!", zap.Uint("id", id), zap.Error(err))
+		}
+		return
+	}
+	global.BlackCache.Set(key, version, securityVersionLocalTTL)
+}
+
+func (userService *UserService) loadSecurityVersion(id uint) (int64, error) {
+	var user system.SysUser
+	err := tenant.Skip(global.GVA_DB).Select("id", "enable", "security_version").Where("id = ?", id).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return securityVersionRevoked, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if user.Enable != 1 {
+		return securityVersionRevoked, nil
+	}
+	return int64(user.SecurityVersion), nil
+}
@@ -0,0 +1,258 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	sessionRevokedPrefix = "session_revoked:"
+	sessionSeenPrefix    = "session_seen:"
+	sessionSeenInterval  = time.Minute // 同一会话最多每分钟回源数据库一次 刷新活跃时间并同步其他实例的注销
+	sessionUserAgentSize = 512
+)
+
+type SessionService struct{}
+
+var SessionServiceApp = new(SessionService)
+
+// CreateSession 登录时创建会话并签发刷新令牌 超出角色会话上限时注销最早活跃的会话
+func (sessionService *SessionService) CreateSession(user *system.SysUser, ip string, userAgent string) (session system.SysUserSession, refreshToken string, refreshExpiresAt time.Time, err error) {
+	limit := user.Authority.MaxSessions
+	if global.GVA_CONFIG.System.UseMultipoint {
+		// 多点登录拦截 同一用户仅保留最新的会话
+		limit = 1
+	}
+	if len(userAgent) > sessionUserAgentSize {
+		userAgent = userAgent[:sessionUserAgentSize]
+	}
+	var evicted []string
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if limit > 0 {
+			var active []string
+			err := tx.Model(&system.SysUserSession{}).
+				Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+				Order("last_seen_at asc").Pluck("session_id", &active).Error
+			if err != nil {
+				return err
+			}
+			if n := len(active) - limit + 1; n > 0 {
+				evicted = active[:n]
+				if err = sessionService.revokeSessions(tx, evicted); err != nil {
+					return err
+				}
+			}
+		}
+		session = system.SysUserSession{
+			GVA_MODEL:  global.GVA_MODEL{TenantId: user.TenantId},
+			UserID:     user.ID,
+			SessionID:  uuid.New().String(),
+			IP:         ip,
+			UserAgent:  userAgent,
+			LastSeenAt: time.Now(),
+		}
+		var err error
+		refreshToken, refreshExpiresAt, err = JwtServiceApp.CreateRefreshToken(tx, user.ID, session.SessionID)
+		if err != nil {
+			return err
+		}
+		session.ExpiresAt = refreshExpiresAt
+		return tx.Create(&session).Error
+	})
+	if err != nil {
+		return system.SysUserSession{}, "", time.Time{}, err
+	}
+	sessionService.markRevoked(evicted...)
+	return session, refreshToken, refreshExpiresAt, nil
+}
+
+// CheckSession 校验令牌所属会话是否有效 并按间隔刷新最后活跃时间与IP 不含会话或查询失败时视为无效
+func (sessionService *SessionService) CheckSession(sessionID string, ip string) bool {
+	if sessionID == "" {
+		return false
+	}
+	if sessionService.isRevoked(sessionID) {
+		return false
+	}
+	if _, ok := global.BlackCache.Get(sessionSeenPrefix + sessionID); ok {
+		return true
+	}
+	var session system.SysUserSession
+	err := global.GVA_DB.Where("session_id = ?", sessionID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			sessionService.markRevoked(sessionID)
+			return false
+		}
+		global.GVA_LOG.Error("查询登录会话失败!", zap.Error(err))
+		return false
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		sessionService.markRevoked(sessionID)
+		return false
+	}
+	global.BlackCache.Set(sessionSeenPrefix+sessionID, struct{}{}, sessionSeenInterval)
+	err = global.GVA_DB.Model(&session).Updates(map[string]interface{}{"last_seen_at": time.Now(), "ip": ip}).Error
+	if err != nil {
+		global.GVA_LOG.Error("更新会话活跃时间失败!", zap.Error(err))
+	}
+	return true
+}
+
+// SessionTenant 会话的当前租户 超级管理员切换租户后与用户所属租户不同 会话不存在时返回fallback
+func (sessionService *SessionService) SessionTenant(sessionID string, fallback uint) uint {
+	var tenantID uint
+	err := global.GVA_DB.Model(&system.SysUserSession{}).Select("tenant_id").Where("session_id = ?", sessionID).Scan(&tenantID).Error
+	if err != nil || tenantID == 0 {
+		return fallback
+	}
+	return tenantID
+}
+
+// GetSessionList 获取用户当前有效的会话
+func (sessionService *SessionService) GetSessionList(userID uint, currentSessionID string) (list []system.SysUserSession, err error) {
+	err = global.GVA_DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").Find(&list).Error
+	for i := range list {
+		list[i].Current = list[i].SessionID == currentSessionID
+	}
+	return list, err
+}
+
+// GetUserSessionList 管理员获取当前租户用户的有效会话
+func (sessionService *SessionService) GetUserSessionList(ctx context.Context, userID uint, currentSessionID string) (list []system.SysUserSession, err error) {
+	if _, err = UserServiceApp.tenantUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return sessionService.GetSessionList(userID, currentSessionID)
+}
+
+// RevokeTenantUserSession 管理员注销当前租户用户的会话 sessionID为空时注销该用户全部会话
+func (sessionService *SessionService) RevokeTenantUserSession(ctx context.Context, userID uint, sessionID string) error {
+	if _, err := UserServiceApp.tenantUser(ctx, userID); err != nil {
+		return err
+	}
+	if sessionID == "" {
+		return sessionService.RevokeUserSessions(userID)
+	}
+	return sessionService.RevokeUserSession(userID, sessionID)
+}
+
+// RevokeUserSession 注销指定用户的某个会话 用户只能注销自己的会话
+func (sessionService *SessionService) RevokeUserSession(userID uint, sessionID string) error {
+	var session system.SysUserSession
+	err := global.GVA_DB.Where("user_id = ? AND session_id = ?", userID, sessionID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("会话不存在")
+		}
+		return err
+	}
+	return sessionService.RevokeSession(sessionID)
+}
+
+// RevokeSession 注销会话 同时吊销其刷新令牌
+func (sessionService *SessionService) RevokeSession(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		return sessionService.revokeSessions(tx, []string{sessionID})
+	})
+	if err != nil {
+		return err
+	}
+	sessionService.markRevoked(sessionID)
+	return nil
+}
+
+// RevokeUserSessions 注销用户的全部会话
+func (sessionService *SessionService) RevokeUserSessions(userID uint) error {
+	return sessionService.RevokeOtherSessions(userID, "")
+}
+
+// RevokeOtherSessions 注销用户除keepSessionID外的全部会话
+func (sessionService *SessionService) RevokeOtherSessions(userID uint, keepSessionID string) error {
+	var ids []string
+	err := global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&system.SysUserSession{}).Where("user_id = ? AND session_id <> ? AND revoked_at IS NULL", userID, keepSessionID).Pluck("session_id", &ids).Error
+		if err != nil {
+			return err
+		}
+		return sessionService.revokeSessions(tx, ids)
+	})
+	if err != nil {
+		return err
+	}
+	sessionService.markRevoked(ids...)
+	return nil
+}
+
+func (sessionService *SessionService) revokeSessions(tx *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	now := time.Now()
+	err := tx.Model(&system.SysUserSession{}).Where("session_id in ? AND revoked_at IS NULL", ids).Update("revoked_at", now).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&system.SysRefreshToken{}).Where("family_id in ? AND revoked_at IS NULL", ids).Update("revoked_at", now).Error
+}
+
+// markRevoked 记录已注销的会话 开启redis时同步给其他实例 记录保留至访问令牌过期
+func (sessionService *SessionService) markRevoked(ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	dr, _ := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
+	for _, id := range ids {
+		global.BlackCache.Set(sessionRevokedPrefix+id, struct{}{}, dr)
+		if global.GVA_REDIS != nil {
+			if err := global.GVA_REDIS.Set(context.Background(), sessionRevokedPrefix+id, 1, dr).Err(); err != nil {
+				global.GVA_LOG.Error("同步会话注销状态失败!", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (sessionService *SessionService) isRevoked(sessionID string) bool {
+	if _, ok := global.BlackCache.Get(sessionRevokedPrefix + sessionID); ok {
+		return true
+	}
+	if global.GVA_REDIS == nil {
+		return false
+	}
+	n, err := global.GVA_REDIS.Exists(context.Background(), sessionRevokedPrefix+sessionID).Result()
+	if err != nil || n == 0 {
+		return false
+	}
+	dr, _ := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
+	global.BlackCache.Set(sessionRevokedPrefix+sessionID, struct{}{}, dr)
+	return true
+}
+
+// LoadRevokedSessions 启动时加载近期注销的会话 避免重启后其未过期的访问令牌重新生效
+func LoadRevokedSessions() {
+	dr, err := utils.ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
+	if err != nil {
+		return
+	}
+	var ids []string
+	err = global.GVA_DB.Model(&system.SysUserSession{}).Where("revoked_at > ?", time.Now().Add(-dr)).Pluck("session_id", &ids).Error
+	if err != nil {
+		global.GVA_LOG.Error("加载已注销会话失败!", zap.Error(err))
+		return
+	}
+	for _, id := range ids {
+		global.BlackCache.Set(sessionRevokedPrefix+id, struct{}{}, dr)
+	}
+}
@@ -0,0 +1,188 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"github.com/songzhibin97/gkit/cache/local_cache"
+)
+
+func TestSessionService_CreateSessionLimit(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "alice", 888)
+	user.Authority.MaxSessions = 2
+
+	var sessions []system.SysUserSession
+	for i := 0; i < 3; i++ {
+		session, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, session)
+	}
+	// 超出上限时注销最早活跃的会话
+	if SessionServiceApp.CheckSession(sessions[0].SessionID, "127.0.0.1") {
+		t.Error("oldest session should be evicted")
+	}
+	for _, s := range sessions[1:] {
+		if !SessionServiceApp.CheckSession(s.SessionID, "127.0.0.1") {
+			t.Errorf("session %s should stay valid", s.SessionID)
+		}
+	}
+	list, err := SessionServiceApp.GetSessionList(user.ID, sessions[2].SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("GetSessionList() len = %d, want 2", len(list))
+	}
+	for _, s := range list {
+		if s.Current != (s.SessionID == sessions[2].SessionID) {
+			t.Errorf("GetSessionList() session %s Current = %v", s.SessionID, s.Current)
+		}
+	}
+}
+
+func TestSessionService_TenantUser(t *testing.T) {
+	setupTestDB(t)
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	user := createTestUser(t, "alice", 888)
+	session, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, err := SessionServiceApp.GetUserSessionList(ctx, user.ID, ""); err != nil || len(list) != 1 {
+		t.Fatalf("GetUserSessionList() = %d, %v, want 1 session", len(list), err)
+	}
+
+	// 管理员不能查看或注销其他租户用户的会话
+	moveTestUserTenant(t, user.ID, 2)
+	if _, err = SessionServiceApp.GetUserSessionList(ctx, user.ID, ""); err == nil {
+		t.Error("GetUserSessionList() should reject user of other tenant")
+	}
+	if err = SessionServiceApp.RevokeTenantUserSession(ctx, user.ID, ""); err == nil {
+		t.Error("RevokeTenantUserSession() should reject user of other tenant")
+	}
+	if !SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Error("session of other tenant should stay valid")
+	}
+	if err = SessionServiceApp.RevokeTenantUserSession(tenant.WithTenant(context.Background(), 2), user.ID, session.SessionID); err != nil {
+		t.Fatal(err)
+	}
+	if SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Error("RevokeTenantUserSession() should revoke session within tenant")
+	}
+}
+
+func TestSessionService_CheckSessionFailClosed(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "alice", 888)
+	session, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SessionServiceApp.CheckSession("", "127.0.0.1") {
+		t.Error("CheckSession() should reject token without session")
+	}
+	// 查询会话失败时拒绝请求
+	if err = global.GVA_DB.Migrator().DropTable(&system.SysUserSession{}); err != nil {
+		t.Fatal(err)
+	}
+	if SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Error("CheckSession() should reject when session lookup fails")
+	}
+}
+
+func TestSessionService_CreateSessionMultipoint(t *testing.T) {
+	setupTestDB(t)
+	global.GVA_CONFIG.System.UseMultipoint = true
+	user := createTestUser(t, "alice", 888)
+	first, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SessionServiceApp.CheckSession(first.SessionID, "127.0.0.1") || !SessionServiceApp.CheckSession(second.SessionID, "127.0.0.1") {
+		t.Error("multipoint login should keep only the latest session")
+	}
+}
+
+func TestSessionService_RevokeUserSession(t *testing.T) {
+	setupTestDB(t)
+	alice := createTestUser(t, "alice", 888)
+	bob := createTestUser(t, "bob", 888)
+	session, _, _, err := SessionServiceApp.CreateSession(&alice, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = SessionServiceApp.RevokeUserSession(bob.ID, session.SessionID); err == nil {
+		t.Error("RevokeUserSession() should not revoke another user's session")
+	}
+	if !SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Fatal("session should stay valid")
+	}
+	if err = SessionServiceApp.RevokeUserSession(alice.ID, session.SessionID); err != nil {
+		t.Fatalf("RevokeUserSession() error = %v", err)
+	}
+	if SessionServiceApp.CheckSession(session.SessionID, "127.0.0.1") {
+		t.Error("revoked session should be invalid")
+	}
+	var active int64
+	global.GVA_DB.Model(&system.SysRefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", session.SessionID).Count(&active)
+	if active != 0 {
+		t.Error("refresh tokens of revoked session should be revoked")
+	}
+}
+
+func TestSessionService_RevokeUserSessions(t *testing.T) {
+	setupTestDB(t)
+	alice := createTestUser(t, "alice", 888)
+	bob := createTestUser(t, "bob", 888)
+	var ids []string
+	for i := 0; i < 2; i++ {
+		session, _, _, err := SessionServiceApp.CreateSession(&alice, "127.0.0.1", "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, session.SessionID)
+	}
+	other, _, _, err := SessionServiceApp.CreateSession(&bob, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = SessionServiceApp.RevokeUserSessions(alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if SessionServiceApp.CheckSession(id, "127.0.0.1") {
+			t.Errorf("session %s should be revoked", id)
+		}
+	}
+	if !SessionServiceApp.CheckSession(other.SessionID, "127.0.0.1") {
+		t.Error("other user's session should stay valid")
+	}
+}
+
+func TestLoadRevokedSessions(t *testing.T) {
+	setupTestDB(t)
+	user := createTestUser(t, "alice", 888)
+	session, _, _, err := SessionServiceApp.CreateSession(&user, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = SessionServiceApp.RevokeSession(session.SessionID); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟重启后内存中的注销记录丢失
+	global.BlackCache = local_cache.NewCache()
+	LoadRevokedSessions()
+	if !SessionServiceApp.isRevoked(session.SessionID) {
+		t.Error("LoadRevokedSessions() should restore recently revoked sessions")
+	}
+}
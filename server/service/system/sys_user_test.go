@@ -0,0 +1,46 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+)
+
+func TestUserService_SetUserInfoSecurityVersion(t *testing.T) {
+	setupTestDB(t, &system.SysAuthorityFieldRule{}, &system.SysUserPasswordHistory{})
+	user := createTestUser(t, "alice", 888)
+	ctx := tenant.WithTenant(context.Background(), tenant.Default)
+	version := func() uint {
+		var u system.SysUser
+		tenant.Skip(global.GVA_DB).Select("security_version").Where("id = ?", user.ID).First(&u)
+		return u.SecurityVersion
+	}
+
+	req := system.SysUser{NickName: "Alice", HeaderImg: "avatar.png", Email: "alice@example.com", Enable: 1}
+	req.ID = user.ID
+	if err := UserServiceApp.SetUserInfo(ctx, 888, req); err != nil {
+		t.Fatal(err)
+	}
+	if v := version(); v != 0 {
+		t.Errorf("editing profile fields bumped security version to %d", v)
+	}
+
+	req.Enable = 2
+	if err := UserServiceApp.SetUserInfo(ctx, 888, req); err != nil {
+		t.Fatal(err)
+	}
+	if v := version(); v != 1 {
+		t.Errorf("disabling user security version = %d, want 1", v)
+	}
+
+	req.Password = "N3w-Passw0rd!"
+	if err := UserServiceApp.SetUserInfo(ctx, 888, req); err != nil {
+		t.Fatal(err)
+	}
+	if v := version(); v != 2 {
+		t.Errorf("resetting password security version = %d, want 2", v)
+	}
+}
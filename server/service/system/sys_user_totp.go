@@ -0,0 +1,270 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorTicketPrefix   = "two_factor_ticket:"
+	twoFactorTicketAttempts = 5 // 单个票据允许的最大验证次数
+)
+
+var (
+	ErrTwoFactorTicketInvalid = errors.New("二次验证已过期，请重新登录")
+	ErrTwoFactorCodeInvalid   = errors.New("验证码错误")
+)
+
+type twoFactorTicket struct {
+	UserID   uint
+	External bool // 通过外部身份(OIDC/LDAP)认证 不使用本地密码 后续不校验密码策略
+	Attempts int
+}
+
+// NeedTwoFactor 判断用户登录是否需要二次验证 用户自行开启或所属任一角色强制开启
+// 角色强制开启时 已绑定通行密钥的用户可直接使用通行密钥验证 无需再绑定验证器
+func (userService *UserService) NeedTwoFactor(user *system.SysUser) (need bool, needSetup bool) {
+	if user.TotpEnable {
+		return true, false
+	}
+	if userService.forceTwoFactor(user) {
+		return true, !PasskeyServiceApp.HasPasskey(user.ID)
+	}
+	return false, false
+}
+
+// forceTwoFactor 用户当前角色或任一所属角色强制开启两步验证
+func (userService *UserService) forceTwoFactor(user *system.SysUser) bool {
+	if user.Authority.RequireTwoFactor {
+		return true
+	}
+	for i := range user.Authorities {
+		if user.Authorities[i].RequireTwoFactor {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTwoFactorTicket 密码校验通过后生成二次验证票据 external为通过外部身份认证
+func (userService *UserService) CreateTwoFactorTicket(userID uint, external bool) string {
+	return createLoginTicket(twoFactorTicketPrefix, userID, external)
+}
+
+// GetTwoFactorTicketUser 通过二次验证票据获取用户及是否通过外部身份认证 每次调用计一次尝试 超过次数票据作废
+func (userService *UserService) GetTwoFactorTicketUser(token string) (user *system.SysUser, external bool, err error) {
+	return getLoginTicketUser(twoFactorTicketPrefix, token, ErrTwoFactorTicketInvalid)
+}
+
+// DeleteTwoFactorTicket 二次验证完成后作废票据
+func (userService *UserService) DeleteTwoFactorTicket(token string) {
+	global.BlackCache.Delete(twoFactorTicketPrefix + token)
+}
+
+// createLoginTicket 登录过程中间步骤的票据 有效期与二次验证票据一致
+func createLoginTicket(prefix string, userID uint, external bool) string {
+	token := uuid.New().String()
+	timeout := time.Second * time.Duration(global.GVA_CONFIG.MFA.TicketTimeout)
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	global.BlackCache.Set(prefix+token, &twoFactorTicket{UserID: userID, External: external}, timeout)
+	return token
+}
+
+func getLoginTicketUser(prefix string, token string, invalid error) (*system.SysUser, bool, error) {
+	v, ok := global.BlackCache.Get(prefix + token)
+	if token == "" || !ok {
+		return nil, false, invalid
+	}
+	ticket := v.(*twoFactorTicket)
+	ticket.Attempts++
+	if ticket.Attempts > twoFactorTicketAttempts {
+		global.BlackCache.Delete(prefix + token)
+		return nil, false, invalid
+	}
+	var u system.SysUser
+	err := tenant.Skip(global.GVA_DB).Where("id = ?", ticket.UserID).Preload("Authorities").Preload("Authority").First(&u).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &u, ticket.External, nil
+}
+
+// SetupTotp 生成两步验证密钥与恢复码 需验证一次验证码后才会开启
+func (userService *UserService) SetupTotp(id uint) (secret string, url string, codes []string, err error) {
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", id).First(&user).Error; err != nil {
+		return
+	}
+	if user.TotpEnable {
+		return "", "", nil, errors.New("已开启两步验证，请先关闭后再重新绑定")
+	}
+	secret, err = utils.GenerateTOTPSecret()
+	if err != nil {
+		return
+	}
+	err = tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+			return err
+		}
+		codes, err = userService.resetRecoveryCodes(tx, id)
+		return err
+	})
+	if err != nil {
+		return
+	}
+	issuer := global.GVA_CONFIG.MFA.Issuer
+	if issuer == "" {
+		issuer = global.GVA_CONFIG.JWT.Issuer
+	}
+	return secret, utils.TOTPURL(issuer, user.Username, secret), codes, nil
+}
+
+// EnableTotp 校验验证码并开启两步验证
+func (userService *UserService) EnableTotp(id uint, code string) (err error) {
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", id).First(&user).Error; err != nil {
+		return err
+	}
+	if user.TotpEnable {
+		return errors.New("已开启两步验证")
+	}
+	if user.TotpSecret == "" {
+		return errors.New("请先获取两步验证绑定信息")
+	}
+	step, ok := utils.ValidateTOTP(user.TotpSecret, code, time.Now())
+	if !ok {
+		return ErrTwoFactorCodeInvalid
+	}
+	return tenant.Skip(global.GVA_DB).Model(&user).Updates(map[string]interface{}{"totp_enable": true, "totp_last_step": step}).Error
+}
+
+// VerifyTwoFactor 校验验证码或恢复码 验证码同一时间片仅可使用一次 恢复码仅可使用一次
+func (userService *UserService) VerifyTwoFactor(user *system.SysUser, code string, recoveryCode string) (err error) {
+	if user.TotpSecret == "" {
+		return errors.New("未绑定两步验证")
+	}
+	if recoveryCode != "" {
+		if !user.TotpEnable {
+			return errors.New("尚未开启两步验证，无法使用恢复码")
+		}
+		result := global.GVA_DB.Model(&system.SysUserRecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.SHA256V([]byte(utils.NormalizeRecoveryCode(recoveryCode)))).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("恢复码无效或已被使用")
+		}
+		return nil
+	}
+	step, ok := utils.ValidateTOTP(user.TotpSecret, code, time.Now())
+	if !ok || step <= user.TotpLastStep {
+		return ErrTwoFactorCodeInvalid
+	}
+	updates := map[string]interface{}{"totp_last_step": step}
+	if !user.TotpEnable {
+		// 角色强制开启时 登录过程中完成绑定
+		updates["totp_enable"] = true
+	}
+	// 以条件更新保证并发请求下同一时间片只会成功一次
+	result := tenant.Skip(global.GVA_DB).Model(&system.SysUser{}).Where("id = ? AND totp_last_step < ?", user.ID, step).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorCodeInvalid
+	}
+	user.TotpEnable = true
+	user.TotpLastStep = step
+	return nil
+}
+
+// DisableTotp 用户自行关闭两步验证 需要提供验证码或恢复码
+func (userService *UserService) DisableTotp(id uint, code string, recoveryCode string) (err error) {
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", id).Preload("Authorities").Preload("Authority").First(&user).Error; err != nil {
+		return err
+	}
+	if !user.TotpEnable {
+		return errors.New("未开启两步验证")
+	}
+	if userService.forceTwoFactor(&user) {
+		return errors.New("当前角色强制开启两步验证，无法关闭")
+	}
+	if err = userService.VerifyTwoFactor(&user, code, recoveryCode); err != nil {
+		return err
+	}
+	return userService.clearTotp(id)
+}
+
+// ResetTotp 管理员重置当前租户用户的两步验证
+func (userService *UserService) ResetTotp(ctx context.Context, id uint) (err error) {
+	if _, err = userService.tenantUser(ctx, id); err != nil {
+		return err
+	}
+	return userService.clearTotp(id)
+}
+
+// clearTotp 清除用户两步验证绑定及恢复码
+func (userService *UserService) clearTotp(id uint) (err error) {
+	return tenant.Skip(global.GVA_DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&system.SysUser{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"totp_enable":    false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&system.SysUserRecoveryCode{}, "user_id = ?", id).Error
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码 旧恢复码全部作废 需要提供当前验证码
+func (userService *UserService) RegenerateRecoveryCodes(id uint, code string) (codes []string, err error) {
+	var user system.SysUser
+	if err = tenant.Skip(global.GVA_DB).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+	if !user.TotpEnable {
+		return nil, errors.New("未开启两步验证")
+	}
+	if err = userService.VerifyTwoFactor(&user, code, ""); err != nil {
+		return nil, err
+	}
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		codes, err = userService.resetRecoveryCodes(tx, id)
+		return err
+	})
+	return codes, err
+}
+
+func (userService *UserService) resetRecoveryCodes(tx *gorm.DB, id uint) ([]string, error) {
+	n := global.GVA_CONFIG.MFA.RecoveryCodes
+	if n <= 0 {
+		n = 10
+	}
+	codes, err := utils.GenerateRecoveryCodes(n)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Unscoped().Delete(&system.SysUserRecoveryCode{}, "user_id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	records := make([]system.SysUserRecoveryCode, 0, len(codes))
+	for i := range codes {
+		records = append(records, system.SysUserRecoveryCode{UserID: id, CodeHash: utils.SHA256V([]byte(utils.NormalizeRecoveryCode(codes[i])))})
+	}
+	return codes, tx.Create(&records).Error
+}
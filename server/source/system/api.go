@@ -44,6 +44,8 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 	}
 	entities := []sysModel.SysApi{
 		{ApiGroup: "jwt", Method: "POST", Path: "/jwt/jsonInBlacklist", Description: "jwt加入黑名单(退出，必选)"},
+		{ApiGroup: "jwt", Method: "POST", Path: "/jwt/rotateKey", Description: "轮换jwt签名密钥"},
+		{ApiGroup: "jwt", Method: "GET", Path: "/jwt/getKeyList", Description: "获取jwt签名密钥"},
 
 		{ApiGroup: "系统用户", Method: "DELETE", Path: "/user/deleteUser", Description: "删除用户"},
 		{ApiGroup: "系统用户", Method: "POST", Path: "/user/admin_register", Description: "用户注册"},
@@ -56,6 +58,34 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "系统用户", Method: "POST", Path: "/user/setUserAuthority", Description: "修改用户角色(必选)"},
 		{ApiGroup: "系统用户", Method: "POST", Path: "/user/resetPassword", Description: "重置用户密码"},
 		{ApiGroup: "系统用户", Method: "PUT", Path: "/user/setSelfSetting", Description: "用户界面配置"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/totpSetup", Description: "获取两步验证绑定信息"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/totpEnable", Description: "开启两步验证"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/totpDisable", Description: "关闭两步验证"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/regenerateRecoveryCodes", Description: "重新生成两步验证恢复码"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/resetTotp", Description: "重置用户两步验证"},
+		{ApiGroup: "系统用户", Method: "GET", Path: "/user/getSessionList", Description: "获取自身登录会话"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/revokeSession", Description: "注销自身登录会话"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/getUserSessionList", Description: "获取用户登录会话"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/revokeUserSession", Description: "注销用户登录会话"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/unlockUser", Description: "解锁被锁定的账号"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/getLockoutList", Description: "获取被锁定的账号"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/createApiKey", Description: "创建自身API密钥"},
+		{ApiGroup: "系统用户", Method: "GET", Path: "/user/getApiKeyList", Description: "获取自身API密钥"},
+		{ApiGroup: "系统用户", Method: "DELETE", Path: "/user/deleteApiKey", Description: "删除自身API密钥"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/getUserApiKeyList", Description: "获取用户API密钥"},
+		{ApiGroup: "系统用户", Method: "DELETE", Path: "/user/deleteUserApiKey", Description: "删除用户API密钥"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/impersonate", Description: "模拟登录为指定用户"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/endImpersonate", Description: "结束模拟登录"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/reviewRegistration", Description: "审核自助注册"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/getRegistrationList", Description: "分页获取自助注册记录"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/passkeyRegisterBegin", Description: "获取通行密钥注册参数"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/passkeyRegisterFinish", Description: "绑定通行密钥"},
+		{ApiGroup: "系统用户", Method: "GET", Path: "/user/getPasskeyList", Description: "获取自身通行密钥"},
+		{ApiGroup: "系统用户", Method: "PUT", Path: "/user/updatePasskey", Description: "修改通行密钥名称"},
+		{ApiGroup: "系统用户", Method: "DELETE", Path: "/user/deletePasskey", Description: "删除通行密钥"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/grantAuthority", Description: "授予用户限时角色"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/revokeAuthorityGrant", Description: "收回用户限时角色"},
+		{ApiGroup: "系统用户", Method: "POST", Path: "/user/getAuthorityGrants", Description: "获取用户的角色授权"},
 
 		{ApiGroup: "api", Method: "POST", Path: "/api/createApi", Description: "创建api"},
 		{ApiGroup: "api", Method: "POST", Path: "/api/deleteApi", Description: "删除Api"},
@@ -75,9 +105,19 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "角色", Method: "PUT", Path: "/authority/updateAuthority", Description: "更新角色信息"},
 		{ApiGroup: "角色", Method: "POST", Path: "/authority/getAuthorityList", Description: "获取角色列表"},
 		{ApiGroup: "角色", Method: "POST", Path: "/authority/setDataAuthority", Description: "设置角色资源权限"},
+		{ApiGroup: "角色", Method: "POST", Path: "/authority/setTwoFactor", Description: "设置角色是否强制两步验证"},
+		{ApiGroup: "角色", Method: "POST", Path: "/authority/setMaxSessions", Description: "设置角色最大同时在线会话数"},
+		{ApiGroup: "角色", Method: "POST", Path: "/authority/setDataScope", Description: "设置角色的数据范围"},
+		{ApiGroup: "角色", Method: "POST", Path: "/authority/setFieldRules", Description: "设置角色的字段规则"},
+		{ApiGroup: "角色", Method: "POST", Path: "/authority/getFieldRules", Description: "获取角色的字段规则"},
+		{ApiGroup: "角色", Method: "GET", Path: "/authority/getFieldRuleModels", Description: "获取支持字段规则的模型"},
 
 		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/updateCasbin", Description: "更改角色api权限"},
 		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/getPolicyPathByAuthorityId", Description: "获取权限列表"},
+		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/explainPermission", Description: "模拟权限判定"},
+		{ApiGroup: "casbin", Method: "GET", Path: "/casbin/exportPolicy", Description: "导出策略文件"},
+		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/diffPolicy", Description: "比较策略文件与当前环境"},
+		{ApiGroup: "casbin", Method: "POST", Path: "/casbin/importPolicy", Description: "导入策略文件"},
 
 		{ApiGroup: "菜单", Method: "POST", Path: "/menu/addBaseMenu", Description: "新增菜单"},
 		{ApiGroup: "菜单", Method: "POST", Path: "/menu/getMenu", Description: "获取菜单树(必选)"},
@@ -147,6 +187,26 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "操作记录", Method: "DELETE", Path: "/sysOperationRecord/deleteSysOperationRecord", Description: "删除操作记录"},
 		{ApiGroup: "操作记录", Method: "DELETE", Path: "/sysOperationRecord/deleteSysOperationRecordByIds", Description: "批量删除操作历史"},
 
+		{ApiGroup: "审计日志", Method: "GET", Path: "/auditLog/getAuditLogList", Description: "获取审计日志列表"},
+
+		{ApiGroup: "登录日志", Method: "GET", Path: "/loginLog/getLoginLogList", Description: "获取登录日志列表"},
+		{ApiGroup: "登录日志", Method: "GET", Path: "/loginLog/getRecentLogins", Description: "获取自身最近的登录记录"},
+		{ApiGroup: "部门", Method: "POST", Path: "/dept/createDept", Description: "新建部门"},
+		{ApiGroup: "部门", Method: "PUT", Path: "/dept/updateDept", Description: "更新部门"},
+		{ApiGroup: "部门", Method: "POST", Path: "/dept/deleteDept", Description: "删除部门"},
+		{ApiGroup: "部门", Method: "POST", Path: "/dept/moveDept", Description: "调整上级部门"},
+		{ApiGroup: "部门", Method: "POST", Path: "/dept/mergeDept", Description: "合并部门"},
+		{ApiGroup: "部门", Method: "POST", Path: "/dept/setUserDepts", Description: "设置用户所属部门"},
+		{ApiGroup: "部门", Method: "GET", Path: "/dept/getDeptTree", Description: "获取部门树"},
+		{ApiGroup: "部门", Method: "POST", Path: "/dept/getUserDepts", Description: "获取用户所属部门"},
+		{ApiGroup: "权限审批", Method: "GET", Path: "/permissionChange/getChangeList", Description: "分页获取权限变更申请"},
+		{ApiGroup: "权限审批", Method: "POST", Path: "/permissionChange/approveChange", Description: "审批通过权限变更"},
+		{ApiGroup: "权限审批", Method: "POST", Path: "/permissionChange/rejectChange", Description: "驳回权限变更"},
+		{ApiGroup: "租户", Method: "POST", Path: "/tenant/createTenant", Description: "创建租户"},
+		{ApiGroup: "租户", Method: "PUT", Path: "/tenant/updateTenant", Description: "更新租户"},
+		{ApiGroup: "租户", Method: "GET", Path: "/tenant/getTenantList", Description: "分页获取租户"},
+		{ApiGroup: "租户", Method: "POST", Path: "/tenant/switchTenant", Description: "切换当前租户"},
+
 		{ApiGroup: "断点续传(插件版)", Method: "POST", Path: "/simpleUploader/upload", Description: "插件版分片上传"},
 		{ApiGroup: "断点续传(插件版)", Method: "GET", Path: "/simpleUploader/checkFileMd5", Description: "文件完整度验证"},
 		{ApiGroup: "断点续传(插件版)", Method: "GET", Path: "/simpleUploader/mergeFileMd5", Description: "上传完成合并文件"},
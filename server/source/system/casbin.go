@@ -2,9 +2,11 @@ package system
 
 import (
 	"context"
+	"strconv"
 
 	adapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
@@ -65,6 +67,12 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/authority/deleteAuthority", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/authority/getAuthorityList", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/authority/setDataAuthority", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authority/setTwoFactor", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authority/setMaxSessions", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authority/setDataScope", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authority/setFieldRules", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authority/getFieldRules", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/authority/getFieldRuleModels", V2: "GET"},
 
 		{Ptype: "p", V0: "888", V1: "/menu/getMenu", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/menu/getMenuList", V2: "POST"},
@@ -86,6 +94,34 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/user/setUserAuthorities", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/user/resetPassword", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/user/setSelfSetting", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/user/totpSetup", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/totpEnable", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/totpDisable", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/regenerateRecoveryCodes", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/resetTotp", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getSessionList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/user/revokeSession", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getUserSessionList", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/revokeUserSession", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/unlockUser", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getLockoutList", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/createApiKey", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getApiKeyList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/user/deleteApiKey", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/user/getUserApiKeyList", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/deleteUserApiKey", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/user/impersonate", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/endImpersonate", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/reviewRegistration", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getRegistrationList", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/passkeyRegisterBegin", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/passkeyRegisterFinish", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getPasskeyList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/user/updatePasskey", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/user/deletePasskey", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/user/grantAuthority", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/revokeAuthorityGrant", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/user/getAuthorityGrants", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/findFile", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/breakpointContinueFinish", V2: "POST"},
@@ -100,8 +136,14 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 
 		{Ptype: "p", V0: "888", V1: "/casbin/updateCasbin", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/casbin/getPolicyPathByAuthorityId", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/casbin/explainPermission", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/casbin/exportPolicy", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/casbin/diffPolicy", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/casbin/importPolicy", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/jwt/jsonInBlacklist", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/jwt/rotateKey", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/jwt/getKeyList", V2: "GET"},
 
 		{Ptype: "p", V0: "888", V1: "/system/getSystemConfig", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/system/setSystemConfig", V2: "POST"},
@@ -149,6 +191,25 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/sysOperationRecord/getSysOperationRecordList", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/sysOperationRecord/deleteSysOperationRecord", V2: "DELETE"},
 		{Ptype: "p", V0: "888", V1: "/sysOperationRecord/deleteSysOperationRecordByIds", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/auditLog/getAuditLogList", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/loginLog/getLoginLogList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/loginLog/getRecentLogins", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/dept/createDept", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dept/updateDept", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/dept/deleteDept", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dept/moveDept", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dept/mergeDept", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dept/setUserDepts", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dept/getDeptTree", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/dept/getUserDepts", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/permissionChange/getChangeList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/permissionChange/approveChange", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/permissionChange/rejectChange", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/tenant/createTenant", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/tenant/updateTenant", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/tenant/getTenantList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/tenant/switchTenant", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/email/emailTest", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/email/sendEmail", V2: "POST"},
@@ -210,6 +271,21 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "8881", V1: "/menu/updateBaseMenu", V2: "POST"},
 		{Ptype: "p", V0: "8881", V1: "/menu/getBaseMenuById", V2: "POST"},
 		{Ptype: "p", V0: "8881", V1: "/user/changePassword", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/totpSetup", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/totpEnable", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/totpDisable", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/regenerateRecoveryCodes", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/getSessionList", V2: "GET"},
+		{Ptype: "p", V0: "8881", V1: "/user/revokeSession", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/createApiKey", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/getApiKeyList", V2: "GET"},
+		{Ptype: "p", V0: "8881", V1: "/user/deleteApiKey", V2: "DELETE"},
+		{Ptype: "p", V0: "8881", V1: "/user/passkeyRegisterBegin", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/passkeyRegisterFinish", V2: "POST"},
+		{Ptype: "p", V0: "8881", V1: "/user/getPasskeyList", V2: "GET"},
+		{Ptype: "p", V0: "8881", V1: "/user/updatePasskey", V2: "PUT"},
+		{Ptype: "p", V0: "8881", V1: "/user/deletePasskey", V2: "DELETE"},
+		{Ptype: "p", V0: "8881", V1: "/loginLog/getRecentLogins", V2: "GET"},
 		{Ptype: "p", V0: "8881", V1: "/user/getUserList", V2: "POST"},
 		{Ptype: "p", V0: "8881", V1: "/user/setUserAuthority", V2: "POST"},
 		{Ptype: "p", V0: "8881", V1: "/fileUploadAndDownload/upload", V2: "POST"},
@@ -252,6 +328,21 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "9528", V1: "/menu/updateBaseMenu", V2: "POST"},
 		{Ptype: "p", V0: "9528", V1: "/menu/getBaseMenuById", V2: "POST"},
 		{Ptype: "p", V0: "9528", V1: "/user/changePassword", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/totpSetup", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/totpEnable", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/totpDisable", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/regenerateRecoveryCodes", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/getSessionList", V2: "GET"},
+		{Ptype: "p", V0: "9528", V1: "/user/revokeSession", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/createApiKey", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/getApiKeyList", V2: "GET"},
+		{Ptype: "p", V0: "9528", V1: "/user/deleteApiKey", V2: "DELETE"},
+		{Ptype: "p", V0: "9528", V1: "/user/passkeyRegisterBegin", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/passkeyRegisterFinish", V2: "POST"},
+		{Ptype: "p", V0: "9528", V1: "/user/getPasskeyList", V2: "GET"},
+		{Ptype: "p", V0: "9528", V1: "/user/updatePasskey", V2: "PUT"},
+		{Ptype: "p", V0: "9528", V1: "/user/deletePasskey", V2: "DELETE"},
+		{Ptype: "p", V0: "9528", V1: "/loginLog/getRecentLogins", V2: "GET"},
 		{Ptype: "p", V0: "9528", V1: "/user/getUserList", V2: "POST"},
 		{Ptype: "p", V0: "9528", V1: "/user/setUserAuthority", V2: "POST"},
 		{Ptype: "p", V0: "9528", V1: "/fileUploadAndDownload/upload", V2: "POST"},
@@ -272,6 +363,10 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "9528", V1: "/autoCode/createTemp", V2: "POST"},
 		{Ptype: "p", V0: "9528", V1: "/user/getUserInfo", V2: "GET"},
 	}
+	// 初始策略属于默认租户的域
+	for i := range entities {
+		entities[i].V3 = strconv.Itoa(int(tenant.Default))
+	}
 	if err := db.Create(&entities).Error; err != nil {
 		return ctx, errors.Wrap(err, "Casbin 表 ("+i.InitializerName()+") 数据初始化失败!")
 	}
@@ -0,0 +1,67 @@
+package system
+
+import (
+	"context"
+
+	sysModel "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tenant"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const initOrderTenant = system.InitOrderSystem + 1
+
+type initTenant struct{}
+
+// auto run
+func init() {
+	system.RegisterInit(initOrderTenant, &initTenant{})
+}
+
+func (i *initTenant) InitializerName() string {
+	return sysModel.SysTenant{}.TableName()
+}
+
+func (i *initTenant) MigrateTable(ctx context.Context) (context.Context, error) {
+	db, ok := ctx.Value("db").(*gorm.DB)
+	if !ok {
+		return ctx, system.ErrMissingDBContext
+	}
+	return ctx, db.AutoMigrate(&sysModel.SysTenant{})
+}
+
+func (i *initTenant) TableCreated(ctx context.Context) bool {
+	db, ok := ctx.Value("db").(*gorm.DB)
+	if !ok {
+		return false
+	}
+	return db.Migrator().HasTable(&sysModel.SysTenant{})
+}
+
+func (i *initTenant) InitializeData(ctx context.Context) (context.Context, error) {
+	db, ok := ctx.Value("db").(*gorm.DB)
+	if !ok {
+		return ctx, system.ErrMissingDBContext
+	}
+	// 空表中首条记录的ID即为默认租户ID
+	entities := []sysModel.SysTenant{
+		{Name: "默认租户", Code: "default", Enable: 1, Remark: "初始化时创建 超级管理员所属租户"},
+	}
+	if err := db.Create(&entities).Error; err != nil {
+		return ctx, errors.Wrap(err, sysModel.SysTenant{}.TableName()+"表数据初始化失败!")
+	}
+	next := context.WithValue(ctx, i.InitializerName(), entities)
+	return next, nil
+}
+
+func (i *initTenant) DataInserted(ctx context.Context) bool {
+	db, ok := ctx.Value("db").(*gorm.DB)
+	if !ok {
+		return false
+	}
+	if errors.Is(db.Where("id = ?", tenant.Default).First(&sysModel.SysTenant{}).Error, gorm.ErrRecordNotFound) {
+		return false
+	}
+	return true
+}
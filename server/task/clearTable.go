@@ -30,6 +30,36 @@ func ClearTable(db *gorm.DB) error {
 		Interval:     "168h",
 	})
 
+	ClearTableDetail = append(ClearTableDetail, common.ClearDB{
+		TableName:    "sys_refresh_tokens",
+		CompareField: "expires_at",
+		Interval:     "24h",
+	})
+
+	ClearTableDetail = append(ClearTableDetail, common.ClearDB{
+		TableName:    "sys_user_sessions",
+		CompareField: "expires_at",
+		Interval:     "24h",
+	})
+
+	ClearTableDetail = append(ClearTableDetail, common.ClearDB{
+		TableName:    "sys_login_lockouts",
+		CompareField: "updated_at",
+		Interval:     "720h",
+	})
+
+	ClearTableDetail = append(ClearTableDetail, common.ClearDB{
+		TableName:    "sys_audit_logs",
+		CompareField: "created_at",
+		Interval:     "2160h",
+	})
+
+	ClearTableDetail = append(ClearTableDetail, common.ClearDB{
+		TableName:    "sys_login_logs",
+		CompareField: "created_at",
+		Interval:     "2160h",
+	})
+
 	if db == nil {
 		return errors.New("db Cannot be empty")
 	}
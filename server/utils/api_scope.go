@@ -0,0 +1,32 @@
+package utils
+
+import "strings"
+
+// MatchApiScope 判断请求是否在授权范围内
+// 范围格式: "*" 全部接口; "/path" 任意方法; "GET /path" 指定方法; 路径以 "*" 结尾时按前缀匹配
+func MatchApiScope(scopes []string, method string, path string) bool {
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "*" {
+			return true
+		}
+		m, p, ok := strings.Cut(scope, " ")
+		if !ok {
+			m, p = "", scope
+		}
+		p = strings.TrimSpace(p)
+		if m != "" && !strings.EqualFold(m, method) {
+			continue
+		}
+		if prefix, wildcard := strings.CutSuffix(p, "*"); wildcard {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestMatchApiScope(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		method string
+		path   string
+		want   bool
+	}{
+		{[]string{"*"}, "POST", "/user/deleteUser", true},
+		{[]string{"GET /user/getUserInfo"}, "GET", "/user/getUserInfo", true},
+		{[]string{"GET /user/getUserInfo"}, "POST", "/user/getUserInfo", false},
+		{[]string{"get /user/getUserInfo"}, "GET", "/user/getUserInfo", true},
+		{[]string{"/user/getUserInfo"}, "PUT", "/user/getUserInfo", true},
+		{[]string{"/sysDictionary/*"}, "GET", "/sysDictionary/findSysDictionary", true},
+		{[]string{"GET /sysDictionary/*"}, "DELETE", "/sysDictionary/deleteSysDictionary", false},
+		{[]string{"/sysDictionary/*"}, "GET", "/sysDictionaryDetail/find", false},
+		{[]string{"/user/getUserInfo"}, "GET", "/user/getUserInfoX", false},
+		{nil, "GET", "/user/getUserInfo", false},
+	}
+	for _, tt := range tests {
+		if got := MatchApiScope(tt.scopes, tt.method, tt.path); got != tt.want {
+			t.Errorf("MatchApiScope(%v, %s, %s) = %v, want %v", tt.scopes, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
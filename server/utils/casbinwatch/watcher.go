@@ -0,0 +1,92 @@
+// Package casbinwatch 基于redis发布订阅的casbin策略监听 多实例部署时某个实例修改策略后通知其他实例重新加载
+package casbinwatch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Channel 策略变更通知使用的redis频道
+const Channel = "casbin_policy_update"
+
+// Watcher 实现casbin的persist.Watcher 消息内容为发布者的实例ID 收到自身发布的消息时忽略
+type Watcher struct {
+	client    redis.UniversalClient
+	pubsub    *redis.PubSub
+	id        string
+	mu        sync.RWMutex
+	callback  func(string)
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New 订阅策略变更频道 订阅失败时返回错误 由调用方决定是否退化为单实例模式
+func New(ctx context.Context, client redis.UniversalClient) (*Watcher, error) {
+	pubsub := client.Subscribe(ctx, Channel)
+	// 等待订阅确认 确保返回后不会错过其他实例的通知
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+	w := &Watcher{
+		client: client,
+		pubsub: pubsub,
+		id:     uuid.New().String(),
+		done:   make(chan struct{}),
+	}
+	go w.listen()
+	return w, nil
+}
+
+// SetUpdateCallback 设置收到其他实例通知时的回调 通常为重新加载策略
+func (w *Watcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	w.callback = callback
+	w.mu.Unlock()
+	return nil
+}
+
+// Update 通知其他实例策略已变更
+func (w *Watcher) Update() error {
+	return w.client.Publish(context.Background(), Channel, w.id).Err()
+}
+
+// Close 取消订阅 之后不再调用回调
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		_ = w.pubsub.Close()
+	})
+}
+
+func (w *Watcher) listen() {
+	// 连接断开时go-redis会自动重连并重新订阅
+	ch := w.pubsub.Channel()
+	for {
+		select {
+		case <-w.done:
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			w.handle(msg.Payload)
+		}
+	}
+}
+
+// handle 处理其他实例的通知 本实例发布的消息已在本地生效 无需重复加载
+func (w *Watcher) handle(publisher string) {
+	if publisher == w.id {
+		return
+	}
+	w.mu.RLock()
+	callback := w.callback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(publisher)
+	}
+}
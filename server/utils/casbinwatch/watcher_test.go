@@ -0,0 +1,23 @@
+package casbinwatch
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2/persist"
+)
+
+var _ persist.Watcher = (*Watcher)(nil)
+
+func TestHandle(t *testing.T) {
+	w := &Watcher{id: "self"}
+	w.handle("other")
+	var got []string
+	_ = w.SetUpdateCallback(func(publisher string) {
+		got = append(got, publisher)
+	})
+	w.handle("self")
+	w.handle("other")
+	if len(got) != 1 || got[0] != "other" {
+		t.Errorf("callback calls = %v, want [other]", got)
+	}
+}
@@ -134,14 +134,23 @@ func GetUserName(c *gin.Context) string {
 	}
 }
 
-func LoginToken(user system.Login) (token string, claims systemReq.CustomClaims, err error) {
+func LoginToken(user system.Login, sessionID string) (token string, claims systemReq.CustomClaims, err error) {
+	return ImpersonateToken(user, sessionID, nil)
+}
+
+// ImpersonateToken 签发jwt impersonator不为空时为管理员模拟登录该用户
+func ImpersonateToken(user system.Login, sessionID string, impersonator *systemReq.Impersonator) (token string, claims systemReq.CustomClaims, err error) {
 	j := NewJWT()
 	claims = j.CreateClaims(systemReq.BaseClaims{
-		UUID:        user.GetUUID(),
-		ID:          user.GetUserId(),
-		NickName:    user.GetNickname(),
-		Username:    user.GetUsername(),
-		AuthorityId: user.GetAuthorityId(),
+		UUID:            user.GetUUID(),
+		ID:              user.GetUserId(),
+		NickName:        user.GetNickname(),
+		Username:        user.GetUsername(),
+		AuthorityId:     user.GetAuthorityId(),
+		SessionID:       sessionID,
+		SecurityVersion: user.GetSecurityVersion(),
+		TenantId:        user.GetTenantId(),
+		Impersonator:    impersonator,
 	})
 	token, err = j.CreateToken(claims)
 	return
@@ -0,0 +1,148 @@
+// Package datascope 行级数据权限 为实现了 Model 接口的模型在查询、更新、删除时自动追加角色的数据范围条件
+package datascope
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 数据范围
+const (
+	ScopeAll      = "all"       // 全部数据
+	ScopeSelf     = "self"      // 仅本人的数据
+	ScopeDept     = "dept"      // 本部门的数据
+	ScopeDeptTree = "dept_tree" // 本部门及下级部门的数据
+	ScopeCustom   = "custom"    // 自定义条件
+)
+
+const (
+	callbackName = "gva:data_scope"
+	skipKey      = "gva:skip_data_scope"
+	maxRuleLen   = 500
+)
+
+var ErrInvalidRule = errors.New("自定义数据范围条件不合法")
+
+// Model 实现该接口的模型参与数据权限过滤
+type Model interface {
+	// DataScopeColumn 记录所属用户ID的列名 如 created_by
+	DataScopeColumn() string
+}
+
+// Subject 发起查询的用户
+type Subject struct {
+	UserID      uint
+	AuthorityID uint
+}
+
+// Rule 角色的数据范围
+type Rule struct {
+	Scope  string // 数据范围 为空时视为全部数据
+	Custom string // 自定义条件 可使用 @userId @authorityId 引用当前用户 语法见 ValidateRule
+}
+
+// Resolver 查询角色的数据范围及部门成员 由业务层实现
+type Resolver interface {
+	Rule(authorityID uint) (Rule, error)
+	DeptUserIDs(userID uint, tree bool) ([]uint, error)
+}
+
+type subjectKey struct{}
+
+// WithSubject 将当前用户写入context 查询时需通过 db.WithContext 传入
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFrom 从context中取出当前用户
+func SubjectFrom(ctx context.Context) (Subject, bool) {
+	if ctx == nil {
+		return Subject{}, false
+	}
+	subject, ok := ctx.Value(subjectKey{}).(Subject)
+	return subject, ok && subject.UserID != 0
+}
+
+// Skip 本次操作不做数据权限过滤 用于系统内部的统计、校验等场景
+func Skip(db *gorm.DB) *gorm.DB {
+	return db.Set(skipKey, true)
+}
+
+// Register 为数据库注册数据权限回调 重复注册时跳过
+func Register(db *gorm.DB, resolver Resolver) error {
+	if db.Callback().Query().Get(callbackName) != nil {
+		return nil
+	}
+	fn := func(tx *gorm.DB) { apply(tx, resolver) }
+	if err := db.Callback().Query().Before("gorm:query").Register(callbackName, fn); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register(callbackName, fn); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register(callbackName, fn); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register(callbackName, fn)
+}
+
+func apply(db *gorm.DB, resolver Resolver) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	if skip, ok := db.Get(skipKey); ok && skip == true {
+		return
+	}
+	model, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(Model)
+	if !ok {
+		return
+	}
+	subject, ok := SubjectFrom(db.Statement.Context)
+	if !ok {
+		return
+	}
+	expr, err := Expression(resolver, subject, model.DataScopeColumn())
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	if expr != nil {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{expr}})
+	}
+}
+
+// Expression 生成当前用户在指定列上的数据范围条件 全部数据时返回nil 未知的数据范围按本人处理
+func Expression(resolver Resolver, subject Subject, column string) (clause.Expression, error) {
+	rule, err := resolver.Rule(subject.AuthorityID)
+	if err != nil {
+		return nil, err
+	}
+	owner := clause.Column{Table: clause.CurrentTable, Name: column}
+	switch rule.Scope {
+	case "", ScopeAll:
+		return nil, nil
+	case ScopeDept, ScopeDeptTree:
+		ids, err := resolver.DeptUserIDs(subject.UserID, rule.Scope == ScopeDeptTree)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			ids = []uint{subject.UserID}
+		}
+		return clause.Expr{SQL: "? IN ?", Vars: []interface{}{owner, ids}}, nil
+	case ScopeCustom:
+		if err = ValidateRule(rule.Custom); err != nil {
+			return nil, err
+		}
+		return clause.NamedExpr{SQL: "(" + rule.Custom + ")", Vars: []interface{}{map[string]interface{}{
+			"userId":      subject.UserID,
+			"authorityId": subject.AuthorityID,
+		}}}, nil
+	default:
+		return clause.Expr{SQL: "? = ?", Vars: []interface{}{owner, subject.UserID}}, nil
+	}
+}
@@ -0,0 +1,186 @@
+package datascope
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type scopedRecord struct {
+	ID        uint
+	Name      string
+	CreatedBy uint
+}
+
+func (scopedRecord) DataScopeColumn() string {
+	return "created_by"
+}
+
+type plainRecord struct {
+	ID        uint
+	CreatedBy uint
+}
+
+type fakeResolver struct {
+	rules map[uint]Rule
+	depts map[uint][]uint
+}
+
+func (r fakeResolver) Rule(authorityID uint) (Rule, error) {
+	return r.rules[authorityID], nil
+}
+
+func (r fakeResolver) DeptUserIDs(userID uint, tree bool) ([]uint, error) {
+	if tree {
+		return append(r.depts[userID], 4), nil
+	}
+	return r.depts[userID], nil
+}
+
+func TestScopes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&scopedRecord{}, &plainRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	resolver := fakeResolver{
+		rules: map[uint]Rule{
+			1: {Scope: ScopeAll},
+			2: {Scope: ScopeSelf},
+			3: {Scope: ScopeDept},
+			4: {Scope: ScopeDeptTree},
+			5: {Scope: ScopeCustom, Custom: "created_by = @userId OR name = 'public'"},
+			6: {Scope: "unknown"},
+			7: {Scope: ScopeCustom, Custom: "1=1 OR id IN (SELECT id FROM scoped_records)"},
+		},
+		depts: map[uint][]uint{1: {1, 2}},
+	}
+	if err = Register(db, resolver); err != nil {
+		t.Fatal(err)
+	}
+	if err = Register(db, resolver); err != nil {
+		t.Fatal(err)
+	}
+	records := []scopedRecord{
+		{Name: "a", CreatedBy: 1},
+		{Name: "b", CreatedBy: 2},
+		{Name: "c", CreatedBy: 3},
+		{Name: "public", CreatedBy: 3},
+		{Name: "d", CreatedBy: 4},
+	}
+	if err = db.Create(&records).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&[]plainRecord{{CreatedBy: 1}, {CreatedBy: 2}}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		authorityID uint
+		want        int64
+	}{
+		{1, 5},
+		{2, 1},
+		{3, 2},
+		{4, 3},
+		{5, 2},
+		{6, 1},
+	}
+	for _, tt := range tests {
+		ctx := WithSubject(context.Background(), Subject{UserID: 1, AuthorityID: tt.authorityID})
+		var count int64
+		if err = db.WithContext(ctx).Model(&scopedRecord{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != tt.want {
+			t.Errorf("authority %d count = %d, want %d", tt.authorityID, count, tt.want)
+		}
+		var list []scopedRecord
+		if err = db.WithContext(ctx).Where("name <> ?", "").Find(&list).Error; err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(list)) != tt.want {
+			t.Errorf("authority %d find = %d, want %d", tt.authorityID, len(list), tt.want)
+		}
+	}
+	// 不合法的自定义条件使查询失败 不会放开数据范围
+	var count int64
+	ctx := WithSubject(context.Background(), Subject{UserID: 1, AuthorityID: 7})
+	if err = db.WithContext(ctx).Model(&scopedRecord{}).Count(&count).Error; !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("invalid custom rule error = %v, want ErrInvalidRule", err)
+	}
+
+	// 未传入用户、跳过过滤及未接入的模型不做过滤
+	ctx = WithSubject(context.Background(), Subject{UserID: 1, AuthorityID: 2})
+	if err = db.Model(&scopedRecord{}).Count(&count).Error; err != nil || count != 5 {
+		t.Errorf("without subject count = %d, %v, want 5", count, err)
+	}
+	if err = Skip(db.WithContext(ctx)).Model(&scopedRecord{}).Count(&count).Error; err != nil || count != 5 {
+		t.Errorf("skip count = %d, %v, want 5", count, err)
+	}
+	if err = db.WithContext(ctx).Model(&plainRecord{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("plain model count = %d, %v, want 2", count, err)
+	}
+
+	// 更新与删除同样按数据范围过滤
+	result := db.WithContext(ctx).Model(&scopedRecord{}).Where("name IN ?", []string{"a", "b"}).Update("name", "x")
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("update affected %d, %v, want 1", result.RowsAffected, result.Error)
+	}
+	result = db.WithContext(ctx).Where("id > ?", 0).Delete(&scopedRecord{})
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("delete affected %d, %v, want 1", result.RowsAffected, result.Error)
+	}
+	db.Model(&scopedRecord{}).Count(&count)
+	if count != 4 {
+		t.Errorf("remaining = %d, want 4", count)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := []string{
+		"created_by = @userId",
+		"status = 1 AND (created_by = @userId OR is_public = 1)",
+		"dept_id IN (1, 2, -3) AND deleted_by IS NULL",
+		"NOT (level >= 2.5) OR name LIKE 'a%' OR owner_id <> updated_by",
+		"authority_id != @authorityId and name not like 'x'",
+	}
+	for _, rule := range valid {
+		if err := ValidateRule(rule); err != nil {
+			t.Errorf("ValidateRule(%q) = %v", rule, err)
+		}
+	}
+	invalid := []string{
+		"", "  ", "1 = 1; DROP TABLE users", "1 = 1 -- x", "1 = 1 /* x */", "1 = 1 # x",
+		"1=1 OR id IN (SELECT id FROM sys_users)",
+		"created_by = @userId OR id IN (SELECT id FROM sys_users)",
+		"EXISTS (SELECT 1 FROM sys_users)",
+		"created_by = sleep(5)",
+		"created_by = @password",
+		"name = 'a\\' OR 1=1",
+		"name = 'a",
+		"created_by = @userId OR",
+		"(created_by = @userId",
+		"created_by",
+		"created_by IN ()",
+		"name LIKE @userId",
+		"created_by NOT = 1",
+		"created_by = 1 UNION SELECT 1",
+		"and = 1",
+		"created_by || 1",
+		"created_by = 1abc",
+		strings.Repeat("(", 20) + "a = 1" + strings.Repeat(")", 20),
+	}
+	for _, rule := range invalid {
+		if err := ValidateRule(rule); err == nil {
+			t.Errorf("ValidateRule(%q) = nil, want error", rule)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package datascope
+
+import (
+	"strings"
+)
+
+// 自定义条件中允许的比较运算符
+var ruleOperators = map[string]bool{"=": true, "<>": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true}
+
+// 自定义条件中允许引用的当前用户参数
+var ruleParams = map[string]bool{"@userId": true, "@authorityId": true}
+
+// 自定义条件的关键字 不能用作列名
+var ruleKeywords = map[string]bool{"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "IN": true, "LIKE": true}
+
+type ruleTokenKind int
+
+const (
+	ruleIdent ruleTokenKind = iota
+	ruleNumber
+	ruleString
+	ruleParam
+	ruleSymbol
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+}
+
+// ValidateRule 校验自定义条件 仅允许由列与值的比较通过 AND OR NOT 及括号组合而成的条件
+//
+//	条件: 列 运算符 值 | 列 IS [NOT] NULL | 列 [NOT] IN (值, ...) | 列 [NOT] LIKE 字符串
+//	运算符: = <> != > >= < <=
+//	值: 列名 数字 不含引号与反斜杠的字符串 @userId @authorityId
+//
+// 不支持函数调用与子查询
+func ValidateRule(rule string) error {
+	rule = strings.TrimSpace(rule)
+	if rule == "" || len(rule) > maxRuleLen {
+		return ErrInvalidRule
+	}
+	tokens, ok := tokenizeRule(rule)
+	if !ok {
+		return ErrInvalidRule
+	}
+	p := &ruleParser{tokens: tokens}
+	if !p.expr(0) || p.pos != len(p.tokens) {
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+func tokenizeRule(rule string) ([]ruleToken, bool) {
+	var tokens []ruleToken
+	for i := 0; i < len(rule); {
+		c := rule[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c) || c == '@':
+			j := i + 1
+			for j < len(rule) && isIdentPart(rule[j]) {
+				j++
+			}
+			kind := ruleIdent
+			if c == '@' {
+				if !ruleParams[rule[i:j]] {
+					return nil, false
+				}
+				kind = ruleParam
+			}
+			tokens = append(tokens, ruleToken{kind, rule[i:j]})
+			i = j
+		case isDigit(c) || (c == '-' && i+1 < len(rule) && isDigit(rule[i+1])):
+			j, dot := i+1, false
+			for j < len(rule) && (isDigit(rule[j]) || (rule[j] == '.' && !dot)) {
+				dot = dot || rule[j] == '.'
+				j++
+			}
+			if j < len(rule) && isIdentPart(rule[j]) {
+				return nil, false
+			}
+			tokens = append(tokens, ruleToken{ruleNumber, rule[i:j]})
+			i = j
+		case c == '\'':
+			j := i + 1
+			for j < len(rule) && rule[j] != '\'' && rule[j] != '\\' {
+				j++
+			}
+			if j >= len(rule) || rule[j] != '\'' {
+				return nil, false
+			}
+			tokens = append(tokens, ruleToken{ruleString, rule[i : j+1]})
+			i = j + 1
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, ruleToken{ruleSymbol, rule[i : i+1]})
+			i++
+		case c == '<' || c == '>' || c == '!':
+			j := i + 1
+			if j < len(rule) && (rule[j] == '=' || (c == '<' && rule[j] == '>')) {
+				j++
+			}
+			if !ruleOperators[rule[i:j]] {
+				return nil, false
+			}
+			tokens = append(tokens, ruleToken{ruleSymbol, rule[i:j]})
+			i = j
+		default:
+			return nil, false
+		}
+	}
+	return tokens, true
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// ruleParser 按 ValidateRule 的语法递归下降解析自定义条件
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+// maxRuleDepth 括号及NOT的最大嵌套层数
+const maxRuleDepth = 10
+
+func (p *ruleParser) peek() (ruleToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return ruleToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword 当前记号为指定关键字时前进
+func (p *ruleParser) keyword(word string) bool {
+	t, ok := p.peek()
+	if ok && t.kind == ruleIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// symbol 当前记号为指定符号时前进
+func (p *ruleParser) symbol(s string) bool {
+	t, ok := p.peek()
+	if ok && t.kind == ruleSymbol && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) expr(depth int) bool {
+	if !p.term(depth) {
+		return false
+	}
+	for p.keyword("AND") || p.keyword("OR") {
+		if !p.term(depth) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ruleParser) term(depth int) bool {
+	if depth > maxRuleDepth {
+		return false
+	}
+	if p.keyword("NOT") {
+		return p.term(depth + 1)
+	}
+	if p.symbol("(") {
+		return p.expr(depth+1) && p.symbol(")")
+	}
+	return p.condition()
+}
+
+func (p *ruleParser) condition() bool {
+	if !p.column() {
+		return false
+	}
+	if p.keyword("IS") {
+		p.keyword("NOT")
+		return p.keyword("NULL")
+	}
+	not := p.keyword("NOT")
+	if p.keyword("IN") {
+		if !p.symbol("(") || !p.value() {
+			return false
+		}
+		for p.symbol(",") {
+			if !p.value() {
+				return false
+			}
+		}
+		return p.symbol(")")
+	}
+	if p.keyword("LIKE") {
+		t, ok := p.peek()
+		p.pos++
+		return ok && t.kind == ruleString
+	}
+	if not {
+		return false
+	}
+	t, ok := p.peek()
+	if !ok || t.kind != ruleSymbol || !ruleOperators[t.text] {
+		return false
+	}
+	p.pos++
+	return p.value()
+}
+
+func (p *ruleParser) column() bool {
+	t, ok := p.peek()
+	if !ok || t.kind != ruleIdent || ruleKeywords[strings.ToUpper(t.text)] {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *ruleParser) value() bool {
+	t, ok := p.peek()
+	if !ok {
+		return false
+	}
+	if t.kind == ruleIdent {
+		return p.column()
+	}
+	if t.kind == ruleSymbol {
+		return false
+	}
+	p.pos++
+	return true
+}
@@ -0,0 +1,216 @@
+// Package fieldrule 字段级权限 按角色隐藏、脱敏返回结果中的字段 并禁止修改受限字段
+package fieldrule
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 字段规则
+const (
+	Hidden   = "hidden"   // 返回时置空
+	Masked   = "masked"   // 返回时脱敏
+	ReadOnly = "readonly" // 只读
+)
+
+// Rules 角色的字段规则 模型名 -> 字段json名 -> 规则
+type Rules map[string]map[string]string
+
+var (
+	mu     sync.RWMutex
+	models = make(map[string]map[string]bool)
+)
+
+// Register 注册可配置字段规则的模型 以结构体名作为模型名
+func Register(values ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		fields := make(map[string]bool)
+		collectFields(t, fields)
+		models[t.Name()] = fields
+	}
+}
+
+// Models 获取已注册的模型及其字段
+func Models() map[string][]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make(map[string][]string, len(models))
+	for name, fields := range models {
+		list := make([]string, 0, len(fields))
+		for field := range fields {
+			list = append(list, field)
+		}
+		sort.Strings(list)
+		result[name] = list
+	}
+	return result
+}
+
+// Validate 校验字段规则 模型需已注册且包含该字段
+func Validate(model, field, rule string) error {
+	switch rule {
+	case Hidden, Masked, ReadOnly:
+	default:
+		return errors.New("不支持的字段规则: " + rule)
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	fields, ok := models[model]
+	if !ok {
+		return errors.New("模型不支持字段规则: " + model)
+	}
+	if !fields[field] {
+		return errors.New("模型" + model + "不存在字段: " + field)
+	}
+	return nil
+}
+
+func collectFields(t reflect.Type, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			collectFields(sf.Type, fields)
+			continue
+		}
+		if name := jsonName(sf); name != "" {
+			fields[name] = true
+		}
+	}
+}
+
+// jsonName 字段的json名 未导出或忽略序列化的字段返回空
+func jsonName(sf reflect.StructField) string {
+	if sf.PkgPath != "" {
+		return ""
+	}
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return sf.Name
+}
+
+// Apply 按规则处理返回结果中的字段 v需为指针或切片 嵌套的模型同样生效
+func Apply(v interface{}, rules Rules) {
+	if len(rules) == 0 || v == nil {
+		return
+	}
+	walk(reflect.ValueOf(v), rules, make(map[uintptr]bool))
+}
+
+func walk(v reflect.Value, rules Rules, visited map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		walk(v.Elem(), rules, visited)
+	case reflect.Interface:
+		if !v.IsNil() {
+			walk(v.Elem(), rules, visited)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), rules, visited)
+		}
+	case reflect.Struct:
+		if !v.CanSet() {
+			return
+		}
+		if fields, ok := rules[v.Type().Name()]; ok {
+			applyFields(v, fields)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				walk(v.Field(i), rules, visited)
+			}
+		}
+	}
+}
+
+func applyFields(v reflect.Value, fields map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			applyFields(v.Field(i), fields)
+			continue
+		}
+		field := v.Field(i)
+		switch fields[jsonName(sf)] {
+		case Hidden:
+			field.SetZero()
+		case Masked:
+			if field.Kind() == reflect.String {
+				field.SetString(Mask(field.String()))
+			} else {
+				field.SetZero()
+			}
+		}
+	}
+}
+
+// Mask 脱敏 邮箱保留首字符及域名 其余保留首尾部分字符
+func Mask(s string) string {
+	if local, domain, ok := strings.Cut(s, "@"); ok && local != "" {
+		runes := []rune(local)
+		return string(runes[0]) + "***@" + domain
+	}
+	runes := []rune(s)
+	n := len(runes)
+	switch {
+	case n == 0:
+		return s
+	case n <= 2:
+		return strings.Repeat("*", n)
+	case n < 7:
+		return string(runes[0]) + strings.Repeat("*", n-2) + string(runes[n-1])
+	default:
+		keep := min(n/3, 4)
+		return string(runes[:keep]) + strings.Repeat("*", n-2*keep) + string(runes[n-keep:])
+	}
+}
+
+// Protect 更新时忽略受限字段 隐藏、脱敏的字段前端拿不到原值 与只读字段一样不允许修改
+func Protect(rules Rules) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		if len(rules) == 0 || model == nil {
+			return db
+		}
+		if err := db.Statement.Parse(model); err != nil {
+			return db
+		}
+		fields, ok := rules[db.Statement.Schema.Name]
+		if !ok {
+			return db
+		}
+		for _, field := range db.Statement.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if _, ok := fields[jsonName(field.StructField)]; ok {
+				db.Statement.Omits = append(db.Statement.Omits, field.DBName)
+			}
+		}
+		return db
+	}
+}
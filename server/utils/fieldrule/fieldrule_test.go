@@ -0,0 +1,117 @@
+package fieldrule
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type Base struct {
+	ID uint `json:"ID" gorm:"primarykey"`
+}
+
+type Member struct {
+	Base
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+	Level int    `json:"level"`
+}
+
+type Order struct {
+	Base
+	Title    string `json:"title"`
+	MemberID uint   `json:"memberId"`
+	Member   Member `json:"member"`
+}
+
+func TestValidate(t *testing.T) {
+	Register(Member{}, &Order{})
+	if err := Validate("Member", "phone", Masked); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	if err := Validate("Member", "ID", ReadOnly); err != nil {
+		t.Errorf("Validate embedded = %v", err)
+	}
+	if err := Validate("Member", "phone", "unknown"); err == nil {
+		t.Error("Validate unknown rule = nil")
+	}
+	if err := Validate("Member", "password", Hidden); err == nil {
+		t.Error("Validate unknown field = nil")
+	}
+	if err := Validate("Customer", "phone", Hidden); err == nil {
+		t.Error("Validate unknown model = nil")
+	}
+}
+
+func TestApply(t *testing.T) {
+	rules := Rules{"Member": {"phone": Masked, "email": Hidden, "level": Masked, "name": ReadOnly}}
+	orders := []Order{
+		{Title: "a", Member: Member{Name: "tom", Phone: "13812345678", Email: "tom@example.com", Level: 3}},
+	}
+	var list interface{} = orders
+	Apply(list, rules)
+	m := orders[0].Member
+	if m.Phone != "138*****678" || m.Email != "" || m.Level != 0 || m.Name != "tom" {
+		t.Errorf("Apply nested = %+v", m)
+	}
+
+	member := Member{Phone: "13812345678", Email: "tom@example.com"}
+	Apply(&member, Rules{"Member": {"email": Masked}})
+	if member.Email != "t***@example.com" || member.Phone != "13812345678" {
+		t.Errorf("Apply pointer = %+v", member)
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := map[string]string{
+		"":            "",
+		"ab":          "**",
+		"abcde":       "a***e",
+		"13812345678": "138*****678",
+		"a@b.com":     "a***@b.com",
+		"张三丰":         "张*丰",
+	}
+	for in, want := range tests {
+		if got := Mask(in); got != want {
+			t.Errorf("Mask(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestProtect(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&Member{}); err != nil {
+		t.Fatal(err)
+	}
+	member := Member{Name: "tom", Phone: "13812345678", Email: "tom@example.com"}
+	if err = db.Create(&member).Error; err != nil {
+		t.Fatal(err)
+	}
+	rules := Rules{"Member": {"phone": Masked, "email": ReadOnly}}
+
+	err = db.Scopes(Protect(rules)).Model(&Member{}).Where("id = ?", member.ID).
+		Updates(map[string]interface{}{"name": "jerry", "phone": "138*****678", "email": "x@example.com"}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Member
+	db.First(&got, member.ID)
+	if got.Name != "jerry" || got.Phone != "13812345678" || got.Email != "tom@example.com" {
+		t.Errorf("Updates = %+v", got)
+	}
+
+	got.Name, got.Phone, got.Email = "spike", "", ""
+	if err = db.Scopes(Protect(rules)).Save(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.First(&got, member.ID)
+	if got.Name != "spike" || got.Phone != "13812345678" || got.Email != "tom@example.com" {
+		t.Errorf("Save = %+v", got)
+	}
+}
@@ -0,0 +1,351 @@
+// Package geoip 读取本地 MaxMind DB(mmdb) 格式的IP地理位置库 如 GeoLite2-City、DB-IP City Lite
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"os"
+)
+
+var (
+	ErrInvalidDatabase = errors.New("geoip: 无效的mmdb数据库文件")
+	metadataMarker     = []byte("\xab\xcd\xefMaxMind.com")
+)
+
+// metadataMaxSize 元数据位于文件末尾 最多128KB
+const metadataMaxSize = 128 * 1024
+
+// dataSectionSeparator 搜索树与数据区之间的16字节分隔
+const dataSectionSeparator = 16
+
+// Reader 整个数据库文件读入内存 可并发使用
+type Reader struct {
+	buf        []byte
+	data       decoder
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	nodeBytes  uint
+	ipv4Start  uint
+	DBType     string // 数据库类型 如 GeoLite2-City
+}
+
+// Location 地理位置 按指定语言取名称 缺失时回退为英文
+type Location struct {
+	Country  string `json:"country"`
+	Province string `json:"province"`
+	City     string `json:"city"`
+}
+
+// Open 打开mmdb数据库文件
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes 从内存中的mmdb数据创建Reader
+func FromBytes(buf []byte) (*Reader, error) {
+	start := 0
+	if len(buf) > metadataMaxSize {
+		start = len(buf) - metadataMaxSize
+	}
+	i := bytes.LastIndex(buf[start:], metadataMarker)
+	if i < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	metaStart := start + i + len(metadataMarker)
+	meta, _, err := decoder{buf: buf[metaStart:]}.decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+	r := &Reader{
+		buf:        buf,
+		nodeCount:  uint(toUint(m["node_count"])),
+		recordSize: uint(toUint(m["record_size"])),
+		ipVersion:  uint(toUint(m["ip_version"])),
+	}
+	r.DBType, _ = m["database_type"].(string)
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, ErrInvalidDatabase
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, ErrInvalidDatabase
+	}
+	r.nodeBytes = r.recordSize / 4
+	treeSize := r.nodeCount * r.nodeBytes
+	if treeSize+dataSectionSeparator > uint(start+i) {
+		return nil, ErrInvalidDatabase
+	}
+	r.data = decoder{buf: buf[treeSize+dataSectionSeparator : start+i]}
+	// IPv6库中的IPv4地址位于 ::/96 之下 预先走完前96位
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for j := 0; j < 96 && node < r.nodeCount; j++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// Lookup 查找IP对应的数据记录 未收录时返回nil
+func (r *Reader) Lookup(ip net.IP) (interface{}, error) {
+	node, bits := uint(0), 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+	if len(ip) != bits/8 {
+		return nil, nil
+	}
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		node = r.readNode(node, uint(ip[i>>3]>>(7-uint(i&7)))&1)
+	}
+	if node == r.nodeCount {
+		return nil, nil
+	}
+	if node < r.nodeCount {
+		return nil, ErrInvalidDatabase
+	}
+	offset := node - r.nodeCount - dataSectionSeparator
+	v, _, err := r.data.decode(offset, 0)
+	return v, err
+}
+
+// Locate 查找IP所在的国家 省份 城市 lang 如 zh-CN en
+func (r *Reader) Locate(ip net.IP, lang string) (Location, error) {
+	v, err := r.Lookup(ip)
+	if err != nil || v == nil {
+		return Location{}, err
+	}
+	record, _ := v.(map[string]interface{})
+	loc := Location{
+		Country: localName(record["country"], lang),
+		City:    localName(record["city"], lang),
+	}
+	if subdivisions, ok := record["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+		loc.Province = localName(subdivisions[0], lang)
+	}
+	return loc, nil
+}
+
+func localName(v interface{}, lang string) string {
+	m, _ := v.(map[string]interface{})
+	names, _ := m["names"].(map[string]interface{})
+	if name, ok := names[lang].(string); ok {
+		return name
+	}
+	name, _ := names["en"].(string)
+	return name
+}
+
+func (r *Reader) readNode(node uint, bit uint) uint {
+	off := node * r.nodeBytes
+	b := r.buf[off : off+r.nodeBytes]
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+// decoder 解码mmdb数据区 指针相对于buf起始位置
+type decoder struct {
+	buf []byte
+}
+
+// decodeMaxDepth 嵌套层数上限 防止损坏的数据库文件导致栈溢出
+const decodeMaxDepth = 32
+
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEnd       = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+func (d decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > decodeMaxDepth || offset >= uint(len(d.buf)) {
+		return nil, 0, ErrInvalidDatabase
+	}
+	ctrl := d.buf[offset]
+	offset++
+	typ := uint(ctrl >> 5)
+	if typ == typePointer {
+		pointer, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		v, _, err := d.decode(pointer, depth+1)
+		return v, next, err
+	}
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, ErrInvalidDatabase
+		}
+		typ = 7 + uint(d.buf[offset])
+		offset++
+	}
+	size, offset, err := d.size(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch typ {
+	case typeMap:
+		m := make(map[string]interface{}, min(size, 64))
+		for i := uint(0); i < size; i++ {
+			var k, v interface{}
+			if k, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, ErrInvalidDatabase
+			}
+			if v, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			m[key] = v
+		}
+		return m, offset, nil
+	case typeArray:
+		list := make([]interface{}, 0, min(size, 64))
+		for i := uint(0); i < size; i++ {
+			var v interface{}
+			if v, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			list = append(list, v)
+		}
+		return list, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	case typeContainer, typeEnd:
+		return nil, offset, nil
+	}
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, ErrInvalidDatabase
+	}
+	b := d.buf[offset : offset+size]
+	offset += size
+	switch typ {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes, typeUint128:
+		return append([]byte(nil), b...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int64(int32(n)), offset, nil
+	}
+	return nil, 0, ErrInvalidDatabase
+}
+
+// size 读取数据项长度 小于29时直接存放在控制字节中
+func (d decoder) size(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+	n := size - 28
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, ErrInvalidDatabase
+	}
+	var v uint
+	for _, c := range d.buf[offset : offset+n] {
+		v = v<<8 | uint(c)
+	}
+	switch size {
+	case 29:
+		v += 29
+	case 30:
+		v += 285
+	default:
+		v += 65821
+	}
+	return v, offset + n, nil
+}
+
+// pointer 读取指针 指针长度由控制字节中的两位决定
+func (d decoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint(ctrl>>3)&0x3 + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, ErrInvalidDatabase
+	}
+	var v uint
+	if n < 4 {
+		v = uint(ctrl & 0x7)
+	}
+	for _, c := range d.buf[offset : offset+n] {
+		v = v<<8 | uint(c)
+	}
+	switch n {
+	case 2:
+		v += 2048
+	case 3:
+		v += 526336
+	}
+	return v, offset + n, nil
+}
+
+func toUint(v interface{}) uint64 {
+	n, _ := v.(uint64)
+	return n
+}
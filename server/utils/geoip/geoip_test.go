@@ -0,0 +1,206 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"net"
+	"sort"
+	"testing"
+)
+
+// encode 测试用的mmdb数据编码 仅支持字符串 无符号整数 映射与数组
+func encode(v interface{}) []byte {
+	head := func(typ byte, size int) []byte {
+		var out []byte
+		if typ > 7 {
+			out = []byte{0, typ - 7}
+		} else {
+			out = []byte{typ << 5}
+		}
+		switch {
+		case size < 29:
+			out[0] |= byte(size)
+		case size < 285:
+			out[0] |= 29
+			out = append(out, byte(size-29))
+		default:
+			out[0] |= 30
+			out = binary.BigEndian.AppendUint16(out, uint16(size-285))
+		}
+		return out
+	}
+	switch x := v.(type) {
+	case string:
+		return append(head(typeString, len(x)), x...)
+	case uint32:
+		b := binary.BigEndian.AppendUint32(nil, x)
+		return append(head(typeUint32, 4), b...)
+	case uint16:
+		b := binary.BigEndian.AppendUint16(nil, x)
+		return append(head(typeUint16, 2), b...)
+	case []interface{}:
+		out := head(typeArray, len(x))
+		for _, item := range x {
+			out = append(out, encode(item)...)
+		}
+		return out
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := head(typeMap, len(x))
+		for _, k := range keys {
+			out = append(append(out, encode(k)...), encode(x[k])...)
+		}
+		return out
+	}
+	panic("unsupported type")
+}
+
+type network struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+// build 生成24位记录的mmdb数据库
+func build(t *testing.T, ipVersion uint16, networks []network) []byte {
+	const empty = ^uint32(0)
+	nodes := [][2]uint32{{empty, empty}}
+	var data []byte
+	type dataRef struct {
+		node, bit int
+		offset    uint32
+	}
+	var refs []dataRef
+	for _, n := range networks {
+		_, ipNet, err := net.ParseCIDR(n.cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ip := ipNet.IP
+		ones, _ := ipNet.Mask.Size()
+		if ipVersion == 6 && len(ip.To4()) == net.IPv4len {
+			ip, ones = ip.To16(), ones+96
+			ip[10], ip[11] = 0, 0
+		}
+		node := 0
+		for i := 0; i < ones; i++ {
+			bit := int(ip[i/8]>>(7-uint(i%8))) & 1
+			if i == ones-1 {
+				refs = append(refs, dataRef{node, bit, uint32(len(data))})
+				break
+			}
+			if nodes[node][bit] == empty {
+				nodes = append(nodes, [2]uint32{empty, empty})
+				nodes[node][bit] = uint32(len(nodes) - 1)
+			}
+			node = int(nodes[node][bit])
+		}
+		data = append(data, encode(n.record)...)
+	}
+	nodeCount := uint32(len(nodes))
+	for _, ref := range refs {
+		nodes[ref.node][ref.bit] = nodeCount + dataSectionSeparator + ref.offset
+	}
+	var out []byte
+	for _, n := range nodes {
+		for _, r := range n {
+			if r == empty {
+				r = nodeCount
+			}
+			out = append(out, byte(r>>16), byte(r>>8), byte(r))
+		}
+	}
+	out = append(out, make([]byte, dataSectionSeparator)...)
+	out = append(out, data...)
+	out = append(out, metadataMarker...)
+	out = append(out, encode(map[string]interface{}{
+		"node_count":    nodeCount,
+		"record_size":   uint16(24),
+		"ip_version":    ipVersion,
+		"database_type": "Test-City",
+	})...)
+	return out
+}
+
+func names(en, zh string) map[string]interface{} {
+	return map[string]interface{}{"names": map[string]interface{}{"en": en, "zh-CN": zh}}
+}
+
+func TestLocate(t *testing.T) {
+	networks := []network{
+		{"1.2.3.0/24", map[string]interface{}{
+			"country":      names("China", "中国"),
+			"subdivisions": []interface{}{names("Guangdong", "广东")},
+			"city":         names("Shenzhen", "深圳"),
+		}},
+		{"8.8.8.0/24", map[string]interface{}{"country": map[string]interface{}{"names": map[string]interface{}{"en": "United States"}}}},
+		{"2001:db8::/32", map[string]interface{}{"country": names("Japan", "日本")}},
+	}
+	for _, ipVersion := range []uint16{4, 6} {
+		list := networks
+		if ipVersion == 4 {
+			list = networks[:2]
+		}
+		r, err := FromBytes(build(t, ipVersion, list))
+		if err != nil {
+			t.Fatalf("ipv%d: %v", ipVersion, err)
+		}
+		if r.DBType != "Test-City" {
+			t.Fatalf("ipv%d: database_type %q", ipVersion, r.DBType)
+		}
+		cases := []struct {
+			ip   string
+			lang string
+			want Location
+		}{
+			{"1.2.3.4", "zh-CN", Location{Country: "中国", Province: "广东", City: "深圳"}},
+			{"1.2.3.255", "en", Location{Country: "China", Province: "Guangdong", City: "Shenzhen"}},
+			{"8.8.8.8", "zh-CN", Location{Country: "United States"}},
+			{"1.2.4.1", "zh-CN", Location{}},
+			{"2001:db8::1", "zh-CN", Location{}},
+		}
+		if ipVersion == 6 {
+			cases[4].want = Location{Country: "日本"}
+		}
+		for _, c := range cases {
+			got, err := r.Locate(net.ParseIP(c.ip), c.lang)
+			if err != nil {
+				t.Fatalf("ipv%d %s: %v", ipVersion, c.ip, err)
+			}
+			if got != c.want {
+				t.Fatalf("ipv%d %s: got %+v, want %+v", ipVersion, c.ip, got, c.want)
+			}
+		}
+	}
+}
+
+func TestDecodePointerAndSize(t *testing.T) {
+	long := make([]byte, 300)
+	for i := range long {
+		long[i] = 'a'
+	}
+	// 偏移0为长字符串 之后为指向它的指针
+	buf := encode(string(long))
+	ptr := len(buf)
+	buf = append(buf, typePointer<<5, 0)
+	d := decoder{buf: buf}
+	v, next, err := d.decode(uint(ptr), 0)
+	if err != nil || v != string(long) || next != uint(len(buf)) {
+		t.Fatalf("pointer decode: %v %v %d", err, v == string(long), next)
+	}
+	// 指向自身的指针不能导致死循环
+	d = decoder{buf: []byte{typePointer << 5, 0}}
+	if _, _, err = d.decode(0, 0); err == nil {
+		t.Fatal("self pointer should fail")
+	}
+}
+
+func TestInvalidDatabase(t *testing.T) {
+	for _, buf := range [][]byte{nil, []byte("not a database"), append([]byte(nil), metadataMarker...)} {
+		if _, err := FromBytes(buf); err == nil {
+			t.Fatalf("%q should be invalid", buf)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -29,3 +30,9 @@ func MD5V(str []byte, b ...byte) string {
 	h.Write(str)
 	return hex.EncodeToString(h.Sum(b))
 }
+
+// SHA256V sha256摘要 用于恢复码、令牌等高熵随机串的存储
+func SHA256V(str []byte) string {
+	h := sha256.Sum256(str)
+	return hex.EncodeToString(h[:])
+}
@@ -29,23 +29,30 @@ func NewJWT() *JWT {
 }
 
 func (j *JWT) CreateClaims(baseClaims request.BaseClaims) request.CustomClaims {
-	bf, _ := ParseDuration(global.GVA_CONFIG.JWT.BufferTime)
 	ep, _ := ParseDuration(global.GVA_CONFIG.JWT.ExpiresTime)
 	claims := request.CustomClaims{
 		BaseClaims: baseClaims,
-		BufferTime: int64(bf / time.Second), // 缓冲时间1天 缓冲时间内会获得新的token刷新令牌 此时一个用户会存在两个有效令牌 但是前端只留一个 另一个会丢失
 		RegisteredClaims: jwt.RegisteredClaims{
 			Audience:  jwt.ClaimStrings{"GVA"},                   // 受众
 			NotBefore: jwt.NewNumericDate(time.Now().Add(-1000)), // 签名生效时间
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ep)),    // 过期时间 7天  配置文件
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ep)),    // 过期时间 配置文件 过期后使用刷新令牌换取
 			Issuer:    global.GVA_CONFIG.JWT.Issuer,              // 签名的发行者
 		},
 	}
 	return claims
 }
 
-// CreateToken 创建一个token
+// CreateToken 创建一个token 启用非对称签名时使用当前签发密钥并在头部写入kid
 func (j *JWT) CreateToken(claims request.CustomClaims) (string, error) {
+	if jwtKeys.enabled() {
+		key := jwtKeys.signingKey()
+		if key == nil {
+			return "", errors.New("jwt签发密钥未加载")
+		}
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.Kid
+		return token.SignedString(key.Private)
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(j.SigningKey)
 }
@@ -60,9 +67,7 @@ func (j *JWT) CreateTokenByOldToken(oldToken string, claims request.CustomClaims
 
 // ParseToken 解析 token
 func (j *JWT) ParseToken(tokenString string) (*request.CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &request.CustomClaims{}, func(token *jwt.Token) (i interface{}, e error) {
-		return j.SigningKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &request.CustomClaims{}, j.keyFunc)
 
 	if err != nil {
 		switch {
@@ -85,3 +90,18 @@ func (j *JWT) ParseToken(tokenString string) (*request.CustomClaims, error) {
 	}
 	return nil, TokenValid
 }
+
+// keyFunc 按kid选择验签密钥 并校验算法与密钥一致 防止算法混淆
+func (j *JWT) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, _ := token.Header["kid"].(string); kid != "" {
+		key := jwtKeys.lookup(kid)
+		if key == nil || key.Method.Alg() != token.Method.Alg() {
+			return nil, TokenSignatureInvalid
+		}
+		return key.Public, nil
+	}
+	if jwtKeys.enabled() || token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+		return nil, TokenSignatureInvalid
+	}
+	return j.SigningKey, nil
+}
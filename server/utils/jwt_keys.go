@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的非对称签名算法 HS256 仍使用配置中的 signing-key
+const (
+	JWTAlgRS256 = "RS256"
+	JWTAlgES256 = "ES256"
+	JWTAlgEdDSA = "EdDSA"
+)
+
+// jwtKeyReloadInterval 遇到未知kid时重新加载密钥的最小间隔 用于感知其他实例轮换出的新密钥
+const jwtKeyReloadInterval = 10 * time.Second
+
+// JWTKey 非对称签名密钥 Private为空时仅用于验签
+type JWTKey struct {
+	Kid     string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// JWTKeyLoader 返回当前签发密钥与全部可用于验签的密钥
+type JWTKeyLoader func() (signing *JWTKey, keys []*JWTKey, err error)
+
+type jwtKeyring struct {
+	mu       sync.RWMutex
+	loader   JWTKeyLoader
+	signing  *JWTKey
+	keys     map[string]*JWTKey
+	loadedAt time.Time
+}
+
+var jwtKeys = &jwtKeyring{}
+
+// SetJWTKeyLoader 启用非对称签名 设置密钥加载方法并立即加载
+func SetJWTKeyLoader(loader JWTKeyLoader) error {
+	jwtKeys.mu.Lock()
+	jwtKeys.loader = loader
+	jwtKeys.mu.Unlock()
+	return ReloadJWTKeys()
+}
+
+// ReloadJWTKeys 重新加载密钥 轮换后调用
+func ReloadJWTKeys() error {
+	jwtKeys.mu.RLock()
+	loader := jwtKeys.loader
+	jwtKeys.mu.RUnlock()
+	if loader == nil {
+		return nil
+	}
+	signing, list, err := loader()
+	jwtKeys.mu.Lock()
+	defer jwtKeys.mu.Unlock()
+	jwtKeys.loadedAt = time.Now()
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]*JWTKey, len(list))
+	for _, key := range list {
+		keys[key.Kid] = key
+	}
+	if signing != nil {
+		keys[signing.Kid] = signing
+	}
+	jwtKeys.signing = signing
+	jwtKeys.keys = keys
+	return nil
+}
+
+// enabled 是否启用了非对称签名
+func (r *jwtKeyring) enabled() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loader != nil
+}
+
+func (r *jwtKeyring) signingKey() *JWTKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.signing
+}
+
+// lookup 按kid查找验签密钥 未命中时限频重新加载一次
+func (r *jwtKeyring) lookup(kid string) *JWTKey {
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	stale := r.loader != nil && time.Since(r.loadedAt) > jwtKeyReloadInterval
+	r.mu.RUnlock()
+	if ok || !stale {
+		return key
+	}
+	if ReloadJWTKeys() != nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[kid]
+}
+
+// GenerateJWTKey 生成指定算法的密钥对
+func GenerateJWTKey(kid string, alg string) (*JWTKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case JWTAlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case JWTAlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case JWTAlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, errors.New("不支持的签名算法: " + alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &JWTKey{Kid: kid, Method: jwt.GetSigningMethod(alg), Private: signer, Public: signer.Public()}, nil
+}
+
+// MarshalJWTKey 将密钥编码为 PKCS8 私钥与 PKIX 公钥 PEM
+func MarshalJWTKey(key *JWTKey) (privatePEM string, publicPEM string, err error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return "", "", err
+	}
+	pub, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return "", "", err
+	}
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+	return privatePEM, publicPEM, nil
+}
+
+// ParseJWTKey 解析 MarshalJWTKey 编码的密钥 privatePEM为空时仅解析公钥
+func ParseJWTKey(kid string, alg string, privatePEM string, publicPEM string) (*JWTKey, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil || alg == jwt.SigningMethodHS256.Alg() {
+		return nil, errors.New("不支持的签名算法: " + alg)
+	}
+	key := &JWTKey{Kid: kid, Method: method}
+	if privatePEM != "" {
+		block, _ := pem.Decode([]byte(privatePEM))
+		if block == nil {
+			return nil, errors.New("私钥格式错误")
+		}
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("私钥格式错误")
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+		return key, nil
+	}
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("公钥格式错误")
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key.Public = public
+	return key, nil
+}
+
+// JWKS 以 JSON Web Key Set 格式返回全部验签公钥 未启用非对称签名时为空集合
+func JWKS() map[string]interface{} {
+	jwtKeys.mu.RLock()
+	defer jwtKeys.mu.RUnlock()
+	keys := make([]map[string]string, 0, len(jwtKeys.keys))
+	for _, key := range jwtKeys.keys {
+		if jwk := publicJWK(key); jwk != nil {
+			keys = append(keys, jwk)
+		}
+	}
+	return map[string]interface{}{"keys": keys}
+}
+
+func publicJWK(key *JWTKey) map[string]string {
+	jwk := map[string]string{"kid": key.Kid, "alg": key.Method.Alg(), "use": "sig"}
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk["kty"] = "EC"
+		jwk["crv"] = pub.Curve.Params().Name
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk["y"] = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk["kty"] = "OKP"
+		jwk["crv"] = "Ed25519"
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return nil
+	}
+	return jwk
+}
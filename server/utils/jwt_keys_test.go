@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+func testClaims() request.CustomClaims {
+	return request.CustomClaims{
+		BaseClaims: request.BaseClaims{ID: 1, Username: "admin"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	defer func() { jwtKeys = &jwtKeyring{} }()
+	j := &JWT{SigningKey: []byte("secret")}
+	legacy, err := j.CreateToken(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, alg := range []string{JWTAlgRS256, JWTAlgES256, JWTAlgEdDSA} {
+		old, err := GenerateJWTKey("old-"+alg, alg)
+		if err != nil {
+			t.Fatalf("GenerateJWTKey(%s) error = %v", alg, err)
+		}
+		privatePEM, publicPEM, err := MarshalJWTKey(old)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ParseJWTKey(old.Kid, alg, privatePEM, ""); err != nil {
+			t.Fatalf("ParseJWTKey(%s, private) error = %v", alg, err)
+		}
+		verifyOnly, err := ParseJWTKey(old.Kid, alg, "", publicPEM)
+		if err != nil {
+			t.Fatalf("ParseJWTKey(%s, public) error = %v", alg, err)
+		}
+
+		signing := old
+		var verify []*JWTKey
+		if err = SetJWTKeyLoader(func() (*JWTKey, []*JWTKey, error) { return signing, verify, nil }); err != nil {
+			t.Fatal(err)
+		}
+		oldToken, err := j.CreateToken(testClaims())
+		if err != nil {
+			t.Fatalf("%s CreateToken() error = %v", alg, err)
+		}
+
+		// 轮换后旧密钥仅用于验签
+		signing, _ = GenerateJWTKey("new-"+alg, alg)
+		verify = []*JWTKey{verifyOnly}
+		if err = ReloadJWTKeys(); err != nil {
+			t.Fatal(err)
+		}
+		newToken, _ := j.CreateToken(testClaims())
+		for _, token := range []string{oldToken, newToken} {
+			if claims, err := j.ParseToken(token); err != nil || claims.Username != "admin" {
+				t.Errorf("%s ParseToken() = %v, %v", alg, claims, err)
+			}
+		}
+		if got := len(JWKS()["keys"].([]map[string]string)); got != 2 {
+			t.Errorf("%s JWKS() has %d keys, want 2", alg, got)
+		}
+		if _, err = j.ParseToken(legacy); err == nil {
+			t.Errorf("%s ParseToken() accepted an HS256 token", alg)
+		}
+
+		// 宽限期结束后旧密钥签发的令牌失效
+		verify = nil
+		_ = ReloadJWTKeys()
+		if _, err = j.ParseToken(oldToken); err == nil {
+			t.Errorf("%s ParseToken() accepted a token signed by a removed key", alg)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testIdP 最小化的身份提供方 用于测试授权码流程
+type testIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+	nonce    string
+	verifier string
+	claims   jwt.MapClaims
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testIdP{key: key, clientID: "gva"}
+	mux := http.NewServeMux()
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("code") != "good-code" || CodeChallenge(r.Form.Get("code_verifier")) != CodeChallenge(idp.verifier) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if id, secret, _ := r.BasicAuth(); id != idp.clientID || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     idp.sign(t, idp.claims),
+		})
+	})
+	return idp
+}
+
+func (idp *testIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	s, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (idp *testIdP) provider(t *testing.T) *Provider {
+	t.Helper()
+	p, err := NewProvider(context.Background(), Config{
+		Issuer:       idp.server.URL,
+		ClientID:     idp.clientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/#/oidc/callback",
+	}, idp.server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.nonce, _ = RandomString()
+	idp.verifier, _ = RandomString()
+	idp.claims = jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"aud":                idp.clientID,
+		"sub":                "user-1",
+		"nonce":              idp.nonce,
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"preferred_username": "alice",
+		"groups":             []string{"admins"},
+	}
+	p := idp.provider(t)
+
+	u, err := url.Parse(p.AuthCodeURL("s1", idp.nonce, idp.verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("state") != "s1" || q.Get("nonce") != idp.nonce || q.Get("code_challenge") != CodeChallenge(idp.verifier) {
+		t.Fatalf("unexpected auth url %s", u)
+	}
+
+	token, err := p.Exchange(context.Background(), "good-code", idp.verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := p.VerifyIDToken(context.Background(), token.IDToken, idp.nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "user-1" || claims["preferred_username"] != "alice" {
+		t.Fatalf("unexpected claims %v", claims)
+	}
+
+	if _, err = p.Exchange(context.Background(), "good-code", "wrong-verifier"); err == nil {
+		t.Fatal("exchange with wrong verifier should fail")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider(t)
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   idp.clientID,
+			"sub":   "user-1",
+			"nonce": "n",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}
+	}
+	if _, err := p.VerifyIDToken(context.Background(), idp.sign(t, valid()), "n"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	cases := map[string]func(c jwt.MapClaims){
+		"nonce":    func(c jwt.MapClaims) { c["nonce"] = "other" },
+		"audience": func(c jwt.MapClaims) { c["aud"] = "other" },
+		"issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+	}
+	for name, mutate := range cases {
+		c := valid()
+		mutate(c)
+		if _, err := p.VerifyIDToken(context.Background(), idp.sign(t, c), "n"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, valid())
+	forged.Header["kid"] = "k1"
+	s, _ := forged.SignedString(other)
+	if _, err := p.VerifyIDToken(context.Background(), s, "n"); err == nil {
+		t.Error("forged signature: expected error")
+	}
+
+	none, _ := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := p.VerifyIDToken(context.Background(), none, "n"); err == nil || !strings.Contains(err.Error(), "id_token") {
+		t.Error("alg none: expected error")
+	}
+}
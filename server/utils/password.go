@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+)
+
+const (
+	passwordLower  = "abcdefghijkmnpqrstuvwxyz"
+	passwordUpper  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordDigit  = "23456789"
+	passwordSymbol = "!@#$%^&*-_=+?"
+)
+
+// CheckPasswordPolicy 校验密码是否符合密码策略
+func CheckPasswordPolicy(conf config.Password, username string, password string) error {
+	if password == "" {
+		return errors.New("密码不能为空")
+	}
+	if conf.MinLength > 0 && len([]rune(password)) < conf.MinLength {
+		return fmt.Errorf("密码长度不能少于%d位", conf.MinLength)
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if conf.RequireUpper && !upper {
+		return errors.New("密码必须包含大写字母")
+	}
+	if conf.RequireLower && !lower {
+		return errors.New("密码必须包含小写字母")
+	}
+	if conf.RequireDigit && !digit {
+		return errors.New("密码必须包含数字")
+	}
+	if conf.RequireSymbol && !symbol {
+		return errors.New("密码必须包含特殊字符")
+	}
+	if conf.NotContainUsername && username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errors.New("密码不能包含用户名")
+	}
+	return nil
+}
+
+// RandomPassword 生成符合密码策略的随机密码 每类要求的字符至少出现一次
+func RandomPassword(conf config.Password) (string, error) {
+	n := conf.ResetLength
+	if n < conf.MinLength {
+		n = conf.MinLength
+	}
+	if n < 12 {
+		n = 12
+	}
+	sets := []string{passwordLower, passwordUpper, passwordDigit}
+	if conf.RequireSymbol {
+		sets = append(sets, passwordSymbol)
+	}
+	all := strings.Join(sets, "")
+	b := make([]byte, n)
+	for i := range b {
+		set := all
+		if i < len(sets) {
+			set = sets[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		b[i] = c
+	}
+	// 打乱顺序 避免固定位置出现固定类别的字符
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		b[i], b[j.Int64()] = b[j.Int64()], b[i]
+	}
+	return string(b), nil
+}
+
+func randomChar(set string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[i.Int64()], nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	conf := config.Password{
+		MinLength:          8,
+		RequireUpper:       true,
+		RequireLower:       true,
+		RequireDigit:       true,
+		RequireSymbol:      true,
+		NotContainUsername: true,
+	}
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"empty", "", true},
+		{"too short", "Ab1!", true},
+		{"no upper", "abcdef1!", true},
+		{"no lower", "ABCDEF1!", true},
+		{"no digit", "Abcdefg!", true},
+		{"no symbol", "Abcdefg1", true},
+		{"contains username", "xAdmin01!", true},
+		{"ok", "Abcdef1!", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPasswordPolicy(conf, "admin", tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPasswordPolicy(%q) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+	if err := CheckPasswordPolicy(config.Password{}, "admin", "admin"); err != nil {
+		t.Errorf("empty policy should accept any non-empty password, got %v", err)
+	}
+}
+
+func TestRandomPassword(t *testing.T) {
+	conf := config.Password{
+		MinLength:     16,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		ResetLength:   10,
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		p, err := RandomPassword(conf)
+		if err != nil {
+			t.Fatalf("RandomPassword() error = %v", err)
+		}
+		if len(p) != 16 {
+			t.Errorf("RandomPassword() length = %d, want 16", len(p))
+		}
+		if err = CheckPasswordPolicy(conf, "", p); err != nil {
+			t.Errorf("RandomPassword() = %q violates policy: %v", p, err)
+		}
+		if seen[p] {
+			t.Errorf("RandomPassword() repeated %q", p)
+		}
+		seen[p] = true
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrSignedTokenInvalid = errors.New("令牌无效")
+
+// SignToken 使用 HMAC-SHA256 对载荷签名 返回 base64url(载荷).base64url(签名)
+func SignToken(key []byte, payload string) string {
+	p := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return p + "." + base64.RawURLEncoding.EncodeToString(signTokenPart(key, p))
+}
+
+// VerifySignedToken 校验签名并返回载荷
+func VerifySignedToken(key []byte, token string) (string, error) {
+	p, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrSignedTokenInvalid
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, signTokenPart(key, p)) {
+		return "", ErrSignedTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return "", ErrSignedTokenInvalid
+	}
+	return string(payload), nil
+}
+
+func signTokenPart(key []byte, part string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(part))
+	return h.Sum(nil)
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignedToken(t *testing.T) {
+	key := []byte("secret")
+	token := SignToken(key, "1:1700000000:abc")
+	payload, err := VerifySignedToken(key, token)
+	if err != nil || payload != "1:1700000000:abc" {
+		t.Fatalf("VerifySignedToken() = %q, %v", payload, err)
+	}
+	if _, err = VerifySignedToken([]byte("other"), token); err == nil {
+		t.Error("VerifySignedToken() accepted a token signed with another key")
+	}
+	p, sig, _ := strings.Cut(token, ".")
+	forged := SignToken(key, "2:1700000000:abc")
+	fp, _, _ := strings.Cut(forged, ".")
+	if _, err = VerifySignedToken(key, fp+"."+sig); err == nil {
+		t.Error("VerifySignedToken() accepted a modified payload")
+	}
+	for _, bad := range []string{"", p, p + ".", "!!." + sig} {
+		if _, err = VerifySignedToken(key, bad); err == nil {
+			t.Errorf("VerifySignedToken(%q) should fail", bad)
+		}
+	}
+}
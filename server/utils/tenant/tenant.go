@@ -0,0 +1,153 @@
+// Package tenant 多租户隔离 为包含 tenant_id 列的模型在新增时写入当前租户 在查询、更新、删除时追加租户条件
+// context中没有租户时拒绝操作 登录前查询、定时任务等系统级操作需通过 Skip 显式跳过隔离
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Default 默认租户ID 初始化数据库时创建 未指定租户的数据归属默认租户
+const Default uint = 1
+
+// Column 租户列名
+const Column = "tenant_id"
+
+const callbackName = "gva:tenant"
+
+// Shared 实现该接口的模型为全部租户共用 不做租户隔离 如api、菜单等系统定义及按用户隔离的登录凭据
+type Shared interface {
+	TenantShared()
+}
+
+// ErrNoTenant 操作租户隔离的模型时context中没有租户且未跳过隔离
+var ErrNoTenant = errors.New("未指定租户 请通过 db.WithContext 传入租户或使用 tenant.Skip")
+
+type (
+	tenantKey struct{}
+	skipKey   struct{}
+)
+
+// WithTenant 将当前租户写入context 操作数据库时需通过 db.WithContext 传入
+func WithTenant(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext 从context中取出当前租户
+func FromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	id, ok := ctx.Value(tenantKey{}).(uint)
+	return id, ok && id != 0
+}
+
+// Domain 当前租户对应的casbin域 context中没有租户时为默认租户
+func Domain(ctx context.Context) string {
+	id, ok := FromContext(ctx)
+	if !ok {
+		id = Default
+	}
+	return strconv.Itoa(int(id))
+}
+
+// Skip 本次操作不做租户隔离 用于超级管理员跨租户的管理操作及登录前、定时任务等不属于任何租户的系统级操作
+// 标记写入context 在事务及预加载中同样生效
+func Skip(db *gorm.DB) *gorm.DB {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(context.WithValue(ctx, skipKey{}, true))
+}
+
+// Register 为数据库注册租户隔离回调 重复注册时跳过
+func Register(db *gorm.DB) error {
+	if db.Callback().Query().Get(callbackName) != nil {
+		return nil
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register(callbackName, create); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register(callbackName, scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register(callbackName, scope); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register(callbackName, update); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register(callbackName, scope)
+}
+
+// current 当前操作的租户及租户列 无需隔离时ok为false 需要隔离但context中没有租户时记录 ErrNoTenant
+func current(db *gorm.DB) (id uint, field *schema.Field, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return 0, nil, false
+	}
+	if ctx := db.Statement.Context; ctx != nil && ctx.Value(skipKey{}) != nil {
+		return 0, nil, false
+	}
+	if _, shared := reflect.New(db.Statement.Schema.ModelType).Interface().(Shared); shared {
+		return 0, nil, false
+	}
+	f := db.Statement.Schema.LookUpField(Column)
+	if f == nil {
+		return 0, nil, false
+	}
+	id, ok = FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+	}
+	return id, f, ok
+}
+
+// create 新增时写入当前租户 忽略调用方传入的租户
+func create(db *gorm.DB) {
+	id, field, ok := current(db)
+	if !ok {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(db.Statement.Context, reflect.Indirect(rv.Index(i)), id); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(db.Statement.Context, rv, id); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
+
+// scope 查询、删除时追加租户条件
+func scope(db *gorm.DB) {
+	if id, _, ok := current(db); ok {
+		where(db, id)
+	}
+}
+
+// update 更新时追加租户条件 并禁止修改记录所属租户
+func update(db *gorm.DB) {
+	if id, _, ok := current(db); ok {
+		where(db, id)
+		db.Statement.Omits = append(db.Statement.Omits, Column)
+	}
+}
+
+func where(db *gorm.DB, id uint) {
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: Column}, Value: id},
+	}})
+}
@@ -0,0 +1,139 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type tenantRecord struct {
+	ID       uint
+	Name     string
+	TenantId uint `gorm:"default:1"`
+}
+
+type sharedRecord struct {
+	ID       uint
+	Name     string
+	TenantId uint `gorm:"default:1"`
+}
+
+func (sharedRecord) TenantShared() {}
+
+func TestCreate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&tenantRecord{}, &sharedRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Register(db); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithTenant(context.Background(), 2)
+	records := []tenantRecord{{Name: "a", TenantId: 3}, {Name: "b"}}
+	if err = db.WithContext(ctx).Create(&records).Error; err != nil {
+		t.Fatal(err)
+	}
+	record := tenantRecord{Name: "c"}
+	if err = db.WithContext(ctx).Create(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 未传入租户时拒绝写入 显式跳过隔离时使用列默认值
+	if err = db.Create(&tenantRecord{Name: "e"}).Error; !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Create without tenant error = %v, want ErrNoTenant", err)
+	}
+	if err = Skip(db).Create(&tenantRecord{Name: "d"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got []tenantRecord
+	Skip(db).Order("id").Find(&got)
+	if len(got) != 4 {
+		t.Fatalf("records = %d, want 4", len(got))
+	}
+	want := []uint{2, 2, 2, Default}
+	for i := range got {
+		if got[i].TenantId != want[i] {
+			t.Errorf("record %s tenant = %d, want %d", got[i].Name, got[i].TenantId, want[i])
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&tenantRecord{}, &sharedRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Register(db); err != nil {
+		t.Fatal(err)
+	}
+	Skip(db).Create(&[]tenantRecord{{Name: "a", TenantId: 1}, {Name: "b", TenantId: 2}, {Name: "c", TenantId: 2}})
+	db.Create(&[]sharedRecord{{Name: "a", TenantId: 1}, {Name: "b", TenantId: 2}})
+	ctx := WithTenant(context.Background(), 2)
+
+	var count int64
+	db.WithContext(ctx).Model(&tenantRecord{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Count = %d, want 2", count)
+	}
+	if err = db.Model(&tenantRecord{}).Count(&count).Error; !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Count without tenant error = %v, want ErrNoTenant", err)
+	}
+	if err = db.Where("name = ?", "a").Delete(&tenantRecord{}).Error; !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Delete without tenant error = %v, want ErrNoTenant", err)
+	}
+	db.WithContext(ctx).Scopes(Skip).Model(&tenantRecord{}).Count(&count)
+	if count != 3 {
+		t.Errorf("Count skipped = %d, want 3", count)
+	}
+	// 跳过隔离的标记在事务中同样生效
+	err = Skip(db).Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&tenantRecord{}).Count(&count).Error
+	})
+	if err != nil || count != 3 {
+		t.Errorf("Count skipped in transaction = %d, %v, want 3", count, err)
+	}
+	var shared []sharedRecord
+	db.WithContext(ctx).Find(&shared)
+	if len(shared) != 2 {
+		t.Errorf("Find shared = %d, want 2", len(shared))
+	}
+
+	err = db.WithContext(ctx).Model(&tenantRecord{}).Where("name = ?", "a").
+		Updates(map[string]interface{}{"name": "x"}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.WithContext(ctx).Model(&tenantRecord{}).Where("name = ?", "b").
+		Updates(map[string]interface{}{"name": "y", "tenant_id": 1}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got tenantRecord
+	Skip(db).Where("name = ?", "a").First(&got)
+	if got.ID == 0 {
+		t.Error("Updates changed record of another tenant")
+	}
+	var updated tenantRecord
+	Skip(db).Where("name = ?", "y").First(&updated)
+	if updated.TenantId != 2 {
+		t.Errorf("Updates tenant = %d, want 2", updated.TenantId)
+	}
+
+	result := db.WithContext(ctx).Where("name = ?", "a").Delete(&tenantRecord{})
+	if result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("Delete other tenant = %d, %v", result.RowsAffected, result.Error)
+	}
+	result = db.WithContext(ctx).Where("name = ?", "c").Delete(&tenantRecord{})
+	if result.Error != nil || result.RowsAffected != 1 {
+		t.Errorf("Delete = %d, %v", result.RowsAffected, result.Error)
+	}
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// UserAgent 从User-Agent中解析出的客户端信息
+type UserAgent struct {
+	Browser string `json:"browser"` // 浏览器及版本 如 Chrome 120.0
+	OS      string `json:"os"`      // 操作系统及版本 如 Windows 10
+	Device  string `json:"device"`  // 设备类型 desktop mobile tablet bot other
+}
+
+// 设备类型
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceOther   = "other"
+)
+
+type uaRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// 按顺序匹配 基于Chromium的浏览器同时带有Chrome标识 需排在Chrome之前
+var browserRules = []uaRule{
+	{"Edge", regexp.MustCompile(`(?:Edg|EdgA|EdgiOS|Edge)/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+	{"WeChat", regexp.MustCompile(`MicroMessenger/([\d.]+)`)},
+	{"DingTalk", regexp.MustCompile(`DingTalk/([\d.]+)`)},
+	{"QQBrowser", regexp.MustCompile(`QQBrowser/([\d.]+)`)},
+	{"UCBrowser", regexp.MustCompile(`UCBrowser/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"IE", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)([\d.]+)`)},
+	{"curl", regexp.MustCompile(`^curl/([\d.]+)`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/([\d.]+)`)},
+	{"Python Requests", regexp.MustCompile(`python-requests/([\d.]+)`)},
+	{"Go HTTP Client", regexp.MustCompile(`Go-http-client/([\d.]+)`)},
+	{"OkHttp", regexp.MustCompile(`okhttp/([\d.]+)`)},
+}
+
+var (
+	windowsRe  = regexp.MustCompile(`Windows NT ([\d.]+)`)
+	iosRe      = regexp.MustCompile(`(?:iPhone|CPU) OS ([\d_]+)`)
+	macRe      = regexp.MustCompile(`Mac OS X ([\d_.]+)`)
+	androidRe  = regexp.MustCompile(`Android ([\d.]+)`)
+	harmonyRe  = regexp.MustCompile(`HarmonyOS(?:[ /]([\d.]+))?`)
+	botRe      = regexp.MustCompile(`(?i)bot\b|spider|crawler|slurp`)
+	windowsMap = map[string]string{"10.0": "10", "6.3": "8.1", "6.2": "8", "6.1": "7", "6.0": "Vista", "5.1": "XP"}
+)
+
+// ParseUserAgent 解析User-Agent 仅识别常见浏览器与系统 无法识别的部分留空
+func ParseUserAgent(ua string) UserAgent {
+	var result UserAgent
+	if ua = strings.TrimSpace(ua); ua == "" {
+		return result
+	}
+	for _, rule := range browserRules {
+		if m := rule.re.FindStringSubmatch(ua); m != nil {
+			result.Browser = rule.name + " " + shortVersion(m[1])
+			break
+		}
+	}
+	result.OS = parseOS(ua)
+	switch {
+	case botRe.MatchString(ua):
+		result.Device = DeviceBot
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		(strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile")):
+		result.Device = DeviceTablet
+	case strings.Contains(ua, "Mobile") || strings.Contains(ua, "iPhone"):
+		result.Device = DeviceMobile
+	case strings.HasPrefix(ua, "Mozilla/"):
+		result.Device = DeviceDesktop
+	default:
+		result.Device = DeviceOther
+	}
+	return result
+}
+
+func parseOS(ua string) string {
+	if m := harmonyRe.FindStringSubmatch(ua); m != nil {
+		return strings.TrimSpace("HarmonyOS " + m[1])
+	}
+	if m := windowsRe.FindStringSubmatch(ua); m != nil {
+		if v, ok := windowsMap[m[1]]; ok {
+			return "Windows " + v
+		}
+		return "Windows NT " + m[1]
+	}
+	if m := iosRe.FindStringSubmatch(ua); m != nil {
+		name := "iOS"
+		if strings.Contains(ua, "iPad") {
+			name = "iPadOS"
+		}
+		return name + " " + strings.ReplaceAll(m[1], "_", ".")
+	}
+	if m := androidRe.FindStringSubmatch(ua); m != nil {
+		return "Android " + m[1]
+	}
+	if m := macRe.FindStringSubmatch(ua); m != nil {
+		return "macOS " + strings.ReplaceAll(m[1], "_", ".")
+	}
+	switch {
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
+
+// shortVersion 仅保留主次版本号
+func shortVersion(v string) string {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ".")
+}
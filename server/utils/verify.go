@@ -15,5 +15,22 @@ var (
 	AuthorityIdVerify      = Rules{"AuthorityId": {NotEmpty()}}
 	OldAuthorityVerify     = Rules{"OldAuthorityId": {NotEmpty()}}
 	ChangePasswordVerify   = Rules{"Password": {NotEmpty()}, "NewPassword": {NotEmpty()}}
+	PasswordTicketVerify   = Rules{"PasswordToken": {NotEmpty()}, "NewPassword": {NotEmpty()}}
+	ForgotPasswordVerify   = Rules{"Account": {NotEmpty()}}
+	ResetByTokenVerify     = Rules{"Token": {NotEmpty()}, "NewPassword": {NotEmpty()}}
+	CreateApiKeyVerify     = Rules{"Name": {NotEmpty()}, "ExpireDays": {NotEmpty()}}
+	SelfRegisterVerify     = Rules{"Username": {NotEmpty()}, "Password": {NotEmpty()}, "Email": {NotEmpty()}, "Captcha": {NotEmpty()}, "CaptchaId": {NotEmpty()}}
+	RegisterTokenVerify    = Rules{"Token": {NotEmpty()}}
 	SetUserAuthorityVerify = Rules{"AuthorityId": {NotEmpty()}}
+	OidcLoginVerify        = Rules{"Code": {NotEmpty()}, "State": {NotEmpty()}}
+	RefreshTokenVerify     = Rules{"RefreshToken": {NotEmpty()}}
+	SessionIdVerify        = Rules{"SessionId": {NotEmpty()}}
+	UpdatePasskeyVerify    = Rules{"ID": {NotEmpty()}, "Name": {NotEmpty()}}
+	CasbinExplainVerify    = Rules{"Path": {NotEmpty()}, "Method": {NotEmpty()}}
+	GrantAuthorityVerify   = Rules{"ID": {NotEmpty()}, "AuthorityId": {NotEmpty()}, "ValidUntil": {NotEmpty()}}
+	RevokeAuthorityVerify  = Rules{"ID": {NotEmpty()}, "AuthorityId": {NotEmpty()}}
+	DeptVerify             = Rules{"Name": {NotEmpty()}}
+	MergeDeptVerify        = Rules{"SourceId": {NotEmpty()}, "TargetId": {NotEmpty()}}
+	TenantVerify           = Rules{"Name": {NotEmpty()}, "Code": {NotEmpty()}}
+	SwitchTenantVerify     = Rules{"TenantId": {NotEmpty()}}
 )
@@ -0,0 +1,432 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// COSE 算法标识
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// 认证器数据标志位
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+var (
+	ErrChallenge      = errors.New("webauthn: 挑战不匹配")
+	ErrOrigin         = errors.New("webauthn: 来源不受信任")
+	ErrRPID           = errors.New("webauthn: 依赖方ID不匹配")
+	ErrUserPresence   = errors.New("webauthn: 未检测到用户在场")
+	ErrUserVerify     = errors.New("webauthn: 未完成用户验证")
+	ErrSignature      = errors.New("webauthn: 签名无效")
+	ErrSignCount      = errors.New("webauthn: 签名计数异常 认证器可能被克隆")
+	ErrMalformed      = errors.New("webauthn: 数据格式错误")
+	ErrUnsupportedKey = errors.New("webauthn: 不支持的公钥算法")
+)
+
+// Config 依赖方配置
+type Config struct {
+	RPID    string   // 依赖方ID 一般为站点域名
+	RPName  string   // 依赖方名称 认证器中展示
+	Origins []string // 允许的来源 如 https://admin.example.com
+	Timeout int      // 浏览器等待用户操作的时长 单位:毫秒
+}
+
+// CredentialDescriptor 凭证描述
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"` // base64url
+	Transports []string `json:"transports,omitempty"`
+}
+
+type rpEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type userEntity struct {
+	ID          string `json:"id"` // base64url
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type credentialParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type authenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions navigator.credentials.create 的 publicKey 参数 二进制字段均为base64url
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     rpEntity               `json:"rp"`
+	User                   userEntity             `json:"user"`
+	PubKeyCredParams       []credentialParam      `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout,omitempty"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection authenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions navigator.credentials.get 的 publicKey 参数 二进制字段均为base64url
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int                    `json:"timeout,omitempty"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// AttestationResponse 注册时浏览器返回的凭证 二进制字段均为base64url
+type AttestationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse 登录时浏览器返回的断言 二进制字段均为base64url
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// Credential 注册成功的凭证
+type Credential struct {
+	ID         []byte
+	PublicKey  []byte // COSE 格式公钥
+	SignCount  uint32
+	AAGUID     []byte
+	Transports []string
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// NewChallenge 生成随机挑战 base64url
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewCreationOptions 生成注册参数 userID为稳定且不含个人信息的用户标识
+func (c Config) NewCreationOptions(challenge string, userID []byte, name string, displayName string, exclude []CredentialDescriptor, userVerification string) CreationOptions {
+	if exclude == nil {
+		exclude = []CredentialDescriptor{}
+	}
+	return CreationOptions{
+		Challenge: challenge,
+		RP:        rpEntity{ID: c.RPID, Name: c.RPName},
+		User:      userEntity{ID: base64.RawURLEncoding.EncodeToString(userID), Name: name, DisplayName: displayName},
+		PubKeyCredParams: []credentialParam{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:                c.Timeout,
+		ExcludeCredentials:     exclude,
+		AuthenticatorSelection: authenticatorSelection{ResidentKey: "preferred", UserVerification: userVerification},
+		Attestation:            "none",
+	}
+}
+
+// NewRequestOptions 生成登录参数 allow为空时由认证器自行选择可发现凭证
+func (c Config) NewRequestOptions(challenge string, allow []CredentialDescriptor, userVerification string) RequestOptions {
+	if allow == nil {
+		allow = []CredentialDescriptor{}
+	}
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          c.Timeout,
+		RPID:             c.RPID,
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+}
+
+// VerifyRegistration 校验注册响应 不校验证明声明(attestation 为 none)
+func (c Config) VerifyRegistration(resp AttestationResponse, challenge string, requireUV bool) (*Credential, error) {
+	clientDataJSON, err := decode(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if err = c.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+	attestation, err := decode(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	obj, _, err := decodeCBOR(attestation)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	m, ok := obj.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrMalformed
+	}
+	rawAuthData, ok := m["authData"].([]byte)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.verifyAuthenticatorData(authData, requireUV); err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttested == 0 || authData.credentialID == nil {
+		return nil, ErrMalformed
+	}
+	if rawID, err := decode(resp.RawID); err != nil || !bytes.Equal(rawID, authData.credentialID) {
+		return nil, ErrMalformed
+	}
+	if _, _, err = parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+	return &Credential{
+		ID:         authData.credentialID,
+		PublicKey:  authData.publicKey,
+		SignCount:  authData.signCount,
+		AAGUID:     authData.aaguid,
+		Transports: resp.Response.Transports,
+	}, nil
+}
+
+// VerifyAssertion 校验登录断言 返回认证器最新的签名计数
+func (c Config) VerifyAssertion(resp AssertionResponse, challenge string, publicKey []byte, storedSignCount uint32, requireUV bool) (uint32, error) {
+	clientDataJSON, err := decode(resp.Response.ClientDataJSON)
+	if err != nil {
+		return 0, ErrMalformed
+	}
+	if err = c.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	rawAuthData, err := decode(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, ErrMalformed
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err = c.verifyAuthenticatorData(authData, requireUV); err != nil {
+		return 0, err
+	}
+	signature, err := decode(resp.Response.Signature)
+	if err != nil {
+		return 0, ErrMalformed
+	}
+	alg, key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if !verifySignature(alg, key, signed, signature) {
+		return 0, ErrSignature
+	}
+	// 计数为0表示认证器不支持计数
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return 0, ErrSignCount
+	}
+	return authData.signCount, nil
+}
+
+// CredentialID 解析断言中的凭证ID 用于查找已注册的凭证
+func (resp AssertionResponse) CredentialID() ([]byte, error) {
+	id, err := decode(resp.RawID)
+	if err != nil || len(id) == 0 {
+		return nil, ErrMalformed
+	}
+	return id, nil
+}
+
+// UserHandle 解析断言中的用户标识 认证器未返回时为空
+func (resp AssertionResponse) UserHandle() ([]byte, error) {
+	if resp.Response.UserHandle == "" {
+		return nil, nil
+	}
+	return decode(resp.Response.UserHandle)
+}
+
+func (c Config) verifyClientData(raw []byte, typ string, challenge string) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return ErrMalformed
+	}
+	if cd.Type != typ {
+		return ErrMalformed
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(challenge)) != 1 {
+		return ErrChallenge
+	}
+	for _, origin := range c.Origins {
+		if cd.Origin == origin {
+			return nil
+		}
+	}
+	return ErrOrigin
+}
+
+func (c Config) verifyAuthenticatorData(authData *authenticatorData, requireUV bool) error {
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if subtle.ConstantTimeCompare(authData.rpIDHash, rpIDHash[:]) != 1 {
+		return ErrRPID
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return ErrUserPresence
+	}
+	if requireUV && authData.flags&flagUserVerified == 0 {
+		return ErrUserVerify
+	}
+	return nil
+}
+
+// parseAuthenticatorData 解析认证器数据 rpIdHash(32) flags(1) signCount(4) [aaguid(16) credIdLen(2) credId 公钥]
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrMalformed
+	}
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.flags&flagAttested == 0 {
+		return authData, nil
+	}
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, ErrMalformed
+	}
+	authData.aaguid = rest[:16]
+	n := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if n == 0 || len(rest) < n {
+		return nil, ErrMalformed
+	}
+	authData.credentialID = rest[:n]
+	rest = rest[n:]
+	_, remaining, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	authData.publicKey = rest[:len(rest)-len(remaining)]
+	return authData, nil
+}
+
+// parsePublicKey 解析 COSE 格式公钥
+func parsePublicKey(cose []byte) (int64, crypto.PublicKey, error) {
+	obj, _, err := decodeCBOR(cose)
+	if err != nil {
+		return 0, nil, ErrMalformed
+	}
+	m, ok := obj.(map[interface{}]interface{})
+	if !ok {
+		return 0, nil, ErrMalformed
+	}
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	switch {
+	case kty == 2 && alg == AlgES256:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return 0, nil, ErrUnsupportedKey
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return alg, key, nil
+	case kty == 1 && alg == AlgEdDSA:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return alg, ed25519.PublicKey(x), nil
+	case kty == 3 && alg == AlgRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return alg, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return 0, nil, ErrUnsupportedKey
+}
+
+func verifySignature(alg int64, key crypto.PublicKey, message []byte, signature []byte) bool {
+	switch alg {
+	case AlgES256:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(key.(*ecdsa.PublicKey), digest[:], signature)
+	case AlgEdDSA:
+		return ed25519.Verify(key.(ed25519.PublicKey), message, signature)
+	case AlgRS256:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+// decode 兼容带填充与不带填充的base64url
+func decode(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
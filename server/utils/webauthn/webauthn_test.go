@@ -0,0 +1,269 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// encodeCBOR 测试用的最小CBOR编码器 映射按键的编码排序
+func encodeCBOR(v interface{}) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		case n < 1<<16:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(n))
+			return b
+		default:
+			b := []byte{major<<5 | 26, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(b[1:], uint32(n))
+			return b
+		}
+	}
+	switch x := v.(type) {
+	case int:
+		if x < 0 {
+			return head(1, uint64(-1-x))
+		}
+		return head(0, uint64(x))
+	case []byte:
+		return append(head(2, uint64(len(x))), x...)
+	case string:
+		return append(head(3, uint64(len(x))), x...)
+	case []interface{}:
+		out := head(4, uint64(len(x)))
+		for _, item := range x {
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	case map[interface{}]interface{}:
+		type pair struct{ k, v []byte }
+		pairs := make([]pair, 0, len(x))
+		for k, val := range x {
+			pairs = append(pairs, pair{encodeCBOR(k), encodeCBOR(val)})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return bytes.Compare(pairs[i].k, pairs[j].k) < 0 })
+		out := head(5, uint64(len(x)))
+		for _, p := range pairs {
+			out = append(append(out, p.k...), p.v...)
+		}
+		return out
+	}
+	panic("unsupported cbor type")
+}
+
+// softAuthenticator 软件认证器 模拟浏览器与认证器完成注册与断言
+type softAuthenticator struct {
+	alg       int
+	signer    crypto.Signer
+	credID    []byte
+	signCount uint32
+	flags     byte
+}
+
+func newSoftAuthenticator(t *testing.T, alg int) *softAuthenticator {
+	a := &softAuthenticator{alg: alg, credID: make([]byte, 16), flags: flagUserPresent | flagUserVerified}
+	_, _ = rand.Read(a.credID)
+	var err error
+	switch alg {
+	case AlgES256:
+		a.signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, a.signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	switch pub := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		x, y := make([]byte, 32), make([]byte, 32)
+		pub.X.FillBytes(x)
+		pub.Y.FillBytes(y)
+		return encodeCBOR(map[interface{}]interface{}{1: 2, 3: AlgES256, -1: 1, -2: x, -3: y})
+	case ed25519.PublicKey:
+		return encodeCBOR(map[interface{}]interface{}{1: 1, 3: AlgEdDSA, -1: 6, -2: []byte(pub)})
+	}
+	return nil
+}
+
+func (a *softAuthenticator) authData(rpID string, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	out := append([]byte(nil), rpIDHash[:]...)
+	flags := a.flags
+	if attested {
+		flags |= flagAttested
+	}
+	out = append(out, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(out[33:], a.signCount)
+	if attested {
+		out = append(out, make([]byte, 16)...)
+		out = append(out, byte(len(a.credID)>>8), byte(len(a.credID)))
+		out = append(out, a.credID...)
+		out = append(out, a.coseKey()...)
+	}
+	return out
+}
+
+func clientDataJSON(typ, challenge, origin string) []byte {
+	b, _ := json.Marshal(clientData{Type: typ, Challenge: challenge, Origin: origin})
+	return b
+}
+
+func (a *softAuthenticator) create(rpID, challenge, origin string) AttestationResponse {
+	var resp AttestationResponse
+	id := base64.RawURLEncoding.EncodeToString(a.credID)
+	resp.ID, resp.RawID, resp.Type = id, id, "public-key"
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientDataJSON("webauthn.create", challenge, origin))
+	resp.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": a.authData(rpID, true),
+	}))
+	return resp
+}
+
+func (a *softAuthenticator) get(t *testing.T, rpID, challenge, origin string, userHandle []byte) AssertionResponse {
+	a.signCount++
+	authData := a.authData(rpID, false)
+	cd := clientDataJSON("webauthn.get", challenge, origin)
+	hash := sha256.Sum256(cd)
+	message := append(append([]byte(nil), authData...), hash[:]...)
+	var sig []byte
+	var err error
+	if a.alg == AlgEdDSA {
+		sig, err = a.signer.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		sig, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp AssertionResponse
+	id := base64.RawURLEncoding.EncodeToString(a.credID)
+	resp.ID, resp.RawID, resp.Type = id, id, "public-key"
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(cd)
+	resp.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	resp.Response.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	resp.Response.UserHandle = base64.RawURLEncoding.EncodeToString(userHandle)
+	return resp
+}
+
+var testConfig = Config{RPID: "admin.example.com", RPName: "gin-vue-admin", Origins: []string{"https://admin.example.com"}}
+
+const testOrigin = "https://admin.example.com"
+
+func TestRegisterAndLogin(t *testing.T) {
+	for _, alg := range []int{AlgES256, AlgEdDSA} {
+		a := newSoftAuthenticator(t, alg)
+		challenge, _ := NewChallenge()
+		cred, err := testConfig.VerifyRegistration(a.create(testConfig.RPID, challenge, testOrigin), challenge, true)
+		if err != nil {
+			t.Fatalf("alg %d: 注册失败: %v", alg, err)
+		}
+		if !bytes.Equal(cred.ID, a.credID) {
+			t.Fatalf("alg %d: 凭证ID不一致", alg)
+		}
+		count := cred.SignCount
+		for i := 0; i < 2; i++ {
+			challenge, _ = NewChallenge()
+			resp := a.get(t, testConfig.RPID, challenge, testOrigin, []byte("user-handle"))
+			id, err := resp.CredentialID()
+			if err != nil || !bytes.Equal(id, cred.ID) {
+				t.Fatalf("alg %d: 凭证ID解析失败: %v", alg, err)
+			}
+			if handle, _ := resp.UserHandle(); string(handle) != "user-handle" {
+				t.Fatalf("alg %d: 用户标识解析失败", alg)
+			}
+			if count, err = testConfig.VerifyAssertion(resp, challenge, cred.PublicKey, count, true); err != nil {
+				t.Fatalf("alg %d: 断言失败: %v", alg, err)
+			}
+		}
+		if count != a.signCount {
+			t.Fatalf("alg %d: 签名计数 %d, 期望 %d", alg, count, a.signCount)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	a := newSoftAuthenticator(t, AlgES256)
+	challenge, _ := NewChallenge()
+	cred, err := testConfig.VerifyRegistration(a.create(testConfig.RPID, challenge, testOrigin), challenge, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := NewChallenge()
+	if _, err = testConfig.VerifyRegistration(a.create(testConfig.RPID, other, testOrigin), challenge, true); !errors.Is(err, ErrChallenge) {
+		t.Fatalf("挑战不匹配应失败, got %v", err)
+	}
+	if _, err = testConfig.VerifyRegistration(a.create(testConfig.RPID, challenge, "https://evil.example.com"), challenge, true); !errors.Is(err, ErrOrigin) {
+		t.Fatalf("来源不受信任应失败, got %v", err)
+	}
+	if _, err = testConfig.VerifyRegistration(a.create("evil.example.com", challenge, testOrigin), challenge, true); !errors.Is(err, ErrRPID) {
+		t.Fatalf("依赖方ID不匹配应失败, got %v", err)
+	}
+
+	resp := a.get(t, testConfig.RPID, challenge, testOrigin, nil)
+	count, err := testConfig.VerifyAssertion(resp, challenge, cred.PublicKey, cred.SignCount, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 重放同一断言
+	if _, err = testConfig.VerifyAssertion(resp, challenge, cred.PublicKey, count, true); !errors.Is(err, ErrSignCount) {
+		t.Fatalf("计数未增加应失败, got %v", err)
+	}
+	// 篡改签名
+	resp = a.get(t, testConfig.RPID, challenge, testOrigin, nil)
+	resp.Response.Signature = base64.RawURLEncoding.EncodeToString([]byte("bad signature"))
+	if _, err = testConfig.VerifyAssertion(resp, challenge, cred.PublicKey, count, true); !errors.Is(err, ErrSignature) {
+		t.Fatalf("签名无效应失败, got %v", err)
+	}
+	// 其他认证器的签名
+	b := newSoftAuthenticator(t, AlgES256)
+	b.credID = a.credID
+	b.signCount = 100
+	if _, err = testConfig.VerifyAssertion(b.get(t, testConfig.RPID, challenge, testOrigin, nil), challenge, cred.PublicKey, count, true); !errors.Is(err, ErrSignature) {
+		t.Fatalf("公钥不匹配应失败, got %v", err)
+	}
+	// 未完成用户验证
+	a.flags = flagUserPresent
+	resp = a.get(t, testConfig.RPID, challenge, testOrigin, nil)
+	if _, err = testConfig.VerifyAssertion(resp, challenge, cred.PublicKey, count, true); !errors.Is(err, ErrUserVerify) {
+		t.Fatalf("要求用户验证时应失败, got %v", err)
+	}
+	if _, err = testConfig.VerifyAssertion(resp, challenge, cred.PublicKey, count, false); err != nil {
+		t.Fatalf("不要求用户验证时应成功, got %v", err)
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	for _, data := range [][]byte{
+		{},
+		{0x5f},                   // 不定长字节串
+		{0x42, 0x01},             // 长度不足
+		{0xa1, 0x41, 0x00, 0x01}, // 字节串作为映射键
+		bytes.Repeat([]byte{0x81}, 32),
+	} {
+		if _, _, err := decodeCBOR(data); err == nil {
+			t.Fatalf("% x 应解码失败", data)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+import service from '@/utils/request'
+
+// @Tags SysAuditLog
+// @Summary 分页获取审计日志
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query request.SysAuditLogSearch true "页码, 每页大小, 搜索条件"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"获取成功"}"
+// @Router /auditLog/getAuditLogList [get]
+export const getAuditLogList = (params) => {
+  return service({
+    url: '/auditLog/getAuditLogList',
+    method: 'get',
+    params
+  })
+}
@@ -83,3 +83,84 @@ export const updateAuthority = (data) => {
     data
   })
 }
+
+// @Summary 设置角色是否强制两步验证
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body {authorityId:"number",requireTwoFactor:"bool"}
+// @Router /authority/setTwoFactor [post]
+export const setAuthorityTwoFactor = (data) => {
+  return service({
+    url: '/authority/setTwoFactor',
+    method: 'post',
+    data
+  })
+}
+
+// @Summary 设置角色最大同时在线会话数
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body {authorityId:"number",maxSessions:"number"}
+// @Router /authority/setMaxSessions [post]
+export const setAuthorityMaxSessions = (data) => {
+  return service({
+    url: '/authority/setMaxSessions',
+    method: 'post',
+    data
+  })
+}
+
+// @Summary 设置角色的数据范围
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body {authorityId:"number",dataScope:"string",dataScopeRule:"string"}
+// @Router /authority/setDataScope [post]
+export const setAuthorityDataScope = (data) => {
+  return service({
+    url: '/authority/setDataScope',
+    method: 'post',
+    data
+  })
+}
+
+// @Summary 设置角色的字段规则
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body {authorityId:"number",rules:"[{model:string,field:string,rule:string}]"}
+// @Router /authority/setFieldRules [post]
+export const setAuthorityFieldRules = (data) => {
+  return service({
+    url: '/authority/setFieldRules',
+    method: 'post',
+    data
+  })
+}
+
+// @Summary 获取角色的字段规则
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body {authorityId:"number"}
+// @Router /authority/getFieldRules [post]
+export const getAuthorityFieldRules = (data) => {
+  return service({
+    url: '/authority/getFieldRules',
+    method: 'post',
+    data
+  })
+}
+
+// @Summary 获取支持字段规则的模型及字段
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Router /authority/getFieldRuleModels [get]
+export const getFieldRuleModels = () => {
+  return service({
+    url: '/authority/getFieldRuleModels',
+    method: 'get'
+  })
+}
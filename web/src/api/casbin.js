@@ -30,3 +30,68 @@ export const getPolicyPathByAuthorityId = (data) => {
     data
   })
 }
+
+// @Tags casbin
+// @Summary 模拟权限判定
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body {userId:number,authorityId:number,path:string,method:string} true "用户ID或角色ID, 路径, 方法"
+// @Success 200 {string} json "{"success":true,"data":{},"msg":"查询成功"}"
+// @Router /casbin/explainPermission [post]
+export const explainPermission = (data) => {
+  return service({
+    url: '/casbin/explainPermission',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags casbin
+// @Summary 导出全部p策略及api为策略文件
+// @Security ApiKeyAuth
+// @Produce application/octet-stream
+// @Param format query string false "文件格式 json或yaml"
+// @Router /casbin/exportPolicy [get]
+export const exportPolicy = (params) => {
+  return service({
+    url: '/casbin/exportPolicy',
+    method: 'get',
+    params,
+    responseType: 'blob'
+  })
+}
+
+// @Tags casbin
+// @Summary 比较上传的策略文件与当前环境的差异
+// @Security ApiKeyAuth
+// @accept multipart/form-data
+// @Produce application/json
+// @Param file formData file true "策略文件 json或yaml"
+// @Router /casbin/diffPolicy [post]
+export const diffPolicy = (data) => {
+  return service({
+    url: '/casbin/diffPolicy',
+    method: 'post',
+    headers: { 'Content-Type': 'multipart/form-data' },
+    data
+  })
+}
+
+// @Tags casbin
+// @Summary 导入策略文件 dryRun为true时仅预演
+// @Security ApiKeyAuth
+// @accept multipart/form-data
+// @Produce application/json
+// @Param file formData file true "策略文件 json或yaml"
+// @Param dryRun formData bool false "是否仅预演"
+// @Param prune formData bool false "是否删除文件中不存在的api及策略 默认仅新增及更新"
+// @Router /casbin/importPolicy [post]
+export const importPolicy = (data) => {
+  return service({
+    url: '/casbin/importPolicy',
+    method: 'post',
+    headers: { 'Content-Type': 'multipart/form-data' },
+    data
+  })
+}
@@ -0,0 +1,126 @@
+import service from '@/utils/request'
+
+// @Tags SysDept
+// @Summary 新建部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysDept true "上级部门ID, 部门名称, 排序, 负责人ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/createDept [post]
+export const createDept = (data) => {
+  return service({
+    url: '/dept/createDept',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags SysDept
+// @Summary 更新部门名称、排序及负责人
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysDept true "部门ID, 部门名称, 排序, 负责人ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/updateDept [put]
+export const updateDept = (data) => {
+  return service({
+    url: '/dept/updateDept',
+    method: 'put',
+    data
+  })
+}
+
+// @Tags SysDept
+// @Summary 删除部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "部门ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/deleteDept [post]
+export const deleteDept = (data) => {
+  return service({
+    url: '/dept/deleteDept',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags SysDept
+// @Summary 调整部门的上级部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.MoveDept true "部门ID, 新的上级部门ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/moveDept [post]
+export const moveDept = (data) => {
+  return service({
+    url: '/dept/moveDept',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags SysDept
+// @Summary 将部门合并到另一部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.MergeDept true "被合并的部门ID, 合并到的部门ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/mergeDept [post]
+export const mergeDept = (data) => {
+  return service({
+    url: '/dept/mergeDept',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags SysDept
+// @Summary 获取部门树
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/getDeptTree [get]
+export const getDeptTree = () => {
+  return service({
+    url: '/dept/getDeptTree',
+    method: 'get'
+  })
+}
+
+// @Tags SysDept
+// @Summary 设置用户的主部门及次部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.SetUserDepts true "用户ID, 主部门ID, 次部门ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/setUserDepts [post]
+export const setUserDepts = (data) => {
+  return service({
+    url: '/dept/setUserDepts',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags SysDept
+// @Summary 获取用户所属部门
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "用户ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"成功"}"
+// @Router /dept/getUserDepts [post]
+export const getUserDepts = (data) => {
+  return service({
+    url: '/dept/getUserDepts',
+    method: 'post',
+    data
+  })
+}
@@ -12,3 +12,27 @@ export const jsonInBlacklist = () => {
     method: 'post'
   })
 }
+
+// @Tags jwt
+// @Summary 立即轮换jwt签名密钥
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Router /jwt/rotateKey [post]
+export const rotateKey = () => {
+  return service({
+    url: '/jwt/rotateKey',
+    method: 'post'
+  })
+}
+
+// @Tags jwt
+// @Summary 获取可用于验签的jwt签名密钥
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Router /jwt/getKeyList [get]
+export const getKeyList = () => {
+  return service({
+    url: '/jwt/getKeyList',
+    method: 'get'
+  })
+}
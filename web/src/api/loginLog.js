@@ -0,0 +1,30 @@
+import service from '@/utils/request'
+
+// @Tags SysLoginLog
+// @Summary 分页获取登录日志
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query request.SysLoginLogSearch true "页码, 每页大小, 搜索条件"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"获取成功"}"
+// @Router /loginLog/getLoginLogList [get]
+export const getLoginLogList = (params) => {
+  return service({
+    url: '/loginLog/getLoginLogList',
+    method: 'get',
+    params
+  })
+}
+
+// @Tags SysLoginLog
+// @Summary 获取自身最近的登录记录
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Success 200 {string} string "{"success":true,"data":[],"msg":"获取成功"}"
+// @Router /loginLog/getRecentLogins [get]
+export const getRecentLogins = () => {
+  return service({
+    url: '/loginLog/getRecentLogins',
+    method: 'get'
+  })
+}
@@ -0,0 +1,48 @@
+import service from '@/utils/request'
+
+// @Tags PermissionChange
+// @Summary 分页获取权限变更申请
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Param data query systemReq.PermissionChangeSearch true "页码, 每页大小, 类型, 状态"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"获取成功"}"
+// @Router /permissionChange/getChangeList [get]
+export const getChangeList = (params) => {
+  return service({
+    url: '/permissionChange/getChangeList',
+    method: 'get',
+    params
+  })
+}
+
+// @Tags PermissionChange
+// @Summary 审批通过权限变更
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.DecidePermissionChange true "申请ID, 审批意见"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"审批成功"}"
+// @Router /permissionChange/approveChange [post]
+export const approveChange = (data) => {
+  return service({
+    url: '/permissionChange/approveChange',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags PermissionChange
+// @Summary 驳回权限变更 申请人可撤回自己的申请
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.DecidePermissionChange true "申请ID, 驳回意见"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"驳回成功"}"
+// @Router /permissionChange/rejectChange [post]
+export const rejectChange = (data) => {
+  return service({
+    url: '/permissionChange/rejectChange',
+    method: 'post',
+    data
+  })
+}
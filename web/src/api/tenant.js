@@ -0,0 +1,64 @@
+import service from '@/utils/request'
+
+// @Tags Tenant
+// @Summary 创建租户 仅超级管理员可用
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysTenant true "租户名称, 租户编码, 备注"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"创建成功"}"
+// @Router /tenant/createTenant [post]
+export const createTenant = (data) => {
+  return service({
+    url: '/tenant/createTenant',
+    method: 'post',
+    data
+  })
+}
+
+// @Tags Tenant
+// @Summary 更新租户名称、状态及备注 仅超级管理员可用
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysTenant true "租户ID, 租户名称, 状态, 备注"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"更新成功"}"
+// @Router /tenant/updateTenant [put]
+export const updateTenant = (data) => {
+  return service({
+    url: '/tenant/updateTenant',
+    method: 'put',
+    data
+  })
+}
+
+// @Tags Tenant
+// @Summary 分页获取租户 仅超级管理员可用
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Param data query systemReq.TenantSearch true "页码, 每页大小, 租户名称或编码"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"获取成功"}"
+// @Router /tenant/getTenantList [get]
+export const getTenantList = (params) => {
+  return service({
+    url: '/tenant/getTenantList',
+    method: 'get',
+    params
+  })
+}
+
+// @Tags Tenant
+// @Summary 超级管理员切换当前会话的租户 返回新的令牌
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.SwitchTenant true "租户ID"
+// @Success 200 {string} string "{"success":true,"data":{},"msg":"切换成功"}"
+// @Router /tenant/switchTenant [post]
+export const switchTenant = (data) => {
+  return service({
+    url: '/tenant/switchTenant',
+    method: 'post',
+    data
+  })
+}
@@ -179,3 +179,486 @@ export const resetPassword = (data) => {
     data: data
   })
 }
+
+// @Summary 登录二次验证
+// @Produce  application/json
+// @Param data body {twoFactorToken:"string",code:"string",recoveryCode:"string"}
+// @Router /base/loginTwoFactor [post]
+export const loginTwoFactor = (data) => {
+  return service({
+    url: '/base/loginTwoFactor',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 登录过程中绑定两步验证
+// @Produce  application/json
+// @Param data body {twoFactorToken:"string"}
+// @Router /base/twoFactorSetup [post]
+export const twoFactorSetup = (data) => {
+  return service({
+    url: '/base/twoFactorSetup',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Tags User
+// @Summary 获取两步验证绑定信息
+// @Security ApiKeyAuth
+// @Router /user/totpSetup [post]
+export const totpSetup = () => {
+  return service({
+    url: '/user/totpSetup',
+    method: 'post'
+  })
+}
+
+// @Tags User
+// @Summary 开启两步验证
+// @Security ApiKeyAuth
+// @Param data body {code:"string"}
+// @Router /user/totpEnable [post]
+export const totpEnable = (data) => {
+  return service({
+    url: '/user/totpEnable',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Tags User
+// @Summary 关闭两步验证
+// @Security ApiKeyAuth
+// @Param data body {code:"string",recoveryCode:"string"}
+// @Router /user/totpDisable [post]
+export const totpDisable = (data) => {
+  return service({
+    url: '/user/totpDisable',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Tags User
+// @Summary 重新生成恢复码
+// @Security ApiKeyAuth
+// @Param data body {code:"string"}
+// @Router /user/regenerateRecoveryCodes [post]
+export const regenerateRecoveryCodes = (data) => {
+  return service({
+    url: '/user/regenerateRecoveryCodes',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Tags User
+// @Summary 重置用户两步验证
+// @Security ApiKeyAuth
+// @Param data body {id:"number"}
+// @Router /user/resetTotp [post]
+export const resetTotp = (data) => {
+  return service({
+    url: '/user/resetTotp',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取OIDC单点登录授权地址
+// @Produce  application/json
+// @Router /base/oidcAuthUrl [get]
+export const oidcAuthUrl = () => {
+  return service({
+    url: '/base/oidcAuthUrl',
+    method: 'get'
+  })
+}
+
+// @Summary OIDC单点登录回调
+// @Produce  application/json
+// @Param data body {code:"string",state:"string"}
+// @Router /base/oidcLogin [post]
+export const oidcLogin = (data) => {
+  return service({
+    url: '/base/oidcLogin',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 登录过程中修改已过期或被要求修改的密码
+// @Produce  application/json
+// @Param data body {passwordToken:"string",newPassword:"string"}
+// @Router /base/changeExpiredPassword [post]
+export const changeExpiredPassword = (data) => {
+  return service({
+    url: '/base/changeExpiredPassword',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 申请找回密码 向账号绑定的邮箱发送重置链接
+// @Produce  application/json
+// @Param data body {account:"string"}
+// @Router /base/forgotPassword [post]
+export const forgotPassword = (data) => {
+  return service({
+    url: '/base/forgotPassword',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 通过找回密码邮件中的链接重置密码
+// @Produce  application/json
+// @Param data body {token:"string",newPassword:"string"}
+// @Router /base/resetPasswordByToken [post]
+export const resetPasswordByToken = (data) => {
+  return service({
+    url: '/base/resetPasswordByToken',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 使用刷新令牌换取新的访问令牌
+// @Produce  application/json
+// @Param data body {refreshToken:"string"}
+// @Router /base/refresh [post]
+export const refreshToken = (data) => {
+  return service({
+    url: '/base/refresh',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取自身登录会话
+// @Produce  application/json
+// @Router /user/getSessionList [get]
+export const getSessionList = () => {
+  return service({
+    url: '/user/getSessionList',
+    method: 'get'
+  })
+}
+
+// @Summary 注销自身登录会话
+// @Produce  application/json
+// @Param data body {sessionId:"string"}
+// @Router /user/revokeSession [post]
+export const revokeSession = (data) => {
+  return service({
+    url: '/user/revokeSession',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取用户登录会话
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/getUserSessionList [post]
+export const getUserSessionList = (data) => {
+  return service({
+    url: '/user/getUserSessionList',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 注销用户登录会话 sessionId为空时注销全部
+// @Produce  application/json
+// @Param data body {id:"number",sessionId:"string"}
+// @Router /user/revokeUserSession [post]
+export const revokeUserSession = (data) => {
+  return service({
+    url: '/user/revokeUserSession',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 分页获取被锁定的账号
+// @Produce  application/json
+// @Param data body {page:"number",pageSize:"number",keyword:"string"}
+// @Router /user/getLockoutList [post]
+export const getLockoutList = (data) => {
+  return service({
+    url: '/user/getLockoutList',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 解锁被锁定的账号
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/unlockUser [post]
+export const unlockUser = (data) => {
+  return service({
+    url: '/user/unlockUser',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 创建自身API密钥 密钥明文仅返回一次
+// @Produce  application/json
+// @Param data body {name:"string",scopes:"array",expireDays:"number"}
+// @Router /user/createApiKey [post]
+export const createApiKey = (data) => {
+  return service({
+    url: '/user/createApiKey',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取自身API密钥
+// @Produce  application/json
+// @Router /user/getApiKeyList [get]
+export const getApiKeyList = () => {
+  return service({
+    url: '/user/getApiKeyList',
+    method: 'get'
+  })
+}
+
+// @Summary 删除自身API密钥
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/deleteApiKey [delete]
+export const deleteApiKey = (data) => {
+  return service({
+    url: '/user/deleteApiKey',
+    method: 'delete',
+    data: data
+  })
+}
+
+// @Summary 获取用户API密钥
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/getUserApiKeyList [post]
+export const getUserApiKeyList = (data) => {
+  return service({
+    url: '/user/getUserApiKeyList',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 删除用户API密钥
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/deleteUserApiKey [delete]
+export const deleteUserApiKey = (data) => {
+  return service({
+    url: '/user/deleteUserApiKey',
+    method: 'delete',
+    data: data
+  })
+}
+
+// @Summary 模拟登录为指定用户 返回的令牌同时携带管理员身份
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/impersonate [post]
+export const impersonate = (data) => {
+  return service({
+    url: '/user/impersonate',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 结束模拟登录 返回管理员的新令牌
+// @Produce  application/json
+// @Router /user/endImpersonate [post]
+export const endImpersonate = () => {
+  return service({
+    url: '/user/endImpersonate',
+    method: 'post'
+  })
+}
+
+// @Summary 用户自助注册
+// @Produce  application/json
+// @Param data body {userName:"string",passWord:"string",nickName:"string",email:"string",captcha:"string",captchaId:"string"}
+// @Router /base/register [post]
+export const selfRegister = (data) => {
+  return service({
+    url: '/base/register',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 验证注册邮箱
+// @Produce  application/json
+// @Param data body {token:"string"}
+// @Router /base/verifyRegisterEmail [post]
+export const verifyRegisterEmail = (data) => {
+  return service({
+    url: '/base/verifyRegisterEmail',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 分页获取自助注册记录
+// @Produce  application/json
+// @Param data body {page:"number",pageSize:"number",status:"string",keyword:"string"}
+// @Router /user/getRegistrationList [post]
+export const getRegistrationList = (data) => {
+  return service({
+    url: '/user/getRegistrationList',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 审核自助注册
+// @Produce  application/json
+// @Param data body {id:"number",approve:"boolean",remark:"string"}
+// @Router /user/reviewRegistration [post]
+export const reviewRegistration = (data) => {
+  return service({
+    url: '/user/reviewRegistration',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取通行密钥注册参数
+// @Produce  application/json
+// @Router /user/passkeyRegisterBegin [post]
+export const passkeyRegisterBegin = () => {
+  return service({
+    url: '/user/passkeyRegisterBegin',
+    method: 'post'
+  })
+}
+
+// @Summary 绑定通行密钥
+// @Produce  application/json
+// @Param data body {sessionId:"string",name:"string",credential:"object"}
+// @Router /user/passkeyRegisterFinish [post]
+export const passkeyRegisterFinish = (data) => {
+  return service({
+    url: '/user/passkeyRegisterFinish',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取自身通行密钥
+// @Produce  application/json
+// @Router /user/getPasskeyList [get]
+export const getPasskeyList = () => {
+  return service({
+    url: '/user/getPasskeyList',
+    method: 'get'
+  })
+}
+
+// @Summary 修改通行密钥名称
+// @Produce  application/json
+// @Param data body {id:"number",name:"string"}
+// @Router /user/updatePasskey [put]
+export const updatePasskey = (data) => {
+  return service({
+    url: '/user/updatePasskey',
+    method: 'put',
+    data: data
+  })
+}
+
+// @Summary 删除通行密钥
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/deletePasskey [delete]
+export const deletePasskey = (data) => {
+  return service({
+    url: '/user/deletePasskey',
+    method: 'delete',
+    data: data
+  })
+}
+
+// @Summary 授予用户限时角色
+// @Produce  application/json
+// @Param data body {id:"number",authorityId:"number",validFrom:"string",validUntil:"string",reason:"string"}
+// @Router /user/grantAuthority [post]
+export const grantAuthority = (data) => {
+  return service({
+    url: '/user/grantAuthority',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 收回用户限时角色
+// @Produce  application/json
+// @Param data body {id:"number",authorityId:"number"}
+// @Router /user/revokeAuthorityGrant [post]
+export const revokeAuthorityGrant = (data) => {
+  return service({
+    url: '/user/revokeAuthorityGrant',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取用户的角色授权及有效期
+// @Produce  application/json
+// @Param data body {id:"number"}
+// @Router /user/getAuthorityGrants [post]
+export const getAuthorityGrants = (data) => {
+  return service({
+    url: '/user/getAuthorityGrants',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取通行密钥登录参数
+// @Produce  application/json
+// @Param data body {username:"string"}
+// @Router /base/passkeyLoginBegin [post]
+export const passkeyLoginBegin = (data) => {
+  return service({
+    url: '/base/passkeyLoginBegin',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 使用通行密钥登录
+// @Produce  application/json
+// @Param data body {sessionId:"string",credential:"object"}
+// @Router /base/passkeyLogin [post]
+export const passkeyLogin = (data) => {
+  return service({
+    url: '/base/passkeyLogin',
+    method: 'post',
+    data: data
+  })
+}
+
+// @Summary 获取通行密钥二次验证参数
+// @Produce  application/json
+// @Param data body {twoFactorToken:"string"}
+// @Router /base/passkeyTwoFactorBegin [post]
+export const passkeyTwoFactorBegin = (data) => {
+  return service({
+    url: '/base/passkeyTwoFactorBegin',
+    method: 'post',
+    data: data
+  })
+}
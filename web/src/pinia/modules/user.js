@@ -1,4 +1,9 @@
-import { login, getUserInfo } from '@/api/user'
+import {
+  login,
+  loginTwoFactor,
+  changeExpiredPassword,
+  getUserInfo
+} from '@/api/user'
 import { jsonInBlacklist } from '@/api/jwt'
 import router from '@/router/index'
 import { ElLoading, ElMessage } from 'element-plus'
@@ -23,6 +28,7 @@ export const useUserStore = defineStore('user', () => {
   const token = useStorage('token', '')
   const xToken = useCookies('x-token')
   const currentToken = computed(() => token.value || xToken.value || '')
+  const refreshToken = useStorage('refreshToken', '')
 
   const setUserInfo = (val) => {
     userInfo.value = val
@@ -41,6 +47,10 @@ export const useUserStore = defineStore('user', () => {
     xToken.value = val
   }
 
+  const setRefreshToken = (val) => {
+    refreshToken.value = val
+  }
+
   const NeedInit = async () => {
     await ClearStorage()
     await router.push({ name: 'Init', replace: true })
@@ -60,23 +70,27 @@ export const useUserStore = defineStore('user', () => {
     }
     return res
   }
-  /* 登录*/
-  const LoginIn = async (loginInfo) => {
+  /* 登录 需要二次验证或修改密码时返回票据信息 由登录页继续后续步骤*/
+  const loginNext = async (request, data) => {
     try {
       loadingInstance.value = ElLoading.service({
         fullscreen: true,
         text: '登录中，请稍候...'
       })
 
-      const res = await login(loginInfo)
+      const res = await request(data)
 
       if (res.code !== 0) {
         ElMessage.error(res.message || '登录失败')
         return false
       }
+      if (res.data.needTwoFactor || res.data.needChangePassword) {
+        return res.data
+      }
       // 登陆成功，设置用户信息和权限相关信息
       setUserInfo(res.data.user)
       setToken(res.data.token)
+      setRefreshToken(res.data.refreshToken)
 
       // 初始化路由信息
       const routerStore = useRouterStore()
@@ -106,6 +120,11 @@ export const useUserStore = defineStore('user', () => {
       loadingInstance.value?.close()
     }
   }
+  const LoginIn = (loginInfo) => loginNext(login, loginInfo)
+  /* 登录二次验证*/
+  const LoginTwoFactor = (data) => loginNext(loginTwoFactor, data)
+  /* 登录过程中修改密码*/
+  const ChangeExpiredPassword = (data) => loginNext(changeExpiredPassword, data)
   /* 登出*/
   const LoginOut = async () => {
     const res = await jsonInBlacklist()
@@ -125,6 +144,7 @@ export const useUserStore = defineStore('user', () => {
   const ClearStorage = async () => {
     token.value = ''
     xToken.value = ''
+    refreshToken.value = ''
     sessionStorage.clear()
     localStorage.removeItem('originSetting')
   }
@@ -132,12 +152,16 @@ export const useUserStore = defineStore('user', () => {
   return {
     userInfo,
     token: currentToken,
+    refreshToken,
     NeedInit,
     ResetUserInfo,
     GetUserInfo,
     LoginIn,
+    LoginTwoFactor,
+    ChangeExpiredPassword,
     LoginOut,
     setToken,
+    setRefreshToken,
     loadingInstance,
     ClearStorage
   }
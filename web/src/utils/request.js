@@ -64,16 +64,60 @@ service.interceptors.request.use(
   }
 )
 
+const REFRESH_URL = '/base/refresh'
+let refreshing = null
+// 访问令牌过期后使用刷新令牌换取新令牌 并发请求共用同一次刷新
+const refreshAccessToken = () => {
+  if (!refreshing) {
+    const userStore = useUserStore()
+    refreshing = service({
+      url: REFRESH_URL,
+      method: 'post',
+      data: { refreshToken: userStore.refreshToken },
+      donNotShowLoading: true
+    })
+      .then((res) => {
+        if (res.code !== 0) {
+          return false
+        }
+        userStore.setToken(res.data.token)
+        userStore.setRefreshToken(res.data.refreshToken)
+        return true
+      })
+      .finally(() => {
+        refreshing = null
+      })
+  }
+  return refreshing
+}
+
+const reLogin = (error) => {
+  ElMessageBox.confirm(
+    `
+    <p>无效的令牌</p>
+    <p>错误码:<span style="color:red"> 401 </span>错误信息:${error}</p>
+    `,
+    '身份信息',
+    {
+      dangerouslyUseHTMLString: true,
+      distinguishCancelAndClose: true,
+      confirmButtonText: '重新登录',
+      cancelButtonText: '取消'
+    }
+  ).then(() => {
+    const userStore = useUserStore()
+    userStore.ClearStorage()
+    router.push({ name: 'Login', replace: true })
+  })
+  return error
+}
+
 // http response 拦截器
 service.interceptors.response.use(
   (response) => {
-    const userStore = useUserStore()
     if (!response.config.donNotShowLoading) {
       closeLoading()
     }
-    if (response.headers['new-token']) {
-      userStore.setToken(response.headers['new-token'])
-    }
     if (response.data.code === 0 || response.headers.success === 'true') {
       if (response.headers.msg) {
         response.data.msg = decodeURI(response.headers.msg)
@@ -145,25 +189,23 @@ service.interceptors.response.use(
           }
         )
         break
-      case 401:
-        ElMessageBox.confirm(
-          `
-          <p>无效的令牌</p>
-          <p>错误码:<span style="color:red"> 401 </span>错误信息:${error}</p>
-          `,
-          '身份信息',
-          {
-            dangerouslyUseHTMLString: true,
-            distinguishCancelAndClose: true,
-            confirmButtonText: '重新登录',
-            cancelButtonText: '取消'
-          }
-        ).then(() => {
-          const userStore = useUserStore()
-          userStore.ClearStorage()
-          router.push({ name: 'Login', replace: true })
-        })
-        break
+      case 401: {
+        if (error.config.url === REFRESH_URL) {
+          return error
+        }
+        const userStore = useUserStore()
+        if (!error.config.isRetry && userStore.refreshToken) {
+          return refreshAccessToken().then((ok) => {
+            if (!ok) {
+              return reLogin(error)
+            }
+            error.config.isRetry = true
+            error.config.headers['x-token'] = userStore.token
+            return service(error.config)
+          })
+        }
+        return reLogin(error)
+      }
     }
 
     return error